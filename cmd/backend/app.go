@@ -0,0 +1,118 @@
+package main
+
+import (
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+
+	pkgForum "github.com/SlavaShagalov/vk-dbms-project/internal/forum"
+	forumGRPC "github.com/SlavaShagalov/vk-dbms-project/internal/forum/delivery/grpc"
+	forumDelivery "github.com/SlavaShagalov/vk-dbms-project/internal/forum/delivery/http"
+	forumService "github.com/SlavaShagalov/vk-dbms-project/internal/forum/service"
+	moderationDelivery "github.com/SlavaShagalov/vk-dbms-project/internal/moderation/delivery/http"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/config"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/filter"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/metrics"
+	mw "github.com/SlavaShagalov/vk-dbms-project/internal/pkg/middleware"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/ratelimit"
+	pkgPost "github.com/SlavaShagalov/vk-dbms-project/internal/post"
+	postGRPC "github.com/SlavaShagalov/vk-dbms-project/internal/post/delivery/grpc"
+	postDelivery "github.com/SlavaShagalov/vk-dbms-project/internal/post/delivery/http"
+	postService "github.com/SlavaShagalov/vk-dbms-project/internal/post/service"
+	pkgService "github.com/SlavaShagalov/vk-dbms-project/internal/service"
+	serviceGRPC "github.com/SlavaShagalov/vk-dbms-project/internal/service/delivery/grpc"
+	serviceDelivery "github.com/SlavaShagalov/vk-dbms-project/internal/service/delivery/http"
+	serviceService "github.com/SlavaShagalov/vk-dbms-project/internal/service/service"
+	pkgThread "github.com/SlavaShagalov/vk-dbms-project/internal/thread"
+	threadGRPC "github.com/SlavaShagalov/vk-dbms-project/internal/thread/delivery/grpc"
+	threadDelivery "github.com/SlavaShagalov/vk-dbms-project/internal/thread/delivery/http"
+	threadService "github.com/SlavaShagalov/vk-dbms-project/internal/thread/service"
+	pkgUser "github.com/SlavaShagalov/vk-dbms-project/internal/user"
+	userGRPC "github.com/SlavaShagalov/vk-dbms-project/internal/user/delivery/grpc"
+	userDelivery "github.com/SlavaShagalov/vk-dbms-project/internal/user/delivery/http"
+	userService "github.com/SlavaShagalov/vk-dbms-project/internal/user/service"
+)
+
+// services are the domain services behind the API and admin routes. They are
+// built outside main so the e2e tests run the same router.
+type services struct {
+	user    pkgUser.Service
+	forum   pkgForum.Service
+	thread  pkgThread.Service
+	post    pkgPost.Service
+	service pkgService.Service
+	reviews *filter.Queue
+}
+
+func newServices(cfg *config.Config, repos *repositories, logger *zap.Logger) *services {
+	// Content filters
+	bayes := filter.NewBayes(cfg.Filters.BayesMinDocs, cfg.Filters.BayesReview, cfg.Filters.BayesReject)
+	reviewQueue := filter.NewQueue(cfg.Filters.ReviewQueueSize, bayes)
+	var contentFilter *filter.Chain
+	if cfg.Features.ContentFilters {
+		bannedWords := make(map[string][]string, len(cfg.Filters.BannedWords)+1)
+		for forum, words := range cfg.Filters.BannedWords {
+			bannedWords[forum] = words
+		}
+		bannedWords[filter.AllForums] = append(bannedWords[filter.AllForums], cfg.Filters.GlobalWords...)
+
+		contentFilter = filter.NewChain(reviewQueue, logger,
+			filter.NewBannedWords(bannedWords, filter.Reject),
+			filter.NewLinkLimit(cfg.Filters.MaxLinks, filter.Review),
+			filter.NewDuplicate(cfg.Filters.DuplicateWindow, 100000, filter.Reject),
+			bayes,
+		)
+	}
+
+	return &services{
+		user:    userService.NewService(repos.user, logger),
+		forum:   forumService.NewService(repos.forum, contentFilter, logger),
+		thread:  threadService.NewService(repos.thread, contentFilter, logger),
+		post:    postService.NewService(repos.post, contentFilter, logger),
+		service: serviceService.NewService(repos.service, logger),
+		reviews: reviewQueue,
+	}
+}
+
+func (s *services) registerHandlers(router *mw.Router, limiter *ratelimit.Limiter, m *metrics.Metrics, logger *zap.Logger) {
+	userDelivery.RegisterHandlers(router, logger, s.user, limiter, m)
+	threadDelivery.RegisterHandlers(router, logger, s.thread, limiter, m)
+	forumDelivery.RegisterHandlers(router, logger, s.forum, limiter, m)
+	postDelivery.RegisterHandlers(router, logger, s.post, limiter, m)
+	serviceDelivery.RegisterHandlers(router, logger, s.service, m)
+}
+
+func (s *services) registerAdminHandlers(router *mw.Router, m *metrics.Metrics, logger *zap.Logger) {
+	threadDelivery.RegisterAdminHandlers(router, logger, s.thread, m)
+	forumDelivery.RegisterAdminHandlers(router, logger, s.forum, m)
+	moderationDelivery.RegisterAdminHandlers(router, logger, s.reviews, m)
+}
+
+func (s *services) registerGRPCHandlers(server *grpc.Server, limiter *ratelimit.Limiter, logger *zap.Logger) {
+	userGRPC.RegisterHandlers(server, logger, s.user)
+	forumGRPC.RegisterHandlers(server, logger, s.forum, limiter)
+	threadGRPC.RegisterHandlers(server, logger, s.thread, limiter)
+	postGRPC.RegisterHandlers(server, logger, s.post)
+	serviceGRPC.RegisterHandlers(server, logger, s.service)
+}
+
+// grpcRoutes gives the gRPC methods the timeouts and rate limits of the HTTP
+// routes they mirror.
+func grpcRoutes() mw.GRPCRoutes {
+	routes := mw.GRPCRoutes{}
+	for _, service := range []mw.GRPCRoutes{userGRPC.Routes, forumGRPC.Routes, threadGRPC.Routes, postGRPC.Routes, serviceGRPC.Routes} {
+		for method, route := range service {
+			routes[method] = route
+		}
+	}
+	return routes
+}
+
+func compressOptions(cfg *config.CompressionConfig) mw.CompressOptions {
+	return mw.CompressOptions{
+		MinSize:     cfg.MinSize,
+		Encodings:   cfg.Encodings,
+		GzipLevel:   cfg.GzipLevel,
+		BrotliLevel: cfg.BrotliLevel,
+		ZstdLevel:   cfg.ZstdLevel,
+	}
+}
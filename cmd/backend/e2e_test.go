@@ -0,0 +1,1253 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/andybalholm/brotli"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/zstd"
+	"github.com/mailru/easyjson"
+	"github.com/redis/go-redis/v9"
+	"github.com/vmihailenco/msgpack/v5"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"gopkg.in/yaml.v3"
+
+	schema "github.com/SlavaShagalov/vk-dbms-project/db"
+	"github.com/SlavaShagalov/vk-dbms-project/docs"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/models"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/cache"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/config"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/db"
+	mw "github.com/SlavaShagalov/vk-dbms-project/internal/pkg/middleware"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/migrate"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/openapi"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/pb"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/ratelimit"
+)
+
+// The e2e tests run the API router against the memory repositories. With
+// E2E_STORAGE=postgres they use a throwaway database created next to the one
+// configured by the POSTGRES_* variables instead. E2E_CACHE=memory or redis
+// puts the repository cache in front of either, Redis is an embedded stand-in.
+
+var e2ePool *pgxpool.Pool
+
+func TestMain(m *testing.M) {
+	if os.Getenv("E2E_STORAGE") != config.StoragePostgres {
+		os.Exit(m.Run())
+	}
+
+	cleanup, err := setupPostgres()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "e2e:", err)
+		os.Exit(1)
+	}
+	code := m.Run()
+	cleanup()
+	os.Exit(code)
+}
+
+func setupPostgres() (func(), error) {
+	ctx := context.Background()
+	log := zap.NewNop()
+
+	cfg, _, err := config.Load("e2e", nil)
+	if err != nil {
+		return nil, err
+	}
+	admin, err := db.NewPgxPool(&cfg.DB, log)
+	if err != nil {
+		return nil, err
+	}
+
+	name := fmt.Sprintf("forum_e2e_%d", time.Now().UnixNano())
+	if _, err = admin.Exec(ctx, "CREATE DATABASE "+name); err != nil {
+		admin.Close()
+		return nil, err
+	}
+	drop := func() {
+		if e2ePool != nil {
+			e2ePool.Close()
+		}
+		_, _ = admin.Exec(ctx, "DROP DATABASE IF EXISTS "+name+" WITH (FORCE)")
+		admin.Close()
+	}
+
+	dbCfg := cfg.DB
+	dbCfg.Name = name
+	if e2ePool, err = db.NewPgxPool(&dbCfg, log); err != nil {
+		drop()
+		return nil, err
+	}
+
+	migrationsFS, err := fs.Sub(schema.Migrations, "migrations")
+	if err != nil {
+		drop()
+		return nil, err
+	}
+	migrator, err := migrate.New(e2ePool, migrationsFS, log)
+	if err != nil {
+		drop()
+		return nil, err
+	}
+	if _, err = migrator.Up(ctx, 0); err != nil {
+		drop()
+		return nil, err
+	}
+	return drop, nil
+}
+
+// newTestStore returns a fresh cache store of the given kind, nil for none.
+func newTestStore(t *testing.T, kind string) cache.Store {
+	t.Helper()
+
+	switch kind {
+	case "":
+		return nil
+	case "memory":
+		return cache.NewMemoryStore(1000, time.Minute)
+	case "redis":
+		server, err := miniredis.Run()
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(server.Close)
+		client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+		t.Cleanup(func() { _ = client.Close() })
+		return cache.NewRedisStore(client, "e2e:", time.Minute)
+	default:
+		t.Fatalf("unknown E2E_CACHE %q", kind)
+		return nil
+	}
+}
+
+func newTestServices(store cache.Store) *services {
+	cfg := config.Default()
+	cfg.Features.Metrics = false
+	logger := zap.NewNop()
+
+	repos := newMemoryRepositories()
+	if e2ePool != nil {
+		repos = newPgxRepositories(e2ePool, logger)
+	}
+	if store != nil {
+		repos.withCache(store, nil, logger)
+	}
+	return newServices(cfg, repos, logger)
+}
+
+func newTestRouter(store cache.Store) *mw.Router {
+	logger := zap.NewNop()
+
+	router := mw.NewRouter()
+	servs := newTestServices(store)
+	servs.registerHandlers(router, nil, nil, logger)
+	servs.registerAdminHandlers(router, nil, logger)
+	return router
+}
+
+// newGRPCConn serves the gRPC API on a local port and connects to it.
+func newGRPCConn(t *testing.T, limiter *ratelimit.Limiter) *grpc.ClientConn {
+	t.Helper()
+
+	server := newGRPCServer(config.Default(), newTestServices(newTestStore(t, os.Getenv("E2E_CACHE"))), limiter, nil, zap.NewNop())
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() { _ = server.Serve(lis) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.Dial(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	if _, err = pb.NewDatabaseServiceClient(conn).Clear(context.Background(), &emptypb.Empty{}); err != nil {
+		t.Fatal(err)
+	}
+	return conn
+}
+
+type client struct {
+	t   *testing.T
+	url string
+}
+
+func newClient(t *testing.T) *client {
+	t.Helper()
+	return newClientWithStore(t, newTestStore(t, os.Getenv("E2E_CACHE")))
+}
+
+func newClientWithStore(t *testing.T, store cache.Store) *client {
+	t.Helper()
+
+	spec, err := openapi.Load(docs.Swagger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Requests that break the contract on purpose must be rejected, anything
+	// else that does not match docs/swagger.yml fails the test.
+	handler := mw.Contract(newTestRouter(store), spec, func(r *http.Request, status int, violations []openapi.Violation) {
+		for _, violation := range violations {
+			if violation.Kind == openapi.KindRequest && status >= http.StatusBadRequest {
+				continue
+			}
+			t.Errorf("%s %s: %d: %v", r.Method, r.URL, status, violation)
+		}
+	})
+
+	// The Go client asks for gzip on its own, so the tests also cover compression.
+	srv := httptest.NewServer(mw.Compress(handler, compressOptions(&config.Default().Compression)))
+	t.Cleanup(srv.Close)
+
+	c := &client{t: t, url: srv.URL}
+	c.do(http.MethodPost, "/api/service/clear", nil, http.StatusOK, nil)
+	return c
+}
+
+// do sends the request, checks the status code and decodes the response into out.
+func (c *client) do(method, path string, body interface{}, status int, out easyjson.Unmarshaler) {
+	c.t.Helper()
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			c.t.Fatal(err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, c.url+path, reader)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	if resp.StatusCode != status {
+		c.t.Fatalf("%s %s: status %d, want %d: %s", method, path, resp.StatusCode, status, data)
+	}
+	if out != nil {
+		if err = easyjson.Unmarshal(data, out); err != nil {
+			c.t.Fatalf("%s %s: decode %q: %v", method, path, data, err)
+		}
+	}
+}
+
+// get sends a GET request with the headers and returns the response with its body.
+func (c *client) get(path string, header http.Header) (*http.Response, []byte) {
+	c.t.Helper()
+	return c.send(http.MethodGet, path, header, nil)
+}
+
+// send makes a raw request, the body is sent as is.
+func (c *client) send(method, path string, header http.Header, body []byte) (*http.Response, []byte) {
+	c.t.Helper()
+
+	req, err := http.NewRequest(method, c.url+path, bytes.NewReader(body))
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	return resp, data
+}
+
+type obj map[string]interface{}
+
+// fixture is a small forum with three threads and a post tree in the first one.
+type fixture struct {
+	threads []models.Thread
+	posts   map[string]int
+	names   map[int]string
+}
+
+func createUser(c *client, nickname string) models.User {
+	c.t.Helper()
+
+	var user models.User
+	c.do(http.MethodPost, "/api/user/"+nickname+"/create", obj{
+		"fullname": "Captain " + nickname,
+		"about":    "sails the seven seas",
+		"email":    strings.ToLower(nickname) + "@sea.org",
+	}, http.StatusCreated, &user)
+	return user
+}
+
+func createPosts(c *client, thread string, posts []obj) models.PostList {
+	c.t.Helper()
+
+	var created models.PostList
+	c.do(http.MethodPost, "/api/thread/"+thread+"/create", posts, http.StatusCreated, &created)
+	if len(created) != len(posts) {
+		c.t.Fatalf("created %d posts, want %d", len(created), len(posts))
+	}
+	return created
+}
+
+func newFixture(c *client) *fixture {
+	c.t.Helper()
+
+	for _, nickname := range []string{"alice", "Bob", "carol", "dave"} {
+		createUser(c, nickname)
+	}
+	c.do(http.MethodPost, "/api/forum/create", obj{
+		"title": "Sea stories",
+		"user":  "alice",
+		"slug":  "Sea-Stories",
+	}, http.StatusCreated, nil)
+
+	f := &fixture{posts: make(map[string]int), names: make(map[int]string)}
+	for _, thread := range []obj{
+		{"title": "Kraken", "author": "bob", "message": "Seen a kraken", "slug": "kraken", "created": "2024-01-01T00:00:00Z"},
+		{"title": "Fog", "author": "carol", "message": "Thick fog today", "created": "2024-01-02T00:00:00Z"},
+		{"title": "Mermaid", "author": "alice", "message": "Heard singing", "slug": "mermaid", "created": "2024-01-03T00:00:00Z"},
+	} {
+		var created models.Thread
+		c.do(http.MethodPost, "/api/forum/sea-stories/create", thread, http.StatusCreated, &created)
+		f.threads = append(f.threads, created)
+	}
+
+	batches := [][]string{{"p1", "p5", "p7"}, {"p2", "p3", "p6"}, {"p4"}}
+	parents := map[string]string{"p2": "p1", "p3": "p1", "p6": "p5", "p4": "p2"}
+	authors := []string{"alice", "bob", "carol"}
+	for _, batch := range batches {
+		posts := make([]obj, 0, len(batch))
+		for i, name := range batch {
+			posts = append(posts, obj{
+				"author":  authors[i%len(authors)],
+				"message": "post " + name,
+				"parent":  f.posts[parents[name]],
+			})
+		}
+		for i, post := range createPosts(c, "kraken", posts) {
+			f.posts[batch[i]] = post.Id
+			f.names[post.Id] = batch[i]
+		}
+	}
+	return f
+}
+
+func (f *fixture) postNames(posts models.PostList) string {
+	names := make([]string, 0, len(posts))
+	for _, post := range posts {
+		names = append(names, f.names[post.Id])
+	}
+	return strings.Join(names, ",")
+}
+
+func TestUsers(t *testing.T) {
+	c := newClient(t)
+
+	alice := createUser(c, "alice")
+	if alice.Nickname != "alice" || alice.Email != "alice@sea.org" {
+		t.Fatalf("created %+v", alice)
+	}
+	createUser(c, "Bob")
+
+	var conflict models.UserList
+	c.do(http.MethodPost, "/api/user/ALICE/create", obj{
+		"fullname": "Another Alice",
+		"email":    "BOB@sea.org",
+	}, http.StatusConflict, &conflict)
+	if len(conflict) != 2 {
+		t.Fatalf("conflict returned %d users, want 2", len(conflict))
+	}
+
+	var user models.User
+	c.do(http.MethodGet, "/api/user/ALICE/profile", nil, http.StatusOK, &user)
+	if user.Nickname != "alice" {
+		t.Fatalf("profile nickname %q", user.Nickname)
+	}
+	c.do(http.MethodGet, "/api/user/nobody/profile", nil, http.StatusNotFound, nil)
+
+	c.do(http.MethodPost, "/api/user/alice/profile", obj{"about": "retired"}, http.StatusOK, &user)
+	if user.About != "retired" || user.Fullname != "Captain alice" || user.Email != "alice@sea.org" {
+		t.Fatalf("partial update %+v", user)
+	}
+	c.do(http.MethodPost, "/api/user/alice/profile", obj{}, http.StatusOK, &user)
+	if user.About != "retired" {
+		t.Fatalf("empty update %+v", user)
+	}
+	c.do(http.MethodPost, "/api/user/alice/profile", obj{"email": "bob@sea.org"}, http.StatusConflict, nil)
+	c.do(http.MethodPost, "/api/user/nobody/profile", obj{"about": "ghost"}, http.StatusNotFound, nil)
+}
+
+func TestForums(t *testing.T) {
+	c := newClient(t)
+	createUser(c, "alice")
+
+	var forum models.Forum
+	c.do(http.MethodPost, "/api/forum/create", obj{"title": "Sea stories", "user": "ALICE", "slug": "Sea-Stories"},
+		http.StatusCreated, &forum)
+	if forum.User != "alice" || forum.Slug != "Sea-Stories" {
+		t.Fatalf("created %+v", forum)
+	}
+
+	c.do(http.MethodPost, "/api/forum/create", obj{"title": "Other", "user": "alice", "slug": "sea-stories"},
+		http.StatusConflict, &forum)
+	if forum.Title != "Sea stories" {
+		t.Fatalf("conflict returned %+v", forum)
+	}
+	c.do(http.MethodPost, "/api/forum/create", obj{"title": "Other", "user": "nobody", "slug": "other"},
+		http.StatusNotFound, nil)
+
+	c.do(http.MethodGet, "/api/forum/SEA-STORIES/details", nil, http.StatusOK, &forum)
+	if forum.Slug != "Sea-Stories" || forum.Threads != 0 || forum.Posts != 0 {
+		t.Fatalf("details %+v", forum)
+	}
+	c.do(http.MethodGet, "/api/forum/other/details", nil, http.StatusNotFound, nil)
+	c.do(http.MethodGet, "/api/forum/other/users", nil, http.StatusNotFound, nil)
+	c.do(http.MethodGet, "/api/forum/other/threads", nil, http.StatusNotFound, nil)
+}
+
+func TestThreads(t *testing.T) {
+	c := newClient(t)
+	f := newFixture(c)
+
+	var thread models.Thread
+	c.do(http.MethodPost, "/api/forum/sea-stories/create", obj{
+		"title": "Kraken again", "author": "carol", "message": "Another one", "slug": "KRAKEN",
+	}, http.StatusConflict, &thread)
+	if thread.Id != f.threads[0].Id {
+		t.Fatalf("conflict returned thread %d, want %d", thread.Id, f.threads[0].Id)
+	}
+	c.do(http.MethodPost, "/api/forum/sea-stories/create", obj{"title": "T", "author": "nobody", "message": "M"},
+		http.StatusNotFound, nil)
+	c.do(http.MethodPost, "/api/forum/nowhere/create", obj{"title": "T", "author": "alice", "message": "M"},
+		http.StatusNotFound, nil)
+
+	if f.threads[1].Slug != "" || f.threads[0].Forum != "Sea-Stories" {
+		t.Fatalf("created %+v", f.threads[:2])
+	}
+
+	c.do(http.MethodGet, "/api/thread/kraken/details", nil, http.StatusOK, &thread)
+	if thread.Title != "Kraken" {
+		t.Fatalf("details by slug %+v", thread)
+	}
+	c.do(http.MethodGet, "/api/thread/"+strconv.Itoa(f.threads[1].Id)+"/details", nil, http.StatusOK, &thread)
+	if thread.Title != "Fog" {
+		t.Fatalf("details by id %+v", thread)
+	}
+	c.do(http.MethodGet, "/api/thread/nowhere/details", nil, http.StatusNotFound, nil)
+
+	c.do(http.MethodPost, "/api/thread/kraken/details", obj{"title": "Giant kraken"}, http.StatusOK, &thread)
+	if thread.Title != "Giant kraken" || thread.Message != "Seen a kraken" {
+		t.Fatalf("partial update %+v", thread)
+	}
+	c.do(http.MethodPost, "/api/thread/nowhere/details", obj{"title": "T"}, http.StatusNotFound, nil)
+
+	var threads models.ThreadList
+	for _, tc := range []struct {
+		query string
+		want  []int
+	}{
+		{"", []int{0, 1, 2}},
+		{"?desc=true&limit=2", []int{2, 1}},
+		{"?since=2024-01-02T00:00:00Z", []int{1, 2}},
+		{"?since=2024-01-02T00:00:00Z&desc=true", []int{1, 0}},
+	} {
+		c.do(http.MethodGet, "/api/forum/sea-stories/threads"+tc.query, nil, http.StatusOK, &threads)
+		if len(threads) != len(tc.want) {
+			t.Fatalf("threads%s: got %d threads, want %d", tc.query, len(threads), len(tc.want))
+		}
+		for i, idx := range tc.want {
+			if threads[i].Id != f.threads[idx].Id {
+				t.Fatalf("threads%s: position %d is thread %d, want %d", tc.query, i, threads[i].Id, f.threads[idx].Id)
+			}
+		}
+	}
+}
+
+func TestForumUsers(t *testing.T) {
+	c := newClient(t)
+	newFixture(c)
+
+	var users models.UserList
+	for _, tc := range []struct {
+		query string
+		want  string
+	}{
+		{"", "alice,Bob,carol"},
+		{"?since=alice", "Bob,carol"},
+		{"?since=carol&desc=true&limit=1", "Bob"},
+	} {
+		c.do(http.MethodGet, "/api/forum/sea-stories/users"+tc.query, nil, http.StatusOK, &users)
+		nicknames := make([]string, 0, len(users))
+		for _, user := range users {
+			nicknames = append(nicknames, user.Nickname)
+		}
+		if got := strings.Join(nicknames, ","); got != tc.want {
+			t.Fatalf("users%s = %s, want %s", tc.query, got, tc.want)
+		}
+	}
+
+	var forum models.Forum
+	c.do(http.MethodGet, "/api/forum/sea-stories/details", nil, http.StatusOK, &forum)
+	if forum.Threads != 3 || forum.Posts != 7 {
+		t.Fatalf("counters %+v", forum)
+	}
+}
+
+func TestPostSorts(t *testing.T) {
+	c := newClient(t)
+	f := newFixture(c)
+
+	var posts models.PostList
+	for _, tc := range []struct {
+		query string
+		want  string
+	}{
+		{"", "p1,p5,p7,p2,p3,p6,p4"},
+		{"?sort=flat", "p1,p5,p7,p2,p3,p6,p4"},
+		{"?sort=flat&desc=true&limit=3", "p4,p6,p3"},
+		{"?sort=flat&since={p7}&limit=3", "p2,p3,p6"},
+		{"?sort=flat&since={p3}&desc=true", "p2,p7,p5,p1"},
+
+		{"?sort=tree", "p1,p2,p4,p3,p5,p6,p7"},
+		{"?sort=tree&desc=true", "p7,p6,p5,p3,p4,p2,p1"},
+		{"?sort=tree&since={p4}&limit=3", "p3,p5,p6"},
+		{"?sort=tree&since={p5}&desc=true&limit=2", "p3,p4"},
+
+		{"?sort=parent_tree&limit=2", "p1,p2,p4,p3,p5,p6"},
+		{"?sort=parent_tree&desc=true&limit=2", "p7,p5,p6"},
+		{"?sort=parent_tree&since={p2}&limit=1", "p5,p6"},
+		{"?sort=parent_tree&since={p6}&desc=true", "p1,p2,p4,p3"},
+	} {
+		query := regexp.MustCompile(`\{p\d\}`).ReplaceAllStringFunc(tc.query, func(name string) string {
+			return strconv.Itoa(f.posts[name[1:len(name)-1]])
+		})
+		c.do(http.MethodGet, "/api/thread/kraken/posts"+query, nil, http.StatusOK, &posts)
+		if got := f.postNames(posts); got != tc.want {
+			t.Errorf("posts%s = %s, want %s", tc.query, got, tc.want)
+		}
+	}
+
+	c.do(http.MethodGet, "/api/thread/"+strconv.Itoa(f.threads[1].Id)+"/posts", nil, http.StatusOK, &posts)
+	if len(posts) != 0 {
+		t.Fatalf("empty thread returned %d posts", len(posts))
+	}
+	c.do(http.MethodGet, "/api/thread/nowhere/posts", nil, http.StatusNotFound, nil)
+}
+
+func TestPosts(t *testing.T) {
+	c := newClient(t)
+	f := newFixture(c)
+
+	var posts models.PostList
+	c.do(http.MethodPost, "/api/thread/mermaid/create", []obj{}, http.StatusCreated, &posts)
+	if len(posts) != 0 {
+		t.Fatalf("empty batch created %d posts", len(posts))
+	}
+	c.do(http.MethodPost, "/api/thread/mermaid/create", []obj{{"author": "nobody", "message": "M"}},
+		http.StatusNotFound, nil)
+	c.do(http.MethodPost, "/api/thread/mermaid/create", []obj{{"author": "alice", "message": "M", "parent": f.posts["p1"]}},
+		http.StatusConflict, nil)
+	c.do(http.MethodPost, "/api/thread/nowhere/create", []obj{{"author": "alice", "message": "M"}},
+		http.StatusNotFound, nil)
+
+	posts = createPosts(c, strconv.Itoa(f.threads[2].Id), []obj{{"author": "dave", "message": "Lovely voice"}})
+	post := posts[0]
+	if !strings.EqualFold(post.Forum, "Sea-Stories") || post.Thread != f.threads[2].Id || post.IsEdited {
+		t.Fatalf("created %+v", post)
+	}
+
+	id := "/api/post/" + strconv.Itoa(post.Id) + "/details"
+	var full models.FullPost
+	c.do(http.MethodGet, id, nil, http.StatusOK, &full)
+	if full.Post == nil || full.Post.Message != "Lovely voice" || full.Author != nil || full.Forum != nil || full.Thread != nil {
+		t.Fatalf("details %+v", full)
+	}
+
+	full = models.FullPost{}
+	c.do(http.MethodGet, id+"?related=user,thread,forum", nil, http.StatusOK, &full)
+	if full.Author == nil || full.Author.Nickname != "dave" {
+		t.Fatalf("related author %+v", full.Author)
+	}
+	if full.Thread == nil || full.Thread.Slug != "mermaid" {
+		t.Fatalf("related thread %+v", full.Thread)
+	}
+	if full.Forum == nil || full.Forum.Slug != "Sea-Stories" || full.Forum.Posts != 8 || full.Forum.Threads != 3 {
+		t.Fatalf("related forum %+v", full.Forum)
+	}
+	c.do(http.MethodGet, "/api/post/100000/details", nil, http.StatusNotFound, nil)
+
+	var updated models.Post
+	c.do(http.MethodPost, id, obj{"message": "Lovely voice"}, http.StatusOK, &updated)
+	if updated.IsEdited {
+		t.Fatal("same message marked the post as edited")
+	}
+	c.do(http.MethodPost, id, obj{}, http.StatusOK, &updated)
+	if updated.IsEdited || updated.Message != "Lovely voice" {
+		t.Fatalf("empty update %+v", updated)
+	}
+	c.do(http.MethodPost, id, obj{"message": "Terrible voice"}, http.StatusOK, &updated)
+	if !updated.IsEdited || updated.Message != "Terrible voice" {
+		t.Fatalf("update %+v", updated)
+	}
+	c.do(http.MethodPost, "/api/post/100000/details", obj{"message": "M"}, http.StatusNotFound, nil)
+}
+
+func TestVotes(t *testing.T) {
+	c := newClient(t)
+	f := newFixture(c)
+
+	var thread models.Thread
+	for _, tc := range []struct {
+		thread   string
+		nickname string
+		voice    int
+		want     int
+	}{
+		{"kraken", "bob", 1, 1},
+		{"kraken", "Bob", -1, -1},
+		{"KRAKEN", "carol", -1, -2},
+		{strconv.Itoa(f.threads[0].Id), "carol", -1, -2},
+		{strconv.Itoa(f.threads[0].Id), "alice", 1, -1},
+	} {
+		c.do(http.MethodPost, "/api/thread/"+tc.thread+"/vote", obj{"nickname": tc.nickname, "voice": tc.voice},
+			http.StatusOK, &thread)
+		if thread.Votes != tc.want {
+			t.Fatalf("%s votes %d on %s: votes %d, want %d", tc.nickname, tc.voice, tc.thread, thread.Votes, tc.want)
+		}
+	}
+
+	c.do(http.MethodGet, "/api/thread/kraken/details", nil, http.StatusOK, &thread)
+	if thread.Votes != -1 {
+		t.Fatalf("details votes %d, want -1", thread.Votes)
+	}
+	c.do(http.MethodPost, "/api/thread/kraken/vote", obj{"nickname": "nobody", "voice": 1}, http.StatusNotFound, nil)
+	c.do(http.MethodPost, "/api/thread/nowhere/vote", obj{"nickname": "alice", "voice": 1}, http.StatusNotFound, nil)
+}
+
+func TestService(t *testing.T) {
+	c := newClient(t)
+	newFixture(c)
+	c.do(http.MethodPost, "/api/thread/kraken/vote", obj{"nickname": "bob", "voice": 1}, http.StatusOK, nil)
+	c.do(http.MethodPost, "/api/thread/kraken/vote", obj{"nickname": "carol", "voice": 1}, http.StatusOK, nil)
+
+	for _, query := range []string{"", "?fresh=true"} {
+		var status models.Status
+		c.do(http.MethodGet, "/api/service/status"+query, nil, http.StatusOK, &status)
+		if status != (models.Status{User: 4, Forum: 1, Thread: 3, Post: 7}) {
+			t.Fatalf("status%s %+v", query, status)
+		}
+
+		var stats models.Stats
+		c.do(http.MethodGet, "/api/service/stats"+query, nil, http.StatusOK, &stats)
+		if stats != (models.Stats{Users: 4, Forums: 1, Threads: 3, Posts: 7, Votes: 2, ActiveUsers: 3}) {
+			t.Fatalf("stats%s %+v", query, stats)
+		}
+	}
+
+	var forums models.ForumStatsList
+	c.do(http.MethodGet, "/api/service/stats/forums?forum=sea-stories", nil, http.StatusOK, &forums)
+	if len(forums) != 1 || forums[0] != (models.ForumStats{Forum: "Sea-Stories", Threads: 3, Posts: 7, Votes: 2, Users: 3}) {
+		t.Fatalf("forum stats %+v", forums)
+	}
+	c.do(http.MethodGet, "/api/service/stats/forums?forum=nowhere", nil, http.StatusNotFound, nil)
+
+	var series models.StatsSeries
+	c.do(http.MethodGet, "/api/service/stats/series?interval=day", nil, http.StatusOK, &series)
+	posts := 0
+	for _, point := range series.Points {
+		posts += point.Posts
+	}
+	if series.Interval != "day" || posts != 7 {
+		t.Fatalf("series has %d posts in %d %s points", posts, len(series.Points), series.Interval)
+	}
+	c.do(http.MethodGet, "/api/service/stats/series?interval=week", nil, http.StatusBadRequest, nil)
+
+	c.do(http.MethodPost, "/api/service/clear", nil, http.StatusOK, nil)
+	var status models.Status
+	c.do(http.MethodGet, "/api/service/status", nil, http.StatusOK, &status)
+	if status != (models.Status{}) {
+		t.Fatalf("status after clear %+v", status)
+	}
+}
+
+// TestCache checks that writes through the API drop the cached threads and
+// forums they change.
+func TestCache(t *testing.T) {
+	for _, kind := range []string{"memory", "redis"} {
+		t.Run(kind, func(t *testing.T) {
+			c := newClientWithStore(t, newTestStore(t, kind))
+			f := newFixture(c)
+			id := strconv.Itoa(f.threads[0].Id)
+
+			var thread models.Thread
+			var forum models.Forum
+			c.do(http.MethodGet, "/api/thread/kraken/details", nil, http.StatusOK, &thread)
+			c.do(http.MethodGet, "/api/thread/"+id+"/details", nil, http.StatusOK, &thread)
+			c.do(http.MethodGet, "/api/forum/sea-stories/details", nil, http.StatusOK, &forum)
+
+			c.do(http.MethodPost, "/api/thread/"+id+"/vote", obj{"nickname": "bob", "voice": 1}, http.StatusOK, nil)
+			for _, slugOrId := range []string{"KRAKEN", id} {
+				c.do(http.MethodGet, "/api/thread/"+slugOrId+"/details", nil, http.StatusOK, &thread)
+				if thread.Votes != 1 {
+					t.Fatalf("%s votes %d after a vote, want 1", slugOrId, thread.Votes)
+				}
+			}
+
+			c.do(http.MethodPost, "/api/thread/kraken/details", obj{"title": "Giant squid"}, http.StatusOK, nil)
+			c.do(http.MethodGet, "/api/thread/"+id+"/details", nil, http.StatusOK, &thread)
+			if thread.Title != "Giant squid" {
+				t.Fatalf("title %q after an update", thread.Title)
+			}
+
+			createPosts(c, "mermaid", []obj{{"author": "dave", "message": "la la la"}})
+			c.do(http.MethodPost, "/api/forum/sea-stories/create", obj{"title": "Whales", "author": "dave", "message": "Whales!"},
+				http.StatusCreated, nil)
+			c.do(http.MethodGet, "/api/forum/Sea-Stories/details", nil, http.StatusOK, &forum)
+			if forum.Posts != 8 || forum.Threads != 4 {
+				t.Fatalf("forum counters %d posts, %d threads, want 8 and 4", forum.Posts, forum.Threads)
+			}
+
+			c.do(http.MethodPost, "/api/service/clear", nil, http.StatusOK, nil)
+			c.do(http.MethodGet, "/api/thread/kraken/details", nil, http.StatusNotFound, nil)
+			c.do(http.MethodGet, "/api/forum/sea-stories/details", nil, http.StatusNotFound, nil)
+		})
+	}
+}
+
+func TestConditional(t *testing.T) {
+	mw.SetCacheControl("no-cache", map[string]string{"GET /api/forum/:slug/threads": "public, max-age=10"})
+	t.Cleanup(func() { mw.SetCacheControl("", nil) })
+
+	c := newClient(t)
+	f := newFixture(c)
+	post := "/api/post/" + strconv.Itoa(f.posts["p1"]) + "/details?related=user,thread,forum"
+
+	// revalidate fetches path, checks that its ETag brings 304 and returns it.
+	revalidate := func(path, cacheControl string) string {
+		t.Helper()
+		resp, body := c.get(path, nil)
+		etag := resp.Header.Get("ETag")
+		if resp.StatusCode != http.StatusOK || !strings.HasPrefix(etag, `"`) || len(body) == 0 {
+			t.Fatalf("%s: status %d, ETag %q", path, resp.StatusCode, etag)
+		}
+		if got := resp.Header.Get("Cache-Control"); got != cacheControl {
+			t.Fatalf("%s: Cache-Control %q, want %q", path, got, cacheControl)
+		}
+
+		resp, body = c.get(path, http.Header{"If-None-Match": {`"other", ` + etag}})
+		if resp.StatusCode != http.StatusNotModified || len(body) != 0 || resp.Header.Get("ETag") != etag {
+			t.Fatalf("%s: revalidation status %d, ETag %q, %d bytes", path, resp.StatusCode, resp.Header.Get("ETag"), len(body))
+		}
+		if got := resp.Header.Get("Cache-Control"); got != cacheControl {
+			t.Fatalf("%s: 304 Cache-Control %q, want %q", path, got, cacheControl)
+		}
+		return etag
+	}
+	changed := func(path, etag string) {
+		t.Helper()
+		resp, _ := c.get(path, http.Header{"If-None-Match": {etag}})
+		if resp.StatusCode != http.StatusOK || resp.Header.Get("ETag") == etag {
+			t.Fatalf("%s: status %d, ETag %q after a change", path, resp.StatusCode, resp.Header.Get("ETag"))
+		}
+	}
+
+	thread := revalidate("/api/thread/kraken/details", "no-cache")
+	forum := revalidate("/api/forum/sea-stories/details", "no-cache")
+	full := revalidate(post, "no-cache")
+	profile := revalidate("/api/user/dave/profile", "no-cache")
+	threads := revalidate("/api/forum/sea-stories/threads", "public, max-age=10")
+	revalidate("/api/thread/kraken/posts?sort=tree", "no-cache")
+	revalidate("/api/forum/sea-stories/users", "no-cache")
+
+	resp, _ := c.get("/api/thread/kraken/details", nil)
+	modified := resp.Header.Get("Last-Modified")
+	if modified == "" {
+		t.Fatal("thread details without Last-Modified")
+	}
+	resp, _ = c.get("/api/thread/kraken/details", http.Header{"If-Modified-Since": {modified}})
+	if resp.StatusCode != http.StatusNotModified {
+		t.Fatalf("If-Modified-Since status %d", resp.StatusCode)
+	}
+
+	// An update that changes nothing keeps the row version.
+	c.do(http.MethodPost, "/api/thread/kraken/details", obj{}, http.StatusOK, nil)
+	if etag := revalidate("/api/thread/kraken/details", "no-cache"); etag != thread {
+		t.Fatalf("ETag %s after an empty update, want %s", etag, thread)
+	}
+
+	c.do(http.MethodPost, "/api/thread/kraken/vote", obj{"nickname": "dave", "voice": 1}, http.StatusOK, nil)
+	changed("/api/thread/kraken/details", thread)
+	changed(post, full)
+
+	c.do(http.MethodPost, "/api/user/dave/profile", obj{"about": "Sailor"}, http.StatusOK, nil)
+	changed("/api/user/dave/profile", profile)
+
+	c.do(http.MethodPost, "/api/forum/sea-stories/create", obj{"title": "Whales", "author": "dave", "message": "Whales!"},
+		http.StatusCreated, nil)
+	changed("/api/forum/sea-stories/details", forum)
+	changed("/api/forum/sea-stories/threads", threads)
+
+	resp, _ = c.get("/api/thread/nowhere/details", nil)
+	if resp.StatusCode != http.StatusNotFound || resp.Header.Get("ETag") != "" || resp.Header.Get("Cache-Control") != "" {
+		t.Fatalf("missing thread: status %d, ETag %q, Cache-Control %q",
+			resp.StatusCode, resp.Header.Get("ETag"), resp.Header.Get("Cache-Control"))
+	}
+}
+
+func TestCompression(t *testing.T) {
+	c := newClient(t)
+	newFixture(c)
+	posts := make([]obj, 100)
+	for i := range posts {
+		posts[i] = obj{"author": "dave", "message": strings.Repeat("All hands on deck! ", 20)}
+	}
+	createPosts(c, "mermaid", posts)
+	path := "/api/thread/mermaid/posts?limit=100"
+
+	resp, plain := c.get(path, http.Header{"Accept-Encoding": {"identity"}})
+	if resp.Header.Get("Content-Encoding") != "" || !strings.Contains(resp.Header.Get("Vary"), "Accept-Encoding") {
+		t.Fatalf("identity: Content-Encoding %q, Vary %q", resp.Header.Get("Content-Encoding"), resp.Header.Get("Vary"))
+	}
+	etag := resp.Header.Get("ETag")
+
+	decoders := map[string]func(io.Reader) (io.Reader, error){
+		"gzip": func(r io.Reader) (io.Reader, error) { return gzip.NewReader(r) },
+		"br":   func(r io.Reader) (io.Reader, error) { return brotli.NewReader(r), nil },
+		"zstd": func(r io.Reader) (io.Reader, error) { return zstd.NewReader(r) },
+	}
+	for accept, want := range map[string]string{
+		"gzip":                    "gzip",
+		"gzip, deflate, br":       "br",
+		"zstd;q=0.9, gzip;q=0.5":  "zstd",
+		"br;q=0, *":               "zstd",
+		"deflate, gzip;q=0.1, br": "br",
+	} {
+		resp, body := c.get(path, http.Header{"Accept-Encoding": {accept}})
+		if got := resp.Header.Get("Content-Encoding"); got != want {
+			t.Fatalf("%q: Content-Encoding %q, want %q", accept, got, want)
+		}
+		if len(body) >= len(plain)/2 {
+			t.Fatalf("%q: %d bytes compressed from %d", accept, len(body), len(plain))
+		}
+		r, err := decoders[want](bytes.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		decoded, err := io.ReadAll(r)
+		if err != nil || !bytes.Equal(decoded, plain) {
+			t.Fatalf("%q: decoded %d bytes, want %d: %v", accept, len(decoded), len(plain), err)
+		}
+
+		compressed := resp.Header.Get("ETag")
+		if compressed == etag || compressed == "" {
+			t.Fatalf("%q: ETag %q of the compressed body, identity %q", accept, compressed, etag)
+		}
+		resp, _ = c.get(path, http.Header{"Accept-Encoding": {accept}, "If-None-Match": {compressed}})
+		if resp.StatusCode != http.StatusNotModified || resp.Header.Get("ETag") != compressed {
+			t.Fatalf("%q: revalidation status %d, ETag %q", accept, resp.StatusCode, resp.Header.Get("ETag"))
+		}
+	}
+
+	// Small bodies and clients that refuse every encoding get plain JSON.
+	for path, accept := range map[string]string{
+		"/api/thread/mermaid/details": "gzip, br",
+		"/api/thread/kraken/posts":    "gzip;q=0, br;q=0",
+	} {
+		resp, body := c.get(path, http.Header{"Accept-Encoding": {accept}})
+		if resp.Header.Get("Content-Encoding") != "" || !json.Valid(body) {
+			t.Fatalf("%s: Content-Encoding %q", path, resp.Header.Get("Content-Encoding"))
+		}
+	}
+}
+
+func TestFormats(t *testing.T) {
+	c := newClient(t)
+	f := newFixture(c)
+
+	// CreatePost accepts a protobuf body and answers in kind.
+	body, err := proto.Marshal(&pb.Posts{Posts: []*pb.Post{
+		{Author: "dave", Message: "proto post", Parent: int64(f.posts["p1"])},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, data := c.send(http.MethodPost, "/api/thread/kraken/create", http.Header{"Content-Type": {"application/x-protobuf"}}, body)
+	if resp.StatusCode != http.StatusCreated || resp.Header.Get("Content-Type") != "application/x-protobuf" {
+		t.Fatalf("protobuf create: status %d, Content-Type %q", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	var created pb.Posts
+	if err = proto.Unmarshal(data, &created); err != nil {
+		t.Fatal(err)
+	}
+	if len(created.Posts) != 1 || created.Posts[0].Id == 0 || created.Posts[0].Thread != int64(f.threads[0].Id) ||
+		!strings.EqualFold(created.Posts[0].Forum, "sea-stories") || created.Posts[0].Created == nil {
+		t.Fatalf("protobuf create: %v", created.Posts)
+	}
+
+	// The same in MessagePack, keys are the JSON ones.
+	body, err = msgpack.Marshal([]map[string]interface{}{{"author": "carol", "message": "msgpack post"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, data = c.send(http.MethodPost, "/api/thread/kraken/create", http.Header{"Content-Type": {"application/msgpack"}}, body)
+	if resp.StatusCode != http.StatusCreated || resp.Header.Get("Content-Type") != "application/msgpack" {
+		t.Fatalf("msgpack create: status %d, Content-Type %q", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	var posts []map[string]interface{}
+	if err = msgpack.Unmarshal(data, &posts); err != nil {
+		t.Fatal(err)
+	}
+	if len(posts) != 1 || posts[0]["author"] != "carol" || posts[0]["message"] != "msgpack post" || posts[0]["isEdited"] != false {
+		t.Fatalf("msgpack create: %v", posts)
+	}
+
+	// Accept picks the response format whatever the request is in.
+	resp, data = c.get("/api/forum/sea-stories/details", http.Header{"Accept": {"application/msgpack;q=0.5, application/x-protobuf"}})
+	var forum pb.Forum
+	if err = proto.Unmarshal(data, &forum); err != nil || resp.Header.Get("Content-Type") != "application/x-protobuf" {
+		t.Fatalf("forum: Content-Type %q: %v", resp.Header.Get("Content-Type"), err)
+	}
+	if forum.Slug != "Sea-Stories" || forum.User != "alice" || forum.Threads != 3 || forum.Posts != 9 {
+		t.Fatalf("forum: %v", &forum)
+	}
+
+	// Each format has its own ETag.
+	resp, _ = c.get("/api/thread/kraken/details", nil)
+	jsonETag := resp.Header.Get("ETag")
+	resp, data = c.get("/api/thread/kraken/details", http.Header{"Accept": {"application/msgpack"}})
+	var thread models.Thread
+	dec := msgpack.NewDecoder(bytes.NewReader(data))
+	dec.SetCustomStructTag("json")
+	if err = dec.Decode(&thread); err != nil || thread.Slug != "kraken" || thread.Id != f.threads[0].Id {
+		t.Fatalf("msgpack thread %+v: %v", thread, err)
+	}
+	etag := resp.Header.Get("ETag")
+	if etag == jsonETag || !strings.Contains(resp.Header.Get("Vary"), "Accept") {
+		t.Fatalf("msgpack ETag %q, JSON %q, Vary %q", etag, jsonETag, resp.Header.Get("Vary"))
+	}
+	resp, _ = c.get("/api/thread/kraken/details", http.Header{"Accept": {"application/msgpack"}, "If-None-Match": {etag}})
+	if resp.StatusCode != http.StatusNotModified {
+		t.Fatalf("msgpack revalidation: status %d", resp.StatusCode)
+	}
+	resp, _ = c.get("/api/thread/kraken/details", http.Header{"If-None-Match": {etag}})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("JSON with the msgpack ETag: status %d", resp.StatusCode)
+	}
+
+	// Errors come in the negotiated format too.
+	resp, data = c.get("/api/user/nobody/profile", http.Header{"Accept": {"application/x-protobuf"}})
+	var protoErr pb.Error
+	if err = proto.Unmarshal(data, &protoErr); err != nil || resp.StatusCode != http.StatusNotFound || protoErr.Message == "" {
+		t.Fatalf("protobuf error: status %d, %v: %v", resp.StatusCode, &protoErr, err)
+	}
+
+	// Types without a protobuf message fall back to JSON in responses and
+	// are refused in requests.
+	resp, data = c.get("/api/service/stats", http.Header{"Accept": {"application/x-protobuf"}})
+	if resp.Header.Get("Content-Type") != "application/json" || !json.Valid(data) {
+		t.Fatalf("stats: Content-Type %q", resp.Header.Get("Content-Type"))
+	}
+	resp, _ = c.send(http.MethodPost, "/api/admin/thread/kraken/slow_mode", http.Header{"Content-Type": {"application/x-protobuf"}}, []byte{})
+	if resp.StatusCode != http.StatusUnsupportedMediaType {
+		t.Fatalf("protobuf slow mode: status %d", resp.StatusCode)
+	}
+	resp, _ = c.send(http.MethodPost, "/api/post/"+strconv.Itoa(f.posts["p1"])+"/details", http.Header{"Content-Type": {"application/msgpack"}}, []byte("{}"))
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("broken msgpack: status %d", resp.StatusCode)
+	}
+}
+
+func TestGRPC(t *testing.T) {
+	conn := newGRPCConn(t, nil)
+	ctx := context.Background()
+	users := pb.NewUserServiceClient(conn)
+	forums := pb.NewForumServiceClient(conn)
+	threads := pb.NewThreadServiceClient(conn)
+	posts := pb.NewPostServiceClient(conn)
+	database := pb.NewDatabaseServiceClient(conn)
+
+	// wantCode fails unless err is a status with the code.
+	wantCode := func(what string, err error, code codes.Code) *status.Status {
+		t.Helper()
+		st, _ := status.FromError(err)
+		if st.Code() != code {
+			t.Fatalf("%s: %v, want %s", what, err, code)
+		}
+		return st
+	}
+
+	for _, nickname := range []string{"alice", "bob"} {
+		if _, err := users.CreateUser(ctx, &pb.CreateUserRequest{Nickname: nickname, Fullname: "Captain " + nickname, Email: nickname + "@sea.org"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Conflicts carry the existing objects like the 409 bodies.
+	_, err := users.CreateUser(ctx, &pb.CreateUserRequest{Nickname: "carol", Email: "ALICE@sea.org"})
+	st := wantCode("duplicate user", err, codes.AlreadyExists)
+	if details := st.Details(); len(details) != 1 || len(details[0].(*pb.Users).GetUsers()) != 1 ||
+		details[0].(*pb.Users).GetUsers()[0].GetNickname() != "alice" {
+		t.Fatalf("duplicate user details: %v", details)
+	}
+	_, err = users.GetUser(ctx, &pb.GetUserRequest{Nickname: "nobody"})
+	wantCode("missing user", err, codes.NotFound)
+	user, err := users.UpdateUser(ctx, &pb.UpdateUserRequest{Nickname: "bob", About: "sails"})
+	if err != nil || user.GetAbout() != "sails" || user.GetFullname() != "Captain bob" {
+		t.Fatalf("update user %v: %v", user, err)
+	}
+
+	forum, err := forums.CreateForum(ctx, &pb.Forum{Slug: "Sea-Stories", Title: "Sea stories", User: "ALICE"})
+	if err != nil || forum.GetUser() != "alice" {
+		t.Fatalf("create forum %v: %v", forum, err)
+	}
+	_, err = forums.CreateForum(ctx, &pb.Forum{Slug: "sea-stories", Title: "Again", User: "bob"})
+	st = wantCode("duplicate forum", err, codes.AlreadyExists)
+	if details := st.Details(); len(details) != 1 || details[0].(*pb.Forum).GetTitle() != "Sea stories" {
+		t.Fatalf("duplicate forum details: %v", details)
+	}
+
+	thread, err := forums.CreateThread(ctx, &pb.Thread{Forum: "sea-stories", Slug: "kraken", Title: "Kraken", Author: "bob", Message: "Seen a kraken"})
+	if err != nil || thread.GetId() == 0 || thread.GetForum() != "Sea-Stories" {
+		t.Fatalf("create thread %v: %v", thread, err)
+	}
+	if got, err := threads.GetThread(ctx, &pb.GetThreadRequest{SlugOrId: strconv.FormatInt(thread.GetId(), 10)}); err != nil || got.GetSlug() != "kraken" {
+		t.Fatalf("get thread %v: %v", got, err)
+	}
+	if got, err := threads.UpdateThread(ctx, &pb.UpdateThreadRequest{SlugOrId: "kraken", Message: "Seen two krakens"}); err != nil ||
+		got.GetMessage() != "Seen two krakens" || got.GetTitle() != "Kraken" {
+		t.Fatalf("update thread %v: %v", got, err)
+	}
+
+	created, err := threads.CreatePosts(ctx, &pb.CreatePostsRequest{SlugOrId: "kraken", Posts: []*pb.Post{
+		{Author: "alice", Message: "first"},
+		{Author: "bob", Message: "second"},
+	}})
+	if err != nil || len(created.GetPosts()) != 2 {
+		t.Fatalf("create posts %v: %v", created, err)
+	}
+	reply, err := threads.CreatePosts(ctx, &pb.CreatePostsRequest{SlugOrId: "kraken", Posts: []*pb.Post{
+		{Author: "bob", Message: "reply", Parent: created.GetPosts()[0].GetId()},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = threads.CreatePosts(ctx, &pb.CreatePostsRequest{SlugOrId: "kraken", Posts: []*pb.Post{
+		{Author: "bob", Message: "orphan", Parent: 1000},
+	}})
+	wantCode("missing parent", err, codes.FailedPrecondition)
+
+	// The posts stream in the order of the sort.
+	stream, err := threads.ListThreadPosts(ctx, &pb.ListThreadPostsRequest{SlugOrId: "kraken", Sort: "tree"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var messages []string
+	for {
+		post, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		messages = append(messages, post.GetMessage())
+	}
+	if got := strings.Join(messages, ","); got != "first,reply,second" {
+		t.Fatalf("tree stream: %s", got)
+	}
+	limit := int32(-1)
+	stream, err = threads.ListThreadPosts(ctx, &pb.ListThreadPostsRequest{SlugOrId: "kraken", Limit: &limit})
+	if err == nil {
+		_, err = stream.Recv()
+	}
+	wantCode("negative limit", err, codes.InvalidArgument)
+
+	voted, err := threads.Vote(ctx, &pb.VoteRequest{SlugOrId: "kraken", Nickname: "alice", Voice: 1})
+	if err != nil || voted.GetVotes() != 1 {
+		t.Fatalf("vote %v: %v", voted, err)
+	}
+
+	full, err := posts.GetPost(ctx, &pb.GetPostRequest{Id: reply.GetPosts()[0].GetId(), Related: []string{"user", "thread"}})
+	if err != nil || full.GetAuthor().GetNickname() != "bob" || full.GetThread().GetVotes() != 1 || full.GetForum() != nil {
+		t.Fatalf("get post %v: %v", full, err)
+	}
+	edited, err := posts.UpdatePost(ctx, &pb.UpdatePostRequest{Id: reply.GetPosts()[0].GetId(), Message: "edited"})
+	if err != nil || !edited.GetIsEdited() || edited.GetMessage() != "edited" {
+		t.Fatalf("update post %v: %v", edited, err)
+	}
+
+	forumUsers, err := forums.ListForumUsers(ctx, &pb.ListForumUsersRequest{Slug: "sea-stories"})
+	if err != nil || len(forumUsers.GetUsers()) != 2 {
+		t.Fatalf("forum users %v: %v", forumUsers, err)
+	}
+	forumThreads, err := forums.ListForumThreads(ctx, &pb.ListForumThreadsRequest{Slug: "sea-stories", Desc: true})
+	if err != nil || len(forumThreads.GetThreads()) != 1 {
+		t.Fatalf("forum threads %v: %v", forumThreads, err)
+	}
+
+	dbStatus, err := database.GetStatus(ctx, &pb.GetStatusRequest{Fresh: true})
+	if err != nil || dbStatus.GetUser() != 2 || dbStatus.GetForum() != 1 || dbStatus.GetThread() != 1 || dbStatus.GetPost() != 3 {
+		t.Fatalf("status %v: %v", dbStatus, err)
+	}
+}
+
+func TestGRPCRateLimit(t *testing.T) {
+	limiter := ratelimit.NewLimiter(ratelimit.NewMemoryStore(100), map[string]ratelimit.Group{
+		ratelimit.GroupUsers: {PerIP: ratelimit.Limit{Rate: 0.001, Burst: 1}},
+	}, false, zap.NewNop())
+	users := pb.NewUserServiceClient(newGRPCConn(t, limiter))
+	ctx := context.Background()
+
+	if _, err := users.CreateUser(ctx, &pb.CreateUserRequest{Nickname: "alice", Email: "alice@sea.org"}); err != nil {
+		t.Fatal(err)
+	}
+	var header metadata.MD
+	_, err := users.CreateUser(ctx, &pb.CreateUserRequest{Nickname: "bob", Email: "bob@sea.org"}, grpc.Header(&header))
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("second create: %v, want %s", err, codes.ResourceExhausted)
+	}
+	if header.Get("retry-after") == nil {
+		t.Fatalf("no retry-after in %v", header)
+	}
+	// Methods without a group are not limited.
+	for i := 0; i < 3; i++ {
+		if _, err = users.GetUser(ctx, &pb.GetUserRequest{Nickname: "alice"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestGRPCUserRateLimit(t *testing.T) {
+	limiter := ratelimit.NewLimiter(ratelimit.NewMemoryStore(100), map[string]ratelimit.Group{
+		ratelimit.GroupThreads: {PerUser: ratelimit.Limit{Rate: 0.001, Burst: 1}},
+	}, false, zap.NewNop())
+	conn := newGRPCConn(t, limiter)
+	users := pb.NewUserServiceClient(conn)
+	forums := pb.NewForumServiceClient(conn)
+	ctx := context.Background()
+
+	for _, nickname := range []string{"alice", "bob"} {
+		if _, err := users.CreateUser(ctx, &pb.CreateUserRequest{Nickname: nickname, Email: nickname + "@sea.org"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := forums.CreateForum(ctx, &pb.Forum{Slug: "sea", Title: "Sea", User: "alice"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := forums.CreateThread(ctx, &pb.Thread{Forum: "sea", Title: "Kraken", Author: "alice", Message: "M"}); err != nil {
+		t.Fatal(err)
+	}
+	// The bucket is keyed by the author, whatever the case.
+	var header metadata.MD
+	_, err := forums.CreateThread(ctx, &pb.Thread{Forum: "sea", Title: "Fog", Author: "ALICE", Message: "M"}, grpc.Header(&header))
+	if status.Code(err) != codes.ResourceExhausted || header.Get("retry-after") == nil {
+		t.Fatalf("second thread of alice: %v, header %v", err, header)
+	}
+	if _, err = forums.CreateThread(ctx, &pb.Thread{Forum: "sea", Title: "Fog", Author: "bob", Message: "M"}); err != nil {
+		t.Fatalf("thread of bob: %v", err)
+	}
+}
+
+func TestSlowMode(t *testing.T) {
+	c := newClient(t)
+	newFixture(c)
+
+	c.do(http.MethodPost, "/api/thread/kraken/slow_mode", obj{"nickname": "bob", "seconds": 60}, http.StatusForbidden, nil)
+	c.do(http.MethodPost, "/api/thread/kraken/slow_mode", obj{"seconds": 60}, http.StatusBadRequest, nil)
+	c.do(http.MethodPost, "/api/thread/nowhere/slow_mode", obj{"nickname": "alice", "seconds": 60}, http.StatusNotFound, nil)
+	// alice owns sea-stories.
+	c.do(http.MethodPost, "/api/thread/kraken/slow_mode", obj{"nickname": "ALICE", "seconds": 60}, http.StatusOK, nil)
+
+	// The batch is checked against itself, a rejected batch writes nothing.
+	c.do(http.MethodPost, "/api/thread/kraken/create", []obj{{"author": "dave", "message": "one"}, {"author": "DAVE", "message": "two"}}, http.StatusTooManyRequests, nil)
+	createPosts(c, "kraken", []obj{{"author": "dave", "message": "first"}})
+	c.do(http.MethodPost, "/api/thread/kraken/create", []obj{{"author": "dave", "message": "second"}}, http.StatusTooManyRequests, nil)
+
+	// Moderators need no nickname.
+	c.do(http.MethodPost, "/api/admin/thread/kraken/slow_mode", obj{"seconds": 0}, http.StatusOK, nil)
+	createPosts(c, "kraken", []obj{{"author": "dave", "message": "second"}})
+}
+
+func TestInvalidParams(t *testing.T) {
+	c := newClient(t)
+	newFixture(c)
+
+	for _, path := range []string{
+		"/api/forum/sea-stories/users?limit=many",
+		"/api/forum/sea-stories/users?desc=maybe",
+		"/api/forum/sea-stories/threads?desc=maybe",
+		"/api/thread/kraken/posts?since=first",
+		"/api/thread/kraken/posts?desc=maybe",
+		"/api/post/first/details",
+		"/api/service/status?fresh=maybe",
+	} {
+		c.do(http.MethodGet, path, nil, http.StatusBadRequest, nil)
+	}
+}
+
+// TestRoutes checks that every operation of docs/swagger.yml is served.
+func TestRoutes(t *testing.T) {
+	data, err := os.ReadFile("../../docs/swagger.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var spec struct {
+		BasePath string                            `yaml:"basePath"`
+		Paths    map[string]map[string]interface{} `yaml:"paths"`
+	}
+	if err = yaml.Unmarshal(data, &spec); err != nil {
+		t.Fatal(err)
+	}
+
+	router := newTestRouter(nil)
+	param := regexp.MustCompile(`\{[^}]+\}`)
+	for path, operations := range spec.Paths {
+		for method := range operations {
+			url := spec.BasePath + param.ReplaceAllString(path, "x")
+			if handle, _, _ := router.Lookup(strings.ToUpper(method), url); handle == nil {
+				t.Errorf("%s %s is not routed", strings.ToUpper(method), spec.BasePath+path)
+			}
+		}
+	}
+}
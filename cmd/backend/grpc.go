@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/config"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/metrics"
+	mw "github.com/SlavaShagalov/vk-dbms-project/internal/pkg/middleware"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/ratelimit"
+)
+
+// newGRPCServer chains the interceptors in the order of the HTTP middleware.
+func newGRPCServer(cfg *config.Config, servs *services, limiter *ratelimit.Limiter, m *metrics.Metrics, logger *zap.Logger) *grpc.Server {
+	routes := grpcRoutes()
+	opts := []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(
+			mw.UnaryTrace(),
+			mw.UnaryMetrics(m),
+			mw.UnaryHandleError(logger),
+			mw.UnaryTimeout(routes),
+			mw.UnaryRateLimit(limiter, routes),
+		),
+		grpc.ChainStreamInterceptor(
+			mw.StreamTrace(),
+			mw.StreamMetrics(m),
+			mw.StreamHandleError(logger),
+			mw.StreamTimeout(routes),
+			mw.StreamRateLimit(limiter, routes),
+		),
+	}
+	if cfg.Server.MaxBodyBytes > 0 {
+		opts = append(opts, grpc.MaxRecvMsgSize(int(cfg.Server.MaxBodyBytes)))
+	}
+	server := grpc.NewServer(opts...)
+	servs.registerGRPCHandlers(server, limiter, logger)
+	return server
+}
+
+// serveGRPC runs the server until ctx is done or it fails, then waits for the
+// in-flight calls for at most drainTimeout, like pkgHTTP.Serve.
+func serveGRPC(ctx context.Context, server *grpc.Server, lis net.Listener, drainTimeout time.Duration, log *zap.Logger) error {
+	errCh := make(chan error, 1)
+	go func() {
+		log.Info("gRPC server started", zap.String("addr", lis.Addr().String()))
+		errCh <- server.Serve(lis)
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(drainTimeout):
+		log.Error("gRPC server drain interrupted", zap.String("addr", lis.Addr().String()))
+		server.Stop()
+	}
+	log.Info("gRPC server stopped")
+	return nil
+}
@@ -1,77 +1,300 @@
 package main
 
 import (
-	"github.com/julienschmidt/httprouter"
+	"context"
+	"fmt"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"io/fs"
+	"net"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
 
+	schema "github.com/SlavaShagalov/vk-dbms-project/db"
+	"github.com/SlavaShagalov/vk-dbms-project/docs"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/cache"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/config"
 	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/db"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/dump"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/health"
+	pkgHTTP "github.com/SlavaShagalov/vk-dbms-project/internal/pkg/http"
 	pkgLog "github.com/SlavaShagalov/vk-dbms-project/internal/pkg/log/zap"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/metrics"
+	mw "github.com/SlavaShagalov/vk-dbms-project/internal/pkg/middleware"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/migrate"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/openapi"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/ratelimit"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/reconcile"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/tracing"
 
-	serviceDelivery "github.com/SlavaShagalov/vk-dbms-project/internal/service/delivery/http"
-	serviceRepository "github.com/SlavaShagalov/vk-dbms-project/internal/service/repository/pgx"
-	serviceService "github.com/SlavaShagalov/vk-dbms-project/internal/service/service"
-
-	postDelivery "github.com/SlavaShagalov/vk-dbms-project/internal/post/delivery/http"
-	postRepository "github.com/SlavaShagalov/vk-dbms-project/internal/post/repository/pgx"
-	postService "github.com/SlavaShagalov/vk-dbms-project/internal/post/service"
-
-	threadDelivery "github.com/SlavaShagalov/vk-dbms-project/internal/thread/delivery/http"
-	threadRepository "github.com/SlavaShagalov/vk-dbms-project/internal/thread/repository/pgx"
-	threadService "github.com/SlavaShagalov/vk-dbms-project/internal/thread/service"
-
-	forumDelivery "github.com/SlavaShagalov/vk-dbms-project/internal/forum/delivery/http"
-	forumRepository "github.com/SlavaShagalov/vk-dbms-project/internal/forum/repository/pgx"
-	forumService "github.com/SlavaShagalov/vk-dbms-project/internal/forum/service"
-
-	userDelivery "github.com/SlavaShagalov/vk-dbms-project/internal/user/delivery/http"
-	userRepository "github.com/SlavaShagalov/vk-dbms-project/internal/user/repository/pgx"
-	userService "github.com/SlavaShagalov/vk-dbms-project/internal/user/service"
+	debugDelivery "github.com/SlavaShagalov/vk-dbms-project/internal/debug/delivery/http"
+	dumpDelivery "github.com/SlavaShagalov/vk-dbms-project/internal/dump/delivery/http"
+	healthDelivery "github.com/SlavaShagalov/vk-dbms-project/internal/health/delivery/http"
 )
 
 func main() {
+	// Config
+	cfg, opts, err := config.Load(os.Args[0], os.Args[1:])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Invalid config:", err)
+		os.Exit(2)
+	}
+	if opts.PrintConfig {
+		fmt.Print(cfg.Dump())
+		return
+	}
+
 	// Logger
-	logger := pkgLog.NewProdLogger()
+	logger, err := pkgLog.NewLogger(cfg.Log.Level, cfg.Log.Format)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Failed to create logger:", err)
+		os.Exit(1)
+	}
 
-	//Database
-	pool, err := db.NewPgxPool(logger)
+	// Tracing
+	shutdownTracing, err := tracing.NewProvider(context.Background(), &tracing.Config{
+		Exporter:    cfg.Tracing.Exporter,
+		Endpoint:    cfg.Tracing.Endpoint,
+		Insecure:    cfg.Tracing.Insecure,
+		SampleRatio: cfg.Tracing.SampleRatio,
+		ServiceName: cfg.Tracing.ServiceName,
+	})
 	if err != nil {
+		logger.Error("Failed to create tracer provider", zap.Error(err))
 		os.Exit(1)
 	}
-	defer pool.Close()
+
+	// Database
+	var pool *pgxpool.Pool
+	var migrator *migrate.Migrator
+	if cfg.Storage == config.StoragePostgres {
+		pool, err = db.NewPgxPool(&cfg.DB, logger)
+		if err != nil {
+			os.Exit(1)
+		}
+		defer pool.Close()
+
+		// Migrations
+		migrationsFS, err := fs.Sub(schema.Migrations, "migrations")
+		if err != nil {
+			logger.Error("Failed to open migrations", zap.Error(err))
+			os.Exit(1)
+		}
+		migrator, err = migrate.New(pool, migrationsFS, logger)
+		if err != nil {
+			logger.Error("Failed to load migrations", zap.Error(err))
+			os.Exit(1)
+		}
+		if len(opts.Args) > 0 {
+			if opts.Args[0] != "migrate" {
+				fmt.Fprintln(os.Stderr, migrateUsage)
+				os.Exit(2)
+			}
+			if err = runMigrate(context.Background(), migrator, opts.Args[1:]); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				pool.Close()
+				os.Exit(1)
+			}
+			return
+		}
+		if err = migrator.Check(context.Background()); err != nil {
+			logger.Error("Refusing to start", zap.Error(err))
+			pool.Close()
+			os.Exit(1)
+		}
+	} else if len(opts.Args) > 0 {
+		fmt.Fprintln(os.Stderr, "migrate requires -storage=postgres")
+		os.Exit(2)
+	}
+	logger.Info("Effective config\n" + cfg.Dump())
+
+	// Metrics
+	var m *metrics.Metrics
+	if cfg.Features.Metrics {
+		m = metrics.New()
+		if pool != nil {
+			m.Register(metrics.NewPoolCollector(pool))
+		}
+	}
 
 	// Repositories
-	userRepo := userRepository.NewRepository(pool, logger)
-	forumRepo := forumRepository.NewRepository(pool, logger)
-	threadRepo := threadRepository.NewRepository(pool, logger)
-	postRepo := postRepository.NewRepository(pool, logger)
-	serviceRepo := serviceRepository.NewRepository(pool, logger)
+	var repos *repositories
+	if pool != nil {
+		repos = newPgxRepositories(pool, logger)
+	} else {
+		logger.Warn("Using memory storage, data is lost on restart")
+		repos = newMemoryRepositories()
+	}
+	if m != nil {
+		repos.withMetrics(m)
+	}
+
+	// Cache
+	var cacheStore cache.Store
+	if cfg.Features.Cache {
+		var closeCache func()
+		cacheStore, closeCache = newCacheStore(&cfg.Cache, logger)
+		defer closeCache()
+		repos.withCache(cacheStore, m, logger)
+	}
 
 	// Services
-	userServ := userService.NewService(userRepo, logger)
-	forumServ := forumService.NewService(forumRepo, logger)
-	threadServ := threadService.NewService(threadRepo, logger)
-	postServ := postService.NewService(postRepo, logger)
-	serviceServ := serviceService.NewService(serviceRepo, logger)
+	servs := newServices(cfg, repos, logger)
+
+	// Rate limits
+	var limiter *ratelimit.Limiter
+	if cfg.Features.RateLimit {
+		var store ratelimit.Store
+		if cfg.RateLimit.Store == "postgres" {
+			store = ratelimit.NewPgxStore(pool)
+		} else {
+			store = ratelimit.NewMemoryStore(cfg.RateLimit.MaxBuckets)
+		}
+
+		groups := make(map[string]ratelimit.Group, len(cfg.RateLimit.Groups))
+		for name, group := range cfg.RateLimit.Groups {
+			groups[name] = ratelimit.Group{
+				PerIP:   ratelimit.Limit{Rate: group.PerIP.Rate, Burst: group.PerIP.Burst},
+				PerUser: ratelimit.Limit{Rate: group.PerUser.Rate, Burst: group.PerUser.Burst},
+			}
+		}
+		limiter = ratelimit.NewLimiter(store, groups, cfg.RateLimit.TrustProxy, logger)
+	}
 
 	// Router
-	router := httprouter.New()
+	router := mw.NewRouter()
 
 	// Delivery
-	userDelivery.RegisterHandlers(router, logger, userServ)
-	threadDelivery.RegisterHandlers(router, logger, threadServ)
-	forumDelivery.RegisterHandlers(router, logger, forumServ)
-	postDelivery.RegisterHandlers(router, logger, postServ)
-	serviceDelivery.RegisterHandlers(router, logger, serviceServ)
+	servs.registerHandlers(router, limiter, m, logger)
 
 	// Server
-	server := http.Server{
-		Addr:    ":5000",
-		Handler: router,
+	pkgHTTP.SetMaxBodySize(cfg.Server.MaxBodyBytes)
+	mw.SetTimeouts(cfg.Server.RequestTimeout, cfg.Server.RouteTimeouts)
+	mw.SetCacheControl(cfg.Server.CacheControl, cfg.Server.RouteCacheControl)
+	mw.SetAccessLogSampling(cfg.Log.AccessSampleRate)
+	serverCfg := pkgHTTP.ServerConfig{
+		Addr:              cfg.Server.Addr,
+		ReadTimeout:       cfg.Server.ReadTimeout,
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+		WriteTimeout:      cfg.Server.WriteTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
+		MaxHeaderBytes:    cfg.Server.MaxHeaderBytes,
 	}
-	err = server.ListenAndServe()
-	if err != nil {
-		logger.Error("Failed to start server", zap.Error(err))
+	var handler http.Handler = router
+	if cfg.Features.Contract {
+		spec, err := openapi.Load(docs.Swagger)
+		if err != nil {
+			logger.Error("Failed to load API spec", zap.Error(err))
+			os.Exit(1)
+		}
+		handler = mw.Contract(router, spec, mw.LogContractViolations(logger))
+	}
+	// Outside the contract check, which validates the uncompressed bodies.
+	if cfg.Features.Compression {
+		handler = mw.Compress(handler, compressOptions(&cfg.Compression))
+	}
+	servers := []*http.Server{pkgHTTP.NewServer(&serverCfg, handler)}
+
+	// Health
+	schemaVersion := 0
+	if migrator != nil {
+		schemaVersion = migrator.Latest()
+	}
+	checker := health.NewChecker(pool, schemaVersion, logger)
+	healthDelivery.RegisterHandlers(router, logger, checker)
+
+	// Admin
+	// Export and import work on the database directly, memory storage has no dumper.
+	var dumper *dump.Dumper
+	if pool != nil {
+		dumper = dump.New(pool, logger)
+	}
+	// The admin routes have no authentication, so they are served only on the
+	// admin listener, which is not meant to be reachable from outside.
+	if cfg.Admin.Addr == "" {
+		if m != nil {
+			router.Handler(http.MethodGet, cfg.Metrics.Path, m.Handler())
+		}
+		logger.Warn("Admin routes are disabled, set admin.addr to serve them")
+	} else {
+		adminRouter := mw.NewRouter()
+		if m != nil {
+			adminRouter.Handler(http.MethodGet, cfg.Metrics.Path, m.Handler())
+		}
+		servs.registerAdminHandlers(adminRouter, m, logger)
+		if dumper != nil {
+			dumpDelivery.RegisterAdminHandlers(adminRouter, logger, dumper, cfg.Admin.ImportMaxBytes, cfg.Admin.ImportTimeout, m)
+		}
+		debugDelivery.RegisterHandlers(adminRouter, logger, pool)
+
+		adminCfg := serverCfg
+		adminCfg.Addr = cfg.Admin.Addr
+		// CPU profiles and traces stream for as long as requested.
+		adminCfg.WriteTimeout = 0
+		servers = append(servers, pkgHTTP.NewServer(&adminCfg, adminRouter))
+	}
+
+	// gRPC
+	var grpcServer *grpc.Server
+	var grpcListener net.Listener
+	if cfg.GRPC.Addr != "" {
+		grpcListener, err = net.Listen("tcp", cfg.GRPC.Addr)
+		if err != nil {
+			logger.Error("Failed to listen for gRPC", zap.String("addr", cfg.GRPC.Addr), zap.Error(err))
+			os.Exit(1)
+		}
+		grpcServer = newGRPCServer(cfg, servs, limiter, m, logger)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	// Writes of other replicas and of the admin tools arrive as notifications.
+	if cacheStore != nil && pool != nil {
+		go cache.Listen(ctx, pool, cacheStore, m, logger)
+	}
+
+	// Idle buckets of the postgres store
+	if limiter != nil {
+		go limiter.Cleanup(ctx, cfg.RateLimit.CleanupInterval)
+	}
+
+	// Reconciliation
+	if cfg.Reconcile.Interval > 0 {
+		go reconcile.New(pool, logger).Schedule(ctx, cfg.Reconcile.Interval, &reconcile.Options{
+			BatchSize: cfg.Reconcile.BatchSize,
+			DryRun:    cfg.Reconcile.DryRun,
+		})
+	}
+
+	// The pool is closed by the deferred call only after Serve has drained the
+	// handlers. A failed gRPC server stops the HTTP ones and the other way round.
+	serveCtx, cancelServe := context.WithCancel(checker.Drain(ctx, cfg.Server.ShutdownDelay))
+	defer cancelServe()
+	grpcErr := make(chan error, 1)
+	if grpcServer != nil {
+		go func() {
+			grpcErr <- serveGRPC(serveCtx, grpcServer, grpcListener, cfg.Server.DrainTimeout, logger)
+			cancelServe()
+		}()
+	} else {
+		grpcErr <- nil
+	}
+	if err = pkgHTTP.Serve(serveCtx, cfg.Server.DrainTimeout, logger, servers...); err != nil {
+		logger.Error("Server error", zap.Error(err))
+	}
+	cancelServe()
+	if err = <-grpcErr; err != nil {
+		logger.Error("gRPC server error", zap.Error(err))
+	}
+
+	// Flush spans of the drained requests.
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.DrainTimeout)
+	defer cancel()
+	if err = shutdownTracing(shutdownCtx); err != nil {
+		logger.Error("Failed to flush traces", zap.Error(err))
 	}
 }
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/migrate"
+)
+
+const migrateUsage = `usage: backend [flags] migrate <command>
+
+commands:
+  up [n]     apply n pending migrations, all by default
+  down [n]   revert n applied migrations, 1 by default
+  status     list migrations and when they were applied`
+
+var errMigrateUsage = errors.New(migrateUsage)
+
+func runMigrate(ctx context.Context, migrator *migrate.Migrator, args []string) error {
+	if len(args) == 0 || len(args) > 2 {
+		return errMigrateUsage
+	}
+
+	steps := 0
+	if len(args) == 2 {
+		n, err := strconv.Atoi(args[1])
+		if err != nil || n < 1 {
+			return errMigrateUsage
+		}
+		steps = n
+	}
+
+	switch args[0] {
+	case "up":
+		done, err := migrator.Up(ctx, steps)
+		printMigrations("Applied", done)
+		return err
+	case "down":
+		if steps == 0 {
+			steps = 1
+		}
+		done, err := migrator.Down(ctx, steps)
+		printMigrations("Reverted", done)
+		return err
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if status.Applied {
+				appliedAt = status.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Fprintf(w, "%04d\t%s\t%s\n", status.Version, status.Name, appliedAt)
+		}
+		return w.Flush()
+	default:
+		return errMigrateUsage
+	}
+}
+
+func printMigrations(action string, migrations []migrate.Migration) {
+	if len(migrations) == 0 {
+		fmt.Println("Nothing to do")
+		return
+	}
+	for _, migration := range migrations {
+		fmt.Printf("%s %04d_%s\n", action, migration.Version, migration.Name)
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+
+	pkgForum "github.com/SlavaShagalov/vk-dbms-project/internal/forum"
+	forumCache "github.com/SlavaShagalov/vk-dbms-project/internal/forum/repository/cache"
+	forumMemory "github.com/SlavaShagalov/vk-dbms-project/internal/forum/repository/memory"
+	forumMetrics "github.com/SlavaShagalov/vk-dbms-project/internal/forum/repository/metrics"
+	forumRepository "github.com/SlavaShagalov/vk-dbms-project/internal/forum/repository/pgx"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/cache"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/config"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/memory"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/metrics"
+	pkgPost "github.com/SlavaShagalov/vk-dbms-project/internal/post"
+	postMemory "github.com/SlavaShagalov/vk-dbms-project/internal/post/repository/memory"
+	postMetrics "github.com/SlavaShagalov/vk-dbms-project/internal/post/repository/metrics"
+	postRepository "github.com/SlavaShagalov/vk-dbms-project/internal/post/repository/pgx"
+	pkgService "github.com/SlavaShagalov/vk-dbms-project/internal/service"
+	serviceCache "github.com/SlavaShagalov/vk-dbms-project/internal/service/repository/cache"
+	serviceMemory "github.com/SlavaShagalov/vk-dbms-project/internal/service/repository/memory"
+	serviceMetrics "github.com/SlavaShagalov/vk-dbms-project/internal/service/repository/metrics"
+	serviceRepository "github.com/SlavaShagalov/vk-dbms-project/internal/service/repository/pgx"
+	pkgThread "github.com/SlavaShagalov/vk-dbms-project/internal/thread"
+	threadCache "github.com/SlavaShagalov/vk-dbms-project/internal/thread/repository/cache"
+	threadMemory "github.com/SlavaShagalov/vk-dbms-project/internal/thread/repository/memory"
+	threadMetrics "github.com/SlavaShagalov/vk-dbms-project/internal/thread/repository/metrics"
+	threadRepository "github.com/SlavaShagalov/vk-dbms-project/internal/thread/repository/pgx"
+	pkgUser "github.com/SlavaShagalov/vk-dbms-project/internal/user"
+	userMemory "github.com/SlavaShagalov/vk-dbms-project/internal/user/repository/memory"
+	userMetrics "github.com/SlavaShagalov/vk-dbms-project/internal/user/repository/metrics"
+	userRepository "github.com/SlavaShagalov/vk-dbms-project/internal/user/repository/pgx"
+)
+
+type repositories struct {
+	user    pkgUser.Repository
+	forum   pkgForum.Repository
+	thread  pkgThread.Repository
+	post    pkgPost.Repository
+	service pkgService.Repository
+}
+
+func newPgxRepositories(pool *pgxpool.Pool, logger *zap.Logger) *repositories {
+	return &repositories{
+		user:    userRepository.NewRepository(pool, logger),
+		forum:   forumRepository.NewRepository(pool, logger),
+		thread:  threadRepository.NewRepository(pool, logger),
+		post:    postRepository.NewRepository(pool, logger),
+		service: serviceRepository.NewRepository(pool, logger),
+	}
+}
+
+func newMemoryRepositories() *repositories {
+	store := memory.NewStore()
+	return &repositories{
+		user:    userMemory.NewRepository(store),
+		forum:   forumMemory.NewRepository(store),
+		thread:  threadMemory.NewRepository(store),
+		post:    postMemory.NewRepository(store),
+		service: serviceMemory.NewRepository(store),
+	}
+}
+
+func (r *repositories) withMetrics(m *metrics.Metrics) {
+	r.user = userMetrics.NewRepository(r.user, m)
+	r.forum = forumMetrics.NewRepository(r.forum, m)
+	r.thread = threadMetrics.NewRepository(r.thread, m)
+	r.post = postMetrics.NewRepository(r.post, m)
+	r.service = serviceMetrics.NewRepository(r.service, m)
+}
+
+// withCache goes on top of withMetrics, so query metrics count only the misses.
+func (r *repositories) withCache(store cache.Store, m *metrics.Metrics, logger *zap.Logger) {
+	threads := cache.New(store, cache.NameThread, threadCache.Codec, m, logger)
+	forums := cache.New(store, cache.NameForum, forumCache.Codec, m, logger)
+	r.thread = threadCache.NewRepository(r.thread, threads)
+	r.forum = forumCache.NewRepository(r.forum, forums)
+	r.service = serviceCache.NewRepository(r.service, forums)
+}
+
+// newCacheStore returns the configured store and a function closing its connections.
+func newCacheStore(cfg *config.CacheConfig, logger *zap.Logger) (cache.Store, func()) {
+	if cfg.Store != "redis" {
+		return cache.NewMemoryStore(cfg.Size, cfg.TTL), func() {}
+	}
+
+	client := redis.NewClient(&redis.Options{
+		Addr:     cfg.Redis.Addr,
+		Password: cfg.Redis.Password,
+		DB:       cfg.Redis.DB,
+	})
+	// Lookups fall back to the database while Redis is down, so it is not fatal.
+	if err := client.Ping(context.Background()).Err(); err != nil {
+		logger.Warn("Redis is unavailable", zap.String("addr", cfg.Redis.Addr), zap.Error(err))
+	}
+	return cache.NewRedisStore(client, cfg.Redis.Prefix, cfg.TTL), func() { _ = client.Close() }
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"io"
+
+	"github.com/SlavaShagalov/vk-dbms-project/internal/models"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/dump"
+	pkgUser "github.com/SlavaShagalov/vk-dbms-project/internal/user"
+)
+
+// client is implemented on top of the services (DB mode) and of the HTTP API.
+type client interface {
+	GetUser(ctx context.Context, nickname string) (*models.User, error)
+	CreateUser(ctx context.Context, params *pkgUser.CreateParams) (*models.User, error)
+	UpdateUser(ctx context.Context, params *pkgUser.UpdateParams) (*models.User, error)
+
+	GetForum(ctx context.Context, slug string) (*models.Forum, error)
+	CreateForum(ctx context.Context, forum *models.Forum) (*models.Forum, error)
+	ForumThreads(ctx context.Context, slug string, page *page) (models.ThreadList, error)
+	ForumUsers(ctx context.Context, slug string, page *page) (models.UserList, error)
+	RecountForum(ctx context.Context, slug string) (*models.Forum, error)
+
+	GetThread(ctx context.Context, slugOrId string) (models.Thread, error)
+	LockThread(ctx context.Context, slugOrId string, locked bool) (models.Thread, error)
+	MoveThread(ctx context.Context, slugOrId string, forum string) (models.Thread, error)
+
+	Status(ctx context.Context, fresh bool) (models.Status, error)
+
+	Export(ctx context.Context, w io.Writer, forum string) error
+	Import(ctx context.Context, r io.Reader) (dump.Stats, error)
+
+	Close()
+}
+
+// page mirrors the limit, since and desc query params of the list endpoints.
+type page struct {
+	Limit int
+	Since string
+	Desc  bool
+}
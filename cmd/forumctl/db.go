@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"io"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+
+	"github.com/SlavaShagalov/vk-dbms-project/internal/models"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/config"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/db"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/dump"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/legacy"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/reconcile"
+	pkgUser "github.com/SlavaShagalov/vk-dbms-project/internal/user"
+
+	pkgForum "github.com/SlavaShagalov/vk-dbms-project/internal/forum"
+	forumRepository "github.com/SlavaShagalov/vk-dbms-project/internal/forum/repository/pgx"
+	forumService "github.com/SlavaShagalov/vk-dbms-project/internal/forum/service"
+
+	pkgService "github.com/SlavaShagalov/vk-dbms-project/internal/service"
+	serviceRepository "github.com/SlavaShagalov/vk-dbms-project/internal/service/repository/pgx"
+	serviceService "github.com/SlavaShagalov/vk-dbms-project/internal/service/service"
+
+	pkgThread "github.com/SlavaShagalov/vk-dbms-project/internal/thread"
+	threadRepository "github.com/SlavaShagalov/vk-dbms-project/internal/thread/repository/pgx"
+	threadService "github.com/SlavaShagalov/vk-dbms-project/internal/thread/service"
+
+	userRepository "github.com/SlavaShagalov/vk-dbms-project/internal/user/repository/pgx"
+	userService "github.com/SlavaShagalov/vk-dbms-project/internal/user/service"
+)
+
+// dbClient talks to PostgreSQL through the same services the backend uses.
+// Content filters are not applied to admin operations.
+type dbClient struct {
+	pool   *pgxpool.Pool
+	users  pkgUser.Service
+	forums pkgForum.Service
+	thread pkgThread.Service
+	status pkgService.Service
+	dumper *dump.Dumper
+	legacy *legacy.Importer
+	recon  *reconcile.Reconciler
+}
+
+func newDBClient(cfg *config.DBConfig, log *zap.Logger) (*dbClient, error) {
+	pool, err := db.NewPgxPool(cfg, log)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dbClient{
+		pool:   pool,
+		users:  userService.NewService(userRepository.NewRepository(pool, log), log),
+		forums: forumService.NewService(forumRepository.NewRepository(pool, log), nil, log),
+		thread: threadService.NewService(threadRepository.NewRepository(pool, log), nil, log),
+		status: serviceService.NewService(serviceRepository.NewRepository(pool, log), log),
+		dumper: dump.New(pool, log),
+		legacy: legacy.NewImporter(pool, log),
+		recon:  reconcile.New(pool, log),
+	}, nil
+}
+
+func (c *dbClient) GetUser(ctx context.Context, nickname string) (*models.User, error) {
+	return c.users.GetByNickname(ctx, nickname)
+}
+
+func (c *dbClient) CreateUser(ctx context.Context, params *pkgUser.CreateParams) (*models.User, error) {
+	users, err := c.users.Create(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	return &users[0], nil
+}
+
+func (c *dbClient) UpdateUser(ctx context.Context, params *pkgUser.UpdateParams) (*models.User, error) {
+	return c.users.Update(ctx, params)
+}
+
+func (c *dbClient) GetForum(ctx context.Context, slug string) (*models.Forum, error) {
+	return c.forums.Get(ctx, slug)
+}
+
+func (c *dbClient) CreateForum(ctx context.Context, forum *models.Forum) (*models.Forum, error) {
+	return c.forums.Create(ctx, forum)
+}
+
+func (c *dbClient) ForumThreads(ctx context.Context, slug string, page *page) (models.ThreadList, error) {
+	return c.forums.GetForumThreads(ctx, slug, page.Limit, page.Since, page.Desc)
+}
+
+func (c *dbClient) ForumUsers(ctx context.Context, slug string, page *page) (models.UserList, error) {
+	return c.forums.GetForumUsers(ctx, slug, page.Limit, page.Since, page.Desc)
+}
+
+func (c *dbClient) RecountForum(ctx context.Context, slug string) (*models.Forum, error) {
+	return c.forums.Recount(ctx, slug)
+}
+
+func (c *dbClient) GetThread(ctx context.Context, slugOrId string) (models.Thread, error) {
+	return c.thread.GetThread(ctx, slugOrId)
+}
+
+func (c *dbClient) LockThread(ctx context.Context, slugOrId string, locked bool) (models.Thread, error) {
+	return c.thread.SetLocked(ctx, slugOrId, locked)
+}
+
+func (c *dbClient) MoveThread(ctx context.Context, slugOrId string, forum string) (models.Thread, error) {
+	return c.thread.MoveThread(ctx, slugOrId, forum)
+}
+
+func (c *dbClient) Status(ctx context.Context, fresh bool) (models.Status, error) {
+	return c.status.GetStatus(ctx, fresh)
+}
+
+func (c *dbClient) Export(ctx context.Context, w io.Writer, forum string) error {
+	_, err := c.dumper.Export(ctx, w, forum)
+	return err
+}
+
+func (c *dbClient) Import(ctx context.Context, r io.Reader) (dump.Stats, error) {
+	return c.dumper.Import(ctx, r)
+}
+
+// ImportLegacy and Reconcile are only available with direct database access.
+func (c *dbClient) ImportLegacy(ctx context.Context, src legacy.Source, opts *legacy.Options) (*legacy.Report, error) {
+	return c.legacy.Run(ctx, src, opts)
+}
+
+func (c *dbClient) Reconcile(ctx context.Context, opts *reconcile.Options) (*reconcile.Report, error) {
+	return c.recon.Run(ctx, opts)
+}
+
+func (c *dbClient) Close() {
+	c.pool.Close()
+}
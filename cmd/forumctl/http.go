@@ -0,0 +1,271 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/SlavaShagalov/vk-dbms-project/internal/models"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/dump"
+	mw "github.com/SlavaShagalov/vk-dbms-project/internal/pkg/middleware"
+	pkgUser "github.com/SlavaShagalov/vk-dbms-project/internal/user"
+)
+
+// httpClient goes through the public API and the admin routes of a running backend.
+type httpClient struct {
+	api    string
+	admin  string
+	client *http.Client
+}
+
+func newHTTPClient(api, admin string) *httpClient {
+	return &httpClient{
+		api:    strings.TrimRight(api, "/"),
+		admin:  strings.TrimRight(admin, "/"),
+		client: &http.Client{},
+	}
+}
+
+// The CLI is not on a hot path, so requests are plain encoding/json structs;
+// easyjson cannot generate code for package main.
+type userRequest struct {
+	Fullname string `json:"fullname"`
+	About    string `json:"about"`
+	Email    string `json:"email"`
+}
+
+type lockRequest struct {
+	Locked bool `json:"locked"`
+}
+
+type moveRequest struct {
+	Forum string `json:"forum"`
+}
+
+// apiError is a non-2xx response of the backend.
+type apiError struct {
+	Status  int
+	Message string
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("%d %s: %s", e.Status, http.StatusText(e.Status), e.Message)
+}
+
+func (c *httpClient) do(ctx context.Context, method, u string, body interface{}, out interface{}) error {
+	var reader io.Reader
+	contentType := ""
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+		contentType = "application/json"
+	}
+
+	resp, err := c.send(ctx, method, u, contentType, reader)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if err = responseError(resp.StatusCode, data); err != nil {
+		return err
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(data, out)
+}
+
+func (c *httpClient) send(ctx context.Context, method, u, contentType string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	return c.client.Do(req)
+}
+
+func responseError(status int, data []byte) error {
+	if status == http.StatusConflict {
+		// Conflicts return the existing objects instead of a message.
+		return &apiError{Status: status, Message: "already exists"}
+	}
+	if status >= http.StatusBadRequest {
+		var response mw.ErrorResponse
+		if err := response.UnmarshalJSON(data); err != nil || response.Message == "" {
+			response.Message = strings.TrimSpace(string(data))
+		}
+		return &apiError{Status: status, Message: response.Message}
+	}
+	return nil
+}
+
+func (c *httpClient) GetUser(ctx context.Context, nickname string) (*models.User, error) {
+	user := new(models.User)
+	err := c.do(ctx, http.MethodGet, c.api+"/api/user/"+url.PathEscape(nickname)+"/profile", nil, user)
+	return user, err
+}
+
+func (c *httpClient) CreateUser(ctx context.Context, params *pkgUser.CreateParams) (*models.User, error) {
+	user := new(models.User)
+	request := &userRequest{Fullname: params.Fullname, About: params.About, Email: params.Email}
+	err := c.do(ctx, http.MethodPost, c.api+"/api/user/"+url.PathEscape(params.Nickname)+"/create", request, user)
+	return user, err
+}
+
+func (c *httpClient) UpdateUser(ctx context.Context, params *pkgUser.UpdateParams) (*models.User, error) {
+	user := new(models.User)
+	request := &userRequest{Fullname: params.Fullname, About: params.About, Email: params.Email}
+	err := c.do(ctx, http.MethodPost, c.api+"/api/user/"+url.PathEscape(params.Nickname)+"/profile", request, user)
+	return user, err
+}
+
+func (c *httpClient) GetForum(ctx context.Context, slug string) (*models.Forum, error) {
+	forum := new(models.Forum)
+	err := c.do(ctx, http.MethodGet, c.api+"/api/forum/"+url.PathEscape(slug)+"/details", nil, forum)
+	return forum, err
+}
+
+func (c *httpClient) CreateForum(ctx context.Context, forum *models.Forum) (*models.Forum, error) {
+	created := new(models.Forum)
+	err := c.do(ctx, http.MethodPost, c.api+"/api/forum/create", forum, created)
+	return created, err
+}
+
+func (c *httpClient) ForumThreads(ctx context.Context, slug string, page *page) (models.ThreadList, error) {
+	var threads models.ThreadList
+	err := c.do(ctx, http.MethodGet, c.api+"/api/forum/"+url.PathEscape(slug)+"/threads?"+page.query(), nil, &threads)
+	return threads, err
+}
+
+func (c *httpClient) ForumUsers(ctx context.Context, slug string, page *page) (models.UserList, error) {
+	var users models.UserList
+	err := c.do(ctx, http.MethodGet, c.api+"/api/forum/"+url.PathEscape(slug)+"/users?"+page.query(), nil, &users)
+	return users, err
+}
+
+func (c *httpClient) RecountForum(ctx context.Context, slug string) (*models.Forum, error) {
+	route, err := c.adminRoute("/api/admin/forum/" + url.PathEscape(slug) + "/recount")
+	if err != nil {
+		return nil, err
+	}
+	forum := new(models.Forum)
+	err = c.do(ctx, http.MethodPost, route, nil, forum)
+	return forum, err
+}
+
+func (c *httpClient) GetThread(ctx context.Context, slugOrId string) (models.Thread, error) {
+	var thread models.Thread
+	err := c.do(ctx, http.MethodGet, c.api+"/api/thread/"+url.PathEscape(slugOrId)+"/details", nil, &thread)
+	return thread, err
+}
+
+func (c *httpClient) LockThread(ctx context.Context, slugOrId string, locked bool) (models.Thread, error) {
+	var thread models.Thread
+	route, err := c.adminRoute("/api/admin/thread/" + url.PathEscape(slugOrId) + "/lock")
+	if err != nil {
+		return thread, err
+	}
+	request := &lockRequest{Locked: locked}
+	err = c.do(ctx, http.MethodPost, route, request, &thread)
+	return thread, err
+}
+
+func (c *httpClient) MoveThread(ctx context.Context, slugOrId string, forum string) (models.Thread, error) {
+	var thread models.Thread
+	route, err := c.adminRoute("/api/admin/thread/" + url.PathEscape(slugOrId) + "/move")
+	if err != nil {
+		return thread, err
+	}
+	request := &moveRequest{Forum: forum}
+	err = c.do(ctx, http.MethodPost, route, request, &thread)
+	return thread, err
+}
+
+func (c *httpClient) Status(ctx context.Context, fresh bool) (models.Status, error) {
+	var status models.Status
+	err := c.do(ctx, http.MethodGet, c.api+"/api/service/status?"+url.Values{"fresh": {strconv.FormatBool(fresh)}}.Encode(), nil, &status)
+	return status, err
+}
+
+func (c *httpClient) Export(ctx context.Context, w io.Writer, forum string) error {
+	route, err := c.adminRoute("/api/admin/export?" + url.Values{"forum": {forum}}.Encode())
+	if err != nil {
+		return err
+	}
+	resp, err := c.send(ctx, http.MethodGet, route, "", nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		data, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return err
+		}
+		return responseError(resp.StatusCode, data)
+	}
+	_, err = io.Copy(w, resp.Body)
+	return err
+}
+
+func (c *httpClient) Import(ctx context.Context, r io.Reader) (dump.Stats, error) {
+	var stats dump.Stats
+	route, err := c.adminRoute("/api/admin/import")
+	if err != nil {
+		return stats, err
+	}
+	resp, err := c.send(ctx, http.MethodPost, route, "application/x-ndjson", r)
+	if err != nil {
+		return stats, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return stats, err
+	}
+	if err = responseError(resp.StatusCode, data); err != nil {
+		return stats, err
+	}
+	return stats, stats.UnmarshalJSON(data)
+}
+
+// adminRoute is the URL of an admin route. The backend serves them only on
+// the admin listener.
+func (c *httpClient) adminRoute(path string) (string, error) {
+	if c.admin == "" {
+		return "", errNoAdminURL
+	}
+	return c.admin + path, nil
+}
+
+func (c *httpClient) Close() {
+	c.client.CloseIdleConnections()
+}
+
+func (p *page) query() string {
+	values := url.Values{}
+	values.Set("limit", strconv.Itoa(p.Limit))
+	if p.Since != "" {
+		values.Set("since", p.Since)
+	}
+	values.Set("desc", strconv.FormatBool(p.Desc))
+	return values.Encode()
+}
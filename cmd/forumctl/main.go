@@ -0,0 +1,465 @@
+// Command forumctl manages forum data either directly in PostgreSQL, through
+// the same services the backend uses, or through a running backend's HTTP API.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/SlavaShagalov/vk-dbms-project/internal/models"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/config"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/legacy"
+	pkgLog "github.com/SlavaShagalov/vk-dbms-project/internal/pkg/log/zap"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/reconcile"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/synth"
+	pkgUser "github.com/SlavaShagalov/vk-dbms-project/internal/user"
+)
+
+const usage = `usage: forumctl [flags] <command> [args]
+
+commands:
+  user get <nickname>
+  user create <nickname> [-fullname f] [-email e] [-about a]
+  user update <nickname> [-fullname f] [-email e] [-about a]
+  forum get <slug>
+  forum create <slug> -title t -user nickname
+  forum threads <slug> [-limit n] [-since s] [-desc]
+  forum users <slug> [-limit n] [-since s] [-desc]
+  forum recount <slug>
+  thread get <slug_or_id>
+  thread lock <slug_or_id>
+  thread unlock <slug_or_id>
+  thread move <slug_or_id> <forum>
+  status [-fresh]
+  export [-forum slug] [-o file]
+  import [-i file]
+  generate [-seed n] [-prefix p] [-users n] [-forums n] [-threads n] [-posts n] [-votes n] [-depth n] [-o file]
+  legacy phpbb|discourse -name n -owner nickname [-dry-run] [-batch n] <path>
+  reconcile [-forum slug] [-batch n] [-dry-run]
+
+Without -url the database from the backend config (-config, CONFIG_FILE and
+POSTGRES_* variables) is used directly.
+
+flags:`
+
+var (
+	errUsage      = errors.New("invalid arguments, run forumctl -h for usage")
+	errDirectOnly = errors.New("the command needs direct database access, run it without -url")
+	errNoAdminURL = errors.New("the command uses the admin routes, set -admin-url to the backend admin.addr")
+)
+
+func main() {
+	fs := flag.NewFlagSet("forumctl", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), usage)
+		fs.PrintDefaults()
+	}
+	configFile := fs.String("config", "", "backend config file, used without -url")
+	apiURL := fs.String("url", "", "backend URL, e.g. http://localhost:5000; empty connects to the database")
+	adminURL := fs.String("admin-url", "", "admin listener URL for lock, move, recount, export and import")
+	output := fs.String("output", outputTable, "output format: table or json")
+	timeout := fs.Duration("timeout", 30*time.Second, "command timeout, export and import are only limited when it is set")
+	verbose := fs.Bool("v", false, "log database errors to stdout")
+	if err := fs.Parse(os.Args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return
+		}
+		os.Exit(2)
+	}
+	if *output != outputTable && *output != outputJSON {
+		fmt.Fprintf(os.Stderr, "unknown output format %q\n", *output)
+		os.Exit(2)
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	c, err := newClient(*configFile, *apiURL, *adminURL, *verbose)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	ctx, cancel := context.Background(), context.CancelFunc(func() {})
+	if cmd := fs.Arg(0); (cmd != "export" && cmd != "import" && cmd != "generate" && cmd != "legacy" && cmd != "reconcile") || isSet(fs, "timeout") {
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+	}
+	err = run(ctx, c, &printer{w: os.Stdout, format: *output}, fs.Args())
+	cancel()
+	c.Close()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		if errors.Is(err, errUsage) {
+			os.Exit(2)
+		}
+		os.Exit(1)
+	}
+}
+
+func newClient(configFile, apiURL, adminURL string, verbose bool) (client, error) {
+	if apiURL != "" {
+		return newHTTPClient(apiURL, adminURL), nil
+	}
+
+	var args []string
+	if configFile != "" {
+		args = []string{"-config", configFile}
+	}
+	cfg, _, err := config.Load("forumctl", args)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+	if cfg.Storage != config.StoragePostgres {
+		return nil, fmt.Errorf("storage %s has no database to connect to, use -url", cfg.Storage)
+	}
+
+	logger := zap.NewNop()
+	if verbose {
+		if logger, err = pkgLog.NewLogger(cfg.Log.Level, cfg.Log.Format); err != nil {
+			return nil, err
+		}
+	}
+	return newDBClient(&cfg.DB, logger)
+}
+
+func run(ctx context.Context, c client, p *printer, args []string) error {
+	switch args[0] {
+	case "user":
+		return runUser(ctx, c, p, args[1:])
+	case "forum":
+		return runForum(ctx, c, p, args[1:])
+	case "thread":
+		return runThread(ctx, c, p, args[1:])
+	case "status":
+		fs := newCommandFlags("status")
+		fresh := fs.Bool("fresh", false, "count the tables instead of reading the stats rollups")
+		if err := fs.Parse(args[1:]); err != nil || fs.NArg() != 0 {
+			return errUsage
+		}
+		status, err := c.Status(ctx, *fresh)
+		if err != nil {
+			return err
+		}
+		return p.print(status)
+	case "export":
+		return runExport(ctx, c, args[1:])
+	case "import":
+		return runImport(ctx, c, p, args[1:])
+	case "generate":
+		return runGenerate(ctx, c, p, args[1:])
+	case "legacy":
+		return runLegacy(ctx, c, p, args[1:])
+	case "reconcile":
+		return runReconcile(ctx, c, p, args[1:])
+	default:
+		return errUsage
+	}
+}
+
+func runUser(ctx context.Context, c client, p *printer, args []string) error {
+	if len(args) < 2 {
+		return errUsage
+	}
+	command, nickname := args[0], args[1]
+
+	var user *models.User
+	var err error
+	switch command {
+	case "get":
+		if len(args) != 2 {
+			return errUsage
+		}
+		user, err = c.GetUser(ctx, nickname)
+	case "create", "update":
+		fs := newCommandFlags("user " + command)
+		fullname := fs.String("fullname", "", "full name")
+		email := fs.String("email", "", "email")
+		about := fs.String("about", "", "about")
+		if err = fs.Parse(args[2:]); err != nil || fs.NArg() != 0 {
+			return errUsage
+		}
+
+		if command == "create" {
+			user, err = c.CreateUser(ctx, &pkgUser.CreateParams{
+				Nickname: nickname,
+				Fullname: *fullname,
+				About:    *about,
+				Email:    *email,
+			})
+		} else {
+			user, err = c.UpdateUser(ctx, &pkgUser.UpdateParams{
+				Nickname: nickname,
+				Fullname: *fullname,
+				About:    *about,
+				Email:    *email,
+			})
+		}
+	default:
+		return errUsage
+	}
+	if err != nil {
+		return err
+	}
+	return p.print(user)
+}
+
+func runForum(ctx context.Context, c client, p *printer, args []string) error {
+	if len(args) < 2 {
+		return errUsage
+	}
+	command, slug := args[0], args[1]
+
+	switch command {
+	case "get", "recount":
+		if len(args) != 2 {
+			return errUsage
+		}
+		get := c.GetForum
+		if command == "recount" {
+			get = c.RecountForum
+		}
+		forum, err := get(ctx, slug)
+		if err != nil {
+			return err
+		}
+		return p.print(forum)
+	case "create":
+		fs := newCommandFlags("forum create")
+		title := fs.String("title", "", "forum title")
+		user := fs.String("user", "", "owner nickname")
+		if err := fs.Parse(args[2:]); err != nil || fs.NArg() != 0 || *title == "" || *user == "" {
+			return errUsage
+		}
+		forum, err := c.CreateForum(ctx, &models.Forum{Title: *title, User: *user, Slug: slug})
+		if err != nil {
+			return err
+		}
+		return p.print(forum)
+	case "threads", "users":
+		fs := newCommandFlags("forum " + command)
+		pg := &page{}
+		fs.IntVar(&pg.Limit, "limit", 100, "page size")
+		fs.StringVar(&pg.Since, "since", "", "threads: created time, users: nickname to start after")
+		fs.BoolVar(&pg.Desc, "desc", false, "descending order")
+		if err := fs.Parse(args[2:]); err != nil || fs.NArg() != 0 || pg.Limit < 0 {
+			return errUsage
+		}
+
+		if command == "threads" {
+			threads, err := c.ForumThreads(ctx, slug, pg)
+			if err != nil {
+				return err
+			}
+			return p.print(threads)
+		}
+		users, err := c.ForumUsers(ctx, slug, pg)
+		if err != nil {
+			return err
+		}
+		return p.print(users)
+	default:
+		return errUsage
+	}
+}
+
+func runThread(ctx context.Context, c client, p *printer, args []string) error {
+	if len(args) < 2 {
+		return errUsage
+	}
+	command, slugOrId := args[0], args[1]
+
+	var thread models.Thread
+	var err error
+	switch {
+	case command == "get" && len(args) == 2:
+		thread, err = c.GetThread(ctx, slugOrId)
+	case command == "lock" && len(args) == 2:
+		thread, err = c.LockThread(ctx, slugOrId, true)
+	case command == "unlock" && len(args) == 2:
+		thread, err = c.LockThread(ctx, slugOrId, false)
+	case command == "move" && len(args) == 3:
+		thread, err = c.MoveThread(ctx, slugOrId, args[2])
+	default:
+		return errUsage
+	}
+	if err != nil {
+		return err
+	}
+	return p.print(thread)
+}
+
+func runExport(ctx context.Context, c client, args []string) error {
+	fs := newCommandFlags("export")
+	forum := fs.String("forum", "", "export only this forum")
+	file := fs.String("o", "", "output file, stdout by default")
+	if err := fs.Parse(args); err != nil || fs.NArg() != 0 {
+		return errUsage
+	}
+
+	if *file == "" {
+		return c.Export(ctx, os.Stdout, *forum)
+	}
+	f, err := os.Create(*file)
+	if err != nil {
+		return err
+	}
+	if err = c.Export(ctx, f, *forum); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func runImport(ctx context.Context, c client, p *printer, args []string) error {
+	fs := newCommandFlags("import")
+	file := fs.String("i", "", "input file, stdin by default")
+	if err := fs.Parse(args); err != nil || fs.NArg() != 0 {
+		return errUsage
+	}
+
+	var in io.Reader = os.Stdin
+	if *file != "" {
+		f, err := os.Open(*file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+
+	stats, err := c.Import(ctx, in)
+	if err != nil {
+		return err
+	}
+	return p.print(stats)
+}
+
+// runGenerate imports a synthetic dataset, or writes it as a dump with -o.
+func runGenerate(ctx context.Context, c client, p *printer, args []string) error {
+	fs := newCommandFlags("generate")
+	opts := synth.Options{}
+	fs.Int64Var(&opts.Seed, "seed", 1, "random seed, the same seed and sizes give the same data")
+	fs.StringVar(&opts.Prefix, "prefix", "", "prefix of nicknames and slugs, syn<seed> by default")
+	fs.IntVar(&opts.Users, "users", 1000, "users")
+	fs.IntVar(&opts.Forums, "forums", 20, "forums")
+	fs.IntVar(&opts.Threads, "threads", 2000, "threads")
+	fs.IntVar(&opts.Posts, "posts", 100000, "posts")
+	fs.IntVar(&opts.Votes, "votes", 20000, "votes")
+	fs.IntVar(&opts.MaxDepth, "depth", 64, "maximum depth of post trees")
+	file := fs.String("o", "", "write a dump to the file instead of importing")
+	if err := fs.Parse(args); err != nil || fs.NArg() != 0 {
+		return errUsage
+	}
+
+	if *file == "" {
+		stats, err := synth.LoadWith(ctx, c.Import, opts)
+		if err != nil {
+			return err
+		}
+		return p.print(stats)
+	}
+	f, err := os.Create(*file)
+	if err != nil {
+		return err
+	}
+	stats, err := synth.Generate(opts, f)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return p.print(stats)
+}
+
+func runLegacy(ctx context.Context, c client, p *printer, args []string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+	format := args[0]
+
+	fs := newCommandFlags("legacy " + format)
+	opts := &legacy.Options{}
+	fs.StringVar(&opts.Name, "name", "", "import name, rerunning with the same name resumes")
+	fs.StringVar(&opts.Owner, "owner", "", "nickname that owns the forums and the content of unknown users")
+	fs.BoolVar(&opts.DryRun, "dry-run", false, "report what would be imported and roll back")
+	fs.IntVar(&opts.BatchSize, "batch", 5000, "records per transaction")
+	if err := fs.Parse(args[1:]); err != nil || fs.NArg() != 1 || opts.Name == "" || opts.Owner == "" {
+		return errUsage
+	}
+
+	direct, ok := c.(*dbClient)
+	if !ok {
+		return errDirectOnly
+	}
+
+	var src legacy.Source
+	var err error
+	switch format {
+	case "phpbb":
+		src, err = legacy.NewPhpBBSource(fs.Arg(0))
+	case "discourse":
+		src, err = legacy.NewDiscourseSource(fs.Arg(0))
+	default:
+		return errUsage
+	}
+	if err != nil {
+		return err
+	}
+
+	report, err := direct.ImportLegacy(ctx, src, opts)
+	if report != nil {
+		if printErr := p.print(report); printErr != nil && err == nil {
+			err = printErr
+		}
+	}
+	return err
+}
+
+func runReconcile(ctx context.Context, c client, p *printer, args []string) error {
+	fs := newCommandFlags("reconcile")
+	opts := &reconcile.Options{}
+	fs.StringVar(&opts.Forum, "forum", "", "reconcile only this forum")
+	fs.IntVar(&opts.BatchSize, "batch", 20, "forums per transaction")
+	fs.BoolVar(&opts.DryRun, "dry-run", false, "report discrepancies without fixing them")
+	if err := fs.Parse(args); err != nil || fs.NArg() != 0 {
+		return errUsage
+	}
+
+	direct, ok := c.(*dbClient)
+	if !ok {
+		return errDirectOnly
+	}
+
+	report, err := direct.Reconcile(ctx, opts)
+	if report != nil {
+		if printErr := p.print(report); printErr != nil && err == nil {
+			err = printErr
+		}
+	}
+	return err
+}
+
+func isSet(fs *flag.FlagSet, name string) bool {
+	set := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
+func newCommandFlags(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	return fs
+}
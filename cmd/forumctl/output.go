@@ -0,0 +1,145 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/SlavaShagalov/vk-dbms-project/internal/models"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/dump"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/legacy"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/reconcile"
+)
+
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+)
+
+type printer struct {
+	w      io.Writer
+	format string
+}
+
+// print writes v as JSON or as a table with one row per object.
+func (p *printer) print(v interface{}) error {
+	if p.format == outputJSON {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(p.w, string(data))
+		return err
+	}
+
+	tw := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0)
+	switch v := v.(type) {
+	case *models.User:
+		writeRows(tw, userHeader, userRow(v))
+	case models.UserList:
+		rows := make([][]string, 0, len(v))
+		for i := range v {
+			rows = append(rows, userRow(&v[i]))
+		}
+		writeRows(tw, userHeader, rows...)
+	case *models.Forum:
+		writeRows(tw, forumHeader, forumRow(v))
+	case models.Thread:
+		writeRows(tw, threadHeader, threadRow(&v))
+	case models.ThreadList:
+		rows := make([][]string, 0, len(v))
+		for i := range v {
+			rows = append(rows, threadRow(&v[i]))
+		}
+		writeRows(tw, threadHeader, rows...)
+	case models.Status:
+		writeRows(tw, []string{"USERS", "FORUMS", "THREADS", "POSTS"}, []string{
+			strconv.Itoa(v.User), strconv.Itoa(v.Forum), strconv.Itoa(v.Thread), strconv.Itoa(v.Post),
+		})
+	case dump.Stats:
+		writeRows(tw, []string{"USERS", "FORUMS", "THREADS", "POSTS", "VOTES", "FORUM USERS"}, []string{
+			strconv.FormatInt(v.Users, 10), strconv.FormatInt(v.Forums, 10), strconv.FormatInt(v.Threads, 10),
+			strconv.FormatInt(v.Posts, 10), strconv.FormatInt(v.Votes, 10), strconv.FormatInt(v.ForumUsers, 10),
+		})
+	case *legacy.Report:
+		rows := make([][]string, 0, 4)
+		for _, stage := range []struct {
+			name   string
+			report *legacy.StageReport
+		}{{"users", &v.Users}, {"categories", &v.Categories}, {"topics", &v.Topics}, {"posts", &v.Posts}} {
+			r := stage.report
+			rows = append(rows, []string{
+				stage.name, strconv.FormatInt(r.Resumed, 10), strconv.FormatInt(r.Read, 10), strconv.FormatInt(r.Imported, 10),
+				strconv.FormatInt(r.Matched, 10), strconv.FormatInt(r.Rejected, 10),
+			})
+		}
+		writeRows(tw, []string{"STAGE", "RESUMED", "READ", "IMPORTED", "MATCHED", "REJECTED"}, rows...)
+		if v.DryRun {
+			fmt.Fprintln(tw, "dry run, nothing was saved")
+		}
+	case *reconcile.Report:
+		rows := make([][]string, 0, len(v.Discrepancies))
+		for _, d := range v.Discrepancies {
+			thread := ""
+			if d.Thread != 0 {
+				thread = strconv.FormatInt(d.Thread, 10)
+			}
+			rows = append(rows, []string{
+				d.Forum, thread, d.Field, strconv.FormatInt(d.Stored, 10), strconv.FormatInt(d.Actual, 10),
+			})
+		}
+		writeRows(tw, []string{"FORUM", "THREAD", "FIELD", "STORED", "ACTUAL"}, rows...)
+		fmt.Fprintf(tw, "\n%d forums checked: %d forum counters, %d thread votes, forum users %d missing, %d extra, %d stale, %d forum stats, %d total stats\n",
+			v.Forums, v.ForumCounters, v.ThreadVotes, v.ForumUsersMissing, v.ForumUsersExtra, v.ForumUsersStale, v.StatsForums, v.StatsTotals)
+		if v.DryRun {
+			fmt.Fprintln(tw, "dry run, nothing was fixed")
+		}
+	default:
+		return fmt.Errorf("unsupported output type %T", v)
+	}
+	return tw.Flush()
+}
+
+var (
+	userHeader   = []string{"NICKNAME", "FULLNAME", "EMAIL", "ABOUT"}
+	forumHeader  = []string{"SLUG", "TITLE", "USER", "THREADS", "POSTS"}
+	threadHeader = []string{"ID", "SLUG", "FORUM", "AUTHOR", "VOTES", "CREATED", "TITLE"}
+)
+
+func userRow(user *models.User) []string {
+	return []string{user.Nickname, user.Fullname, user.Email, user.About}
+}
+
+func forumRow(forum *models.Forum) []string {
+	return []string{
+		forum.Slug, forum.Title, forum.User,
+		strconv.FormatInt(forum.Threads, 10), strconv.FormatInt(forum.Posts, 10),
+	}
+}
+
+func threadRow(thread *models.Thread) []string {
+	return []string{
+		strconv.Itoa(thread.Id), thread.Slug, thread.Forum, thread.Author,
+		strconv.Itoa(thread.Votes), thread.Created.Format(time.RFC3339), thread.Title,
+	}
+}
+
+func writeRows(w io.Writer, header []string, rows ...[]string) {
+	writeRow(w, header)
+	for _, row := range rows {
+		writeRow(w, row)
+	}
+}
+
+func writeRow(w io.Writer, row []string) {
+	for i, cell := range row {
+		if i > 0 {
+			fmt.Fprint(w, "\t")
+		}
+		fmt.Fprint(w, cell)
+	}
+	fmt.Fprintln(w)
+}
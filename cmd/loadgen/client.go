@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// client sends API requests and records their latency. Bodies are encoded with
+// encoding/json, easyjson cannot generate code for package main.
+type client struct {
+	url  string
+	http *http.Client
+}
+
+func newClient(url string, concurrency int, timeout time.Duration) *client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConns = concurrency * 2
+	transport.MaxIdleConnsPerHost = concurrency * 2
+	return &client{
+		url:  strings.TrimRight(url, "/"),
+		http: &http.Client{Transport: transport, Timeout: timeout},
+	}
+}
+
+// statusError is a response with a status code the operation did not expect.
+type statusError struct {
+	status int
+	body   string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("unexpected status %d: %s", e.status, e.body)
+}
+
+// do sends the request and decodes the response into out when its status is
+// one of expected. The returned status is 0 for transport errors.
+func (c *client) do(ctx context.Context, method, path string, body, out interface{}, expected ...int) (int, time.Duration, error) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return 0, 0, err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.url+path, reader)
+	if err != nil {
+		return 0, 0, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	start := time.Now()
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return 0, time.Since(start), err
+	}
+	data, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	latency := time.Since(start)
+	if err != nil {
+		return resp.StatusCode, latency, err
+	}
+
+	for _, status := range expected {
+		if resp.StatusCode != status {
+			continue
+		}
+		if out != nil {
+			if err = json.Unmarshal(data, out); err != nil {
+				return resp.StatusCode, latency, fmt.Errorf("decode response: %w", err)
+			}
+		}
+		return resp.StatusCode, latency, nil
+	}
+	if len(data) > 200 {
+		data = data[:200]
+	}
+	return resp.StatusCode, latency, &statusError{status: resp.StatusCode, body: strings.TrimSpace(string(data))}
+}
+
+type user struct {
+	Fullname string `json:"fullname"`
+	About    string `json:"about"`
+	Email    string `json:"email"`
+}
+
+type forum struct {
+	Title string `json:"title"`
+	User  string `json:"user"`
+	Slug  string `json:"slug"`
+}
+
+type thread struct {
+	ID      int       `json:"id,omitempty"`
+	Title   string    `json:"title"`
+	Author  string    `json:"author"`
+	Message string    `json:"message"`
+	Slug    string    `json:"slug,omitempty"`
+	Created time.Time `json:"created"`
+}
+
+type post struct {
+	ID      int    `json:"id,omitempty"`
+	Parent  int    `json:"parent"`
+	Author  string `json:"author"`
+	Message string `json:"message"`
+}
+
+type vote struct {
+	Nickname string `json:"nickname"`
+	Voice    int    `json:"voice"`
+}
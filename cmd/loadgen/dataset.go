@@ -0,0 +1,374 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/mailru/easyjson"
+
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/dump"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/synth"
+)
+
+// dataset is what the workload knows about the data in the backend. Names are
+// derived from the seed, so a later run can find a dataset seeded before.
+type dataset struct {
+	users   []string
+	forums  []string
+	threads []*threadState
+}
+
+type threadState struct {
+	id    int
+	slug  string
+	forum string
+
+	mu    sync.Mutex
+	posts []postRef
+}
+
+type postRef struct {
+	id    int
+	depth int
+}
+
+type seedOptions struct {
+	seed    int64
+	users   int
+	forums  int
+	threads int
+	posts   int
+	depth   int
+	batch   int
+	workers int
+	// cleared means the database was empty, so there are no earlier posts to load.
+	cleared bool
+}
+
+// synth returns the options of the synth dataset loadgen seeds. The prefix
+// keeps the names of earlier loadgen runs.
+func (o *seedOptions) synth() synth.Options {
+	return synth.Options{
+		Seed:     o.seed,
+		Prefix:   fmt.Sprintf("lg%d", o.seed),
+		Users:    o.users,
+		Forums:   o.forums,
+		Threads:  o.threads,
+		Posts:    o.posts,
+		MaxDepth: o.depth,
+	}
+}
+
+func userName(seed int64, i int) string  { return fmt.Sprintf("lg%d.u%d", seed, i) }
+func forumSlug(seed int64, i int) string { return fmt.Sprintf("lg%d-f%d", seed, i) }
+func threadSlug(seed int64, i int) string {
+	return fmt.Sprintf("lg%d-t%d", seed, i)
+}
+
+// parallel runs fn for 0..n-1 on the given number of workers and stops at the
+// first error.
+func parallel(ctx context.Context, n, workers int, fn func(ctx context.Context, i int) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var next atomic.Int64
+	var once sync.Once
+	var firstErr error
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				i := int(next.Add(1) - 1)
+				if i >= n || ctx.Err() != nil {
+					return
+				}
+				if err := fn(ctx, i); err != nil {
+					once.Do(func() {
+						firstErr = err
+						cancel()
+					})
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	return firstErr
+}
+
+// records is the synth dataset split by kind, posts by thread.
+type records struct {
+	users   []*dump.User
+	forums  []*dump.Forum
+	threads []*dump.Thread
+	posts   [][]*dump.Post
+}
+
+func generate(opts synth.Options) (*records, error) {
+	recs := &records{}
+	_, err := synth.Walk(opts, func(_ string, data easyjson.Marshaler) error {
+		switch v := data.(type) {
+		case *dump.User:
+			recs.users = append(recs.users, v)
+		case *dump.Forum:
+			recs.forums = append(recs.forums, v)
+		case *dump.Thread:
+			recs.threads = append(recs.threads, v)
+		case *dump.Post:
+			if recs.posts == nil {
+				recs.posts = make([][]*dump.Post, len(recs.threads))
+			}
+			recs.posts[v.Thread-1] = append(recs.posts[v.Thread-1], v)
+		}
+		return nil
+	})
+	return recs, err
+}
+
+// seed creates the synth dataset through the API. Objects that already exist
+// are reused, so seeding twice with the same seed only adds posts.
+func seed(ctx context.Context, c *client, opts seedOptions, progress func(string)) (*dataset, error) {
+	recs, err := generate(opts.synth())
+	if err != nil {
+		return nil, fmt.Errorf("generate dataset: %w", err)
+	}
+	ds := &dataset{
+		users:   make([]string, len(recs.users)),
+		forums:  make([]string, len(recs.forums)),
+		threads: make([]*threadState, len(recs.threads)),
+	}
+
+	progress(fmt.Sprintf("seeding %d users", len(recs.users)))
+	err = parallel(ctx, len(recs.users), opts.workers, func(ctx context.Context, i int) error {
+		u := recs.users[i]
+		_, _, err := c.do(ctx, http.MethodPost, "/api/user/"+u.Nickname+"/create", user{
+			Fullname: u.Fullname,
+			About:    u.About,
+			Email:    u.Email,
+		}, nil, http.StatusCreated, http.StatusConflict)
+		ds.users[i] = u.Nickname
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("seed users: %w", err)
+	}
+
+	progress(fmt.Sprintf("seeding %d forums", len(recs.forums)))
+	err = parallel(ctx, len(recs.forums), opts.workers, func(ctx context.Context, i int) error {
+		f := recs.forums[i]
+		_, _, err := c.do(ctx, http.MethodPost, "/api/forum/create", forum{
+			Title: f.Title,
+			User:  f.User,
+			Slug:  f.Slug,
+		}, nil, http.StatusCreated, http.StatusConflict)
+		ds.forums[i] = f.Slug
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("seed forums: %w", err)
+	}
+
+	// Every thread gets a slug, which lets a later run find it.
+	progress(fmt.Sprintf("seeding %d threads", len(recs.threads)))
+	err = parallel(ctx, len(recs.threads), opts.workers, func(ctx context.Context, i int) error {
+		t := recs.threads[i]
+		slug := threadSlug(opts.seed, i)
+		var created thread
+		_, _, err := c.do(ctx, http.MethodPost, "/api/forum/"+t.Forum+"/create", thread{
+			Title:   t.Title,
+			Author:  t.Author,
+			Message: t.Message,
+			Slug:    slug,
+			Created: t.Created,
+		}, &created, http.StatusCreated, http.StatusConflict)
+		ds.threads[i] = &threadState{id: created.ID, slug: slug, forum: t.Forum}
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("seed threads: %w", err)
+	}
+
+	if !opts.cleared && opts.posts > 0 {
+		// Posts of earlier runs are read and answered by the workload too.
+		if err = loadPosts(ctx, c, ds, opts.workers); err != nil {
+			return nil, err
+		}
+	}
+
+	progress(fmt.Sprintf("seeding %d posts in batches of up to %d", opts.posts, opts.batch))
+	err = parallel(ctx, len(recs.posts), opts.workers, func(ctx context.Context, i int) error {
+		return seedPosts(ctx, c, ds.threads[i], recs.posts[i], opts.batch)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("seed posts: %w", err)
+	}
+	return ds, nil
+}
+
+// seedPosts sends the posts of a thread in order. The API assigns new ids and
+// a parent must exist before its replies are sent, so a batch ends early at
+// a reply to a post of the same batch.
+func seedPosts(ctx context.Context, c *client, t *threadState, posts []*dump.Post, size int) error {
+	ids := make(map[int64]int, len(posts))
+	for len(posts) > 0 {
+		batch := make([]post, 0, size)
+		pending := make(map[int64]bool, size)
+		n := 0
+		for ; n < len(posts) && n < size; n++ {
+			p := posts[n]
+			if pending[p.Parent] {
+				break
+			}
+			pending[p.ID] = true
+			batch = append(batch, post{Parent: ids[p.Parent], Author: p.Author, Message: p.Message})
+		}
+
+		var created []post
+		if _, _, err := c.do(ctx, http.MethodPost, fmt.Sprintf("/api/thread/%d/create", t.id), batch, &created, http.StatusCreated); err != nil {
+			return err
+		}
+		if len(created) != len(batch) {
+			return errors.New("created a different number of posts")
+		}
+
+		t.mu.Lock()
+		for i, p := range created {
+			ids[posts[i].ID] = p.ID
+			t.posts = append(t.posts, postRef{id: p.ID, depth: len(posts[i].Path)})
+		}
+		t.mu.Unlock()
+		posts = posts[n:]
+	}
+	return nil
+}
+
+// discover finds the dataset created by an earlier run with the same seed and sizes.
+func discover(ctx context.Context, c *client, opts seedOptions) (*dataset, error) {
+	ds := &dataset{
+		users:   make([]string, opts.users),
+		forums:  make([]string, opts.forums),
+		threads: make([]*threadState, opts.threads),
+	}
+	for i := range ds.users {
+		ds.users[i] = userName(opts.seed, i)
+	}
+	for i := range ds.forums {
+		ds.forums[i] = forumSlug(opts.seed, i)
+	}
+
+	err := parallel(ctx, opts.threads, opts.workers, func(ctx context.Context, i int) error {
+		slug := threadSlug(opts.seed, i)
+		var found struct {
+			thread
+			Forum string `json:"forum"`
+		}
+		if _, _, err := c.do(ctx, http.MethodGet, "/api/thread/"+slug+"/details", nil, &found, http.StatusOK); err != nil {
+			return fmt.Errorf("thread %s: %w", slug, err)
+		}
+		ds.threads[i] = &threadState{id: found.ID, slug: slug, forum: found.Forum}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("discover dataset, seed it first: %w", err)
+	}
+	return ds, loadPosts(ctx, c, ds, opts.workers)
+}
+
+// loadPosts reads the post ids of every thread in tree order to restore their depth.
+func loadPosts(ctx context.Context, c *client, ds *dataset, workers int) error {
+	return parallel(ctx, len(ds.threads), workers, func(ctx context.Context, i int) error {
+		t := ds.threads[i]
+		depth := make(map[int]int)
+		since := 0
+		for {
+			var posts []post
+			path := fmt.Sprintf("/api/thread/%d/posts?sort=tree&limit=10000", t.id)
+			if since != 0 {
+				path += "&since=" + strconv.Itoa(since)
+			}
+			if _, _, err := c.do(ctx, http.MethodGet, path, nil, &posts, http.StatusOK); err != nil {
+				return fmt.Errorf("posts of thread %s: %w", t.slug, err)
+			}
+			t.mu.Lock()
+			for _, p := range posts {
+				d := 1
+				if p.Parent != 0 {
+					d = depth[p.Parent] + 1
+				}
+				depth[p.ID] = d
+				t.posts = append(t.posts, postRef{id: p.ID, depth: d})
+			}
+			t.mu.Unlock()
+			if len(posts) < 10000 {
+				return nil
+			}
+			since = posts[len(posts)-1].ID
+		}
+	})
+}
+
+// newThreadPicker returns a Zipf distributed thread index drawn from rng, a
+// few threads get most of the requests. perm spreads the hot threads over
+// forums and is shared by the workers, each with its own rng.
+func newThreadPicker(perm []int, rng *rand.Rand) func() int {
+	if len(perm) < 2 {
+		return func() int { return 0 }
+	}
+	zipf := rand.NewZipf(rng, 1.1, 4, uint64(len(perm)-1))
+	return func() int {
+		return perm[zipf.Uint64()]
+	}
+}
+
+// createPosts sends a batch of posts to the thread. Parents are mostly recent
+// posts, which builds long branches, and never deeper than maxDepth.
+func createPosts(ctx context.Context, c *client, ds *dataset, t *threadState, rng *rand.Rand, size, maxDepth int) (int, time.Duration, error) {
+	batch := make([]post, size)
+	depths := make([]int, size)
+	t.mu.Lock()
+	for i := range batch {
+		batch[i] = post{
+			Author:  ds.users[rng.Intn(len(ds.users))],
+			Message: synth.Text(rng, 3, 50),
+		}
+		depths[i] = 1
+		if len(t.posts) == 0 || rng.Intn(5) == 0 {
+			continue
+		}
+		window := len(t.posts)
+		if window > 50 && rng.Intn(4) != 0 {
+			window = 50
+		}
+		parent := t.posts[len(t.posts)-1-rng.Intn(window)]
+		if parent.depth < maxDepth {
+			batch[i].Parent = parent.id
+			depths[i] = parent.depth + 1
+		}
+	}
+	t.mu.Unlock()
+
+	var created []post
+	status, latency, err := c.do(ctx, http.MethodPost, fmt.Sprintf("/api/thread/%d/create", t.id), batch, &created, http.StatusCreated)
+	if err != nil {
+		return status, latency, err
+	}
+	if len(created) != len(batch) {
+		return status, latency, errors.New("created a different number of posts")
+	}
+
+	t.mu.Lock()
+	for i, p := range created {
+		t.posts = append(t.posts, postRef{id: p.ID, depth: depths[i]})
+	}
+	t.mu.Unlock()
+	return status, latency, nil
+}
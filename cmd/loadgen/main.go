@@ -0,0 +1,176 @@
+// Command loadgen seeds a dataset through the API of a running backend and
+// replays a weighted mix of API calls against it, reporting throughput and
+// latency percentiles per endpoint.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const usage = `usage: loadgen [flags]
+
+Seeds users, forums, threads and post trees, then runs the mix of operations
+for -duration. Objects are named after -seed, so -skip-seed reuses a dataset
+seeded by an earlier run with the same seed and sizes.
+
+operations: %s
+
+flags:
+`
+
+func main() {
+	fs := flag.NewFlagSet("loadgen", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), usage, strings.Join(operationNames(), ", "))
+		fs.PrintDefaults()
+	}
+	url := fs.String("url", "http://localhost:5000", "backend URL")
+	seedValue := fs.Int64("seed", 1, "seed of the dataset and the request mix")
+	users := fs.Int("users", 1000, "users to seed")
+	forums := fs.Int("forums", 20, "forums to seed")
+	threads := fs.Int("threads", 2000, "threads to seed")
+	posts := fs.Int("posts", 100000, "posts to seed")
+	depth := fs.Int("depth", 30, "maximum depth of post trees")
+	seedBatch := fs.Int("seed-batch", 100, "posts per request while seeding")
+	clear := fs.Bool("clear", false, "clear the database before seeding")
+	skipSeed := fs.Bool("skip-seed", false, "reuse the dataset of an earlier run instead of seeding")
+	mix := fs.String("mix", defaultMix, "weighted operations, name=weight,...")
+	batch := fs.Int("batch", 20, "maximum posts per create_posts request")
+	workers := fs.Int("c", 16, "concurrent workers")
+	duration := fs.Duration("duration", 30*time.Second, "run duration, 0 only seeds")
+	rate := fs.Int("rate", 0, "maximum requests per second, 0 is unlimited")
+	timeout := fs.Duration("timeout", 10*time.Second, "request timeout")
+	out := fs.String("o", "", "write the results as JSON to the file")
+	baseline := fs.String("baseline", "", "JSON results of an earlier run to compare with")
+	if err := fs.Parse(os.Args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return
+		}
+		os.Exit(2)
+	}
+
+	ops, err := parseMix(*mix)
+	if err == nil {
+		err = validate(*users, *forums, *threads, *posts, *depth, *seedBatch, *batch, *workers, *rate)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	var base *result
+	if *baseline != "" {
+		res, err := readResult(*baseline)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "read baseline:", err)
+			os.Exit(1)
+		}
+		base = &res
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	c := newClient(*url, *workers, *timeout)
+	progress := func(msg string) { fmt.Fprintln(os.Stderr, msg) }
+
+	if *clear {
+		if _, _, err = c.do(ctx, http.MethodPost, "/api/service/clear", nil, nil, http.StatusOK); err != nil {
+			fmt.Fprintln(os.Stderr, "clear:", err)
+			os.Exit(1)
+		}
+	}
+
+	opts := seedOptions{
+		seed:    *seedValue,
+		users:   *users,
+		forums:  *forums,
+		threads: *threads,
+		posts:   *posts,
+		depth:   *depth,
+		batch:   *seedBatch,
+		workers: *workers,
+		cleared: *clear,
+	}
+	start := time.Now()
+	var ds *dataset
+	if *skipSeed {
+		progress("loading the dataset")
+		ds, err = discover(ctx, c, opts)
+	} else {
+		ds, err = seed(ctx, c, opts, progress)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	progress(fmt.Sprintf("dataset ready in %s", time.Since(start).Round(time.Millisecond)))
+	if *duration == 0 {
+		return
+	}
+
+	progress(fmt.Sprintf("running for %s with %d workers", *duration, *workers))
+	started := time.Now().UTC()
+	recorders, elapsed := replay(ctx, c, ds, ops, runOptions{
+		workers:  *workers,
+		duration: *duration,
+		rate:     *rate,
+		batch:    *batch,
+		depth:    *depth,
+		seed:     *seedValue,
+	})
+
+	res := buildResult(recorders, elapsed)
+	res.Started = started
+	res.URL = *url
+	res.Options = map[string]interface{}{
+		"seed":     *seedValue,
+		"users":    *users,
+		"forums":   *forums,
+		"threads":  *threads,
+		"posts":    *posts,
+		"depth":    *depth,
+		"mix":      *mix,
+		"batch":    *batch,
+		"workers":  *workers,
+		"duration": duration.String(),
+		"rate":     *rate,
+	}
+	printResult(os.Stdout, res)
+	if base != nil {
+		printComparison(os.Stdout, res, *base)
+	}
+	if *out != "" {
+		if err = writeResult(*out, res); err != nil {
+			fmt.Fprintln(os.Stderr, "write results:", err)
+			os.Exit(1)
+		}
+	}
+}
+
+func validate(users, forums, threads, posts, depth, seedBatch, batch, workers, rate int) error {
+	switch {
+	case users < 1 || forums < 1 || threads < 1:
+		return errors.New("-users, -forums and -threads must be positive")
+	case posts < 0:
+		return errors.New("-posts must not be negative")
+	case depth < 1:
+		return errors.New("-depth must be positive")
+	case seedBatch < 1 || batch < 1:
+		return errors.New("-seed-batch and -batch must be positive")
+	case workers < 1:
+		return errors.New("-c must be positive")
+	case rate < 0:
+		return errors.New("-rate must not be negative")
+	}
+	return nil
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+	"time"
+)
+
+// recorder collects the latencies and statuses of one operation.
+type recorder struct {
+	latencies []time.Duration
+	statuses  map[int]int
+	errors    int
+	lastError string
+}
+
+func newRecorder() *recorder {
+	return &recorder{statuses: make(map[int]int)}
+}
+
+func (r *recorder) add(status int, latency time.Duration, err error) {
+	r.latencies = append(r.latencies, latency)
+	r.statuses[status]++
+	if err != nil {
+		r.errors++
+		r.lastError = err.Error()
+	}
+}
+
+func (r *recorder) merge(other *recorder) {
+	r.latencies = append(r.latencies, other.latencies...)
+	for status, n := range other.statuses {
+		r.statuses[status] += n
+	}
+	r.errors += other.errors
+	if other.lastError != "" {
+		r.lastError = other.lastError
+	}
+}
+
+// result is the JSON saved after a run. Latencies are in milliseconds.
+type result struct {
+	Started   time.Time              `json:"started"`
+	Duration  float64                `json:"duration_seconds"`
+	URL       string                 `json:"url"`
+	Options   map[string]interface{} `json:"options"`
+	Total     endpointResult         `json:"total"`
+	Endpoints []endpointResult       `json:"endpoints"`
+}
+
+type endpointResult struct {
+	Name       string         `json:"name"`
+	Requests   int            `json:"requests"`
+	Errors     int            `json:"errors"`
+	Throughput float64        `json:"throughput"`
+	Latency    latencyResult  `json:"latency_ms"`
+	Statuses   map[string]int `json:"statuses"`
+	LastError  string         `json:"last_error,omitempty"`
+}
+
+type latencyResult struct {
+	Mean float64 `json:"mean"`
+	P50  float64 `json:"p50"`
+	P90  float64 `json:"p90"`
+	P95  float64 `json:"p95"`
+	P99  float64 `json:"p99"`
+	Max  float64 `json:"max"`
+}
+
+func summarize(name string, r *recorder, elapsed time.Duration) endpointResult {
+	latencies := append([]time.Duration(nil), r.latencies...)
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+
+	res := endpointResult{
+		Name:       name,
+		Requests:   len(latencies),
+		Errors:     r.errors,
+		Throughput: float64(len(latencies)) / elapsed.Seconds(),
+		Statuses:   make(map[string]int, len(r.statuses)),
+		LastError:  r.lastError,
+	}
+	for status, n := range r.statuses {
+		key := strconv.Itoa(status)
+		if status == 0 {
+			key = "transport"
+		}
+		res.Statuses[key] = n
+	}
+	if len(latencies) == 0 {
+		return res
+	}
+
+	var sum time.Duration
+	for _, latency := range latencies {
+		sum += latency
+	}
+	res.Latency = latencyResult{
+		Mean: ms(sum / time.Duration(len(latencies))),
+		P50:  ms(percentile(latencies, 0.50)),
+		P90:  ms(percentile(latencies, 0.90)),
+		P95:  ms(percentile(latencies, 0.95)),
+		P99:  ms(percentile(latencies, 0.99)),
+		Max:  ms(latencies[len(latencies)-1]),
+	}
+	return res
+}
+
+// percentile uses the nearest rank of the sorted latencies.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(p*float64(len(sorted))+0.5) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	if rank >= len(sorted) {
+		rank = len(sorted) - 1
+	}
+	return sorted[rank]
+}
+
+func ms(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+func buildResult(recorders map[string]*recorder, elapsed time.Duration) result {
+	res := result{Duration: elapsed.Seconds()}
+	total := newRecorder()
+	for _, name := range operationNames() {
+		r, ok := recorders[name]
+		if !ok {
+			continue
+		}
+		res.Endpoints = append(res.Endpoints, summarize(name, r, elapsed))
+		total.merge(r)
+	}
+	res.Total = summarize("total", total, elapsed)
+	res.Total.LastError = ""
+	return res
+}
+
+func printResult(w io.Writer, res result) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "endpoint\trequests\terrors\treq/s\tmean\tp50\tp90\tp95\tp99\tmax\t")
+	for _, e := range append(res.Endpoints, res.Total) {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%.1f\t%.2f\t%.2f\t%.2f\t%.2f\t%.2f\t%.2f\t\n", e.Name, e.Requests, e.Errors,
+			e.Throughput, e.Latency.Mean, e.Latency.P50, e.Latency.P90, e.Latency.P95, e.Latency.P99, e.Latency.Max)
+	}
+	tw.Flush()
+	fmt.Fprintln(w, "latencies in ms")
+
+	for _, e := range res.Endpoints {
+		if e.LastError != "" {
+			fmt.Fprintf(w, "%s: last error: %s\n", e.Name, e.LastError)
+		}
+	}
+}
+
+// printComparison shows the change of throughput and latency against an
+// earlier run, negative latency changes are improvements.
+func printComparison(w io.Writer, res, baseline result) {
+	old := make(map[string]endpointResult, len(baseline.Endpoints)+1)
+	for _, e := range baseline.Endpoints {
+		old[e.Name] = e
+	}
+	old[baseline.Total.Name] = baseline.Total
+
+	fmt.Fprintf(w, "\ncompared to the run of %s\n", baseline.Started.Format(time.RFC3339))
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "endpoint\treq/s\tp50\tp95\tp99\t")
+	for _, e := range append(res.Endpoints, res.Total) {
+		b, ok := old[e.Name]
+		if !ok {
+			continue
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t\n", e.Name, change(e.Throughput, b.Throughput),
+			change(e.Latency.P50, b.Latency.P50), change(e.Latency.P95, b.Latency.P95), change(e.Latency.P99, b.Latency.P99))
+	}
+	tw.Flush()
+}
+
+func change(now, before float64) string {
+	if before == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%+.1f%%", (now-before)/before*100)
+}
+
+func readResult(path string) (result, error) {
+	var res result
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return res, err
+	}
+	if err = json.Unmarshal(data, &res); err != nil {
+		return res, fmt.Errorf("%s: %w", path, err)
+	}
+	return res, nil
+}
+
+func writeResult(path string, res result) error {
+	data, err := json.MarshalIndent(res, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
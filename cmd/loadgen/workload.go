@@ -0,0 +1,234 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const defaultMix = "create_posts=10,posts_flat=15,posts_tree=15,posts_parent_tree=15,vote=15,forum_users=15,forum_threads=10,thread_details=5"
+
+// operation is one kind of API call of the workload.
+type operation struct {
+	name   string
+	weight int
+	run    func(ctx context.Context, w *worker) (int, time.Duration, error)
+}
+
+type worker struct {
+	c        *client
+	ds       *dataset
+	rng      *rand.Rand
+	pick     func() int
+	batch    int
+	maxDepth int
+}
+
+func (w *worker) thread() *threadState {
+	return w.ds.threads[w.pick()]
+}
+
+// since returns a random post of the thread to page from, or 0 for the first page.
+func (w *worker) since(t *threadState) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.posts) == 0 || w.rng.Intn(2) == 0 {
+		return 0
+	}
+	return t.posts[w.rng.Intn(len(t.posts))].id
+}
+
+func (w *worker) page() string {
+	q := "limit=" + strconv.Itoa([]int{10, 20, 50, 100}[w.rng.Intn(4)])
+	if w.rng.Intn(3) == 0 {
+		q += "&desc=true"
+	}
+	return q
+}
+
+func readPosts(sort string) func(ctx context.Context, w *worker) (int, time.Duration, error) {
+	return func(ctx context.Context, w *worker) (int, time.Duration, error) {
+		t := w.thread()
+		path := fmt.Sprintf("/api/thread/%d/posts?sort=%s&%s", t.id, sort, w.page())
+		if since := w.since(t); since != 0 {
+			path += "&since=" + strconv.Itoa(since)
+		}
+		return w.c.do(ctx, http.MethodGet, path, nil, nil, http.StatusOK)
+	}
+}
+
+var operations = map[string]func(ctx context.Context, w *worker) (int, time.Duration, error){
+	"create_posts": func(ctx context.Context, w *worker) (int, time.Duration, error) {
+		return createPosts(ctx, w.c, w.ds, w.thread(), w.rng, 1+w.rng.Intn(w.batch), w.maxDepth)
+	},
+	"posts_flat":        readPosts("flat"),
+	"posts_tree":        readPosts("tree"),
+	"posts_parent_tree": readPosts("parent_tree"),
+	"vote": func(ctx context.Context, w *worker) (int, time.Duration, error) {
+		voice := 1
+		if w.rng.Intn(3) == 0 {
+			voice = -1
+		}
+		return w.c.do(ctx, http.MethodPost, "/api/thread/"+w.thread().slug+"/vote", vote{
+			Nickname: w.ds.users[w.rng.Intn(len(w.ds.users))],
+			Voice:    voice,
+		}, nil, http.StatusOK)
+	},
+	"forum_users": func(ctx context.Context, w *worker) (int, time.Duration, error) {
+		path := "/api/forum/" + w.thread().forum + "/users?" + w.page()
+		if w.rng.Intn(2) == 0 {
+			path += "&since=" + w.ds.users[w.rng.Intn(len(w.ds.users))]
+		}
+		return w.c.do(ctx, http.MethodGet, path, nil, nil, http.StatusOK)
+	},
+	"forum_threads": func(ctx context.Context, w *worker) (int, time.Duration, error) {
+		path := "/api/forum/" + w.thread().forum + "/threads?" + w.page()
+		if w.rng.Intn(2) == 0 {
+			since := time.Now().UTC().Add(-time.Duration(w.rng.Int63n(int64(365 * 24 * time.Hour))))
+			path += "&since=" + since.Format(time.RFC3339)
+		}
+		return w.c.do(ctx, http.MethodGet, path, nil, nil, http.StatusOK)
+	},
+	"thread_details": func(ctx context.Context, w *worker) (int, time.Duration, error) {
+		return w.c.do(ctx, http.MethodGet, "/api/thread/"+w.thread().slug+"/details", nil, nil, http.StatusOK)
+	},
+}
+
+// parseMix reads "name=weight,..." into operations, zero weights are dropped.
+func parseMix(mix string) ([]operation, error) {
+	var ops []operation
+	for _, item := range strings.Split(mix, ",") {
+		name, weight, ok := strings.Cut(strings.TrimSpace(item), "=")
+		if !ok {
+			return nil, fmt.Errorf("mix item %q is not name=weight", item)
+		}
+		run, ok := operations[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown operation %q", name)
+		}
+		w, err := strconv.Atoi(weight)
+		if err != nil || w < 0 {
+			return nil, fmt.Errorf("invalid weight of %s: %q", name, weight)
+		}
+		if w > 0 {
+			ops = append(ops, operation{name: name, weight: w, run: run})
+		}
+	}
+	if len(ops) == 0 {
+		return nil, fmt.Errorf("mix %q has no operations", mix)
+	}
+	return ops, nil
+}
+
+func operationNames() []string {
+	names := make([]string, 0, len(operations))
+	for name := range operations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+type runOptions struct {
+	workers  int
+	duration time.Duration
+	rate     int
+	batch    int
+	depth    int
+	seed     int64
+}
+
+// replay runs the mix until the duration passes or ctx is canceled. A positive
+// rate caps the requests per second of all workers together.
+func replay(ctx context.Context, c *client, ds *dataset, ops []operation, opts runOptions) (map[string]*recorder, time.Duration) {
+	ctx, cancel := context.WithTimeout(ctx, opts.duration)
+	defer cancel()
+
+	total := 0
+	for _, op := range ops {
+		total += op.weight
+	}
+
+	var tokens <-chan time.Time
+	if opts.rate > 0 {
+		ticker := time.NewTicker(time.Second / time.Duration(opts.rate))
+		defer ticker.Stop()
+		tokens = ticker.C
+	}
+
+	perm := rand.New(rand.NewSource(opts.seed)).Perm(len(ds.threads))
+	results := make([]map[string]*recorder, opts.workers)
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < opts.workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			rng := rand.New(rand.NewSource(opts.seed*4_000_003 + int64(i)))
+			w := &worker{
+				c:        c,
+				ds:       ds,
+				rng:      rng,
+				pick:     newThreadPicker(perm, rng),
+				batch:    opts.batch,
+				maxDepth: opts.depth,
+			}
+			recorders := make(map[string]*recorder, len(ops))
+			results[i] = recorders
+			for {
+				if tokens != nil {
+					select {
+					case <-tokens:
+					case <-ctx.Done():
+						return
+					}
+				}
+				if ctx.Err() != nil {
+					return
+				}
+
+				n := w.rng.Intn(total)
+				op := ops[0]
+				for _, candidate := range ops {
+					if n < candidate.weight {
+						op = candidate
+						break
+					}
+					n -= candidate.weight
+				}
+
+				status, latency, err := op.run(ctx, w)
+				if ctx.Err() != nil {
+					// Requests cut by the end of the run are not counted.
+					return
+				}
+				r, ok := recorders[op.name]
+				if !ok {
+					r = newRecorder()
+					recorders[op.name] = r
+				}
+				r.add(status, latency, err)
+			}
+		}(i)
+	}
+	wg.Wait()
+	elapsed := time.Since(start)
+
+	merged := make(map[string]*recorder)
+	for _, recorders := range results {
+		for name, r := range recorders {
+			if m, ok := merged[name]; ok {
+				m.merge(r)
+			} else {
+				merged[name] = r
+			}
+		}
+	}
+	return merged, elapsed
+}
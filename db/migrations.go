@@ -0,0 +1,8 @@
+package db
+
+import "embed"
+
+// Migrations holds the numbered schema migrations, NNNN_name.up.sql and NNNN_name.down.sql.
+//
+//go:embed migrations/*.sql
+var Migrations embed.FS
@@ -0,0 +1,13 @@
+DROP TABLE IF EXISTS votes;
+DROP TABLE IF EXISTS posts;
+DROP TABLE IF EXISTS threads;
+DROP TABLE IF EXISTS forum_users;
+DROP TABLE IF EXISTS forums;
+DROP TABLE IF EXISTS users;
+
+DROP FUNCTION IF EXISTS update_post_path();
+DROP FUNCTION IF EXISTS update_forum_users();
+DROP FUNCTION IF EXISTS increment_forum_threads();
+DROP FUNCTION IF EXISTS increment_forum_posts();
+DROP FUNCTION IF EXISTS increment_thread_votes();
+DROP FUNCTION IF EXISTS update_thread_votes();
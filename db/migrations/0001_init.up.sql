@@ -77,7 +77,7 @@ BEGIN
 END;
 $$ LANGUAGE plpgsql;
 
-CREATE TRIGGER update_post_path_trigger
+CREATE OR REPLACE TRIGGER update_post_path_trigger
     BEFORE INSERT
     ON posts
     FOR EACH ROW
@@ -106,13 +106,13 @@ BEGIN
 END;
 $$ LANGUAGE plpgsql;
 
-CREATE TRIGGER update_forum_users_by_post_trigger
+CREATE OR REPLACE TRIGGER update_forum_users_by_post_trigger
     AFTER INSERT
     ON posts
     FOR EACH ROW
 EXECUTE FUNCTION update_forum_users();
 
-CREATE TRIGGER update_forum_users_by_thread_trigger
+CREATE OR REPLACE TRIGGER update_forum_users_by_thread_trigger
     AFTER INSERT
     ON threads
     FOR EACH ROW
@@ -169,25 +169,25 @@ END;
 $$
     LANGUAGE plpgsql;
 
-CREATE TRIGGER increment_forum_threads_trigger
+CREATE OR REPLACE TRIGGER increment_forum_threads_trigger
     AFTER INSERT
     ON threads
     FOR EACH ROW
 EXECUTE FUNCTION increment_forum_threads();
 
-CREATE TRIGGER increment_forum_posts_trigger
+CREATE OR REPLACE TRIGGER increment_forum_posts_trigger
     AFTER INSERT
     ON posts
     FOR EACH ROW
 EXECUTE FUNCTION increment_forum_posts();
 
-CREATE TRIGGER increment_thread_votes_trigger
+CREATE OR REPLACE TRIGGER increment_thread_votes_trigger
     AFTER INSERT
     ON votes
     FOR EACH ROW
 EXECUTE FUNCTION increment_thread_votes();
 
-CREATE TRIGGER update_thread_votes_trigger
+CREATE OR REPLACE TRIGGER update_thread_votes_trigger
     AFTER UPDATE
     ON votes
     FOR EACH ROW
@@ -218,5 +218,3 @@ CREATE INDEX IF NOT EXISTS user_vote ON votes (nickname, thread);
 -- Posts
 CREATE INDEX IF NOT EXISTS post_id_hash ON posts using hash (id);
 CREATE INDEX IF NOT EXISTS post_thread_hash ON posts using hash (thread);
-
-VACUUM ANALYZE;
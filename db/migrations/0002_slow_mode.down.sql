@@ -0,0 +1,7 @@
+DROP TRIGGER IF EXISTS check_thread_slow_mode_trigger ON posts;
+DROP FUNCTION IF EXISTS check_thread_slow_mode();
+
+DROP TABLE IF EXISTS rate_limits;
+
+ALTER TABLE threads
+    DROP COLUMN IF EXISTS slow_mode;
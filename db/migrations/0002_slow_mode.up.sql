@@ -0,0 +1,36 @@
+ALTER TABLE threads
+    ADD COLUMN IF NOT EXISTS slow_mode int NOT NULL DEFAULT 0;
+
+CREATE UNLOGGED TABLE IF NOT EXISTS rate_limits
+(
+    key     text PRIMARY KEY,
+    tokens  double precision         NOT NULL,
+    allowed boolean                  NOT NULL,
+    updated timestamp with time zone NOT NULL
+);
+
+-- Триггер медленного режима треда: автор может оставлять не больше одного поста
+-- в треде за slow_mode секунд.
+CREATE OR REPLACE FUNCTION check_thread_slow_mode()
+    RETURNS TRIGGER AS
+$$
+DECLARE
+    slow_mode_tmp int;
+BEGIN
+    SELECT slow_mode FROM threads WHERE id = NEW.thread INTO slow_mode_tmp;
+    IF slow_mode_tmp > 0 AND EXISTS(SELECT 1
+                                    FROM posts
+                                    WHERE thread = NEW.thread
+                                      AND author = NEW.author
+                                      AND created > NEW.created - make_interval(secs => slow_mode_tmp)) THEN
+        RAISE EXCEPTION 'Slow mode';
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE TRIGGER check_thread_slow_mode_trigger
+    BEFORE INSERT
+    ON posts
+    FOR EACH ROW
+EXECUTE FUNCTION check_thread_slow_mode();
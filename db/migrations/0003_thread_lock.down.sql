@@ -0,0 +1,5 @@
+DROP TRIGGER IF EXISTS check_thread_locked_trigger ON posts;
+DROP FUNCTION IF EXISTS check_thread_locked();
+
+ALTER TABLE threads
+    DROP COLUMN IF EXISTS locked;
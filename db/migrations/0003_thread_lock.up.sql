@@ -0,0 +1,20 @@
+ALTER TABLE threads
+    ADD COLUMN IF NOT EXISTS locked boolean NOT NULL DEFAULT false;
+
+-- Триггер закрытого треда: в закрытый тред нельзя добавлять посты.
+CREATE OR REPLACE FUNCTION check_thread_locked()
+    RETURNS TRIGGER AS
+$$
+BEGIN
+    IF EXISTS(SELECT 1 FROM threads WHERE id = NEW.thread AND locked) THEN
+        RAISE EXCEPTION 'Thread locked';
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE TRIGGER check_thread_locked_trigger
+    BEFORE INSERT
+    ON posts
+    FOR EACH ROW
+EXECUTE FUNCTION check_thread_locked();
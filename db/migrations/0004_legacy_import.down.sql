@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS legacy_checkpoints;
+DROP TABLE IF EXISTS legacy_posts;
+DROP TABLE IF EXISTS legacy_threads;
+DROP TABLE IF EXISTS legacy_forums;
+DROP TABLE IF EXISTS legacy_users;
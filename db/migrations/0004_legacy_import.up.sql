@@ -0,0 +1,49 @@
+-- Соответствие идентификаторов импортированных сообществ (phpBB, Discourse) записям форума.
+-- source - имя импорта, под ним же хранятся контрольные точки.
+CREATE TABLE IF NOT EXISTS legacy_users
+(
+    source    text   NOT NULL,
+    legacy_id bigint NOT NULL,
+    nickname  citext NOT NULL,
+    PRIMARY KEY (source, legacy_id)
+);
+
+CREATE TABLE IF NOT EXISTS legacy_forums
+(
+    source    text   NOT NULL,
+    legacy_id bigint NOT NULL,
+    slug      citext NOT NULL,
+    PRIMARY KEY (source, legacy_id)
+);
+
+CREATE TABLE IF NOT EXISTS legacy_threads
+(
+    source     text   NOT NULL,
+    legacy_id  bigint NOT NULL,
+    id         bigint NOT NULL,
+    first_post bigint,
+    PRIMARY KEY (source, legacy_id)
+);
+
+-- number - номер поста в теме, по нему Discourse ссылается на родителя.
+CREATE TABLE IF NOT EXISTS legacy_posts
+(
+    source    text   NOT NULL,
+    legacy_id bigint NOT NULL,
+    id        bigint NOT NULL,
+    topic_id  bigint NOT NULL,
+    number    int,
+    PRIMARY KEY (source, legacy_id)
+);
+
+CREATE INDEX IF NOT EXISTS legacy_posts_number ON legacy_posts (source, topic_id, number);
+
+-- Контрольная точка: сколько записей этапа уже импортировано.
+CREATE TABLE IF NOT EXISTS legacy_checkpoints
+(
+    source     text                     NOT NULL,
+    stage      text                     NOT NULL,
+    position   bigint                   NOT NULL,
+    updated_at timestamp with time zone NOT NULL DEFAULT now(),
+    PRIMARY KEY (source, stage)
+);
@@ -0,0 +1,23 @@
+DROP TRIGGER IF EXISTS stats_posts_moved_trigger ON posts;
+DROP TRIGGER IF EXISTS stats_threads_moved_trigger ON threads;
+DROP TRIGGER IF EXISTS stats_forum_users_inserted_trigger ON forum_users;
+DROP TRIGGER IF EXISTS stats_votes_inserted_trigger ON votes;
+DROP TRIGGER IF EXISTS stats_posts_inserted_trigger ON posts;
+DROP TRIGGER IF EXISTS stats_threads_inserted_trigger ON threads;
+DROP TRIGGER IF EXISTS stats_forums_inserted_trigger ON forums;
+DROP TRIGGER IF EXISTS stats_users_inserted_trigger ON users;
+
+DROP FUNCTION IF EXISTS stats_rebuild();
+DROP FUNCTION IF EXISTS stats_posts_moved();
+DROP FUNCTION IF EXISTS stats_threads_moved();
+DROP FUNCTION IF EXISTS stats_forum_users_inserted();
+DROP FUNCTION IF EXISTS stats_votes_inserted();
+DROP FUNCTION IF EXISTS stats_posts_inserted();
+DROP FUNCTION IF EXISTS stats_threads_inserted();
+DROP FUNCTION IF EXISTS stats_forums_inserted();
+DROP FUNCTION IF EXISTS stats_users_inserted();
+DROP FUNCTION IF EXISTS stats_add_total(text, bigint);
+
+DROP TABLE IF EXISTS stats_hourly;
+DROP TABLE IF EXISTS stats_forums;
+DROP TABLE IF EXISTS stats_totals;
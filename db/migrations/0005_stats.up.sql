@@ -0,0 +1,287 @@
+-- Агрегаты для статистики сервиса, поддерживаются триггерами уровня оператора,
+-- чтобы /api/service/status и /api/service/stats не считали count() по большим таблицам.
+-- Глобальные суммы тредов, постов и голосов складываются из stats_forums, поэтому
+-- вставки в разные форумы не конкурируют за одну строку.
+CREATE TABLE IF NOT EXISTS stats_totals
+(
+    name  text PRIMARY KEY,
+    value bigint NOT NULL DEFAULT 0
+);
+
+CREATE TABLE IF NOT EXISTS stats_forums
+(
+    forum   citext PRIMARY KEY,
+    threads bigint NOT NULL DEFAULT 0,
+    posts   bigint NOT NULL DEFAULT 0,
+    votes   bigint NOT NULL DEFAULT 0,
+    users   bigint NOT NULL DEFAULT 0
+);
+
+CREATE TABLE IF NOT EXISTS stats_hourly
+(
+    forum   citext                   NOT NULL,
+    bucket  timestamp with time zone NOT NULL,
+    threads bigint                   NOT NULL DEFAULT 0,
+    posts   bigint                   NOT NULL DEFAULT 0,
+    PRIMARY KEY (forum, bucket)
+);
+
+CREATE INDEX IF NOT EXISTS stats_hourly_bucket ON stats_hourly (bucket);
+
+CREATE OR REPLACE FUNCTION stats_add_total(name_tmp text, delta bigint)
+    RETURNS void AS
+$$
+BEGIN
+    IF delta <> 0 THEN
+        INSERT INTO stats_totals (name, value)
+        VALUES (name_tmp, delta)
+        ON CONFLICT (name) DO UPDATE SET value = stats_totals.value + excluded.value;
+    END IF;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Пользователи
+CREATE OR REPLACE FUNCTION stats_users_inserted()
+    RETURNS TRIGGER AS
+$$
+BEGIN
+    PERFORM stats_add_total('users', (SELECT count(*) FROM new_rows));
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE TRIGGER stats_users_inserted_trigger
+    AFTER INSERT
+    ON users
+    REFERENCING NEW TABLE AS new_rows
+    FOR EACH STATEMENT
+EXECUTE FUNCTION stats_users_inserted();
+
+-- Форумы
+CREATE OR REPLACE FUNCTION stats_forums_inserted()
+    RETURNS TRIGGER AS
+$$
+BEGIN
+    PERFORM stats_add_total('forums', (SELECT count(*) FROM new_rows));
+    INSERT INTO stats_forums (forum)
+    SELECT slug FROM new_rows ORDER BY slug
+    ON CONFLICT DO NOTHING;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE TRIGGER stats_forums_inserted_trigger
+    AFTER INSERT
+    ON forums
+    REFERENCING NEW TABLE AS new_rows
+    FOR EACH STATEMENT
+EXECUTE FUNCTION stats_forums_inserted();
+
+-- Треды: счетчик форума и почасовой ряд. Строки форумов обновляются в порядке slug,
+-- чтобы параллельные вставки не приводили к взаимоблокировкам.
+CREATE OR REPLACE FUNCTION stats_threads_inserted()
+    RETURNS TRIGGER AS
+$$
+BEGIN
+    INSERT INTO stats_forums (forum, threads)
+    SELECT forum, count(*) FROM new_rows GROUP BY forum ORDER BY forum
+    ON CONFLICT (forum) DO UPDATE SET threads = stats_forums.threads + excluded.threads;
+
+    INSERT INTO stats_hourly (forum, bucket, threads)
+    SELECT forum, date_trunc('hour', coalesce(created, now())), count(*)
+    FROM new_rows
+    GROUP BY 1, 2
+    ORDER BY 1, 2
+    ON CONFLICT (forum, bucket) DO UPDATE SET threads = stats_hourly.threads + excluded.threads;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE TRIGGER stats_threads_inserted_trigger
+    AFTER INSERT
+    ON threads
+    REFERENCING NEW TABLE AS new_rows
+    FOR EACH STATEMENT
+EXECUTE FUNCTION stats_threads_inserted();
+
+-- Посты
+CREATE OR REPLACE FUNCTION stats_posts_inserted()
+    RETURNS TRIGGER AS
+$$
+BEGIN
+    INSERT INTO stats_forums (forum, posts)
+    SELECT forum, count(*) FROM new_rows GROUP BY forum ORDER BY forum
+    ON CONFLICT (forum) DO UPDATE SET posts = stats_forums.posts + excluded.posts;
+
+    INSERT INTO stats_hourly (forum, bucket, posts)
+    SELECT forum, date_trunc('hour', coalesce(created, now())), count(*)
+    FROM new_rows
+    GROUP BY 1, 2
+    ORDER BY 1, 2
+    ON CONFLICT (forum, bucket) DO UPDATE SET posts = stats_hourly.posts + excluded.posts;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE TRIGGER stats_posts_inserted_trigger
+    AFTER INSERT
+    ON posts
+    REFERENCING NEW TABLE AS new_rows
+    FOR EACH STATEMENT
+EXECUTE FUNCTION stats_posts_inserted();
+
+-- Голоса: учитывается количество голосов, смена голоса его не меняет.
+CREATE OR REPLACE FUNCTION stats_votes_inserted()
+    RETURNS TRIGGER AS
+$$
+BEGIN
+    INSERT INTO stats_forums (forum, votes)
+    SELECT t.forum, count(*)
+    FROM new_rows v
+             JOIN threads t ON t.id = v.thread
+    GROUP BY t.forum
+    ORDER BY t.forum
+    ON CONFLICT (forum) DO UPDATE SET votes = stats_forums.votes + excluded.votes;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE TRIGGER stats_votes_inserted_trigger
+    AFTER INSERT
+    ON votes
+    REFERENCING NEW TABLE AS new_rows
+    FOR EACH STATEMENT
+EXECUTE FUNCTION stats_votes_inserted();
+
+-- Пользователи форума. Активный пользователь - тот, кто писал хотя бы в одном форуме.
+CREATE OR REPLACE FUNCTION stats_forum_users_inserted()
+    RETURNS TRIGGER AS
+$$
+BEGIN
+    INSERT INTO stats_forums (forum, users)
+    SELECT forum, count(*) FROM new_rows GROUP BY forum ORDER BY forum
+    ON CONFLICT (forum) DO UPDATE SET users = stats_forums.users + excluded.users;
+
+    PERFORM stats_add_total('active_users', (SELECT count(DISTINCT n.nickname)
+                                             FROM new_rows n
+                                             WHERE NOT EXISTS(SELECT 1
+                                                              FROM forum_users fu
+                                                              WHERE fu.nickname = n.nickname
+                                                                AND fu.forum <> n.forum)));
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE TRIGGER stats_forum_users_inserted_trigger
+    AFTER INSERT
+    ON forum_users
+    REFERENCING NEW TABLE AS new_rows
+    FOR EACH STATEMENT
+EXECUTE FUNCTION stats_forum_users_inserted();
+
+-- Перенос треда в другой форум переносит его посты, голоса и почасовые значения.
+CREATE OR REPLACE FUNCTION stats_threads_moved()
+    RETURNS TRIGGER AS
+$$
+BEGIN
+    WITH moved AS (SELECT o.forum                                                    AS old_forum,
+                          n.forum                                                    AS new_forum,
+                          date_trunc('hour', coalesce(n.created, now()))             AS bucket,
+                          (SELECT count(*) FROM votes WHERE thread = n.id)           AS votes
+                   FROM old_rows o
+                            JOIN new_rows n ON n.id = o.id
+                   WHERE o.forum <> n.forum),
+         deltas AS (SELECT old_forum AS forum, bucket, -1 AS threads, -votes AS votes FROM moved
+                    UNION ALL
+                    SELECT new_forum, bucket, 1, votes FROM moved),
+         forums AS (
+             INSERT INTO stats_forums (forum, threads, votes)
+                 SELECT forum, sum(threads), sum(votes) FROM deltas GROUP BY forum ORDER BY forum
+                 ON CONFLICT (forum) DO UPDATE SET threads = stats_forums.threads + excluded.threads,
+                     votes = stats_forums.votes + excluded.votes)
+    INSERT
+    INTO stats_hourly (forum, bucket, threads)
+    SELECT forum, bucket, sum(threads)
+    FROM deltas
+    GROUP BY 1, 2
+    ORDER BY 1, 2
+    ON CONFLICT (forum, bucket) DO UPDATE SET threads = stats_hourly.threads + excluded.threads;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE TRIGGER stats_threads_moved_trigger
+    AFTER UPDATE
+    ON threads
+    REFERENCING OLD TABLE AS old_rows NEW TABLE AS new_rows
+    FOR EACH STATEMENT
+EXECUTE FUNCTION stats_threads_moved();
+
+CREATE OR REPLACE FUNCTION stats_posts_moved()
+    RETURNS TRIGGER AS
+$$
+BEGIN
+    WITH moved AS (SELECT o.forum AS old_forum, n.forum AS new_forum, date_trunc('hour', coalesce(n.created, now())) AS bucket
+                   FROM old_rows o
+                            JOIN new_rows n ON n.id = o.id
+                   WHERE o.forum IS DISTINCT FROM n.forum),
+         deltas AS (SELECT old_forum AS forum, bucket, -1 AS posts FROM moved WHERE old_forum IS NOT NULL
+                    UNION ALL
+                    SELECT new_forum, bucket, 1 FROM moved WHERE new_forum IS NOT NULL),
+         forums AS (
+             INSERT INTO stats_forums (forum, posts)
+                 SELECT forum, sum(posts) FROM deltas GROUP BY forum ORDER BY forum
+                 ON CONFLICT (forum) DO UPDATE SET posts = stats_forums.posts + excluded.posts)
+    INSERT
+    INTO stats_hourly (forum, bucket, posts)
+    SELECT forum, bucket, sum(posts)
+    FROM deltas
+    GROUP BY 1, 2
+    ORDER BY 1, 2
+    ON CONFLICT (forum, bucket) DO UPDATE SET posts = stats_hourly.posts + excluded.posts;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE TRIGGER stats_posts_moved_trigger
+    AFTER UPDATE
+    ON posts
+    REFERENCING OLD TABLE AS old_rows NEW TABLE AS new_rows
+    FOR EACH STATEMENT
+EXECUTE FUNCTION stats_posts_moved();
+
+-- Полный пересчет агрегатов по исходным таблицам: начальное заполнение и исправление
+-- после массовых загрузок с отключенными триггерами.
+CREATE OR REPLACE FUNCTION stats_rebuild()
+    RETURNS void AS
+$$
+BEGIN
+    TRUNCATE stats_totals, stats_forums, stats_hourly;
+
+    INSERT INTO stats_totals (name, value)
+    VALUES ('users', (SELECT count(*) FROM users)),
+           ('forums', (SELECT count(*) FROM forums)),
+           ('active_users', (SELECT count(DISTINCT nickname) FROM forum_users));
+
+    INSERT INTO stats_forums (forum, threads, posts, votes, users)
+    SELECT f.slug,
+           (SELECT count(*) FROM threads WHERE forum = f.slug),
+           (SELECT count(*) FROM posts WHERE forum = f.slug),
+           (SELECT count(*) FROM votes v JOIN threads t ON t.id = v.thread WHERE t.forum = f.slug),
+           (SELECT count(*) FROM forum_users WHERE forum = f.slug)
+    FROM forums f;
+
+    INSERT INTO stats_hourly (forum, bucket, threads, posts)
+    SELECT forum, bucket, sum(threads), sum(posts)
+    FROM (SELECT forum, date_trunc('hour', created) AS bucket, 1 AS threads, 0 AS posts
+          FROM threads
+          UNION ALL
+          SELECT forum, date_trunc('hour', created), 0, 1
+          FROM posts
+          WHERE forum IS NOT NULL) c
+    WHERE bucket IS NOT NULL
+    GROUP BY 1, 2;
+END;
+$$ LANGUAGE plpgsql;
+
+SELECT stats_rebuild();
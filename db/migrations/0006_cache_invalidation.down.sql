@@ -0,0 +1,10 @@
+DROP TRIGGER IF EXISTS notify_forums_truncated_trigger ON forums;
+DROP TRIGGER IF EXISTS notify_threads_truncated_trigger ON threads;
+DROP TRIGGER IF EXISTS notify_forums_deleted_trigger ON forums;
+DROP TRIGGER IF EXISTS notify_forums_updated_trigger ON forums;
+DROP TRIGGER IF EXISTS notify_threads_deleted_trigger ON threads;
+DROP TRIGGER IF EXISTS notify_threads_updated_trigger ON threads;
+
+DROP FUNCTION IF EXISTS notify_cache_truncated();
+DROP FUNCTION IF EXISTS notify_forums_changed();
+DROP FUNCTION IF EXISTS notify_threads_changed();
@@ -0,0 +1,75 @@
+-- Сброс кэша тредов и форумов через LISTEN/NOTIFY. Полезная нагрузка совпадает с
+-- ключом кэша: thread:<id>, thread-slug:<slug> или forum:<slug>, слаги в нижнем
+-- регистре, '*' сбрасывает всё.
+-- Одинаковые уведомления внутри транзакции PostgreSQL объединяет, поэтому
+-- счётчик форума, обновляемый на каждый пост, даёт одно уведомление на пачку.
+CREATE OR REPLACE FUNCTION notify_threads_changed()
+    RETURNS TRIGGER AS
+$$
+BEGIN
+    PERFORM pg_notify('cache_invalidation', key)
+    FROM (SELECT 'thread:' || id AS key FROM old_rows
+          UNION
+          SELECT 'thread-slug:' || lower(slug::text) FROM old_rows WHERE slug IS NOT NULL AND slug <> '') k;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION notify_forums_changed()
+    RETURNS TRIGGER AS
+$$
+BEGIN
+    PERFORM pg_notify('cache_invalidation', 'forum:' || lower(slug::text))
+    FROM (SELECT DISTINCT slug FROM old_rows) f;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION notify_cache_truncated()
+    RETURNS TRIGGER AS
+$$
+BEGIN
+    PERFORM pg_notify('cache_invalidation', '*');
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE TRIGGER notify_threads_updated_trigger
+    AFTER UPDATE
+    ON threads
+    REFERENCING OLD TABLE AS old_rows
+    FOR EACH STATEMENT
+EXECUTE FUNCTION notify_threads_changed();
+
+CREATE OR REPLACE TRIGGER notify_threads_deleted_trigger
+    AFTER DELETE
+    ON threads
+    REFERENCING OLD TABLE AS old_rows
+    FOR EACH STATEMENT
+EXECUTE FUNCTION notify_threads_changed();
+
+CREATE OR REPLACE TRIGGER notify_forums_updated_trigger
+    AFTER UPDATE
+    ON forums
+    REFERENCING OLD TABLE AS old_rows
+    FOR EACH STATEMENT
+EXECUTE FUNCTION notify_forums_changed();
+
+CREATE OR REPLACE TRIGGER notify_forums_deleted_trigger
+    AFTER DELETE
+    ON forums
+    REFERENCING OLD TABLE AS old_rows
+    FOR EACH STATEMENT
+EXECUTE FUNCTION notify_forums_changed();
+
+CREATE OR REPLACE TRIGGER notify_threads_truncated_trigger
+    AFTER TRUNCATE
+    ON threads
+    FOR EACH STATEMENT
+EXECUTE FUNCTION notify_cache_truncated();
+
+CREATE OR REPLACE TRIGGER notify_forums_truncated_trigger
+    AFTER TRUNCATE
+    ON forums
+    FOR EACH STATEMENT
+EXECUTE FUNCTION notify_cache_truncated();
@@ -0,0 +1,11 @@
+DROP TRIGGER IF EXISTS set_posts_updated_at_trigger ON posts;
+DROP TRIGGER IF EXISTS set_threads_updated_at_trigger ON threads;
+DROP TRIGGER IF EXISTS set_forums_updated_at_trigger ON forums;
+DROP TRIGGER IF EXISTS set_users_updated_at_trigger ON users;
+
+DROP FUNCTION IF EXISTS set_updated_at();
+
+ALTER TABLE posts DROP COLUMN IF EXISTS updated_at;
+ALTER TABLE threads DROP COLUMN IF EXISTS updated_at;
+ALTER TABLE forums DROP COLUMN IF EXISTS updated_at;
+ALTER TABLE users DROP COLUMN IF EXISTS updated_at;
@@ -0,0 +1,49 @@
+-- Версия строки для ETag и Last-Modified. Триггер обновляет updated_at только
+-- когда строка действительно изменилась, поэтому пересчёт счётчиков без
+-- изменений не сбрасывает кэш клиентов. clock_timestamp() вместо now() даёт
+-- разные версии двум изменениям строки в одной транзакции.
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS updated_at timestamp with time zone NOT NULL DEFAULT now();
+ALTER TABLE forums
+    ADD COLUMN IF NOT EXISTS updated_at timestamp with time zone NOT NULL DEFAULT now();
+ALTER TABLE threads
+    ADD COLUMN IF NOT EXISTS updated_at timestamp with time zone NOT NULL DEFAULT now();
+ALTER TABLE posts
+    ADD COLUMN IF NOT EXISTS updated_at timestamp with time zone NOT NULL DEFAULT now();
+
+CREATE OR REPLACE FUNCTION set_updated_at()
+    RETURNS TRIGGER AS
+$$
+BEGIN
+    NEW.updated_at = clock_timestamp();
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE TRIGGER set_users_updated_at_trigger
+    BEFORE UPDATE
+    ON users
+    FOR EACH ROW
+    WHEN (OLD.* IS DISTINCT FROM NEW.*)
+EXECUTE FUNCTION set_updated_at();
+
+CREATE OR REPLACE TRIGGER set_forums_updated_at_trigger
+    BEFORE UPDATE
+    ON forums
+    FOR EACH ROW
+    WHEN (OLD.* IS DISTINCT FROM NEW.*)
+EXECUTE FUNCTION set_updated_at();
+
+CREATE OR REPLACE TRIGGER set_threads_updated_at_trigger
+    BEFORE UPDATE
+    ON threads
+    FOR EACH ROW
+    WHEN (OLD.* IS DISTINCT FROM NEW.*)
+EXECUTE FUNCTION set_updated_at();
+
+CREATE OR REPLACE TRIGGER set_posts_updated_at_trigger
+    BEFORE UPDATE
+    ON posts
+    FOR EACH ROW
+    WHEN (OLD.* IS DISTINCT FROM NEW.*)
+EXECUTE FUNCTION set_updated_at();
@@ -0,0 +1,9 @@
+DROP TRIGGER IF EXISTS notify_forums_updated_trigger ON forums;
+DROP FUNCTION IF EXISTS notify_forum_changed();
+
+CREATE OR REPLACE TRIGGER notify_forums_updated_trigger
+    AFTER UPDATE
+    ON forums
+    REFERENCING OLD TABLE AS old_rows
+    FOR EACH STATEMENT
+EXECUTE FUNCTION notify_forums_changed();
@@ -0,0 +1,25 @@
+-- Счётчики posts и threads обновляются триггерами на каждую вставку поста и
+-- треда. Уведомление на каждое такое обновление сериализует коммиты всех
+-- пишущих транзакций на глобальной очереди NOTIFY и сбрасывает кэш форума на
+-- каждый пост. Уведомление теперь отправляется только при изменении полей
+-- самого форума. Счётчики сбрасывает декоратор репозитория, который их меняет,
+-- изменения счётчиков в обход него (reconcile, импорт) видны по истечении TTL.
+DROP TRIGGER IF EXISTS notify_forums_updated_trigger ON forums;
+
+CREATE OR REPLACE FUNCTION notify_forum_changed()
+    RETURNS TRIGGER AS
+$$
+BEGIN
+    PERFORM pg_notify('cache_invalidation', 'forum:' || lower(OLD.slug::text));
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE TRIGGER notify_forums_updated_trigger
+    AFTER UPDATE
+    ON forums
+    FOR EACH ROW
+    WHEN (OLD.slug IS DISTINCT FROM NEW.slug OR
+          OLD.title IS DISTINCT FROM NEW.title OR
+          OLD.user_nickname IS DISTINCT FROM NEW.user_nickname)
+EXECUTE FUNCTION notify_forum_changed();
@@ -0,0 +1,46 @@
+DROP TRIGGER IF EXISTS check_posts_thread_trigger ON posts;
+DROP FUNCTION IF EXISTS check_posts_thread();
+
+-- Триггер медленного режима треда: автор может оставлять не больше одного поста
+-- в треде за slow_mode секунд.
+CREATE OR REPLACE FUNCTION check_thread_slow_mode()
+    RETURNS TRIGGER AS
+$$
+DECLARE
+    slow_mode_tmp int;
+BEGIN
+    SELECT slow_mode FROM threads WHERE id = NEW.thread INTO slow_mode_tmp;
+    IF slow_mode_tmp > 0 AND EXISTS(SELECT 1
+                                    FROM posts
+                                    WHERE thread = NEW.thread
+                                      AND author = NEW.author
+                                      AND created > NEW.created - make_interval(secs => slow_mode_tmp)) THEN
+        RAISE EXCEPTION 'Slow mode';
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE TRIGGER check_thread_slow_mode_trigger
+    BEFORE INSERT
+    ON posts
+    FOR EACH ROW
+EXECUTE FUNCTION check_thread_slow_mode();
+
+-- Триггер закрытого треда: в закрытый тред нельзя добавлять посты.
+CREATE OR REPLACE FUNCTION check_thread_locked()
+    RETURNS TRIGGER AS
+$$
+BEGIN
+    IF EXISTS(SELECT 1 FROM threads WHERE id = NEW.thread AND locked) THEN
+        RAISE EXCEPTION 'Thread locked';
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE TRIGGER check_thread_locked_trigger
+    BEFORE INSERT
+    ON posts
+    FOR EACH ROW
+EXECUTE FUNCTION check_thread_locked();
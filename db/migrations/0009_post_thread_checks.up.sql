@@ -0,0 +1,47 @@
+-- Проверки закрытого треда (0003) и медленного режима (0002) выполнялись двумя
+-- триггерами на каждый пост, и каждый читал строку треда отдельно. Теперь это
+-- один триггер уровня оператора: locked и slow_mode читаются одним запросом на
+-- всю вставку, а последние посты автора ищутся только в тредах с медленным
+-- режимом. Посты той же вставки учитываются, как и раньше.
+DROP TRIGGER IF EXISTS check_thread_locked_trigger ON posts;
+DROP TRIGGER IF EXISTS check_thread_slow_mode_trigger ON posts;
+DROP FUNCTION IF EXISTS check_thread_locked();
+DROP FUNCTION IF EXISTS check_thread_slow_mode();
+
+CREATE OR REPLACE FUNCTION check_posts_thread()
+    RETURNS TRIGGER AS
+$$
+DECLARE
+    locked_tmp    boolean;
+    slow_mode_tmp boolean;
+BEGIN
+    SELECT coalesce(bool_or(locked), false), coalesce(bool_or(slow_mode > 0), false)
+    FROM threads
+    WHERE id IN (SELECT thread FROM new_rows)
+    INTO locked_tmp, slow_mode_tmp;
+
+    IF locked_tmp THEN
+        RAISE EXCEPTION 'Thread locked';
+    END IF;
+    IF slow_mode_tmp AND EXISTS(SELECT 1
+                                FROM new_rows n
+                                         JOIN threads t ON t.id = n.thread
+                                WHERE t.slow_mode > 0
+                                  AND EXISTS(SELECT 1
+                                             FROM posts p
+                                             WHERE p.thread = n.thread
+                                               AND p.author = n.author
+                                               AND p.id <> n.id
+                                               AND p.created > n.created - make_interval(secs => t.slow_mode))) THEN
+        RAISE EXCEPTION 'Slow mode';
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE TRIGGER check_posts_thread_trigger
+    AFTER INSERT
+    ON posts
+    REFERENCING NEW TABLE AS new_rows
+    FOR EACH STATEMENT
+EXECUTE FUNCTION check_posts_thread();
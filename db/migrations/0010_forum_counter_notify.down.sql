@@ -0,0 +1,49 @@
+DROP TRIGGER IF EXISTS notify_forums_updated_trigger ON forums;
+
+CREATE OR REPLACE TRIGGER notify_forums_updated_trigger
+    AFTER UPDATE
+    ON forums
+    FOR EACH ROW
+    WHEN (OLD.slug IS DISTINCT FROM NEW.slug OR
+          OLD.title IS DISTINCT FROM NEW.title OR
+          OLD.user_nickname IS DISTINCT FROM NEW.user_nickname)
+EXECUTE FUNCTION notify_forum_changed();
+
+DROP TRIGGER IF EXISTS increment_forum_threads_trigger ON threads;
+DROP TRIGGER IF EXISTS increment_forum_posts_trigger ON posts;
+
+CREATE OR REPLACE FUNCTION increment_forum_threads()
+    RETURNS TRIGGER AS
+$$
+BEGIN
+    UPDATE forums
+    SET threads = threads + 1
+    WHERE slug = NEW.forum;
+    RETURN NEW;
+END;
+$$
+    LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION increment_forum_posts()
+    RETURNS TRIGGER AS
+$$
+BEGIN
+    UPDATE forums
+    SET posts = posts + 1
+    WHERE slug = NEW.forum;
+    RETURN NEW;
+END;
+$$
+    LANGUAGE plpgsql;
+
+CREATE OR REPLACE TRIGGER increment_forum_threads_trigger
+    AFTER INSERT
+    ON threads
+    FOR EACH ROW
+EXECUTE FUNCTION increment_forum_threads();
+
+CREATE OR REPLACE TRIGGER increment_forum_posts_trigger
+    AFTER INSERT
+    ON posts
+    FOR EACH ROW
+EXECUTE FUNCTION increment_forum_posts();
@@ -0,0 +1,58 @@
+-- После 0008 изменения счётчиков threads и posts не сбрасывали кэш форума на
+-- других репликах, а также после reconcile, импорта и forumctl. Счётчики теперь
+-- увеличиваются одним UPDATE на форум за оператор вставки, а не на каждую
+-- строку, и уведомление снова отправляется при их изменении: на пачку постов
+-- приходится одно уведомление на форум.
+DROP TRIGGER IF EXISTS increment_forum_threads_trigger ON threads;
+DROP TRIGGER IF EXISTS increment_forum_posts_trigger ON posts;
+
+CREATE OR REPLACE FUNCTION increment_forum_threads()
+    RETURNS TRIGGER AS
+$$
+BEGIN
+    UPDATE forums f
+    SET threads = f.threads + n.count
+    FROM (SELECT forum, count(*) AS count FROM new_rows GROUP BY forum) n
+    WHERE f.slug = n.forum;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION increment_forum_posts()
+    RETURNS TRIGGER AS
+$$
+BEGIN
+    UPDATE forums f
+    SET posts = f.posts + n.count
+    FROM (SELECT forum, count(*) AS count FROM new_rows GROUP BY forum) n
+    WHERE f.slug = n.forum;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE TRIGGER increment_forum_threads_trigger
+    AFTER INSERT
+    ON threads
+    REFERENCING NEW TABLE AS new_rows
+    FOR EACH STATEMENT
+EXECUTE FUNCTION increment_forum_threads();
+
+CREATE OR REPLACE TRIGGER increment_forum_posts_trigger
+    AFTER INSERT
+    ON posts
+    REFERENCING NEW TABLE AS new_rows
+    FOR EACH STATEMENT
+EXECUTE FUNCTION increment_forum_posts();
+
+DROP TRIGGER IF EXISTS notify_forums_updated_trigger ON forums;
+
+CREATE OR REPLACE TRIGGER notify_forums_updated_trigger
+    AFTER UPDATE
+    ON forums
+    FOR EACH ROW
+    WHEN (OLD.slug IS DISTINCT FROM NEW.slug OR
+          OLD.title IS DISTINCT FROM NEW.title OR
+          OLD.user_nickname IS DISTINCT FROM NEW.user_nickname OR
+          OLD.threads IS DISTINCT FROM NEW.threads OR
+          OLD.posts IS DISTINCT FROM NEW.posts)
+EXECUTE FUNCTION notify_forum_changed();
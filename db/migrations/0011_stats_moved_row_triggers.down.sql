@@ -0,0 +1,72 @@
+DROP TRIGGER IF EXISTS stats_threads_moved_trigger ON threads;
+DROP TRIGGER IF EXISTS stats_posts_moved_trigger ON posts;
+
+CREATE OR REPLACE FUNCTION stats_threads_moved()
+    RETURNS TRIGGER AS
+$$
+BEGIN
+    WITH moved AS (SELECT o.forum                                                    AS old_forum,
+                          n.forum                                                    AS new_forum,
+                          date_trunc('hour', coalesce(n.created, now()))             AS bucket,
+                          (SELECT count(*) FROM votes WHERE thread = n.id)           AS votes
+                   FROM old_rows o
+                            JOIN new_rows n ON n.id = o.id
+                   WHERE o.forum <> n.forum),
+         deltas AS (SELECT old_forum AS forum, bucket, -1 AS threads, -votes AS votes FROM moved
+                    UNION ALL
+                    SELECT new_forum, bucket, 1, votes FROM moved),
+         forums AS (
+             INSERT INTO stats_forums (forum, threads, votes)
+                 SELECT forum, sum(threads), sum(votes) FROM deltas GROUP BY forum ORDER BY forum
+                 ON CONFLICT (forum) DO UPDATE SET threads = stats_forums.threads + excluded.threads,
+                     votes = stats_forums.votes + excluded.votes)
+    INSERT
+    INTO stats_hourly (forum, bucket, threads)
+    SELECT forum, bucket, sum(threads)
+    FROM deltas
+    GROUP BY 1, 2
+    ORDER BY 1, 2
+    ON CONFLICT (forum, bucket) DO UPDATE SET threads = stats_hourly.threads + excluded.threads;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE TRIGGER stats_threads_moved_trigger
+    AFTER UPDATE
+    ON threads
+    REFERENCING OLD TABLE AS old_rows NEW TABLE AS new_rows
+    FOR EACH STATEMENT
+EXECUTE FUNCTION stats_threads_moved();
+
+CREATE OR REPLACE FUNCTION stats_posts_moved()
+    RETURNS TRIGGER AS
+$$
+BEGIN
+    WITH moved AS (SELECT o.forum AS old_forum, n.forum AS new_forum, date_trunc('hour', coalesce(n.created, now())) AS bucket
+                   FROM old_rows o
+                            JOIN new_rows n ON n.id = o.id
+                   WHERE o.forum IS DISTINCT FROM n.forum),
+         deltas AS (SELECT old_forum AS forum, bucket, -1 AS posts FROM moved WHERE old_forum IS NOT NULL
+                    UNION ALL
+                    SELECT new_forum, bucket, 1 FROM moved WHERE new_forum IS NOT NULL),
+         forums AS (
+             INSERT INTO stats_forums (forum, posts)
+                 SELECT forum, sum(posts) FROM deltas GROUP BY forum ORDER BY forum
+                 ON CONFLICT (forum) DO UPDATE SET posts = stats_forums.posts + excluded.posts)
+    INSERT
+    INTO stats_hourly (forum, bucket, posts)
+    SELECT forum, bucket, sum(posts)
+    FROM deltas
+    GROUP BY 1, 2
+    ORDER BY 1, 2
+    ON CONFLICT (forum, bucket) DO UPDATE SET posts = stats_hourly.posts + excluded.posts;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE TRIGGER stats_posts_moved_trigger
+    AFTER UPDATE
+    ON posts
+    REFERENCING OLD TABLE AS old_rows NEW TABLE AS new_rows
+    FOR EACH STATEMENT
+EXECUTE FUNCTION stats_posts_moved();
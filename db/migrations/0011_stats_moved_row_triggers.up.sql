@@ -0,0 +1,68 @@
+-- Триггеры переноса из 0005 были уровня оператора и срабатывали на каждый UPDATE
+-- тредов и постов, включая голоса и правку сообщений. Теперь они строчные и
+-- срабатывают только при изменении forum.
+DROP TRIGGER IF EXISTS stats_threads_moved_trigger ON threads;
+DROP TRIGGER IF EXISTS stats_posts_moved_trigger ON posts;
+
+CREATE OR REPLACE FUNCTION stats_threads_moved()
+    RETURNS TRIGGER AS
+$$
+DECLARE
+    hour_bucket  timestamptz := date_trunc('hour', coalesce(NEW.created, now()));
+    thread_votes bigint      := (SELECT count(*) FROM votes WHERE thread = NEW.id);
+BEGIN
+    INSERT INTO stats_forums (forum, threads, votes)
+    VALUES (OLD.forum, -1, -thread_votes),
+           (NEW.forum, 1, thread_votes)
+    ON CONFLICT (forum) DO UPDATE SET threads = stats_forums.threads + excluded.threads,
+                                      votes   = stats_forums.votes + excluded.votes;
+
+    INSERT INTO stats_hourly (forum, bucket, threads)
+    VALUES (OLD.forum, hour_bucket, -1),
+           (NEW.forum, hour_bucket, 1)
+    ON CONFLICT (forum, bucket) DO UPDATE SET threads = stats_hourly.threads + excluded.threads;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER stats_threads_moved_trigger
+    AFTER UPDATE OF forum
+    ON threads
+    FOR EACH ROW
+    WHEN (OLD.forum IS DISTINCT FROM NEW.forum)
+EXECUTE FUNCTION stats_threads_moved();
+
+CREATE OR REPLACE FUNCTION stats_posts_moved()
+    RETURNS TRIGGER AS
+$$
+DECLARE
+    hour_bucket timestamptz := date_trunc('hour', coalesce(NEW.created, now()));
+BEGIN
+    IF OLD.forum IS NOT NULL THEN
+        INSERT INTO stats_forums (forum, posts)
+        VALUES (OLD.forum, -1)
+        ON CONFLICT (forum) DO UPDATE SET posts = stats_forums.posts + excluded.posts;
+
+        INSERT INTO stats_hourly (forum, bucket, posts)
+        VALUES (OLD.forum, hour_bucket, -1)
+        ON CONFLICT (forum, bucket) DO UPDATE SET posts = stats_hourly.posts + excluded.posts;
+    END IF;
+    IF NEW.forum IS NOT NULL THEN
+        INSERT INTO stats_forums (forum, posts)
+        VALUES (NEW.forum, 1)
+        ON CONFLICT (forum) DO UPDATE SET posts = stats_forums.posts + excluded.posts;
+
+        INSERT INTO stats_hourly (forum, bucket, posts)
+        VALUES (NEW.forum, hour_bucket, 1)
+        ON CONFLICT (forum, bucket) DO UPDATE SET posts = stats_hourly.posts + excluded.posts;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER stats_posts_moved_trigger
+    AFTER UPDATE OF forum
+    ON posts
+    FOR EACH ROW
+    WHEN (OLD.forum IS DISTINCT FROM NEW.forum)
+EXECUTE FUNCTION stats_posts_moved();
@@ -0,0 +1,8 @@
+package docs
+
+import _ "embed"
+
+// Swagger is the API contract served by the backend.
+//
+//go:embed swagger.yml
+var Swagger []byte
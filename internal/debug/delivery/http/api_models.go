@@ -0,0 +1,58 @@
+package http
+
+import (
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/buildinfo"
+)
+
+//go:generate easyjson -all -snake_case api_models.go
+
+// API responses
+type buildResponse struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	GoVersion string `json:"go_version"`
+	Modified  bool   `json:"modified"`
+}
+
+func newBuildResponse(info buildinfo.Info) buildResponse {
+	return buildResponse{
+		Version:   info.Version,
+		Commit:    info.Commit,
+		GoVersion: info.GoVersion,
+		Modified:  info.Modified,
+	}
+}
+
+type poolResponse struct {
+	MaxConns                int32   `json:"max_conns"`
+	TotalConns              int32   `json:"total_conns"`
+	AcquiredConns           int32   `json:"acquired_conns"`
+	IdleConns               int32   `json:"idle_conns"`
+	ConstructingConns       int32   `json:"constructing_conns"`
+	AcquireCount            int64   `json:"acquire_count"`
+	EmptyAcquireCount       int64   `json:"empty_acquire_count"`
+	CanceledAcquireCount    int64   `json:"canceled_acquire_count"`
+	AcquireDurationSeconds  float64 `json:"acquire_duration_seconds"`
+	NewConnsCount           int64   `json:"new_conns_count"`
+	MaxLifetimeDestroyCount int64   `json:"max_lifetime_destroy_count"`
+	MaxIdleDestroyCount     int64   `json:"max_idle_destroy_count"`
+}
+
+func newPoolResponse(stat *pgxpool.Stat) poolResponse {
+	return poolResponse{
+		MaxConns:                stat.MaxConns(),
+		TotalConns:              stat.TotalConns(),
+		AcquiredConns:           stat.AcquiredConns(),
+		IdleConns:               stat.IdleConns(),
+		ConstructingConns:       stat.ConstructingConns(),
+		AcquireCount:            stat.AcquireCount(),
+		EmptyAcquireCount:       stat.EmptyAcquireCount(),
+		CanceledAcquireCount:    stat.CanceledAcquireCount(),
+		AcquireDurationSeconds:  stat.AcquireDuration().Seconds(),
+		NewConnsCount:           stat.NewConnsCount(),
+		MaxLifetimeDestroyCount: stat.MaxLifetimeDestroyCount(),
+		MaxIdleDestroyCount:     stat.MaxIdleDestroyCount(),
+	}
+}
@@ -0,0 +1,249 @@
+// Code generated by easyjson for marshaling/unmarshaling. DO NOT EDIT.
+
+package http
+
+import (
+	json "encoding/json"
+	easyjson "github.com/mailru/easyjson"
+	jlexer "github.com/mailru/easyjson/jlexer"
+	jwriter "github.com/mailru/easyjson/jwriter"
+)
+
+// suppress unused package warning
+var (
+	_ *json.RawMessage
+	_ *jlexer.Lexer
+	_ *jwriter.Writer
+	_ easyjson.Marshaler
+)
+
+func easyjsonC0ea9389DecodeGithubComSlavaShagalovVkDbmsProjectInternalDebugDeliveryHttp(in *jlexer.Lexer, out *poolResponse) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "max_conns":
+			out.MaxConns = int32(in.Int32())
+		case "total_conns":
+			out.TotalConns = int32(in.Int32())
+		case "acquired_conns":
+			out.AcquiredConns = int32(in.Int32())
+		case "idle_conns":
+			out.IdleConns = int32(in.Int32())
+		case "constructing_conns":
+			out.ConstructingConns = int32(in.Int32())
+		case "acquire_count":
+			out.AcquireCount = int64(in.Int64())
+		case "empty_acquire_count":
+			out.EmptyAcquireCount = int64(in.Int64())
+		case "canceled_acquire_count":
+			out.CanceledAcquireCount = int64(in.Int64())
+		case "acquire_duration_seconds":
+			out.AcquireDurationSeconds = float64(in.Float64())
+		case "new_conns_count":
+			out.NewConnsCount = int64(in.Int64())
+		case "max_lifetime_destroy_count":
+			out.MaxLifetimeDestroyCount = int64(in.Int64())
+		case "max_idle_destroy_count":
+			out.MaxIdleDestroyCount = int64(in.Int64())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC0ea9389EncodeGithubComSlavaShagalovVkDbmsProjectInternalDebugDeliveryHttp(out *jwriter.Writer, in poolResponse) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"max_conns\":"
+		out.RawString(prefix[1:])
+		out.Int32(int32(in.MaxConns))
+	}
+	{
+		const prefix string = ",\"total_conns\":"
+		out.RawString(prefix)
+		out.Int32(int32(in.TotalConns))
+	}
+	{
+		const prefix string = ",\"acquired_conns\":"
+		out.RawString(prefix)
+		out.Int32(int32(in.AcquiredConns))
+	}
+	{
+		const prefix string = ",\"idle_conns\":"
+		out.RawString(prefix)
+		out.Int32(int32(in.IdleConns))
+	}
+	{
+		const prefix string = ",\"constructing_conns\":"
+		out.RawString(prefix)
+		out.Int32(int32(in.ConstructingConns))
+	}
+	{
+		const prefix string = ",\"acquire_count\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.AcquireCount))
+	}
+	{
+		const prefix string = ",\"empty_acquire_count\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.EmptyAcquireCount))
+	}
+	{
+		const prefix string = ",\"canceled_acquire_count\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.CanceledAcquireCount))
+	}
+	{
+		const prefix string = ",\"acquire_duration_seconds\":"
+		out.RawString(prefix)
+		out.Float64(float64(in.AcquireDurationSeconds))
+	}
+	{
+		const prefix string = ",\"new_conns_count\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.NewConnsCount))
+	}
+	{
+		const prefix string = ",\"max_lifetime_destroy_count\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.MaxLifetimeDestroyCount))
+	}
+	{
+		const prefix string = ",\"max_idle_destroy_count\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.MaxIdleDestroyCount))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v poolResponse) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC0ea9389EncodeGithubComSlavaShagalovVkDbmsProjectInternalDebugDeliveryHttp(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v poolResponse) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC0ea9389EncodeGithubComSlavaShagalovVkDbmsProjectInternalDebugDeliveryHttp(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *poolResponse) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC0ea9389DecodeGithubComSlavaShagalovVkDbmsProjectInternalDebugDeliveryHttp(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *poolResponse) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC0ea9389DecodeGithubComSlavaShagalovVkDbmsProjectInternalDebugDeliveryHttp(l, v)
+}
+func easyjsonC0ea9389DecodeGithubComSlavaShagalovVkDbmsProjectInternalDebugDeliveryHttp1(in *jlexer.Lexer, out *buildResponse) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "version":
+			out.Version = string(in.String())
+		case "commit":
+			out.Commit = string(in.String())
+		case "go_version":
+			out.GoVersion = string(in.String())
+		case "modified":
+			out.Modified = bool(in.Bool())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC0ea9389EncodeGithubComSlavaShagalovVkDbmsProjectInternalDebugDeliveryHttp1(out *jwriter.Writer, in buildResponse) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"version\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Version))
+	}
+	{
+		const prefix string = ",\"commit\":"
+		out.RawString(prefix)
+		out.String(string(in.Commit))
+	}
+	{
+		const prefix string = ",\"go_version\":"
+		out.RawString(prefix)
+		out.String(string(in.GoVersion))
+	}
+	{
+		const prefix string = ",\"modified\":"
+		out.RawString(prefix)
+		out.Bool(bool(in.Modified))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v buildResponse) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC0ea9389EncodeGithubComSlavaShagalovVkDbmsProjectInternalDebugDeliveryHttp1(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v buildResponse) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC0ea9389EncodeGithubComSlavaShagalovVkDbmsProjectInternalDebugDeliveryHttp1(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *buildResponse) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC0ea9389DecodeGithubComSlavaShagalovVkDbmsProjectInternalDebugDeliveryHttp1(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *buildResponse) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC0ea9389DecodeGithubComSlavaShagalovVkDbmsProjectInternalDebugDeliveryHttp1(l, v)
+}
@@ -0,0 +1,58 @@
+package http
+
+import (
+	"net/http"
+	"net/http/pprof"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/julienschmidt/httprouter"
+	"go.uber.org/zap"
+
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/buildinfo"
+	pkgHTTP "github.com/SlavaShagalov/vk-dbms-project/internal/pkg/http"
+	mw "github.com/SlavaShagalov/vk-dbms-project/internal/pkg/middleware"
+)
+
+type delivery struct {
+	pool *pgxpool.Pool
+	log  *zap.Logger
+}
+
+// RegisterHandlers mounts the /debug group. It must only be served on the admin listener.
+func RegisterHandlers(router *mw.Router, log *zap.Logger, pool *pgxpool.Pool) {
+	del := delivery{pool, log}
+
+	router.GET("/debug/build", mw.AccessLog(mw.HandleError(del.Build, log), log))
+	if pool != nil {
+		router.GET("/debug/pool", mw.AccessLog(mw.HandleError(del.Pool, log), log))
+	}
+	router.GET("/debug/pprof/*item", mw.AccessLog(del.Pprof, log))
+	router.POST("/debug/pprof/*item", mw.AccessLog(del.Pprof, log))
+}
+
+func (del *delivery) Build(w http.ResponseWriter, r *http.Request, _ httprouter.Params) error {
+	response := newBuildResponse(buildinfo.Get())
+	return pkgHTTP.WriteResponse(w, r, http.StatusOK, &response)
+}
+
+func (del *delivery) Pool(w http.ResponseWriter, r *http.Request, _ httprouter.Params) error {
+	response := newPoolResponse(del.pool.Stat())
+	return pkgHTTP.WriteResponse(w, r, http.StatusOK, &response)
+}
+
+// Pprof dispatches like http.DefaultServeMux does for net/http/pprof, a
+// catch-all route can't share its prefix with static ones in httprouter.
+func (del *delivery) Pprof(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	switch p.ByName("item") {
+	case "/cmdline":
+		pprof.Cmdline(w, r)
+	case "/profile":
+		pprof.Profile(w, r)
+	case "/symbol":
+		pprof.Symbol(w, r)
+	case "/trace":
+		pprof.Trace(w, r)
+	default:
+		pprof.Index(w, r)
+	}
+}
@@ -0,0 +1,106 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"go.uber.org/zap"
+
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/dump"
+	pkgErrors "github.com/SlavaShagalov/vk-dbms-project/internal/pkg/errors"
+	pkgHTTP "github.com/SlavaShagalov/vk-dbms-project/internal/pkg/http"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/metrics"
+	mw "github.com/SlavaShagalov/vk-dbms-project/internal/pkg/middleware"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/tracing"
+)
+
+type delivery struct {
+	dumper         *dump.Dumper
+	importMaxBytes int64
+	importTimeout  time.Duration
+	log            *zap.Logger
+}
+
+// RegisterAdminHandlers registers export and import. They run without the
+// request deadline and body limit of the API, import has its own ones as it
+// locks the tables it loads.
+func RegisterAdminHandlers(router *mw.Router, log *zap.Logger, dumper *dump.Dumper,
+	importMaxBytes int64, importTimeout time.Duration, m *metrics.Metrics) {
+	del := delivery{dumper, importMaxBytes, importTimeout, log}
+
+	router.GET("/api/admin/export", mw.Trace(mw.AccessLog(mw.Metrics(mw.HandleError(del.Export, log), m), log)))
+	router.POST("/api/admin/import", mw.Trace(mw.AccessLog(mw.Metrics(mw.HandleError(del.Import, log), m), log)))
+}
+
+func (del *delivery) Export(w http.ResponseWriter, r *http.Request, _ httprouter.Params) error {
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	out := &countingWriter{w: w}
+	_, err := del.dumper.Export(r.Context(), out, r.URL.Query().Get("forum"))
+	if err != nil && out.n > 0 {
+		// The status is already sent, the missing end record tells the
+		// importer that the dump is truncated.
+		tracing.Log(r.Context(), del.log).Error("Export interrupted", zap.Int64("written", out.n), zap.Error(err))
+		return nil
+	}
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+	}
+	return err
+}
+
+func (del *delivery) Import(w http.ResponseWriter, r *http.Request, _ httprouter.Params) error {
+	if r.ContentLength > del.importMaxBytes {
+		return pkgErrors.ErrBodyTooLarge
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), del.importTimeout)
+	defer cancel()
+
+	body := &limitedReader{r: r.Body, limit: del.importMaxBytes}
+	stats, err := del.dumper.Import(ctx, body)
+	if body.exceeded {
+		// The reader error reaches the importer wrapped as an invalid dump.
+		return pkgErrors.ErrBodyTooLarge
+	}
+	if errors.Is(err, pkgErrors.ErrInvalidDump) {
+		// Unlike the other errors the details are useful to the caller.
+		return pkgHTTP.WriteResponse(w, r, http.StatusBadRequest, &mw.ErrorResponse{Message: err.Error()})
+	}
+	if err != nil {
+		return err
+	}
+
+	return pkgHTTP.WriteResponse(w, r, http.StatusOK, &stats)
+}
+
+// limitedReader fails once more than limit bytes are read.
+type limitedReader struct {
+	r        io.Reader
+	limit    int64
+	n        int64
+	exceeded bool
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	n, err := l.r.Read(p)
+	l.n += int64(n)
+	if l.n > l.limit {
+		l.exceeded = true
+		return n, pkgErrors.ErrBodyTooLarge
+	}
+	return n, err
+}
+
+type countingWriter struct {
+	w http.ResponseWriter
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,96 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+
+	pkgForum "github.com/SlavaShagalov/vk-dbms-project/internal/forum"
+	pkgErrors "github.com/SlavaShagalov/vk-dbms-project/internal/pkg/errors"
+	mw "github.com/SlavaShagalov/vk-dbms-project/internal/pkg/middleware"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/pb"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/ratelimit"
+)
+
+type delivery struct {
+	pb.UnimplementedForumServiceServer
+	serv    pkgForum.Service
+	limiter *ratelimit.Limiter
+	log     *zap.Logger
+}
+
+func RegisterHandlers(server *grpc.Server, logger *zap.Logger, serv pkgForum.Service, limiter *ratelimit.Limiter) {
+	pb.RegisterForumServiceServer(server, &delivery{serv: serv, limiter: limiter, log: logger})
+}
+
+// Routes are the HTTP routes the methods mirror.
+var Routes = mw.GRPCRoutes{
+	pb.ForumService_CreateForum_FullMethodName:      {Route: "POST /api/forum/:slug", Group: ratelimit.GroupForums},
+	pb.ForumService_GetForum_FullMethodName:         {Route: "GET /api/forum/:slug/details"},
+	pb.ForumService_CreateThread_FullMethodName:     {Route: "POST /api/forum/:slug/:action", Group: ratelimit.GroupThreads},
+	pb.ForumService_ListForumUsers_FullMethodName:   {Route: "GET /api/forum/:slug/users"},
+	pb.ForumService_ListForumThreads_FullMethodName: {Route: "GET /api/forum/:slug/threads"},
+}
+
+func (del *delivery) CreateForum(ctx context.Context, request *pb.Forum) (*pb.Forum, error) {
+	forum := request.Model()
+	created, err := del.serv.Create(ctx, &forum)
+	if err != nil {
+		if errors.Is(err, pkgErrors.ErrForumAlreadyExists) {
+			return nil, mw.GRPCConflict(err, pb.NewForum(created))
+		}
+		return nil, err
+	}
+	return pb.NewForum(created), nil
+}
+
+func (del *delivery) GetForum(ctx context.Context, request *pb.GetForumRequest) (*pb.Forum, error) {
+	forum, err := del.serv.Get(ctx, request.GetSlug())
+	if err != nil {
+		return nil, err
+	}
+	return pb.NewForum(forum), nil
+}
+
+func (del *delivery) CreateThread(ctx context.Context, request *pb.Thread) (*pb.Thread, error) {
+	thread := request.Model()
+	if err := mw.AllowGRPCUsers(ctx, del.limiter, ratelimit.GroupThreads, thread.Author); err != nil {
+		return nil, err
+	}
+	created, err := del.serv.CreateThread(ctx, &thread)
+	if err != nil {
+		if errors.Is(err, pkgErrors.ErrThreadAlreadyExists) {
+			return nil, mw.GRPCConflict(err, pb.NewThread(&created))
+		}
+		return nil, err
+	}
+	return pb.NewThread(&created), nil
+}
+
+func (del *delivery) ListForumUsers(ctx context.Context, request *pb.ListForumUsersRequest) (*pb.Users, error) {
+	limit, err := pb.Limit(request.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	users, err := del.serv.GetForumUsers(ctx, request.GetSlug(), limit, request.GetSince(), request.GetDesc())
+	if err != nil {
+		return nil, err
+	}
+	return pb.NewUsers(users), nil
+}
+
+func (del *delivery) ListForumThreads(ctx context.Context, request *pb.ListForumThreadsRequest) (*pb.Threads, error) {
+	limit, err := pb.Limit(request.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	threads, err := del.serv.GetForumThreads(ctx, request.GetSlug(), limit, request.GetSince(), request.GetDesc())
+	if err != nil {
+		return nil, err
+	}
+	return pb.NewThreads(threads), nil
+}
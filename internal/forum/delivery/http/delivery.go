@@ -1,13 +1,14 @@
 package http
 
 import (
-	"context"
 	"errors"
 	pkgForum "github.com/SlavaShagalov/vk-dbms-project/internal/forum"
 	"github.com/SlavaShagalov/vk-dbms-project/internal/models"
 	pkgErrors "github.com/SlavaShagalov/vk-dbms-project/internal/pkg/errors"
 	pkgHTTP "github.com/SlavaShagalov/vk-dbms-project/internal/pkg/http"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/metrics"
 	mw "github.com/SlavaShagalov/vk-dbms-project/internal/pkg/middleware"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/ratelimit"
 	"github.com/julienschmidt/httprouter"
 	"go.uber.org/zap"
 	"net/http"
@@ -15,21 +16,29 @@ import (
 )
 
 type delivery struct {
-	serv pkgForum.Service
-	log  *zap.Logger
+	serv    pkgForum.Service
+	limiter *ratelimit.Limiter
+	log     *zap.Logger
 }
 
-func RegisterHandlers(router *httprouter.Router, logger *zap.Logger, serv pkgForum.Service) {
-	del := delivery{serv, logger}
+func RegisterHandlers(router *mw.Router, logger *zap.Logger, serv pkgForum.Service, limiter *ratelimit.Limiter, m *metrics.Metrics) {
+	del := delivery{serv: serv, limiter: limiter, log: logger}
 
-	router.POST("/api/forum/:slug", mw.AccessLog(mw.HandleError(del.Create, logger), logger))
-	router.POST("/api/forum/:slug/:action", mw.AccessLog(mw.HandleError(del.CreateThread, logger), logger))
-	//router.POST("/api/forum/:slug/create", mw.AccessLog(mw.HandleError(del.Create, logger), logger))
-	//router.POST("/api/forum/create", mw.AccessLog(mw.HandleError(del.Create, logger), logger))
-	router.GET("/api/forum/:slug/details", mw.AccessLog(mw.HandleError(del.Get, logger), logger))
+	router.POST("/api/forum/:slug", mw.Trace(mw.Timeout(mw.AccessLog(mw.Metrics(mw.HandleError(mw.RateLimit(del.Create, limiter, ratelimit.GroupForums), logger), m), logger))))
+	router.POST("/api/forum/:slug/:action", mw.Trace(mw.Timeout(mw.AccessLog(mw.Metrics(mw.HandleError(mw.RateLimit(del.CreateThread, limiter, ratelimit.GroupThreads), logger), m), logger))))
+	//router.POST("/api/forum/:slug/create", mw.Trace(mw.Timeout(mw.AccessLog(mw.HandleError(del.Create, logger), logger))))
+	//router.POST("/api/forum/create", mw.Trace(mw.Timeout(mw.AccessLog(mw.HandleError(del.Create, logger), logger))))
+	router.GET("/api/forum/:slug/details", mw.Trace(mw.Timeout(mw.AccessLog(mw.Metrics(mw.Conditional(mw.HandleError(del.Get, logger)), m), logger))))
 
-	router.GET("/api/forum/:slug/users", mw.AccessLog(mw.HandleError(del.GetForumUsers, logger), logger))
-	router.GET("/api/forum/:slug/threads", mw.AccessLog(mw.HandleError(del.GetForumThreads, logger), logger))
+	router.GET("/api/forum/:slug/users", mw.Trace(mw.Timeout(mw.AccessLog(mw.Metrics(mw.Conditional(mw.HandleError(del.GetForumUsers, logger)), m), logger))))
+	router.GET("/api/forum/:slug/threads", mw.Trace(mw.Timeout(mw.AccessLog(mw.Metrics(mw.Conditional(mw.HandleError(del.GetForumThreads, logger)), m), logger))))
+}
+
+// RegisterAdminHandlers registers forum maintenance routes for the admin listener.
+func RegisterAdminHandlers(router *mw.Router, logger *zap.Logger, serv pkgForum.Service, m *metrics.Metrics) {
+	del := delivery{serv: serv, log: logger}
+
+	router.POST("/api/admin/forum/:slug/recount", mw.Trace(mw.Timeout(mw.AccessLog(mw.Metrics(mw.HandleError(del.Recount, logger), m), logger))))
 }
 
 func (del *delivery) CreateThread(w http.ResponseWriter, r *http.Request, p httprouter.Params) error {
@@ -41,42 +50,25 @@ func (del *delivery) CreateThread(w http.ResponseWriter, r *http.Request, p http
 
 	slug := p.ByName("slug")
 
-	body, errCreate := pkgHTTP.ReadBody(r, del.log)
-	if errCreate != nil {
-		return errCreate
-	}
-
 	thread := models.Thread{}
-	if err := thread.UnmarshalJSON(body); err != nil {
-		return pkgErrors.ErrParseJSON
+	if err := pkgHTTP.ReadRequest(r, del.log, &thread); err != nil {
+		return err
 	}
-
-	thread.Forum = slug
-	thread, errCreate = del.serv.CreateThread(&thread)
-
-	if errCreate != nil {
-		switch {
-		case errors.Is(pkgErrors.ErrUserNotFound, errCreate):
-			return errCreate
-		case errors.Is(pkgErrors.ErrForumNotFound, errCreate):
-			return errCreate
-		case errors.Is(pkgErrors.ErrThreadAlreadyExists, errCreate):
-			w.WriteHeader(http.StatusConflict)
-		}
-	} else {
-		w.WriteHeader(http.StatusCreated)
+	mw.SetUser(w, thread.Author)
+	if err := mw.AllowUsers(w, r, del.limiter, ratelimit.GroupThreads, thread.Author); err != nil {
+		return err
 	}
 
-	data, err := thread.MarshalJSON()
+	thread.Forum = slug
+	thread, err := del.serv.CreateThread(r.Context(), &thread)
 	if err != nil {
-		return pkgErrors.ErrInternal
+		if !errors.Is(err, pkgErrors.ErrThreadAlreadyExists) {
+			return err
+		}
+		return pkgHTTP.WriteResponse(w, r, http.StatusConflict, &thread)
 	}
 
-	_, err = w.Write(data)
-	if err != nil {
-		return pkgErrors.ErrInternal
-	}
-	return nil
+	return pkgHTTP.WriteResponse(w, r, http.StatusCreated, &thread)
 }
 
 func (del *delivery) Create(w http.ResponseWriter, r *http.Request, p httprouter.Params) error {
@@ -86,56 +78,34 @@ func (del *delivery) Create(w http.ResponseWriter, r *http.Request, p httprouter
 		return nil
 	}
 
-	body, err := pkgHTTP.ReadBody(r, del.log)
-	if err != nil {
+	forum := new(models.Forum)
+	if err := pkgHTTP.ReadRequest(r, del.log, forum); err != nil {
 		return err
 	}
+	mw.SetUser(w, forum.User)
 
-	forum := new(models.Forum)
-	err = forum.UnmarshalJSON(body)
+	forum, err := del.serv.Create(r.Context(), forum)
 	if err != nil {
-		return pkgErrors.ErrParseJSON
-	}
-
-	forum, errCreate := del.serv.Create(context.Background(), forum)
-	if errCreate != nil {
-		if errCreate != pkgErrors.ErrForumAlreadyExists {
-			return errCreate
+		if err != pkgErrors.ErrForumAlreadyExists {
+			return err
 		}
-		w.WriteHeader(http.StatusConflict)
-	} else {
-		w.WriteHeader(http.StatusCreated)
+		return pkgHTTP.WriteResponse(w, r, http.StatusConflict, forum)
 	}
 
-	data, err := forum.MarshalJSON()
-	if err != nil {
-		return pkgErrors.ErrInternal
-	}
-
-	_, err = w.Write(data)
-	if err != nil {
-		return pkgErrors.ErrInternal
-	}
-	return nil
+	return pkgHTTP.WriteResponse(w, r, http.StatusCreated, forum)
 }
 
-func (del *delivery) Get(w http.ResponseWriter, _ *http.Request, p httprouter.Params) error {
+func (del *delivery) Get(w http.ResponseWriter, r *http.Request, p httprouter.Params) error {
 	slug := p.ByName("slug")
-	forum, err := del.serv.Get(context.Background(), slug)
+	forum, err := del.serv.Get(r.Context(), slug)
 	if err != nil {
 		return err
 	}
-
-	data, err := forum.MarshalJSON()
-	if err != nil {
-		return pkgErrors.ErrInternal
+	if pkgHTTP.NotModified(w, r, forum.UpdatedAt) {
+		return nil
 	}
 
-	_, err = w.Write(data)
-	if err != nil {
-		return pkgErrors.ErrInternal
-	}
-	return nil
+	return pkgHTTP.WriteResponse(w, r, http.StatusOK, forum)
 }
 
 func (del *delivery) GetForumUsers(w http.ResponseWriter, r *http.Request, p httprouter.Params) error {
@@ -164,21 +134,12 @@ func (del *delivery) GetForumUsers(w http.ResponseWriter, r *http.Request, p htt
 		}
 	}
 
-	users, err := del.serv.GetForumUsers(context.Background(), slug, limit, since, desc)
+	users, err := del.serv.GetForumUsers(r.Context(), slug, limit, since, desc)
 	if err != nil {
 		return err
 	}
 
-	data, err := users.MarshalJSON()
-	if err != nil {
-		return pkgErrors.ErrInternal
-	}
-
-	_, err = w.Write(data)
-	if err != nil {
-		return pkgErrors.ErrInternal
-	}
-	return nil
+	return pkgHTTP.WriteResponse(w, r, http.StatusOK, users)
 }
 
 func (del *delivery) GetForumThreads(w http.ResponseWriter, r *http.Request, p httprouter.Params) error {
@@ -207,19 +168,19 @@ func (del *delivery) GetForumThreads(w http.ResponseWriter, r *http.Request, p h
 		}
 	}
 
-	threads, err := del.serv.GetForumThreads(context.Background(), slug, limit, since, desc)
+	threads, err := del.serv.GetForumThreads(r.Context(), slug, limit, since, desc)
 	if err != nil {
 		return err
 	}
 
-	data, err := threads.MarshalJSON()
-	if err != nil {
-		return pkgErrors.ErrInternal
-	}
+	return pkgHTTP.WriteResponse(w, r, http.StatusOK, threads)
+}
 
-	_, err = w.Write(data)
+func (del *delivery) Recount(w http.ResponseWriter, r *http.Request, p httprouter.Params) error {
+	forum, err := del.serv.Recount(r.Context(), p.ByName("slug"))
 	if err != nil {
-		return pkgErrors.ErrInternal
+		return err
 	}
-	return nil
+
+	return pkgHTTP.WriteResponse(w, r, http.StatusOK, forum)
 }
@@ -10,5 +10,6 @@ type Repository interface {
 	GetForumUsers(ctx context.Context, slug string, limit int, since string, desc bool) ([]models.User, error)
 	Get(ctx context.Context, slug string) (*models.Forum, error)
 	GetForumThreads(ctx context.Context, slug string, limit int, since string, desc bool) (models.ThreadList, error)
-	CreateThread(thread *models.Thread) (models.Thread, error)
+	CreateThread(ctx context.Context, thread *models.Thread) (models.Thread, error)
+	Recount(ctx context.Context, slug string) (*models.Forum, error)
 }
@@ -0,0 +1,31 @@
+package cache
+
+import (
+	"time"
+
+	"github.com/SlavaShagalov/vk-dbms-project/internal/models"
+	pkgCache "github.com/SlavaShagalov/vk-dbms-project/internal/pkg/cache"
+)
+
+//go:generate easyjson -all -snake_case entry.go
+
+// entry is a forum in Redis, with the row version the API form leaves out.
+type entry struct {
+	Forum     models.Forum `json:"forum"`
+	UpdatedAt time.Time    `json:"updated_at"`
+}
+
+var Codec = pkgCache.Codec[models.Forum]{
+	Marshal: func(forum *models.Forum) ([]byte, error) {
+		return entry{Forum: *forum, UpdatedAt: forum.UpdatedAt}.MarshalJSON()
+	},
+	Unmarshal: func(data []byte, forum *models.Forum) error {
+		var e entry
+		if err := e.UnmarshalJSON(data); err != nil {
+			return err
+		}
+		*forum = e.Forum
+		forum.UpdatedAt = e.UpdatedAt
+		return nil
+	},
+}
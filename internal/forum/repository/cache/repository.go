@@ -0,0 +1,62 @@
+package cache
+
+import (
+	"context"
+
+	pkgForum "github.com/SlavaShagalov/vk-dbms-project/internal/forum"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/models"
+	pkgCache "github.com/SlavaShagalov/vk-dbms-project/internal/pkg/cache"
+)
+
+type repository struct {
+	rep   pkgForum.Repository
+	cache *pkgCache.Cache[models.Forum]
+}
+
+// NewRepository wraps rep and serves Get from the cache. Creating a thread and
+// recounting drop the cached forum counters.
+func NewRepository(rep pkgForum.Repository, cache *pkgCache.Cache[models.Forum]) pkgForum.Repository {
+	return &repository{rep: rep, cache: cache}
+}
+
+func (rep *repository) Create(ctx context.Context, forum *models.Forum) (*models.Forum, error) {
+	return rep.rep.Create(ctx, forum)
+}
+
+func (rep *repository) GetForumUsers(ctx context.Context, slug string, limit int, since string, desc bool) ([]models.User, error) {
+	return rep.rep.GetForumUsers(ctx, slug, limit, since, desc)
+}
+
+func (rep *repository) Get(ctx context.Context, slug string) (*models.Forum, error) {
+	key := pkgCache.ForumKey(slug)
+	forum := &models.Forum{}
+	version, ok := rep.cache.Get(ctx, key, forum)
+	if ok {
+		return forum, nil
+	}
+	forum, err := rep.rep.Get(ctx, slug)
+	if err == nil {
+		rep.cache.Set(ctx, key, version, forum)
+	}
+	return forum, err
+}
+
+func (rep *repository) GetForumThreads(ctx context.Context, slug string, limit int, since string, desc bool) (models.ThreadList, error) {
+	return rep.rep.GetForumThreads(ctx, slug, limit, since, desc)
+}
+
+func (rep *repository) CreateThread(ctx context.Context, thread *models.Thread) (models.Thread, error) {
+	created, err := rep.rep.CreateThread(ctx, thread)
+	if err == nil {
+		rep.cache.Delete(ctx, pkgCache.ForumKey(created.Forum))
+	}
+	return created, err
+}
+
+func (rep *repository) Recount(ctx context.Context, slug string) (*models.Forum, error) {
+	forum, err := rep.rep.Recount(ctx, slug)
+	if err == nil {
+		rep.cache.Delete(ctx, pkgCache.ForumKey(slug))
+	}
+	return forum, err
+}
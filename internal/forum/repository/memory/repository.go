@@ -0,0 +1,218 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	pkgForum "github.com/SlavaShagalov/vk-dbms-project/internal/forum"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/models"
+	pkgErrors "github.com/SlavaShagalov/vk-dbms-project/internal/pkg/errors"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/memory"
+)
+
+type repository struct {
+	store *memory.Store
+}
+
+func NewRepository(store *memory.Store) pkgForum.Repository {
+	return &repository{store: store}
+}
+
+func (rep *repository) Create(_ context.Context, forum *models.Forum) (*models.Forum, error) {
+	rep.store.Lock()
+	defer rep.store.Unlock()
+
+	user, ok := rep.store.Users[memory.Key(forum.User)]
+	if !ok {
+		return nil, pkgErrors.ErrUserNotFound
+	}
+	if existing, ok := rep.store.Forums[memory.Key(forum.Slug)]; ok {
+		tmp := *existing
+		return &tmp, pkgErrors.ErrForumAlreadyExists
+	}
+
+	created := &models.Forum{
+		ID:    rep.store.NextForumID(),
+		Title: forum.Title,
+		User:  user.Nickname,
+		Slug:  forum.Slug,
+	}
+	created.UpdatedAt = rep.store.Version()
+	rep.store.Forums[memory.Key(created.Slug)] = created
+
+	tmp := *created
+	return &tmp, nil
+}
+
+func (rep *repository) Get(_ context.Context, slug string) (*models.Forum, error) {
+	rep.store.RLock()
+	defer rep.store.RUnlock()
+
+	forum, ok := rep.store.Forums[memory.Key(slug)]
+	if !ok {
+		return nil, pkgErrors.ErrForumNotFound
+	}
+	tmp := *forum
+	return &tmp, nil
+}
+
+func (rep *repository) CreateThread(_ context.Context, thread *models.Thread) (models.Thread, error) {
+	rep.store.Lock()
+	defer rep.store.Unlock()
+
+	if thread.Slug != "" {
+		if existing, ok := rep.store.ThreadSlugs[memory.Key(thread.Slug)]; ok {
+			return existing.Thread, pkgErrors.ErrThreadAlreadyExists
+		}
+	}
+	if _, ok := rep.store.Users[memory.Key(thread.Author)]; !ok {
+		return models.Thread{}, pkgErrors.ErrUserNotFound
+	}
+	forum, ok := rep.store.Forums[memory.Key(thread.Forum)]
+	if !ok {
+		return models.Thread{}, pkgErrors.ErrForumNotFound
+	}
+
+	created := &memory.Thread{Thread: models.Thread{
+		Id:      rep.store.NextThreadID(),
+		Title:   thread.Title,
+		Author:  thread.Author,
+		Forum:   thread.Forum,
+		Message: thread.Message,
+		Slug:    thread.Slug,
+		Created: thread.Created,
+	}}
+	created.UpdatedAt = rep.store.Version()
+	rep.store.Threads[created.Id] = created
+	if created.Slug != "" {
+		rep.store.ThreadSlugs[memory.Key(created.Slug)] = created
+	}
+	forum.Threads++
+	forum.UpdatedAt = created.UpdatedAt
+	rep.store.AddForumUser(forum.Slug, created.Author)
+
+	// Like the pgx repository the response carries the forum slug as stored in forums.
+	tmp := created.Thread
+	tmp.Forum = forum.Slug
+	return tmp, nil
+}
+
+func (rep *repository) GetForumUsers(_ context.Context, slug string, limit int, since string, desc bool) ([]models.User, error) {
+	rep.store.RLock()
+	defer rep.store.RUnlock()
+
+	if _, ok := rep.store.Forums[memory.Key(slug)]; !ok {
+		return []models.User{}, pkgErrors.ErrForumNotFound
+	}
+
+	// forum_users.nickname uses the "ucs_basic" collation, so lowercased
+	// nicknames are compared byte by byte.
+	since = memory.Key(since)
+	keys := make([]string, 0, len(rep.store.ForumUsers[memory.Key(slug)]))
+	for key := range rep.store.ForumUsers[memory.Key(slug)] {
+		if since != "" && (desc && key >= since || !desc && key <= since) {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	if desc {
+		sort.Sort(sort.Reverse(sort.StringSlice(keys)))
+	} else {
+		sort.Strings(keys)
+	}
+
+	users := make([]models.User, 0)
+	for _, key := range keys {
+		if len(users) == limit {
+			break
+		}
+		users = append(users, rep.store.ForumUsers[memory.Key(slug)][key])
+	}
+	return users, nil
+}
+
+func (rep *repository) GetForumThreads(_ context.Context, slug string, limit int, since string,
+	desc bool) (models.ThreadList, error) {
+	rep.store.RLock()
+	defer rep.store.RUnlock()
+
+	if _, ok := rep.store.Forums[memory.Key(slug)]; !ok {
+		return nil, pkgErrors.ErrForumNotFound
+	}
+
+	var sinceTime time.Time
+	if since != "" {
+		var err error
+		if sinceTime, err = time.Parse(time.RFC3339Nano, since); err != nil {
+			return nil, pkgErrors.ErrInvalidSinceParam
+		}
+	}
+
+	threads := make([]models.Thread, 0)
+	for _, thread := range rep.store.Threads {
+		if memory.Key(thread.Forum) != memory.Key(slug) {
+			continue
+		}
+		if since != "" && (desc && thread.Created.After(sinceTime) || !desc && thread.Created.Before(sinceTime)) {
+			continue
+		}
+		threads = append(threads, thread.Thread)
+	}
+	sort.Slice(threads, func(i, j int) bool {
+		if !threads[i].Created.Equal(threads[j].Created) {
+			return threads[i].Created.Before(threads[j].Created) != desc
+		}
+		return threads[i].Id < threads[j].Id
+	})
+
+	if len(threads) > limit {
+		threads = threads[:limit]
+	}
+	return threads, nil
+}
+
+// Recount rebuilds the counters, thread votes and forum users of one forum.
+func (rep *repository) Recount(_ context.Context, slug string) (*models.Forum, error) {
+	rep.store.Lock()
+	defer rep.store.Unlock()
+
+	forum, ok := rep.store.Forums[memory.Key(slug)]
+	if !ok {
+		return nil, pkgErrors.ErrForumNotFound
+	}
+
+	before := *forum
+	forum.Threads, forum.Posts = 0, 0
+	delete(rep.store.ForumUsers, memory.Key(forum.Slug))
+	for _, thread := range rep.store.Threads {
+		if memory.Key(thread.Forum) != memory.Key(forum.Slug) {
+			continue
+		}
+		forum.Threads++
+		rep.store.AddForumUser(forum.Slug, thread.Author)
+
+		votes := 0
+		for key, voice := range rep.store.Votes {
+			if key.Thread == thread.Id {
+				votes += voice
+			}
+		}
+		if thread.Votes != votes {
+			thread.Votes = votes
+			thread.UpdatedAt = rep.store.Version()
+		}
+	}
+	for _, post := range rep.store.Posts {
+		if memory.Key(post.Forum) == memory.Key(forum.Slug) {
+			forum.Posts++
+			rep.store.AddForumUser(forum.Slug, post.Author)
+		}
+	}
+	if *forum != before {
+		forum.UpdatedAt = rep.store.Version()
+	}
+
+	tmp := *forum
+	return &tmp, nil
+}
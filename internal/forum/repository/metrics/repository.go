@@ -0,0 +1,57 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	pkgForum "github.com/SlavaShagalov/vk-dbms-project/internal/forum"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/models"
+	pkgMetrics "github.com/SlavaShagalov/vk-dbms-project/internal/pkg/metrics"
+)
+
+const name = "forum"
+
+type repository struct {
+	rep pkgForum.Repository
+	m   *pkgMetrics.Metrics
+}
+
+// NewRepository wraps rep and records the duration of every call and the
+// number of created threads.
+func NewRepository(rep pkgForum.Repository, m *pkgMetrics.Metrics) pkgForum.Repository {
+	return &repository{rep: rep, m: m}
+}
+
+func (rep *repository) Create(ctx context.Context, forum *models.Forum) (created *models.Forum, err error) {
+	defer func(start time.Time) { rep.m.ObserveQuery(name, "Create", start, err) }(time.Now())
+	return rep.rep.Create(ctx, forum)
+}
+
+func (rep *repository) GetForumUsers(ctx context.Context, slug string, limit int, since string, desc bool) (users []models.User, err error) {
+	defer func(start time.Time) { rep.m.ObserveQuery(name, "GetForumUsers", start, err) }(time.Now())
+	return rep.rep.GetForumUsers(ctx, slug, limit, since, desc)
+}
+
+func (rep *repository) Get(ctx context.Context, slug string) (forum *models.Forum, err error) {
+	defer func(start time.Time) { rep.m.ObserveQuery(name, "Get", start, err) }(time.Now())
+	return rep.rep.Get(ctx, slug)
+}
+
+func (rep *repository) GetForumThreads(ctx context.Context, slug string, limit int, since string, desc bool) (threads models.ThreadList, err error) {
+	defer func(start time.Time) { rep.m.ObserveQuery(name, "GetForumThreads", start, err) }(time.Now())
+	return rep.rep.GetForumThreads(ctx, slug, limit, since, desc)
+}
+
+func (rep *repository) CreateThread(ctx context.Context, thread *models.Thread) (created models.Thread, err error) {
+	defer func(start time.Time) { rep.m.ObserveQuery(name, "CreateThread", start, err) }(time.Now())
+	created, err = rep.rep.CreateThread(ctx, thread)
+	if err == nil {
+		rep.m.AddCreated(pkgMetrics.EntityThread, 1)
+	}
+	return created, err
+}
+
+func (rep *repository) Recount(ctx context.Context, slug string) (forum *models.Forum, err error) {
+	defer func(start time.Time) { rep.m.ObserveQuery(name, "Recount", start, err) }(time.Now())
+	return rep.rep.Recount(ctx, slug)
+}
@@ -14,7 +14,9 @@ import (
 
 	pkgForum "github.com/SlavaShagalov/vk-dbms-project/internal/forum"
 	"github.com/SlavaShagalov/vk-dbms-project/internal/models"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/db"
 	pkgErrors "github.com/SlavaShagalov/vk-dbms-project/internal/pkg/errors"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/tracing"
 )
 
 type repository struct {
@@ -43,7 +45,7 @@ func (rep *repository) Create(ctx context.Context, forum *models.Forum) (*models
 		if errors.Is(pgx.ErrNoRows, err) {
 			return nil, pkgErrors.ErrUserNotFound
 		}
-		return nil, pkgErrors.ErrInternal
+		return nil, db.Error(ctx, err)
 	}
 
 	row := rep.pool.QueryRow(ctx, createCmd, forum.Title, forum.User, forum.Slug)
@@ -59,27 +61,27 @@ func (rep *repository) Create(ctx context.Context, forum *models.Forum) (*models
 				return forum, pkgErrors.ErrUserNotFound
 			}
 
-			rep.log.Error(constants.DBError, zap.Error(err))
-			return forum, pkgErrors.ErrInternal
+			tracing.Log(ctx, rep.log).Error(constants.DBError, zap.Error(err))
+			return forum, db.Error(ctx, err)
 		}
 	}
 	return forum, nil
 }
 
 const getCmd = `
-SELECT id, title, user_nickname, slug, posts, threads
+SELECT id, title, user_nickname, slug, posts, threads, updated_at
 FROM forums
 WHERE slug = $1;`
 
 func (rep *repository) Get(ctx context.Context, slug string) (*models.Forum, error) {
 	row := rep.pool.QueryRow(ctx, getCmd, slug)
 	forum := new(models.Forum)
-	if err := row.Scan(&forum.ID, &forum.Title, &forum.User, &forum.Slug, &forum.Posts, &forum.Threads); err != nil {
+	if err := row.Scan(&forum.ID, &forum.Title, &forum.User, &forum.Slug, &forum.Posts, &forum.Threads, &forum.UpdatedAt); err != nil {
 		if errors.Is(pgx.ErrNoRows, err) {
 			return nil, pkgErrors.ErrForumNotFound
 		} else {
-			rep.log.Error(constants.DBError, zap.Error(err))
-			return nil, pkgErrors.ErrInternal
+			tracing.Log(ctx, rep.log).Error(constants.DBError, zap.Error(err))
+			return nil, db.Error(ctx, err)
 		}
 	}
 	return forum, nil
@@ -90,16 +92,16 @@ INSERT INTO threads (title, author, forum, message, slug, created)
 VALUES($1, $2, $3, $4, $5, $6)
 RETURNING  id, title, author, (SELECT slug from forums WHERE slug = $3), message, slug, created;`
 
-func (rep *repository) CreateThread(thread *models.Thread) (models.Thread, error) {
+func (rep *repository) CreateThread(ctx context.Context, thread *models.Thread) (models.Thread, error) {
 	if thread.Slug != "" {
-		tmp, err := rep.GetThread(thread.Slug)
+		tmp, err := rep.GetThread(ctx, thread.Slug)
 		if err == nil {
 			return tmp, pkgErrors.ErrThreadAlreadyExists
 		}
 	}
 
 	row := rep.pool.QueryRow(
-		context.Background(),
+		ctx,
 		createThreadCmd,
 		thread.Title,
 		thread.Author,
@@ -119,7 +121,7 @@ func (rep *repository) CreateThread(thread *models.Thread) (models.Thread, error
 			case "threads_author_fkey":
 				return models.Thread{}, pkgErrors.ErrUserNotFound
 			default:
-				return tmp, pkgErrors.ErrInternal
+				return tmp, db.Error(ctx, err)
 			}
 		}
 	}
@@ -137,22 +139,22 @@ SELECT  id, title, author, forum, message, slug, votes, created
 FROM threads
 WHERE id = $1;`
 
-func (rep *repository) GetThread(slugOrId string) (models.Thread, error) {
+func (rep *repository) GetThread(ctx context.Context, slugOrId string) (models.Thread, error) {
 	tmp := models.Thread{}
 	var row pgx.Row
 
 	if id, err := strconv.Atoi(slugOrId); err == nil {
-		row = rep.pool.QueryRow(context.Background(), getThreadByIdCmd, id)
+		row = rep.pool.QueryRow(ctx, getThreadByIdCmd, id)
 	} else {
-		row = rep.pool.QueryRow(context.Background(), getThreadBySlugCmd, slugOrId)
+		row = rep.pool.QueryRow(ctx, getThreadBySlugCmd, slugOrId)
 	}
 
 	if err := row.Scan(&tmp.Id, &tmp.Title, &tmp.Author, &tmp.Forum, &tmp.Message, &tmp.Slug, &tmp.Votes, &tmp.Created); err != nil {
 		if errors.Is(pgx.ErrNoRows, err) {
 			return tmp, pkgErrors.ErrThreadNotFound
 		}
-		rep.log.Error(constants.DBError, zap.Error(err))
-		return tmp, pkgErrors.ErrInternal
+		tracing.Log(ctx, rep.log).Error(constants.DBError, zap.Error(err))
+		return tmp, db.Error(ctx, err)
 	}
 
 	return tmp, nil
@@ -210,18 +212,22 @@ func (rep *repository) GetForumUsers(ctx context.Context, slug string, limit int
 	defer rows.Close()
 
 	if err != nil {
-		rep.log.Error(constants.DBError, zap.Error(err))
-		return []models.User{}, pkgErrors.ErrInternal
+		tracing.Log(ctx, rep.log).Error(constants.DBError, zap.Error(err))
+		return []models.User{}, db.Error(ctx, err)
 	}
 
 	tmp := models.User{}
 	for rows.Next() {
 		if err := rows.Scan(&tmp.Nickname, &tmp.Fullname, &tmp.About, &tmp.Email); err != nil {
-			rep.log.Error(constants.DBError, zap.Error(err))
-			return []models.User{}, pkgErrors.ErrInternal
+			tracing.Log(ctx, rep.log).Error(constants.DBError, zap.Error(err))
+			return []models.User{}, db.Error(ctx, err)
 		}
 		users = append(users, tmp)
 	}
+	if err := rows.Err(); err != nil {
+		tracing.Log(ctx, rep.log).Error(constants.DBError, zap.Error(err))
+		return []models.User{}, db.Error(ctx, err)
+	}
 	return users, nil
 }
 
@@ -263,7 +269,7 @@ func (rep *repository) GetForumThreads(ctx context.Context, slug string, limit i
 		if errors.Is(pkgErrors.ErrForumNotFound, err) {
 			return nil, pkgErrors.ErrForumNotFound
 		} else {
-			return nil, pkgErrors.ErrInternal
+			return nil, err
 		}
 	}
 
@@ -288,8 +294,10 @@ func (rep *repository) GetForumThreads(ctx context.Context, slug string, limit i
 	}
 
 	if err != nil {
-		rep.log.Error(constants.DBError, zap.Error(err))
+		tracing.Log(ctx, rep.log).Error(constants.DBError, zap.Error(err))
+		return nil, db.Error(ctx, err)
 	}
+	defer rows.Close()
 
 	threads := make([]models.Thread, 0)
 	tmp := models.Thread{}
@@ -306,12 +314,77 @@ func (rep *repository) GetForumThreads(ctx context.Context, slug string, limit i
 			&tmp.Votes,
 			&tmp.Created,
 		); err != nil {
-			rep.log.Error(constants.DBError, zap.Error(err))
-			return threads, pkgErrors.ErrInternal
+			tracing.Log(ctx, rep.log).Error(constants.DBError, zap.Error(err))
+			return threads, db.Error(ctx, err)
 		}
 
 		threads = append(threads, tmp)
 	}
+	if err := rows.Err(); err != nil {
+		tracing.Log(ctx, rep.log).Error(constants.DBError, zap.Error(err))
+		return threads, db.Error(ctx, err)
+	}
 
 	return threads, nil
 }
+
+const recountForumCmd = `
+UPDATE forums f
+SET threads = (SELECT count(*) FROM threads WHERE forum = f.slug),
+	posts = (SELECT count(*) FROM posts WHERE forum = f.slug)
+WHERE slug = $1
+RETURNING id, title, user_nickname, slug, posts, threads;`
+
+const recountThreadVotesCmd = `
+UPDATE threads t
+SET votes = coalesce((SELECT sum(voice) FROM votes WHERE thread = t.id), 0)
+WHERE forum = $1;`
+
+const clearForumUsersCmd = `
+DELETE FROM forum_users
+WHERE forum = $1;`
+
+const fillForumUsersCmd = `
+INSERT INTO forum_users (nickname, fullname, about, email, forum)
+SELECT u.nickname, u.fullname, u.about, u.email, $1
+FROM users u
+WHERE u.nickname IN (SELECT author FROM threads WHERE forum = $1
+                     UNION
+                     SELECT author FROM posts WHERE forum = $1)
+ON CONFLICT DO NOTHING;`
+
+// Recount rebuilds the denormalized thread and post counters, thread votes and
+// forum users of one forum from the source tables.
+func (rep *repository) Recount(ctx context.Context, slug string) (*models.Forum, error) {
+	tx, err := rep.pool.Begin(ctx)
+	if err != nil {
+		tracing.Log(ctx, rep.log).Error(constants.DBError, zap.Error(err))
+		return nil, db.Error(ctx, err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	forum := new(models.Forum)
+	row := tx.QueryRow(ctx, recountForumCmd, slug)
+	if err = row.Scan(&forum.ID, &forum.Title, &forum.User, &forum.Slug, &forum.Posts, &forum.Threads); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, pkgErrors.ErrForumNotFound
+		}
+		tracing.Log(ctx, rep.log).Error(constants.DBError, zap.Error(err))
+		return nil, db.Error(ctx, err)
+	}
+
+	batch := &pgx.Batch{}
+	batch.Queue(recountThreadVotesCmd, forum.Slug)
+	batch.Queue(clearForumUsersCmd, forum.Slug)
+	batch.Queue(fillForumUsersCmd, forum.Slug)
+	if err = tx.SendBatch(ctx, batch).Close(); err != nil {
+		tracing.Log(ctx, rep.log).Error(constants.DBError, zap.Error(err))
+		return nil, db.Error(ctx, err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		tracing.Log(ctx, rep.log).Error(constants.DBError, zap.Error(err))
+		return nil, db.Error(ctx, err)
+	}
+	return forum, nil
+}
@@ -4,24 +4,47 @@ import (
 	"context"
 	"github.com/SlavaShagalov/vk-dbms-project/internal/forum"
 	"github.com/SlavaShagalov/vk-dbms-project/internal/models"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/filter"
 	"go.uber.org/zap"
 )
 
 type service struct {
-	rep forum.Repository
-	log *zap.Logger
+	rep    forum.Repository
+	filter *filter.Chain
+	log    *zap.Logger
 }
 
-func NewService(rep forum.Repository, log *zap.Logger) forum.Service {
-	return &service{rep: rep, log: log}
+func NewService(rep forum.Repository, filter *filter.Chain, log *zap.Logger) forum.Service {
+	return &service{rep: rep, filter: filter, log: log}
 }
 
 func (serv *service) Create(ctx context.Context, forum *models.Forum) (*models.Forum, error) {
 	return serv.rep.Create(ctx, forum)
 }
 
-func (serv *service) CreateThread(thread *models.Thread) (models.Thread, error) {
-	return serv.rep.CreateThread(thread)
+func (serv *service) CreateThread(ctx context.Context, thread *models.Thread) (models.Thread, error) {
+	contents := []filter.Content{{
+		Kind:    filter.KindThread,
+		Forum:   thread.Forum,
+		Author:  thread.Author,
+		Title:   thread.Title,
+		Message: thread.Message,
+	}}
+	pending := *thread
+	err := serv.filter.Apply(ctx, contents, func(ctx context.Context) error {
+		_, err := serv.rep.CreateThread(ctx, &pending)
+		return err
+	})
+	if err != nil {
+		return models.Thread{}, err
+	}
+
+	created, err := serv.rep.CreateThread(ctx, thread)
+	if err != nil {
+		return created, err
+	}
+	serv.filter.Published(contents)
+	return created, nil
 }
 
 func (serv *service) Get(ctx context.Context, slug string) (*models.Forum, error) {
@@ -37,3 +60,7 @@ func (serv *service) GetForumThreads(ctx context.Context, slug string, limit int
 	desc bool) (models.ThreadList, error) {
 	return serv.rep.GetForumThreads(ctx, slug, limit, since, desc)
 }
+
+func (serv *service) Recount(ctx context.Context, slug string) (*models.Forum, error) {
+	return serv.rep.Recount(ctx, slug)
+}
@@ -0,0 +1,8 @@
+package http
+
+//go:generate easyjson -all -snake_case api_models.go
+
+// API responses
+type statusResponse struct {
+	Status string `json:"status"`
+}
@@ -0,0 +1,85 @@
+// Code generated by easyjson for marshaling/unmarshaling. DO NOT EDIT.
+
+package http
+
+import (
+	json "encoding/json"
+	easyjson "github.com/mailru/easyjson"
+	jlexer "github.com/mailru/easyjson/jlexer"
+	jwriter "github.com/mailru/easyjson/jwriter"
+)
+
+// suppress unused package warning
+var (
+	_ *json.RawMessage
+	_ *jlexer.Lexer
+	_ *jwriter.Writer
+	_ easyjson.Marshaler
+)
+
+func easyjsonC0ea9389DecodeGithubComSlavaShagalovVkDbmsProjectInternalHealthDeliveryHttp(in *jlexer.Lexer, out *statusResponse) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "status":
+			out.Status = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC0ea9389EncodeGithubComSlavaShagalovVkDbmsProjectInternalHealthDeliveryHttp(out *jwriter.Writer, in statusResponse) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"status\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Status))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v statusResponse) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC0ea9389EncodeGithubComSlavaShagalovVkDbmsProjectInternalHealthDeliveryHttp(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v statusResponse) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC0ea9389EncodeGithubComSlavaShagalovVkDbmsProjectInternalHealthDeliveryHttp(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *statusResponse) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC0ea9389DecodeGithubComSlavaShagalovVkDbmsProjectInternalHealthDeliveryHttp(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *statusResponse) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC0ea9389DecodeGithubComSlavaShagalovVkDbmsProjectInternalHealthDeliveryHttp(l, v)
+}
@@ -0,0 +1,42 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"go.uber.org/zap"
+
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/health"
+	pkgHTTP "github.com/SlavaShagalov/vk-dbms-project/internal/pkg/http"
+	mw "github.com/SlavaShagalov/vk-dbms-project/internal/pkg/middleware"
+)
+
+type delivery struct {
+	checker *health.Checker
+	log     *zap.Logger
+}
+
+// RegisterHandlers registers the probes without access logs and metrics, they
+// are polled every few seconds.
+func RegisterHandlers(router *mw.Router, log *zap.Logger, checker *health.Checker) {
+	del := delivery{checker, log}
+
+	router.GET("/healthz", mw.HandleError(del.Healthz, log))
+	router.GET("/readyz", mw.Timeout(mw.HandleError(del.Readyz, log)))
+}
+
+func (del *delivery) Healthz(w http.ResponseWriter, r *http.Request, _ httprouter.Params) error {
+	return writeStatus(w, r, "ok")
+}
+
+func (del *delivery) Readyz(w http.ResponseWriter, r *http.Request, _ httprouter.Params) error {
+	if err := del.checker.Ready(r.Context()); err != nil {
+		return err
+	}
+	return writeStatus(w, r, "ready")
+}
+
+func writeStatus(w http.ResponseWriter, r *http.Request, status string) error {
+	response := statusResponse{Status: status}
+	return pkgHTTP.WriteResponse(w, r, http.StatusOK, &response)
+}
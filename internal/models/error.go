@@ -0,0 +1,7 @@
+package models
+
+//go:generate easyjson -all -snake_case error.go
+
+type Error struct {
+	Message string `json:"message"`
+}
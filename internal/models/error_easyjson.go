@@ -1,6 +1,6 @@
 // Code generated by easyjson for marshaling/unmarshaling. DO NOT EDIT.
 
-package middleware
+package models
 
 import (
 	json "encoding/json"
@@ -17,7 +17,7 @@ var (
 	_ easyjson.Marshaler
 )
 
-func easyjsonE37f3ad8DecodeGithubComSlavaShagalovVkDbmsProjectInternalPkgMiddleware(in *jlexer.Lexer, out *ErrorResponse) {
+func easyjsonE34310f8DecodeGithubComSlavaShagalovVkDbmsProjectInternalModels(in *jlexer.Lexer, out *Error) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -48,7 +48,7 @@ func easyjsonE37f3ad8DecodeGithubComSlavaShagalovVkDbmsProjectInternalPkgMiddlew
 		in.Consumed()
 	}
 }
-func easyjsonE37f3ad8EncodeGithubComSlavaShagalovVkDbmsProjectInternalPkgMiddleware(out *jwriter.Writer, in ErrorResponse) {
+func easyjsonE34310f8EncodeGithubComSlavaShagalovVkDbmsProjectInternalModels(out *jwriter.Writer, in Error) {
 	out.RawByte('{')
 	first := true
 	_ = first
@@ -61,25 +61,25 @@ func easyjsonE37f3ad8EncodeGithubComSlavaShagalovVkDbmsProjectInternalPkgMiddlew
 }
 
 // MarshalJSON supports json.Marshaler interface
-func (v ErrorResponse) MarshalJSON() ([]byte, error) {
+func (v Error) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
-	easyjsonE37f3ad8EncodeGithubComSlavaShagalovVkDbmsProjectInternalPkgMiddleware(&w, v)
+	easyjsonE34310f8EncodeGithubComSlavaShagalovVkDbmsProjectInternalModels(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
-func (v ErrorResponse) MarshalEasyJSON(w *jwriter.Writer) {
-	easyjsonE37f3ad8EncodeGithubComSlavaShagalovVkDbmsProjectInternalPkgMiddleware(w, v)
+func (v Error) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonE34310f8EncodeGithubComSlavaShagalovVkDbmsProjectInternalModels(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
-func (v *ErrorResponse) UnmarshalJSON(data []byte) error {
+func (v *Error) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
-	easyjsonE37f3ad8DecodeGithubComSlavaShagalovVkDbmsProjectInternalPkgMiddleware(&r, v)
+	easyjsonE34310f8DecodeGithubComSlavaShagalovVkDbmsProjectInternalModels(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
-func (v *ErrorResponse) UnmarshalEasyJSON(l *jlexer.Lexer) {
-	easyjsonE37f3ad8DecodeGithubComSlavaShagalovVkDbmsProjectInternalPkgMiddleware(l, v)
+func (v *Error) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonE34310f8DecodeGithubComSlavaShagalovVkDbmsProjectInternalModels(l, v)
 }
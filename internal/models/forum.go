@@ -2,11 +2,15 @@ package models
 
 //go:generate easyjson -all -snake_case forum.go
 
+import "time"
+
 type Forum struct {
-	ID      int64
-	Title   string
-	User    string
-	Slug    string
-	Posts   int64
-	Threads int64
+	ID      int64  `json:"id"`
+	Title   string `json:"title"`
+	User    string `json:"user"`
+	Slug    string `json:"slug"`
+	Posts   int64  `json:"posts"`
+	Threads int64  `json:"threads"`
+	// UpdatedAt is the row version behind ETag and Last-Modified.
+	UpdatedAt time.Time `json:"-"`
 }
@@ -16,6 +16,8 @@ type Post struct {
 	Forum    string    `json:"forum"`
 	Thread   int       `json:"thread"`
 	Created  time.Time `json:"created"`
+	// UpdatedAt is the row version behind ETag and Last-Modified.
+	UpdatedAt time.Time `json:"-"`
 }
 
 type FullPost struct {
@@ -24,3 +26,21 @@ type FullPost struct {
 	Forum  *Forum  `json:"forum,omitempty"`
 	Thread *Thread `json:"thread,omitempty"`
 }
+
+// Versions lists the row versions of the post and the related objects.
+func (p *FullPost) Versions() []time.Time {
+	var versions []time.Time
+	if p.Post != nil {
+		versions = append(versions, p.Post.UpdatedAt)
+	}
+	if p.Author != nil {
+		versions = append(versions, p.Author.UpdatedAt)
+	}
+	if p.Forum != nil {
+		versions = append(versions, p.Forum.UpdatedAt)
+	}
+	if p.Thread != nil {
+		versions = append(versions, p.Thread.UpdatedAt)
+	}
+	return versions
+}
@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+//go:generate easyjson -all -snake_case stats.go
+
+type Stats struct {
+	Users       int `json:"users"`
+	Forums      int `json:"forums"`
+	Threads     int `json:"threads"`
+	Posts       int `json:"posts"`
+	Votes       int `json:"votes"`
+	ActiveUsers int `json:"active_users"`
+}
+
+type ForumStats struct {
+	Forum   string `json:"forum"`
+	Threads int    `json:"threads"`
+	Posts   int    `json:"posts"`
+	Votes   int    `json:"votes"`
+	Users   int    `json:"users"`
+}
+
+//easyjson:json
+type ForumStatsList []ForumStats
+
+type StatsPoint struct {
+	Time    time.Time `json:"time"`
+	Threads int       `json:"threads"`
+	Posts   int       `json:"posts"`
+}
+
+type StatsSeries struct {
+	Interval string       `json:"interval"`
+	From     time.Time    `json:"from"`
+	To       time.Time    `json:"to"`
+	Points   []StatsPoint `json:"points"`
+}
@@ -0,0 +1,485 @@
+// Code generated by easyjson for marshaling/unmarshaling. DO NOT EDIT.
+
+package models
+
+import (
+	json "encoding/json"
+	easyjson "github.com/mailru/easyjson"
+	jlexer "github.com/mailru/easyjson/jlexer"
+	jwriter "github.com/mailru/easyjson/jwriter"
+)
+
+// suppress unused package warning
+var (
+	_ *json.RawMessage
+	_ *jlexer.Lexer
+	_ *jwriter.Writer
+	_ easyjson.Marshaler
+)
+
+func easyjsonE3ab7953DecodeGithubComSlavaShagalovVkDbmsProjectInternalModels(in *jlexer.Lexer, out *StatsSeries) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "interval":
+			out.Interval = string(in.String())
+		case "from":
+			if data := in.Raw(); in.Ok() {
+				in.AddError((out.From).UnmarshalJSON(data))
+			}
+		case "to":
+			if data := in.Raw(); in.Ok() {
+				in.AddError((out.To).UnmarshalJSON(data))
+			}
+		case "points":
+			if in.IsNull() {
+				in.Skip()
+				out.Points = nil
+			} else {
+				in.Delim('[')
+				if out.Points == nil {
+					if !in.IsDelim(']') {
+						out.Points = make([]StatsPoint, 0, 1)
+					} else {
+						out.Points = []StatsPoint{}
+					}
+				} else {
+					out.Points = (out.Points)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v1 StatsPoint
+					(v1).UnmarshalEasyJSON(in)
+					out.Points = append(out.Points, v1)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonE3ab7953EncodeGithubComSlavaShagalovVkDbmsProjectInternalModels(out *jwriter.Writer, in StatsSeries) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"interval\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Interval))
+	}
+	{
+		const prefix string = ",\"from\":"
+		out.RawString(prefix)
+		out.Raw((in.From).MarshalJSON())
+	}
+	{
+		const prefix string = ",\"to\":"
+		out.RawString(prefix)
+		out.Raw((in.To).MarshalJSON())
+	}
+	{
+		const prefix string = ",\"points\":"
+		out.RawString(prefix)
+		if in.Points == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v2, v3 := range in.Points {
+				if v2 > 0 {
+					out.RawByte(',')
+				}
+				(v3).MarshalEasyJSON(out)
+			}
+			out.RawByte(']')
+		}
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v StatsSeries) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonE3ab7953EncodeGithubComSlavaShagalovVkDbmsProjectInternalModels(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v StatsSeries) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonE3ab7953EncodeGithubComSlavaShagalovVkDbmsProjectInternalModels(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *StatsSeries) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonE3ab7953DecodeGithubComSlavaShagalovVkDbmsProjectInternalModels(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *StatsSeries) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonE3ab7953DecodeGithubComSlavaShagalovVkDbmsProjectInternalModels(l, v)
+}
+func easyjsonE3ab7953DecodeGithubComSlavaShagalovVkDbmsProjectInternalModels1(in *jlexer.Lexer, out *StatsPoint) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "time":
+			if data := in.Raw(); in.Ok() {
+				in.AddError((out.Time).UnmarshalJSON(data))
+			}
+		case "threads":
+			out.Threads = int(in.Int())
+		case "posts":
+			out.Posts = int(in.Int())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonE3ab7953EncodeGithubComSlavaShagalovVkDbmsProjectInternalModels1(out *jwriter.Writer, in StatsPoint) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"time\":"
+		out.RawString(prefix[1:])
+		out.Raw((in.Time).MarshalJSON())
+	}
+	{
+		const prefix string = ",\"threads\":"
+		out.RawString(prefix)
+		out.Int(int(in.Threads))
+	}
+	{
+		const prefix string = ",\"posts\":"
+		out.RawString(prefix)
+		out.Int(int(in.Posts))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v StatsPoint) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonE3ab7953EncodeGithubComSlavaShagalovVkDbmsProjectInternalModels1(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v StatsPoint) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonE3ab7953EncodeGithubComSlavaShagalovVkDbmsProjectInternalModels1(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *StatsPoint) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonE3ab7953DecodeGithubComSlavaShagalovVkDbmsProjectInternalModels1(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *StatsPoint) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonE3ab7953DecodeGithubComSlavaShagalovVkDbmsProjectInternalModels1(l, v)
+}
+func easyjsonE3ab7953DecodeGithubComSlavaShagalovVkDbmsProjectInternalModels2(in *jlexer.Lexer, out *Stats) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "users":
+			out.Users = int(in.Int())
+		case "forums":
+			out.Forums = int(in.Int())
+		case "threads":
+			out.Threads = int(in.Int())
+		case "posts":
+			out.Posts = int(in.Int())
+		case "votes":
+			out.Votes = int(in.Int())
+		case "active_users":
+			out.ActiveUsers = int(in.Int())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonE3ab7953EncodeGithubComSlavaShagalovVkDbmsProjectInternalModels2(out *jwriter.Writer, in Stats) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"users\":"
+		out.RawString(prefix[1:])
+		out.Int(int(in.Users))
+	}
+	{
+		const prefix string = ",\"forums\":"
+		out.RawString(prefix)
+		out.Int(int(in.Forums))
+	}
+	{
+		const prefix string = ",\"threads\":"
+		out.RawString(prefix)
+		out.Int(int(in.Threads))
+	}
+	{
+		const prefix string = ",\"posts\":"
+		out.RawString(prefix)
+		out.Int(int(in.Posts))
+	}
+	{
+		const prefix string = ",\"votes\":"
+		out.RawString(prefix)
+		out.Int(int(in.Votes))
+	}
+	{
+		const prefix string = ",\"active_users\":"
+		out.RawString(prefix)
+		out.Int(int(in.ActiveUsers))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v Stats) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonE3ab7953EncodeGithubComSlavaShagalovVkDbmsProjectInternalModels2(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v Stats) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonE3ab7953EncodeGithubComSlavaShagalovVkDbmsProjectInternalModels2(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *Stats) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonE3ab7953DecodeGithubComSlavaShagalovVkDbmsProjectInternalModels2(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *Stats) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonE3ab7953DecodeGithubComSlavaShagalovVkDbmsProjectInternalModels2(l, v)
+}
+func easyjsonE3ab7953DecodeGithubComSlavaShagalovVkDbmsProjectInternalModels3(in *jlexer.Lexer, out *ForumStatsList) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		in.Skip()
+		*out = nil
+	} else {
+		in.Delim('[')
+		if *out == nil {
+			if !in.IsDelim(']') {
+				*out = make(ForumStatsList, 0, 1)
+			} else {
+				*out = ForumStatsList{}
+			}
+		} else {
+			*out = (*out)[:0]
+		}
+		for !in.IsDelim(']') {
+			var v4 ForumStats
+			(v4).UnmarshalEasyJSON(in)
+			*out = append(*out, v4)
+			in.WantComma()
+		}
+		in.Delim(']')
+	}
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonE3ab7953EncodeGithubComSlavaShagalovVkDbmsProjectInternalModels3(out *jwriter.Writer, in ForumStatsList) {
+	if in == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+		out.RawString("null")
+	} else {
+		out.RawByte('[')
+		for v5, v6 := range in {
+			if v5 > 0 {
+				out.RawByte(',')
+			}
+			(v6).MarshalEasyJSON(out)
+		}
+		out.RawByte(']')
+	}
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v ForumStatsList) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonE3ab7953EncodeGithubComSlavaShagalovVkDbmsProjectInternalModels3(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v ForumStatsList) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonE3ab7953EncodeGithubComSlavaShagalovVkDbmsProjectInternalModels3(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *ForumStatsList) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonE3ab7953DecodeGithubComSlavaShagalovVkDbmsProjectInternalModels3(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *ForumStatsList) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonE3ab7953DecodeGithubComSlavaShagalovVkDbmsProjectInternalModels3(l, v)
+}
+func easyjsonE3ab7953DecodeGithubComSlavaShagalovVkDbmsProjectInternalModels4(in *jlexer.Lexer, out *ForumStats) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "forum":
+			out.Forum = string(in.String())
+		case "threads":
+			out.Threads = int(in.Int())
+		case "posts":
+			out.Posts = int(in.Int())
+		case "votes":
+			out.Votes = int(in.Int())
+		case "users":
+			out.Users = int(in.Int())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonE3ab7953EncodeGithubComSlavaShagalovVkDbmsProjectInternalModels4(out *jwriter.Writer, in ForumStats) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"forum\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Forum))
+	}
+	{
+		const prefix string = ",\"threads\":"
+		out.RawString(prefix)
+		out.Int(int(in.Threads))
+	}
+	{
+		const prefix string = ",\"posts\":"
+		out.RawString(prefix)
+		out.Int(int(in.Posts))
+	}
+	{
+		const prefix string = ",\"votes\":"
+		out.RawString(prefix)
+		out.Int(int(in.Votes))
+	}
+	{
+		const prefix string = ",\"users\":"
+		out.RawString(prefix)
+		out.Int(int(in.Users))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v ForumStats) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonE3ab7953EncodeGithubComSlavaShagalovVkDbmsProjectInternalModels4(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v ForumStats) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonE3ab7953EncodeGithubComSlavaShagalovVkDbmsProjectInternalModels4(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *ForumStats) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonE3ab7953DecodeGithubComSlavaShagalovVkDbmsProjectInternalModels4(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *ForumStats) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonE3ab7953DecodeGithubComSlavaShagalovVkDbmsProjectInternalModels4(l, v)
+}
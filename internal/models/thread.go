@@ -14,6 +14,8 @@ type Thread struct {
 	Forum   string    `json:"forum"`
 	Message string    `json:"message"`
 	Votes   int       `json:"votes"`
-	Slug    string    `json:"slug"`
+	Slug    string    `json:"slug,omitempty"`
 	Created time.Time `json:"created"`
+	// UpdatedAt is the row version behind ETag and Last-Modified.
+	UpdatedAt time.Time `json:"-"`
 }
@@ -164,7 +164,7 @@ func easyjson2d00218EncodeGithubComSlavaShagalovVkDbmsProjectInternalModels1(out
 		out.RawString(prefix)
 		out.Int(int(in.Votes))
 	}
-	{
+	if in.Slug != "" {
 		const prefix string = ",\"slug\":"
 		out.RawString(prefix)
 		out.String(string(in.Slug))
@@ -2,13 +2,17 @@ package models
 
 //go:generate easyjson -all -snake_case user.go
 
+import "time"
+
 //easyjson:json
 type UserList []User
 
 type User struct {
-	ID       int
-	Nickname string
-	Fullname string
-	About    string
-	Email    string
+	ID       int    `json:"id"`
+	Nickname string `json:"nickname"`
+	Fullname string `json:"fullname"`
+	About    string `json:"about"`
+	Email    string `json:"email"`
+	// UpdatedAt is the row version behind ETag and Last-Modified.
+	UpdatedAt time.Time `json:"-"`
 }
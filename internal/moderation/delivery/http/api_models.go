@@ -0,0 +1,66 @@
+package http
+
+import (
+	"time"
+
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/filter"
+)
+
+//go:generate easyjson -all -snake_case api_models.go
+
+// API responses
+type contentResponse struct {
+	Kind    string `json:"kind"`
+	Forum   string `json:"forum"`
+	Thread  int    `json:"thread,omitempty"`
+	Author  string `json:"author"`
+	Title   string `json:"title,omitempty"`
+	Message string `json:"message"`
+}
+
+type verdictResponse struct {
+	Action string `json:"action"`
+	Filter string `json:"filter"`
+	Reason string `json:"reason"`
+}
+
+type itemResponse struct {
+	ID       int               `json:"id"`
+	Contents []contentResponse `json:"contents"`
+	Verdicts []verdictResponse `json:"verdicts"`
+	Created  time.Time         `json:"created"`
+}
+
+//easyjson:json
+type queueResponse []itemResponse
+
+func newQueueResponse(items []filter.Item) queueResponse {
+	response := make(queueResponse, 0, len(items))
+	for _, item := range items {
+		tmp := itemResponse{
+			ID:       item.ID,
+			Contents: make([]contentResponse, 0, len(item.Contents)),
+			Verdicts: make([]verdictResponse, 0, len(item.Verdicts)),
+			Created:  item.Created,
+		}
+		for _, content := range item.Contents {
+			tmp.Contents = append(tmp.Contents, contentResponse{
+				Kind:    content.Kind,
+				Forum:   content.Forum,
+				Thread:  content.Thread,
+				Author:  content.Author,
+				Title:   content.Title,
+				Message: content.Message,
+			})
+		}
+		for _, verdict := range item.Verdicts {
+			tmp.Verdicts = append(tmp.Verdicts, verdictResponse{
+				Action: verdict.Action.String(),
+				Filter: verdict.Filter,
+				Reason: verdict.Reason,
+			})
+		}
+		response = append(response, tmp)
+	}
+	return response
+}
@@ -0,0 +1,57 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/julienschmidt/httprouter"
+	"go.uber.org/zap"
+
+	pkgErrors "github.com/SlavaShagalov/vk-dbms-project/internal/pkg/errors"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/filter"
+	pkgHTTP "github.com/SlavaShagalov/vk-dbms-project/internal/pkg/http"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/metrics"
+	mw "github.com/SlavaShagalov/vk-dbms-project/internal/pkg/middleware"
+)
+
+type delivery struct {
+	queue *filter.Queue
+	log   *zap.Logger
+}
+
+// RegisterAdminHandlers registers the review queue routes for the admin listener.
+func RegisterAdminHandlers(router *mw.Router, log *zap.Logger, queue *filter.Queue, m *metrics.Metrics) {
+	del := delivery{queue, log}
+
+	router.GET("/api/moderation/queue", mw.Trace(mw.Timeout(mw.AccessLog(mw.Metrics(mw.HandleError(del.List, log), m), log))))
+	router.POST("/api/moderation/queue/:id/:action", mw.Trace(mw.Timeout(mw.AccessLog(mw.Metrics(mw.HandleError(del.Resolve, log), m), log))))
+}
+
+func (del *delivery) List(w http.ResponseWriter, r *http.Request, _ httprouter.Params) error {
+	response := newQueueResponse(del.queue.List())
+	return pkgHTTP.WriteResponse(w, r, http.StatusOK, response)
+}
+
+func (del *delivery) Resolve(w http.ResponseWriter, r *http.Request, p httprouter.Params) error {
+	id, err := strconv.Atoi(p.ByName("id"))
+	if err != nil {
+		return pkgErrors.ErrInvalidIDParam
+	}
+
+	approve := false
+	switch p.ByName("action") {
+	case "approve":
+		approve = true
+	case "reject":
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		return nil
+	}
+
+	if err = del.queue.Resolve(r.Context(), id, approve); err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
@@ -0,0 +1,42 @@
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// Set at build time:
+//
+//	go build -ldflags "-X github.com/SlavaShagalov/vk-dbms-project/internal/pkg/buildinfo.Version=v1.2.0"
+var (
+	Version = "dev"
+	Commit  = ""
+)
+
+type Info struct {
+	Version   string
+	Commit    string
+	GoVersion string
+	Modified  bool
+}
+
+// Get falls back to the VCS stamp of the binary when Commit was not set.
+func Get() Info {
+	info := Info{Version: Version, Commit: Commit, GoVersion: runtime.Version()}
+
+	build, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	for _, setting := range build.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			if info.Commit == "" {
+				info.Commit = setting.Value
+			}
+		case "vcs.modified":
+			info.Modified = setting.Value == "true"
+		}
+	}
+	return info
+}
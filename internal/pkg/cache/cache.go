@@ -0,0 +1,130 @@
+// Package cache keeps hot thread and forum lookups out of the database.
+//
+// The in-process LRU keeps the models as they are, Redis keeps them encoded
+// by the codec of the cache, together with the row versions the API form
+// leaves out.
+// Writes through the repository decorators drop the affected keys at once,
+// changes made elsewhere arrive through PostgreSQL notifications. Every drop
+// bumps the version of the key, and a fill that read the database before the
+// drop is discarded by the version check in Set. The TTL bounds the staleness
+// only when a notification is lost.
+package cache
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/metrics"
+)
+
+// Channel is the PostgreSQL notification channel, payloads are keys or All.
+const Channel = "cache_invalidation"
+
+// All as a payload drops every entry.
+const All = "*"
+
+// Names of the caches in metrics.
+const (
+	NameThread = "thread"
+	NameForum  = "forum"
+)
+
+type Store interface {
+	// Get returns the value stored under key, false on a miss. The version
+	// of the key is passed to Set when the miss is filled.
+	Get(ctx context.Context, key string) (value interface{}, version uint64, ok bool, err error)
+	// Set stores value unless key was deleted after Get returned version.
+	Set(ctx context.Context, key string, version uint64, value interface{}) error
+	// Delete drops keys and bumps their versions.
+	Delete(ctx context.Context, keys ...string) error
+	// Purge drops every entry.
+	Purge(ctx context.Context) error
+	// Shared reports whether other processes use the store too. Values of a
+	// shared store are passed encoded.
+	Shared() bool
+}
+
+// Codec encodes values for a shared store.
+type Codec[T any] struct {
+	Marshal   func(v *T) ([]byte, error)
+	Unmarshal func(data []byte, v *T) error
+}
+
+// Slugs are case-insensitive, like the citext columns they come from. A thread
+// is cached under the id or slug key it was looked up by.
+func ThreadKey(id int) string          { return "thread:" + strconv.Itoa(id) }
+func ThreadSlugKey(slug string) string { return "thread-slug:" + strings.ToLower(slug) }
+func ForumKey(slug string) string      { return "forum:" + strings.ToLower(slug) }
+
+// Cache reads and writes values of one kind through a store. Store errors are
+// logged and treated as misses, the database stays the source of truth when
+// Redis is unavailable.
+type Cache[T any] struct {
+	store Store
+	codec Codec[T]
+	name  string
+	m     *metrics.Metrics
+	log   *zap.Logger
+}
+
+func New[T any](store Store, name string, codec Codec[T], m *metrics.Metrics, log *zap.Logger) *Cache[T] {
+	return &Cache[T]{store: store, codec: codec, name: name, m: m, log: log}
+}
+
+// Get copies the value under key into v and reports whether it was found.
+// A miss returns the version to fill the key with.
+func (c *Cache[T]) Get(ctx context.Context, key string, v *T) (uint64, bool) {
+	value, version, ok, err := c.store.Get(ctx, key)
+	if err == nil && ok {
+		switch value := value.(type) {
+		case T:
+			*v = value
+		case []byte:
+			err = c.codec.Unmarshal(value, v)
+		default:
+			err = fmt.Errorf("unexpected %T in the %s cache", value, c.name)
+		}
+	}
+	if err != nil {
+		c.log.Warn("Cache get failed", zap.String("key", key), zap.Error(err))
+		ok = false
+	}
+	c.m.ObserveCache(c.name, ok)
+	return version, ok
+}
+
+// Set fills key with v read from the database after the miss that returned version.
+func (c *Cache[T]) Set(ctx context.Context, key string, version uint64, v *T) {
+	var value interface{} = *v
+	if c.store.Shared() {
+		data, err := c.codec.Marshal(v)
+		if err != nil {
+			c.log.Warn("Cache encode failed", zap.String("cache", c.name), zap.Error(err))
+			return
+		}
+		value = data
+	}
+	if err := c.store.Set(ctx, key, version, value); err != nil {
+		c.log.Warn("Cache set failed", zap.String("key", key), zap.Error(err))
+	}
+}
+
+// Delete drops keys after a write.
+func (c *Cache[T]) Delete(ctx context.Context, keys ...string) {
+	if err := c.store.Delete(ctx, keys...); err != nil {
+		c.log.Warn("Cache delete failed", zap.Strings("keys", keys), zap.Error(err))
+		return
+	}
+	c.m.AddCacheInvalidations("write", len(keys))
+}
+
+// Purge drops every entry of the store, not only the keys of this cache.
+func (c *Cache[T]) Purge(ctx context.Context) {
+	if err := c.store.Purge(ctx); err != nil {
+		c.log.Warn("Cache purge failed", zap.Error(err))
+	}
+}
@@ -0,0 +1,68 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/metrics"
+)
+
+const listenRetry = 5 * time.Second
+
+// Listen drops the keys named by notifications on Channel until ctx is done.
+// It takes one connection out of the pool. Notifications sent while it was not listening
+// are lost, so the store is purged on every (re)connect.
+func Listen(ctx context.Context, pool *pgxpool.Pool, store Store, m *metrics.Metrics, log *zap.Logger) {
+	for {
+		err := listen(ctx, pool, store, m, log)
+		if ctx.Err() != nil {
+			return
+		}
+		log.Error("Cache invalidation listener failed", zap.Error(err), zap.Duration("retry", listenRetry))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(listenRetry):
+		}
+	}
+}
+
+func listen(ctx context.Context, pool *pgxpool.Pool, store Store, m *metrics.Metrics, log *zap.Logger) error {
+	pooled, err := pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	// The connection keeps listening, it is taken out of the pool for good.
+	conn := pooled.Hijack()
+	defer conn.Close(context.Background())
+
+	if _, err = conn.Exec(ctx, "LISTEN "+pgx.Identifier{Channel}.Sanitize()); err != nil {
+		return err
+	}
+	if err = store.Purge(ctx); err != nil {
+		return err
+	}
+	log.Info("Listening for cache invalidations", zap.String("channel", Channel))
+
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		if n.Payload == All {
+			err = store.Purge(ctx)
+		} else {
+			err = store.Delete(ctx, n.Payload)
+		}
+		if err != nil {
+			log.Warn("Cache invalidation failed", zap.String("payload", n.Payload), zap.Error(err))
+			continue
+		}
+		m.AddCacheInvalidations("notify", 1)
+	}
+}
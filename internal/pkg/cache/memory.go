@@ -0,0 +1,109 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// entry is a cached value or, with a nil value, a deleted key that keeps its
+// version.
+type entry struct {
+	key     string
+	value   interface{}
+	version uint64
+	expires time.Time
+}
+
+type memoryStore struct {
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	// order has the most recently used entries first.
+	order   *list.List
+	size    int
+	ttl     time.Duration
+	version uint64
+}
+
+// NewMemoryStore returns an LRU of at most size entries living for ttl.
+func NewMemoryStore(size int, ttl time.Duration) Store {
+	return &memoryStore{entries: make(map[string]*list.Element, size), order: list.New(), size: size, ttl: ttl}
+}
+
+func (s *memoryStore) Get(_ context.Context, key string) (interface{}, uint64, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	elem, ok := s.entries[key]
+	if !ok {
+		return nil, 0, false, nil
+	}
+	e := elem.Value.(*entry)
+	if e.value == nil || time.Now().After(e.expires) {
+		return nil, e.version, false, nil
+	}
+	s.order.MoveToFront(elem)
+	return e.value, e.version, true, nil
+}
+
+func (s *memoryStore) Set(_ context.Context, key string, version uint64, value interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.put(key, version, value)
+	return nil
+}
+
+func (s *memoryStore) Delete(_ context.Context, keys ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, key := range keys {
+		s.version++
+		s.put(key, s.version, nil)
+	}
+	return nil
+}
+
+func (s *memoryStore) Purge(_ context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries = make(map[string]*list.Element, s.size)
+	s.order.Init()
+	return nil
+}
+
+// put stores value when version matches the stored one, a delete passes the
+// new version.
+func (s *memoryStore) put(key string, version uint64, value interface{}) {
+	expires := time.Now().Add(s.ttl)
+	if elem, ok := s.entries[key]; ok {
+		e := elem.Value.(*entry)
+		if value != nil && e.version != version {
+			return
+		}
+		e.value, e.version, e.expires = value, version, expires
+		s.order.MoveToFront(elem)
+		return
+	}
+	if value != nil && version != 0 && version <= s.version {
+		// The deleted entry was evicted, its version is unknown.
+		return
+	}
+
+	s.entries[key] = s.order.PushFront(&entry{key: key, value: value, version: version, expires: expires})
+	for s.order.Len() > s.size {
+		s.remove(s.order.Back())
+	}
+}
+
+func (s *memoryStore) Shared() bool {
+	return false
+}
+
+func (s *memoryStore) remove(elem *list.Element) {
+	s.order.Remove(elem)
+	delete(s.entries, elem.Value.(*entry).key)
+}
@@ -0,0 +1,106 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const purgeBatch = 500
+
+// versionSuffix names the key holding the version of a cached key.
+const versionSuffix = "#version"
+
+// setScript stores the value only while the version key still has the value
+// read on the miss, a missing version key is 0.
+var setScript = redis.NewScript(`
+if (redis.call('GET', KEYS[2]) or '0') == ARGV[1] then
+    redis.call('SET', KEYS[1], ARGV[2], 'PX', ARGV[3])
+end
+return 0`)
+
+type redisStore struct {
+	client redis.UniversalClient
+	prefix string
+	ttl    time.Duration
+}
+
+// NewRedisStore keeps entries in Redis under prefix, so replicas share them.
+func NewRedisStore(client redis.UniversalClient, prefix string, ttl time.Duration) Store {
+	return &redisStore{client: client, prefix: prefix, ttl: ttl}
+}
+
+func (s *redisStore) Get(ctx context.Context, key string) (interface{}, uint64, bool, error) {
+	values, err := s.client.MGet(ctx, s.prefix+key, s.prefix+key+versionSuffix).Result()
+	if err != nil {
+		return nil, 0, false, err
+	}
+
+	var version uint64
+	if raw, ok := values[1].(string); ok {
+		if version, err = strconv.ParseUint(raw, 10, 64); err != nil {
+			return nil, 0, false, err
+		}
+	}
+	value, ok := values[0].(string)
+	if !ok {
+		return nil, version, false, nil
+	}
+	return []byte(value), version, true, nil
+}
+
+func (s *redisStore) Set(ctx context.Context, key string, version uint64, value interface{}) error {
+	data, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("redis store needs encoded values, got %T", value)
+	}
+	keys := []string{s.prefix + key, s.prefix + key + versionSuffix}
+	return setScript.Run(ctx, s.client, keys, version, data, s.ttl.Milliseconds()).Err()
+}
+
+func (s *redisStore) Shared() bool {
+	return true
+}
+
+// Delete keeps the bumped versions for the TTL, a fill running longer than
+// that may still store what it read.
+func (s *redisStore) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, key := range keys {
+			pipe.Del(ctx, s.prefix+key)
+			pipe.Incr(ctx, s.prefix+key+versionSuffix)
+			pipe.PExpire(ctx, s.prefix+key+versionSuffix, s.ttl)
+		}
+		return nil
+	})
+	return err
+}
+
+// Purge deletes the keys under the prefix in batches, other data in the same
+// Redis database is kept.
+func (s *redisStore) Purge(ctx context.Context) error {
+	iter := s.client.Scan(ctx, 0, s.prefix+"*", purgeBatch).Iterator()
+	batch := make([]string, 0, purgeBatch)
+	for iter.Next(ctx) {
+		batch = append(batch, iter.Val())
+		if len(batch) == purgeBatch {
+			if err := s.client.Del(ctx, batch...).Err(); err != nil {
+				return err
+			}
+			batch = batch[:0]
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return err
+	}
+	if len(batch) > 0 {
+		return s.client.Del(ctx, batch...).Err()
+	}
+	return nil
+}
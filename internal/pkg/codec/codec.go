@@ -0,0 +1,93 @@
+package codec
+
+import (
+	"errors"
+	"mime"
+	"strconv"
+	"strings"
+)
+
+// ErrUnsupported is returned for values that have no representation in a
+// format, the caller answers in JSON or with 415 then.
+var ErrUnsupported = errors.New("codec: type is not supported by the format")
+
+// Codec encodes API models in one wire format.
+type Codec interface {
+	// Name is the short name used in metrics, logs and ETag suffixes.
+	Name() string
+	// ContentType is the media type of the responses.
+	ContentType() string
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+var (
+	JSON     Codec = jsonCodec{}
+	MsgPack  Codec = msgpackCodec{}
+	Protobuf Codec = protobufCodec{}
+)
+
+var mediaTypes = map[string]Codec{
+	"application/json":                JSON,
+	"application/msgpack":             MsgPack,
+	"application/x-msgpack":           MsgPack,
+	"application/vnd.msgpack":         MsgPack,
+	"application/x-protobuf":          Protobuf,
+	"application/protobuf":            Protobuf,
+	"application/vnd.google.protobuf": Protobuf,
+}
+
+// ForContentType returns the codec of a Content-Type header. A missing header
+// means JSON, the only format of the API before; unknown types return nil.
+func ForContentType(header string) Codec {
+	if strings.TrimSpace(header) == "" {
+		return JSON
+	}
+	mediaType, _, err := mime.ParseMediaType(header)
+	if err != nil {
+		return nil
+	}
+	return mediaTypes[mediaType]
+}
+
+// Negotiate picks the codec with the highest quality in Accept, ties go to the
+// first one listed. Wildcards and a missing or unsatisfiable header mean
+// fallback, the format of the request.
+func Negotiate(accept string, fallback Codec) Codec {
+	if strings.TrimSpace(accept) == "" {
+		return fallback
+	}
+
+	var best Codec
+	bestQ := 0.0
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if value, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(value, 64); err != nil {
+				continue
+			}
+		}
+
+		var c Codec
+		switch mediaType {
+		case "*/*", "application/*":
+			c = fallback
+		default:
+			c = mediaTypes[mediaType]
+		}
+		if c == nil || q <= 0 {
+			continue
+		}
+		if q > bestQ {
+			best, bestQ = c, q
+		}
+	}
+	if best == nil {
+		return fallback
+	}
+	return best
+}
@@ -0,0 +1,75 @@
+package codec
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/SlavaShagalov/vk-dbms-project/internal/models"
+)
+
+func TestForContentType(t *testing.T) {
+	for _, tc := range []struct {
+		header string
+		want   Codec
+	}{
+		{"", JSON},
+		{"application/json; charset=utf-8", JSON},
+		{"application/x-msgpack", MsgPack},
+		{"application/vnd.google.protobuf", Protobuf},
+		{"text/plain", nil},
+		{"not a media type;", nil},
+	} {
+		if got := ForContentType(tc.header); got != tc.want {
+			t.Errorf("%q: %v, want %v", tc.header, got, tc.want)
+		}
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	for _, tc := range []struct {
+		accept   string
+		fallback Codec
+		want     Codec
+	}{
+		{"", MsgPack, MsgPack},
+		{"application/msgpack", JSON, MsgPack},
+		{"application/json, application/x-protobuf", MsgPack, JSON},
+		{"application/json;q=0.5, application/x-protobuf", JSON, Protobuf},
+		{"application/x-protobuf;q=0, application/json;q=0.1", MsgPack, JSON},
+		{"*/*", Protobuf, Protobuf},
+		{"application/*;q=0.9, application/msgpack", JSON, MsgPack},
+		{"text/html", MsgPack, MsgPack},
+		{"application/msgpack;q=bad", JSON, JSON},
+	} {
+		if got := Negotiate(tc.accept, tc.fallback); got != tc.want {
+			t.Errorf("%q: %s, want %s", tc.accept, got.Name(), tc.want.Name())
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	user := models.User{ID: 1, Nickname: "j.sparrow", Fullname: "Jack Sparrow", About: "Captain", Email: "jack@pearl.sea"}
+	for _, c := range []Codec{JSON, MsgPack, Protobuf} {
+		data, err := c.Marshal(&user)
+		if err != nil {
+			t.Fatalf("%s: %v", c.Name(), err)
+		}
+		var decoded models.User
+		if err = c.Unmarshal(data, &decoded); err != nil {
+			t.Fatalf("%s: %v", c.Name(), err)
+		}
+		if decoded != user {
+			t.Errorf("%s: decoded %+v, want %+v", c.Name(), decoded, user)
+		}
+	}
+}
+
+func TestProtobufUnsupported(t *testing.T) {
+	if _, err := Protobuf.Marshal(map[string]int{"a": 1}); !errors.Is(err, ErrUnsupported) {
+		t.Fatalf("marshal: %v, want %v", err, ErrUnsupported)
+	}
+	var v map[string]int
+	if err := Protobuf.Unmarshal(nil, &v); !errors.Is(err, ErrUnsupported) {
+		t.Fatalf("unmarshal: %v, want %v", err, ErrUnsupported)
+	}
+}
@@ -0,0 +1,27 @@
+package codec
+
+import (
+	"encoding/json"
+
+	"github.com/mailru/easyjson"
+)
+
+type jsonCodec struct{}
+
+func (jsonCodec) Name() string { return "json" }
+
+func (jsonCodec) ContentType() string { return "application/json" }
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	if m, ok := v.(easyjson.Marshaler); ok {
+		return easyjson.Marshal(m)
+	}
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	if u, ok := v.(easyjson.Unmarshaler); ok {
+		return easyjson.Unmarshal(data, u)
+	}
+	return json.Unmarshal(data, v)
+}
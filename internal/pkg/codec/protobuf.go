@@ -0,0 +1,108 @@
+package codec
+
+import (
+	"google.golang.org/protobuf/proto"
+
+	"github.com/SlavaShagalov/vk-dbms-project/internal/models"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/pb"
+)
+
+// protobufCodec encodes the models through the messages of proto/forum.proto,
+// other types are ErrUnsupported.
+type protobufCodec struct{}
+
+func (protobufCodec) Name() string { return "protobuf" }
+
+func (protobufCodec) ContentType() string { return "application/x-protobuf" }
+
+func (protobufCodec) Marshal(v interface{}) ([]byte, error) {
+	msg := toMessage(v)
+	if msg == nil {
+		return nil, ErrUnsupported
+	}
+	return proto.Marshal(msg)
+}
+
+func toMessage(v interface{}) proto.Message {
+	switch v := v.(type) {
+	case proto.Message:
+		return v
+	case *models.User:
+		return pb.NewUser(v)
+	case models.UserList:
+		return pb.NewUsers(v)
+	case []models.User:
+		return pb.NewUsers(v)
+	case *models.Forum:
+		return pb.NewForum(v)
+	case *models.Thread:
+		return pb.NewThread(v)
+	case models.ThreadList:
+		return pb.NewThreads(v)
+	case *models.Post:
+		return pb.NewPost(v)
+	case models.PostList:
+		return pb.NewPosts(v)
+	case *models.FullPost:
+		return pb.NewPostFull(v)
+	case *models.Vote:
+		return pb.NewVote(v)
+	case *models.Status:
+		return pb.NewStatus(v)
+	case *models.Error:
+		return &pb.Error{Message: v.Message}
+	}
+	return nil
+}
+
+func (protobufCodec) Unmarshal(data []byte, v interface{}) error {
+	switch v := v.(type) {
+	case proto.Message:
+		return proto.Unmarshal(data, v)
+	case *models.User:
+		msg := new(pb.User)
+		if err := proto.Unmarshal(data, msg); err != nil {
+			return err
+		}
+		*v = msg.Model()
+	case *models.Forum:
+		msg := new(pb.Forum)
+		if err := proto.Unmarshal(data, msg); err != nil {
+			return err
+		}
+		*v = msg.Model()
+	case *models.Thread:
+		msg := new(pb.Thread)
+		if err := proto.Unmarshal(data, msg); err != nil {
+			return err
+		}
+		*v = msg.Model()
+	case *models.Post:
+		msg := new(pb.Post)
+		if err := proto.Unmarshal(data, msg); err != nil {
+			return err
+		}
+		*v = msg.Model()
+	case *models.PostList:
+		msg := new(pb.Posts)
+		if err := proto.Unmarshal(data, msg); err != nil {
+			return err
+		}
+		*v = msg.Model()
+	case *models.Vote:
+		msg := new(pb.Vote)
+		if err := proto.Unmarshal(data, msg); err != nil {
+			return err
+		}
+		*v = msg.Model()
+	case *models.Error:
+		msg := new(pb.Error)
+		if err := proto.Unmarshal(data, msg); err != nil {
+			return err
+		}
+		v.Message = msg.GetMessage()
+	default:
+		return ErrUnsupported
+	}
+	return nil
+}
@@ -0,0 +1,245 @@
+package config
+
+import (
+	"time"
+
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/ratelimit"
+)
+
+const (
+	StoragePostgres = "postgres"
+	StorageMemory   = "memory"
+)
+
+// Config is the effective backend configuration. Values are applied in order:
+// defaults, config file (YAML or TOML), environment variables, command-line flags.
+type Config struct {
+	Storage     string            `yaml:"storage" toml:"storage" env:"STORAGE" flag:"storage" usage:"postgres or memory; memory keeps all data in the process and needs no database"`
+	Server      ServerConfig      `yaml:"server" toml:"server"`
+	DB          DBConfig          `yaml:"db" toml:"db"`
+	Log         LogConfig         `yaml:"log" toml:"log"`
+	Features    FeaturesConfig    `yaml:"features" toml:"features"`
+	Metrics     MetricsConfig     `yaml:"metrics" toml:"metrics"`
+	Admin       AdminConfig       `yaml:"admin" toml:"admin"`
+	GRPC        GRPCConfig        `yaml:"grpc" toml:"grpc"`
+	RateLimit   RateLimitConfig   `yaml:"rate_limit" toml:"rate_limit"`
+	Filters     FiltersConfig     `yaml:"filters" toml:"filters"`
+	Tracing     TracingConfig     `yaml:"tracing" toml:"tracing"`
+	Reconcile   ReconcileConfig   `yaml:"reconcile" toml:"reconcile"`
+	Cache       CacheConfig       `yaml:"cache" toml:"cache"`
+	Compression CompressionConfig `yaml:"compression" toml:"compression"`
+}
+
+type ServerConfig struct {
+	Addr              string                   `yaml:"addr" toml:"addr" env:"SERVER_ADDR" flag:"server.addr" usage:"listen address"`
+	ReadTimeout       time.Duration            `yaml:"read_timeout" toml:"read_timeout" env:"SERVER_READ_TIMEOUT" flag:"server.read-timeout" usage:"request read timeout"`
+	ReadHeaderTimeout time.Duration            `yaml:"read_header_timeout" toml:"read_header_timeout" env:"SERVER_READ_HEADER_TIMEOUT" flag:"server.read-header-timeout" usage:"request header read timeout"`
+	WriteTimeout      time.Duration            `yaml:"write_timeout" toml:"write_timeout" env:"SERVER_WRITE_TIMEOUT" flag:"server.write-timeout" usage:"response write timeout"`
+	IdleTimeout       time.Duration            `yaml:"idle_timeout" toml:"idle_timeout" env:"SERVER_IDLE_TIMEOUT" flag:"server.idle-timeout" usage:"keep-alive idle timeout"`
+	DrainTimeout      time.Duration            `yaml:"drain_timeout" toml:"drain_timeout" env:"SERVER_DRAIN_TIMEOUT" flag:"server.drain-timeout" usage:"graceful shutdown timeout"`
+	ShutdownDelay     time.Duration            `yaml:"shutdown_delay" toml:"shutdown_delay" env:"SERVER_SHUTDOWN_DELAY" flag:"server.shutdown-delay" usage:"time /readyz reports shutdown before the listeners close"`
+	MaxHeaderBytes    int                      `yaml:"max_header_bytes" toml:"max_header_bytes" env:"SERVER_MAX_HEADER_BYTES" flag:"server.max-header-bytes" usage:"max request header size"`
+	MaxBodyBytes      int64                    `yaml:"max_body_bytes" toml:"max_body_bytes" env:"SERVER_MAX_BODY_BYTES" flag:"server.max-body-bytes" usage:"max request body size, 0 disables the limit"`
+	RequestTimeout    time.Duration            `yaml:"request_timeout" toml:"request_timeout" env:"SERVER_REQUEST_TIMEOUT" flag:"server.request-timeout" usage:"request deadline, also used as PostgreSQL statement_timeout; 0 disables it"`
+	RouteTimeouts     map[string]time.Duration `yaml:"route_timeouts" toml:"route_timeouts"`
+	CacheControl      string                   `yaml:"cache_control" toml:"cache_control" env:"SERVER_CACHE_CONTROL" flag:"server.cache-control" usage:"Cache-Control of successful GET responses, empty leaves the header out"`
+	RouteCacheControl map[string]string        `yaml:"route_cache_control" toml:"route_cache_control"`
+}
+
+type DBConfig struct {
+	Host              string        `yaml:"host" toml:"host" env:"POSTGRES_HOST" flag:"db.host" usage:"PostgreSQL host"`
+	Port              int           `yaml:"port" toml:"port" env:"POSTGRES_PORT" flag:"db.port" usage:"PostgreSQL port"`
+	User              string        `yaml:"user" toml:"user" env:"POSTGRES_USER" flag:"db.user" usage:"PostgreSQL user"`
+	Password          string        `yaml:"password" toml:"password" env:"POSTGRES_PASSWORD" flag:"db.password" usage:"PostgreSQL password" secret:"true"`
+	Name              string        `yaml:"name" toml:"name" env:"POSTGRES_DB" flag:"db.name" usage:"PostgreSQL database"`
+	SSLMode           string        `yaml:"ssl_mode" toml:"ssl_mode" env:"POSTGRES_SSL_MODE" flag:"db.ssl-mode" usage:"PostgreSQL sslmode"`
+	MaxConns          int           `yaml:"max_conns" toml:"max_conns" env:"DB_MAX_CONNS" flag:"db.max-conns" usage:"pool max connections"`
+	MinConns          int           `yaml:"min_conns" toml:"min_conns" env:"DB_MIN_CONNS" flag:"db.min-conns" usage:"pool min connections"`
+	MaxConnLifetime   time.Duration `yaml:"max_conn_lifetime" toml:"max_conn_lifetime" env:"DB_MAX_CONN_LIFETIME" flag:"db.max-conn-lifetime" usage:"pool connection lifetime"`
+	MaxConnIdleTime   time.Duration `yaml:"max_conn_idle_time" toml:"max_conn_idle_time" env:"DB_MAX_CONN_IDLE_TIME" flag:"db.max-conn-idle-time" usage:"pool connection idle time"`
+	HealthCheckPeriod time.Duration `yaml:"health_check_period" toml:"health_check_period" env:"DB_HEALTH_CHECK_PERIOD" flag:"db.health-check-period" usage:"pool health check period"`
+}
+
+type LogConfig struct {
+	Level            string  `yaml:"level" toml:"level" env:"LOG_LEVEL" flag:"log.level" usage:"debug, info, warn or error"`
+	Format           string  `yaml:"format" toml:"format" env:"LOG_FORMAT" flag:"log.format" usage:"console or json"`
+	AccessSampleRate float64 `yaml:"access_sample_rate" toml:"access_sample_rate" env:"LOG_ACCESS_SAMPLE_RATE" flag:"log.access-sample-rate" usage:"fraction of successful GET requests written to the access log"`
+}
+
+type FeaturesConfig struct {
+	ContentFilters bool `yaml:"content_filters" toml:"content_filters" env:"CONTENT_FILTERS" flag:"features.content-filters" usage:"enable content filters"`
+	RateLimit      bool `yaml:"rate_limit" toml:"rate_limit" env:"RATE_LIMIT" flag:"features.rate-limit" usage:"enable rate limits"`
+	Metrics        bool `yaml:"metrics" toml:"metrics" env:"METRICS" flag:"features.metrics" usage:"enable Prometheus metrics"`
+	Contract       bool `yaml:"contract" toml:"contract" env:"CONTRACT_VALIDATION" flag:"features.contract" usage:"validate API traffic against docs/swagger.yml and log violations"`
+	Cache          bool `yaml:"cache" toml:"cache" env:"CACHE" flag:"features.cache" usage:"cache thread and forum lookups"`
+	Compression    bool `yaml:"compression" toml:"compression" env:"COMPRESSION" flag:"features.compression" usage:"compress responses negotiated through Accept-Encoding"`
+}
+
+type MetricsConfig struct {
+	Path string `yaml:"path" toml:"path" env:"METRICS_PATH" flag:"metrics.path" usage:"metrics endpoint path"`
+}
+
+type AdminConfig struct {
+	Addr           string        `yaml:"addr" toml:"addr" env:"ADMIN_ADDR" flag:"admin.addr" usage:"admin listen address for the admin routes, metrics and /debug; empty serves metrics on the main server and disables the admin routes and /debug"`
+	ImportMaxBytes int64         `yaml:"import_max_bytes" toml:"import_max_bytes" env:"ADMIN_IMPORT_MAX_BYTES" flag:"admin.import-max-bytes" usage:"max dump size accepted by /api/admin/import"`
+	ImportTimeout  time.Duration `yaml:"import_timeout" toml:"import_timeout" env:"ADMIN_IMPORT_TIMEOUT" flag:"admin.import-timeout" usage:"deadline of /api/admin/import"`
+}
+
+type GRPCConfig struct {
+	Addr string `yaml:"addr" toml:"addr" env:"GRPC_ADDR" flag:"grpc.addr" usage:"gRPC listen address, empty disables the gRPC API"`
+}
+
+type LimitConfig struct {
+	Rate  float64 `yaml:"rate" toml:"rate"`
+	Burst int     `yaml:"burst" toml:"burst"`
+}
+
+type RateLimitGroupConfig struct {
+	PerIP LimitConfig `yaml:"per_ip" toml:"per_ip"`
+	// PerUser is keyed by the acting nickname of posts, votes and new threads.
+	PerUser LimitConfig `yaml:"per_user" toml:"per_user"`
+}
+
+type RateLimitConfig struct {
+	Store           string                          `yaml:"store" toml:"store" env:"RATE_LIMIT_STORE" flag:"rate-limit.store" usage:"memory or postgres"`
+	TrustProxy      bool                            `yaml:"trust_proxy" toml:"trust_proxy" env:"RATE_LIMIT_TRUST_PROXY" flag:"rate-limit.trust-proxy" usage:"take client ip from proxy headers"`
+	MaxBuckets      int                             `yaml:"max_buckets" toml:"max_buckets" env:"RATE_LIMIT_MAX_BUCKETS" flag:"rate-limit.max-buckets" usage:"buckets kept by the memory store"`
+	CleanupInterval time.Duration                   `yaml:"cleanup_interval" toml:"cleanup_interval" env:"RATE_LIMIT_CLEANUP_INTERVAL" flag:"rate-limit.cleanup-interval" usage:"how often idle buckets are deleted from the postgres store"`
+	Groups          map[string]RateLimitGroupConfig `yaml:"groups" toml:"groups"`
+}
+
+type FiltersConfig struct {
+	BannedWords     map[string][]string `yaml:"banned_words" toml:"banned_words"`
+	GlobalWords     []string            `yaml:"global_banned_words" toml:"global_banned_words" env:"CONTENT_BANNED_WORDS" flag:"filters.banned-words" usage:"comma separated words banned on every forum"`
+	MaxLinks        int                 `yaml:"max_links" toml:"max_links" env:"CONTENT_MAX_LINKS" flag:"filters.max-links" usage:"links allowed before review"`
+	DuplicateWindow time.Duration       `yaml:"duplicate_window" toml:"duplicate_window" env:"CONTENT_DUPLICATE_WINDOW" flag:"filters.duplicate-window" usage:"window for duplicate messages"`
+	ReviewQueueSize int                 `yaml:"review_queue_size" toml:"review_queue_size" env:"CONTENT_REVIEW_QUEUE_SIZE" flag:"filters.review-queue-size" usage:"items held for review, the queue is kept in memory"`
+	BayesMinDocs    int                 `yaml:"bayes_min_docs" toml:"bayes_min_docs" env:"CONTENT_BAYES_MIN_DOCS" flag:"filters.bayes-min-docs" usage:"training documents needed per class"`
+	BayesReview     float64             `yaml:"bayes_review" toml:"bayes_review" env:"CONTENT_BAYES_REVIEW" flag:"filters.bayes-review" usage:"spam score sending content to review"`
+	BayesReject     float64             `yaml:"bayes_reject" toml:"bayes_reject" env:"CONTENT_BAYES_REJECT" flag:"filters.bayes-reject" usage:"spam score rejecting content"`
+}
+
+type TracingConfig struct {
+	Exporter    string  `yaml:"exporter" toml:"exporter" env:"TRACING_EXPORTER" flag:"tracing.exporter" usage:"none, stdout or otlp"`
+	Endpoint    string  `yaml:"endpoint" toml:"endpoint" env:"OTEL_EXPORTER_OTLP_ENDPOINT" flag:"tracing.endpoint" usage:"OTLP/HTTP collector host:port"`
+	Insecure    bool    `yaml:"insecure" toml:"insecure" env:"TRACING_INSECURE" flag:"tracing.insecure" usage:"send spans over plain HTTP"`
+	SampleRatio float64 `yaml:"sample_ratio" toml:"sample_ratio" env:"TRACING_SAMPLE_RATIO" flag:"tracing.sample-ratio" usage:"fraction of root spans sampled"`
+	ServiceName string  `yaml:"service_name" toml:"service_name" env:"OTEL_SERVICE_NAME" flag:"tracing.service-name" usage:"service name reported with spans"`
+}
+
+type ReconcileConfig struct {
+	Interval  time.Duration `yaml:"interval" toml:"interval" env:"RECONCILE_INTERVAL" flag:"reconcile.interval" usage:"period of the counter reconciliation job, 0 disables it"`
+	BatchSize int           `yaml:"batch_size" toml:"batch_size" env:"RECONCILE_BATCH_SIZE" flag:"reconcile.batch-size" usage:"forums reconciled per transaction"`
+	DryRun    bool          `yaml:"dry_run" toml:"dry_run" env:"RECONCILE_DRY_RUN" flag:"reconcile.dry-run" usage:"only log discrepancies"`
+}
+
+type CacheConfig struct {
+	Store string        `yaml:"store" toml:"store" env:"CACHE_STORE" flag:"cache.store" usage:"memory or redis"`
+	Size  int           `yaml:"size" toml:"size" env:"CACHE_SIZE" flag:"cache.size" usage:"entries kept by the memory store"`
+	TTL   time.Duration `yaml:"ttl" toml:"ttl" env:"CACHE_TTL" flag:"cache.ttl" usage:"entry lifetime, bounds staleness when an invalidation is missed"`
+	Redis RedisConfig   `yaml:"redis" toml:"redis"`
+}
+
+type RedisConfig struct {
+	Addr     string `yaml:"addr" toml:"addr" env:"REDIS_ADDR" flag:"cache.redis.addr" usage:"Redis host:port"`
+	Password string `yaml:"password" toml:"password" env:"REDIS_PASSWORD" flag:"cache.redis.password" usage:"Redis password" secret:"true"`
+	DB       int    `yaml:"db" toml:"db" env:"REDIS_DB" flag:"cache.redis.db" usage:"Redis database number"`
+	Prefix   string `yaml:"prefix" toml:"prefix" env:"REDIS_PREFIX" flag:"cache.redis.prefix" usage:"prefix of cache keys"`
+}
+
+type CompressionConfig struct {
+	MinSize     int      `yaml:"min_size" toml:"min_size" env:"COMPRESSION_MIN_SIZE" flag:"compression.min-size" usage:"smallest response body that is compressed"`
+	Encodings   []string `yaml:"encodings" toml:"encodings" env:"COMPRESSION_ENCODINGS" flag:"compression.encodings" usage:"comma separated br, zstd and gzip in the order of preference"`
+	GzipLevel   int      `yaml:"gzip_level" toml:"gzip_level" env:"COMPRESSION_GZIP_LEVEL" flag:"compression.gzip-level" usage:"gzip level, 1 to 9"`
+	BrotliLevel int      `yaml:"brotli_level" toml:"brotli_level" env:"COMPRESSION_BROTLI_LEVEL" flag:"compression.brotli-level" usage:"brotli level, 0 to 11"`
+	ZstdLevel   int      `yaml:"zstd_level" toml:"zstd_level" env:"COMPRESSION_ZSTD_LEVEL" flag:"compression.zstd-level" usage:"zstd level, 1 (fastest) to 4 (best)"`
+}
+
+func Default() *Config {
+	return &Config{
+		Storage: StoragePostgres,
+		Server: ServerConfig{
+			Addr:              ":5000",
+			ReadTimeout:       30 * time.Second,
+			ReadHeaderTimeout: 5 * time.Second,
+			WriteTimeout:      60 * time.Second,
+			IdleTimeout:       120 * time.Second,
+			DrainTimeout:      15 * time.Second,
+			ShutdownDelay:     5 * time.Second,
+			MaxHeaderBytes:    1 << 20,
+			MaxBodyBytes:      16 << 20,
+			RequestTimeout:    30 * time.Second,
+			CacheControl:      "no-cache",
+		},
+		DB: DBConfig{
+			Host:              "localhost",
+			Port:              5432,
+			SSLMode:           "disable",
+			MaxConns:          100,
+			MinConns:          0,
+			MaxConnLifetime:   time.Hour,
+			MaxConnIdleTime:   30 * time.Minute,
+			HealthCheckPeriod: time.Minute,
+		},
+		Features: FeaturesConfig{
+			Metrics: true,
+		},
+		Metrics: MetricsConfig{
+			Path: "/metrics",
+		},
+		Admin: AdminConfig{
+			ImportMaxBytes: 4 << 30,
+			ImportTimeout:  time.Hour,
+		},
+		Log: LogConfig{
+			Level:            "info",
+			Format:           "console",
+			AccessSampleRate: 1,
+		},
+		RateLimit: RateLimitConfig{
+			Store:           "memory",
+			MaxBuckets:      100000,
+			CleanupInterval: time.Minute,
+			Groups: map[string]RateLimitGroupConfig{
+				ratelimit.GroupUsers:   {PerIP: LimitConfig{Rate: 5, Burst: 20}},
+				ratelimit.GroupForums:  {PerIP: LimitConfig{Rate: 1, Burst: 5}},
+				ratelimit.GroupThreads: {PerIP: LimitConfig{Rate: 5, Burst: 20}, PerUser: LimitConfig{Rate: 1, Burst: 5}},
+				ratelimit.GroupPosts:   {PerIP: LimitConfig{Rate: 20, Burst: 100}, PerUser: LimitConfig{Rate: 5, Burst: 20}},
+				ratelimit.GroupVotes:   {PerIP: LimitConfig{Rate: 20, Burst: 50}, PerUser: LimitConfig{Rate: 5, Burst: 10}},
+			},
+		},
+		Filters: FiltersConfig{
+			MaxLinks:        5,
+			DuplicateWindow: time.Minute,
+			ReviewQueueSize: 1000,
+			BayesMinDocs:    20,
+			BayesReview:     0.8,
+			BayesReject:     0.99,
+		},
+		Tracing: TracingConfig{
+			Exporter:    "none",
+			Endpoint:    "localhost:4318",
+			SampleRatio: 1,
+			ServiceName: "forum",
+		},
+		Reconcile: ReconcileConfig{
+			BatchSize: 20,
+		},
+		Cache: CacheConfig{
+			Store: "memory",
+			Size:  100000,
+			TTL:   time.Minute,
+			Redis: RedisConfig{
+				Addr:   "localhost:6379",
+				Prefix: "forum:",
+			},
+		},
+		Compression: CompressionConfig{
+			MinSize:     1024,
+			Encodings:   []string{"br", "zstd", "gzip"},
+			GzipLevel:   5,
+			BrotliLevel: 4,
+			ZstdLevel:   2,
+		},
+	}
+}
@@ -0,0 +1,26 @@
+package config
+
+import (
+	"reflect"
+
+	"gopkg.in/yaml.v3"
+)
+
+const redacted = "******"
+
+// Dump returns the configuration as YAML with secrets redacted.
+func (cfg *Config) Dump() string {
+	tmp := *cfg
+	_ = walk(reflect.ValueOf(&tmp).Elem(), func(field reflect.Value, tag reflect.StructTag) error {
+		if tag.Get("secret") == "true" && field.Kind() == reflect.String && field.String() != "" {
+			field.SetString(redacted)
+		}
+		return nil
+	})
+
+	data, err := yaml.Marshal(&tmp)
+	if err != nil {
+		return err.Error()
+	}
+	return string(data)
+}
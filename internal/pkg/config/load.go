@@ -0,0 +1,214 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+const configFileEnv = "CONFIG_FILE"
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// Options are command-line switches that are not part of the configuration.
+type Options struct {
+	File        string
+	PrintConfig bool
+	// Args are the positional arguments left after the flags.
+	Args []string
+}
+
+// Load builds the configuration from defaults, the config file, environment
+// variables and args, and validates the result.
+func Load(name string, args []string) (*Config, *Options, error) {
+	cfg := Default()
+	opts := &Options{}
+
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.StringVar(&opts.File, "config", os.Getenv(configFileEnv), "path to a YAML or TOML config file")
+	fs.BoolVar(&opts.PrintConfig, "print-config", false, "print the effective config and exit")
+	values := registerFlags(fs, reflect.ValueOf(cfg).Elem())
+	if err := fs.Parse(args); err != nil {
+		return nil, nil, err
+	}
+
+	if opts.File != "" {
+		if err := loadFile(opts.File, cfg); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if err := loadEnv(reflect.ValueOf(cfg).Elem()); err != nil {
+		return nil, nil, err
+	}
+
+	var flagErr error
+	fs.Visit(func(f *flag.Flag) {
+		if value, ok := values[f.Name]; ok && flagErr == nil {
+			if err := setValue(value.field, value.raw); err != nil {
+				flagErr = fmt.Errorf("flag -%s: %w", f.Name, err)
+			}
+		}
+	})
+	if flagErr != nil {
+		return nil, nil, flagErr
+	}
+	opts.Args = fs.Args()
+
+	if err := cfg.Validate(); err != nil {
+		return nil, nil, err
+	}
+	return cfg, opts, nil
+}
+
+func loadFile(path string, cfg *Config) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read config file: %w", err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, cfg)
+	case ".toml":
+		err = toml.Unmarshal(data, cfg)
+	default:
+		return fmt.Errorf("unsupported config file format: %s", path)
+	}
+	if err != nil {
+		return fmt.Errorf("parse config file %s: %w", path, err)
+	}
+	return nil
+}
+
+func loadEnv(v reflect.Value) error {
+	return walk(v, func(field reflect.Value, tag reflect.StructTag) error {
+		key := tag.Get("env")
+		if key == "" {
+			return nil
+		}
+
+		raw, ok := os.LookupEnv(key)
+		if !ok || raw == "" {
+			return nil
+		}
+		if err := setValue(field, raw); err != nil {
+			return fmt.Errorf("env %s: %w", key, err)
+		}
+		return nil
+	})
+}
+
+type flagValue struct {
+	field reflect.Value
+	raw   string
+}
+
+func (f *flagValue) String() string {
+	return f.raw
+}
+
+func (f *flagValue) Set(raw string) error {
+	f.raw = raw
+	return nil
+}
+
+func (f *flagValue) IsBoolFlag() bool {
+	return f.field.Kind() == reflect.Bool
+}
+
+// registerFlags declares a flag for every tagged field. Values are kept as raw
+// strings and applied after the file and environment have been loaded.
+func registerFlags(fs *flag.FlagSet, v reflect.Value) map[string]*flagValue {
+	values := make(map[string]*flagValue)
+	_ = walk(v, func(field reflect.Value, tag reflect.StructTag) error {
+		name := tag.Get("flag")
+		if name == "" {
+			return nil
+		}
+
+		value := &flagValue{field: field}
+		usage := tag.Get("usage")
+		if tag.Get("env") != "" {
+			usage += " (env " + tag.Get("env") + ")"
+		}
+		fs.Var(value, name, usage)
+		values[name] = value
+		return nil
+	})
+	return values
+}
+
+func walk(v reflect.Value, fn func(field reflect.Value, tag reflect.StructTag) error) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := v.Field(i)
+		sf := t.Field(i)
+		if sf.Type.Kind() == reflect.Struct && sf.Type != durationType {
+			if err := walk(field, fn); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := fn(field, sf.Tag); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func setValue(field reflect.Value, raw string) error {
+	if field.Type() == durationType {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(d))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return err
+		}
+		field.SetInt(n)
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return err
+		}
+		field.SetFloat(f)
+	case reflect.Slice:
+		if field.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported slice type %s", field.Type())
+		}
+		items := make([]string, 0)
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		field.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported type %s", field.Type())
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+func (cfg *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(cfg.Server.Addr != "", "server.addr is empty")
+	check(cfg.Server.ReadTimeout >= 0, "server.read_timeout is negative")
+	check(cfg.Server.ReadHeaderTimeout >= 0, "server.read_header_timeout is negative")
+	check(cfg.Server.WriteTimeout >= 0, "server.write_timeout is negative")
+	check(cfg.Server.IdleTimeout >= 0, "server.idle_timeout is negative")
+	check(cfg.Server.DrainTimeout > 0, "server.drain_timeout must be positive")
+	check(cfg.Server.ShutdownDelay >= 0, "server.shutdown_delay is negative")
+	check(cfg.Server.MaxHeaderBytes >= 0, "server.max_header_bytes is negative")
+	check(cfg.Server.MaxBodyBytes >= 0, "server.max_body_bytes is negative")
+	check(cfg.Server.RequestTimeout >= 0, "server.request_timeout is negative")
+	for route, timeout := range cfg.Server.RouteTimeouts {
+		check(timeout >= 0, "server.route_timeouts[%q] is negative", route)
+	}
+
+	check(oneOf(cfg.Storage, StoragePostgres, StorageMemory), "storage %q is unknown", cfg.Storage)
+	if cfg.Storage == StoragePostgres {
+		check(cfg.DB.Host != "", "db.host is empty")
+		check(cfg.DB.Port > 0 && cfg.DB.Port < 65536, "db.port %d is out of range", cfg.DB.Port)
+		check(cfg.DB.Name != "", "db.name is empty")
+		check(cfg.DB.MaxConns > 0, "db.max_conns must be positive")
+		check(cfg.DB.MinConns >= 0 && cfg.DB.MinConns <= cfg.DB.MaxConns,
+			"db.min_conns must be between 0 and db.max_conns")
+		check(oneOf(cfg.DB.SSLMode, "disable", "allow", "prefer", "require", "verify-ca", "verify-full"),
+			"db.ssl_mode %q is unknown", cfg.DB.SSLMode)
+	} else {
+		check(cfg.RateLimit.Store != "postgres", "rate_limit.store postgres requires storage postgres")
+		check(cfg.Reconcile.Interval == 0, "reconcile.interval requires storage postgres")
+	}
+
+	check(strings.HasPrefix(cfg.Metrics.Path, "/"), "metrics.path must start with /")
+	check(cfg.Admin.Addr != cfg.Server.Addr, "admin.addr must differ from server.addr")
+	check(cfg.Admin.ImportMaxBytes > 0, "admin.import_max_bytes must be positive")
+	check(cfg.Admin.ImportTimeout > 0, "admin.import_timeout must be positive")
+	if cfg.GRPC.Addr != "" {
+		check(cfg.GRPC.Addr != cfg.Server.Addr && cfg.GRPC.Addr != cfg.Admin.Addr, "grpc.addr must differ from server.addr and admin.addr")
+	}
+
+	check(oneOf(cfg.Log.Level, "debug", "info", "warn", "error"), "log.level %q is unknown", cfg.Log.Level)
+	check(oneOf(cfg.Log.Format, "console", "json"), "log.format %q is unknown", cfg.Log.Format)
+	check(cfg.Log.AccessSampleRate >= 0 && cfg.Log.AccessSampleRate <= 1, "log.access_sample_rate must be between 0 and 1")
+
+	check(oneOf(cfg.RateLimit.Store, "memory", "postgres"), "rate_limit.store %q is unknown", cfg.RateLimit.Store)
+	check(cfg.RateLimit.MaxBuckets > 0, "rate_limit.max_buckets must be positive")
+	check(cfg.RateLimit.CleanupInterval > 0, "rate_limit.cleanup_interval must be positive")
+	for name, group := range cfg.RateLimit.Groups {
+		check(group.PerIP.Rate >= 0 && group.PerIP.Burst >= 0, "rate_limit.groups.%s.per_ip is negative", name)
+		check(group.PerUser.Rate >= 0 && group.PerUser.Burst >= 0, "rate_limit.groups.%s.per_user is negative", name)
+	}
+
+	check(cfg.Filters.MaxLinks >= 0, "filters.max_links is negative")
+	check(cfg.Filters.DuplicateWindow >= 0, "filters.duplicate_window is negative")
+	check(cfg.Filters.ReviewQueueSize > 0, "filters.review_queue_size must be positive")
+	check(cfg.Filters.BayesReview >= 0 && cfg.Filters.BayesReview <= cfg.Filters.BayesReject && cfg.Filters.BayesReject <= 1,
+		"filters bayes thresholds must satisfy 0 <= bayes_review <= bayes_reject <= 1")
+
+	check(oneOf(cfg.Tracing.Exporter, "none", "stdout", "otlp"), "tracing.exporter %q is unknown", cfg.Tracing.Exporter)
+	check(cfg.Tracing.SampleRatio >= 0 && cfg.Tracing.SampleRatio <= 1, "tracing.sample_ratio must be between 0 and 1")
+
+	check(cfg.Reconcile.Interval >= 0, "reconcile.interval is negative")
+	check(cfg.Reconcile.BatchSize > 0, "reconcile.batch_size must be positive")
+
+	check(oneOf(cfg.Cache.Store, "memory", "redis"), "cache.store %q is unknown", cfg.Cache.Store)
+	check(cfg.Cache.Size > 0, "cache.size must be positive")
+	check(cfg.Cache.TTL > 0, "cache.ttl must be positive")
+	if cfg.Features.Cache && cfg.Cache.Store == "redis" {
+		check(cfg.Cache.Redis.Addr != "", "cache.redis.addr is empty")
+		check(cfg.Cache.Redis.DB >= 0, "cache.redis.db is negative")
+	}
+
+	check(cfg.Compression.MinSize >= 0, "compression.min_size is negative")
+	for _, encoding := range cfg.Compression.Encodings {
+		check(oneOf(encoding, "br", "zstd", "gzip"), "compression.encodings: %q is unknown", encoding)
+	}
+	check(cfg.Compression.GzipLevel >= 1 && cfg.Compression.GzipLevel <= 9, "compression.gzip_level must be between 1 and 9")
+	check(cfg.Compression.BrotliLevel >= 0 && cfg.Compression.BrotliLevel <= 11, "compression.brotli_level must be between 0 and 11")
+	check(cfg.Compression.ZstdLevel >= 1 && cfg.Compression.ZstdLevel <= 4, "compression.zstd_level must be between 1 and 4")
+
+	return errors.Join(errs...)
+}
+
+func oneOf(value string, allowed ...string) bool {
+	for _, item := range allowed {
+		if value == item {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,30 @@
+package db
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+
+	pkgErrors "github.com/SlavaShagalov/vk-dbms-project/internal/pkg/errors"
+)
+
+// queryCanceled is raised when statement_timeout expires.
+const queryCanceled = "57014"
+
+// Error maps a failed query to the error returned to the client: an expired
+// deadline or statement_timeout becomes ErrTimeout, a disconnected client
+// ErrRequestCanceled and anything else ErrInternal.
+func Error(ctx context.Context, err error) error {
+	var pgErr *pgconn.PgError
+	switch {
+	case errors.Is(err, context.DeadlineExceeded) || errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return pkgErrors.ErrTimeout
+	case errors.As(err, &pgErr) && pgErr.Code == queryCanceled:
+		return pkgErrors.ErrTimeout
+	case errors.Is(err, context.Canceled) || errors.Is(ctx.Err(), context.Canceled):
+		return pkgErrors.ErrRequestCanceled
+	default:
+		return pkgErrors.ErrInternal
+	}
+}
@@ -2,19 +2,42 @@ package db
 
 import (
 	"context"
+	"net"
+	"net/url"
+	"strconv"
+
 	"github.com/jackc/pgx/v5/pgxpool"
 	"go.uber.org/zap"
-	"os"
+
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/config"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/tracing"
 )
 
-func NewPgxPool(log *zap.Logger) (*pgxpool.Pool, error) {
-	dbUser := os.Getenv("POSTGRES_USER")
-	dbPassword := os.Getenv("POSTGRES_PASSWORD")
-	dbName := os.Getenv("POSTGRES_DB")
-	dbHost := os.Getenv("POSTGRES_HOST")
-	dbPort := os.Getenv("POSTGRES_PORT")
+func NewPgxPool(cfg *config.DBConfig, log *zap.Logger) (*pgxpool.Pool, error) {
+	dsn := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(cfg.User, cfg.Password),
+		Host:     net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port)),
+		Path:     cfg.Name,
+		RawQuery: url.Values{"sslmode": {cfg.SSLMode}}.Encode(),
+	}
+
+	conf, err := pgxpool.ParseConfig(dsn.String())
+	if err != nil {
+		log.Error("Failed to parse db config", zap.Error(err))
+		return nil, err
+	}
+	conf.MaxConns = int32(cfg.MaxConns)
+	conf.MinConns = int32(cfg.MinConns)
+	conf.MaxConnLifetime = cfg.MaxConnLifetime
+	conf.MaxConnIdleTime = cfg.MaxConnIdleTime
+	conf.HealthCheckPeriod = cfg.HealthCheckPeriod
+	conf.ConnConfig.Tracer = tracing.NewQueryTracer()
+
+	timeouts := newStatementTimeouts()
+	conf.BeforeAcquire = timeouts.beforeAcquire
+	conf.BeforeClose = timeouts.beforeClose
 
-	conf, _ := pgxpool.ParseConfig("postgres://" + dbUser + ":" + dbPassword + "@" + dbHost + ":" + dbPort + "/" + dbName + "?" + "pool_max_conns=100")
 	pool, err := pgxpool.NewWithConfig(context.Background(), conf)
 	if err != nil {
 		log.Error("Failed to connect to db ", zap.Error(err))
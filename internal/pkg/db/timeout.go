@@ -0,0 +1,70 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const setStatementTimeoutCmd = `SELECT set_config('statement_timeout', $1, false);`
+
+// statementTimeouts keeps statement_timeout in sync with the deadline of the
+// request that acquires a connection, so PostgreSQL aborts queries the client
+// no longer waits for.
+type statementTimeouts struct {
+	mu      sync.Mutex
+	current map[*pgx.Conn]int64
+}
+
+func newStatementTimeouts() *statementTimeouts {
+	return &statementTimeouts{current: make(map[*pgx.Conn]int64)}
+}
+
+func (st *statementTimeouts) beforeAcquire(ctx context.Context, conn *pgx.Conn) bool {
+	// A request that is already over fails on its first query without touching
+	// the connection.
+	if ctx.Err() != nil {
+		return true
+	}
+
+	var ms int64
+	if deadline, ok := ctx.Deadline(); ok {
+		// Rounded up to whole seconds, so requests with the same route deadline
+		// reuse the setting instead of changing it on every acquire.
+		ms = int64((time.Until(deadline) + time.Second - 1) / time.Second * 1000)
+		if ms < 1000 {
+			ms = 1000
+		}
+	}
+
+	st.mu.Lock()
+	current := st.current[conn]
+	st.mu.Unlock()
+	if current == ms {
+		return true
+	}
+
+	if _, err := conn.Exec(ctx, setStatementTimeoutCmd, strconv.FormatInt(ms, 10)); err != nil {
+		// The deadline ran out during the call, the connection itself is fine.
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return true
+		}
+		// Connection state is unknown, let the pool replace it.
+		return false
+	}
+
+	st.mu.Lock()
+	st.current[conn] = ms
+	st.mu.Unlock()
+	return true
+}
+
+func (st *statementTimeouts) beforeClose(conn *pgx.Conn) {
+	st.mu.Lock()
+	delete(st.current, conn)
+	st.mu.Unlock()
+}
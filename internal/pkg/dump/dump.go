@@ -0,0 +1,20 @@
+// Package dump writes forum data as NDJSON and loads it back.
+//
+// Every line is a record {"type": ..., "data": ...}. Records are grouped by
+// type, referenced records come first, and the dump ends with an end record
+// holding the counts.
+package dump
+
+import (
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+type Dumper struct {
+	pool *pgxpool.Pool
+	log  *zap.Logger
+}
+
+func New(pool *pgxpool.Pool, log *zap.Logger) *Dumper {
+	return &Dumper{pool: pool, log: log}
+}
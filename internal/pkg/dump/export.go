@@ -0,0 +1,185 @@
+package dump
+
+import (
+	"bufio"
+	"context"
+	"io"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/mailru/easyjson"
+	"go.uber.org/zap"
+
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/constants"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/db"
+	pkgErrors "github.com/SlavaShagalov/vk-dbms-project/internal/pkg/errors"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/tracing"
+)
+
+// An empty $1 exports all forums.
+const exportUsersCmd = `
+SELECT nickname, fullname, coalesce(about, ''), email
+FROM users
+WHERE $1 = ''
+   OR nickname IN (SELECT user_nickname FROM forums WHERE slug = $1
+                   UNION
+                   SELECT nickname FROM forum_users WHERE forum = $1
+                   UNION
+                   SELECT v.nickname FROM votes v JOIN threads t ON t.id = v.thread WHERE t.forum = $1)
+ORDER BY id;`
+
+const exportForumsCmd = `
+SELECT slug, title, user_nickname
+FROM forums
+WHERE $1 = '' OR slug = $1
+ORDER BY id;`
+
+const exportThreadsCmd = `
+SELECT id, slug, forum, author, title, message, created, slow_mode, locked
+FROM threads
+WHERE $1 = '' OR forum = $1
+ORDER BY id;`
+
+const exportPostsCmd = `
+SELECT id, coalesce(parent, 0), path, thread, author, message, isEdited, created
+FROM posts
+WHERE $1 = '' OR forum = $1
+ORDER BY id;`
+
+const exportVotesCmd = `
+SELECT v.nickname, v.thread, v.voice
+FROM votes v
+         JOIN threads t ON t.id = v.thread
+WHERE $1 = '' OR t.forum = $1
+ORDER BY v.id;`
+
+const exportForumUsersCmd = `
+SELECT forum, nickname
+FROM forum_users
+WHERE $1 = '' OR forum = $1
+ORDER BY forum, nickname;`
+
+const forumExistsCmd = `SELECT EXISTS(SELECT 1 FROM forums WHERE slug = $1);`
+
+// Export writes all forums, or only forum with its threads, posts, votes and
+// the users they reference, from a single snapshot.
+func (d *Dumper) Export(ctx context.Context, w io.Writer, forum string) (Stats, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "dump.Export")
+	defer span.End()
+
+	var stats Stats
+	tx, err := d.pool.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		tracing.Log(ctx, d.log).Error(constants.DBError, zap.Error(err))
+		return stats, db.Error(ctx, err)
+	}
+	defer tx.Rollback(ctx)
+
+	if forum != "" {
+		exists := false
+		if err = tx.QueryRow(ctx, forumExistsCmd, forum).Scan(&exists); err != nil {
+			tracing.Log(ctx, d.log).Error(constants.DBError, zap.Error(err))
+			return stats, db.Error(ctx, err)
+		}
+		if !exists {
+			return stats, pkgErrors.ErrForumNotFound
+		}
+	}
+
+	out := &writer{w: bufio.NewWriter(w)}
+	sections := []struct {
+		query string
+		typ   string
+		count *int64
+		scan  func(rows pgx.Rows) (easyjson.Marshaler, error)
+	}{
+		{exportUsersCmd, TypeUser, &stats.Users, func(rows pgx.Rows) (easyjson.Marshaler, error) {
+			var u User
+			return &u, rows.Scan(&u.Nickname, &u.Fullname, &u.About, &u.Email)
+		}},
+		{exportForumsCmd, TypeForum, &stats.Forums, func(rows pgx.Rows) (easyjson.Marshaler, error) {
+			var f Forum
+			return &f, rows.Scan(&f.Slug, &f.Title, &f.User)
+		}},
+		{exportThreadsCmd, TypeThread, &stats.Threads, func(rows pgx.Rows) (easyjson.Marshaler, error) {
+			var t Thread
+			return &t, rows.Scan(&t.ID, &t.Slug, &t.Forum, &t.Author, &t.Title, &t.Message, &t.Created, &t.SlowMode, &t.Locked)
+		}},
+		{exportPostsCmd, TypePost, &stats.Posts, func(rows pgx.Rows) (easyjson.Marshaler, error) {
+			var p Post
+			return &p, rows.Scan(&p.ID, &p.Parent, &p.Path, &p.Thread, &p.Author, &p.Message, &p.IsEdited, &p.Created)
+		}},
+		{exportVotesCmd, TypeVote, &stats.Votes, func(rows pgx.Rows) (easyjson.Marshaler, error) {
+			var v Vote
+			return &v, rows.Scan(&v.Nickname, &v.Thread, &v.Voice)
+		}},
+		{exportForumUsersCmd, TypeForumUser, &stats.ForumUsers, func(rows pgx.Rows) (easyjson.Marshaler, error) {
+			var fu ForumUser
+			return &fu, rows.Scan(&fu.Forum, &fu.Nickname)
+		}},
+	}
+
+	for _, section := range sections {
+		n, err := exportSection(ctx, tx, out, section.query, forum, section.typ, section.scan)
+		*section.count = n
+		if err != nil {
+			if out.err != nil {
+				return stats, out.err
+			}
+			tracing.Log(ctx, d.log).Error(constants.DBError, zap.String("type", section.typ), zap.Error(err))
+			return stats, db.Error(ctx, err)
+		}
+	}
+
+	out.write(TypeEnd, &stats)
+	if out.err == nil {
+		out.err = out.w.Flush()
+	}
+	return stats, out.err
+}
+
+func exportSection(ctx context.Context, tx pgx.Tx, out *writer, query, forum, typ string,
+	scan func(rows pgx.Rows) (easyjson.Marshaler, error)) (int64, error) {
+	rows, err := tx.Query(ctx, query, forum)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	var n int64
+	for rows.Next() {
+		data, err := scan(rows)
+		if err != nil {
+			return n, err
+		}
+		if !out.write(typ, data) {
+			return n, out.err
+		}
+		n++
+	}
+	return n, rows.Err()
+}
+
+type writer struct {
+	w   *bufio.Writer
+	err error
+}
+
+// write appends one record and reports whether the output is still usable.
+func (w *writer) write(typ string, data easyjson.Marshaler) bool {
+	if w.err != nil {
+		return false
+	}
+
+	raw, err := easyjson.Marshal(data)
+	if err != nil {
+		w.err = err
+		return false
+	}
+	record := Record{Type: typ, Data: raw}
+	if _, err = easyjson.MarshalToWriter(&record, w.w); err != nil {
+		w.err = err
+		return false
+	}
+	w.err = w.w.WriteByte('\n')
+	return w.err == nil
+}
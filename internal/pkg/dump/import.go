@@ -0,0 +1,482 @@
+package dump
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.uber.org/zap"
+
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/constants"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/db"
+	pkgErrors "github.com/SlavaShagalov/vk-dbms-project/internal/pkg/errors"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/tracing"
+)
+
+const maxRecordSize = 64 << 20
+
+// Staging tables are loaded with COPY. They use text instead of citext, pgx
+// has no binary codec for the extension type.
+const createStagingCmd = `
+CREATE TEMP TABLE import_users
+(
+    nickname text,
+    fullname text,
+    about    text,
+    email    text
+) ON COMMIT DROP;
+
+CREATE TEMP TABLE import_forums
+(
+    slug          text,
+    title         text,
+    user_nickname text
+) ON COMMIT DROP;
+
+CREATE TEMP TABLE import_threads
+(
+    id        bigint,
+    slug      text,
+    forum     text,
+    author    text,
+    title     text,
+    message   text,
+    created   timestamp with time zone,
+    slow_mode int,
+    locked    boolean,
+    new_id    bigint
+) ON COMMIT DROP;
+
+CREATE TEMP TABLE import_posts
+(
+    id        bigint,
+    parent    bigint,
+    path      bigint[],
+    thread    bigint,
+    author    text,
+    message   text,
+    is_edited boolean,
+    created   timestamp with time zone,
+    new_id    bigint,
+    new_path  bigint[]
+) ON COMMIT DROP;
+
+CREATE TEMP TABLE import_votes
+(
+    nickname text,
+    thread   bigint,
+    voice    int
+) ON COMMIT DROP;
+
+CREATE TEMP TABLE import_forum_users
+(
+    forum    text,
+    nickname text
+) ON COMMIT DROP;`
+
+// Counters, stats rollups, forum_users and paths are rebuilt below, so the row triggers are
+// switched off for the bulk insert. This locks the tables until commit.
+const disableTriggersCmd = `
+ALTER TABLE threads DISABLE TRIGGER USER;
+ALTER TABLE posts DISABLE TRIGGER USER;
+ALTER TABLE votes DISABLE TRIGGER USER;`
+
+const enableTriggersCmd = `
+ALTER TABLE threads ENABLE TRIGGER USER;
+ALTER TABLE posts ENABLE TRIGGER USER;
+ALTER TABLE votes ENABLE TRIGGER USER;`
+
+// Existing users are kept as is.
+const importUsersCmd = `
+INSERT INTO users (nickname, fullname, about, email)
+SELECT nickname, fullname, about, email
+FROM import_users
+ON CONFLICT DO NOTHING;`
+
+const importForumsCmd = `
+INSERT INTO forums (slug, title, user_nickname)
+SELECT slug, title, user_nickname
+FROM import_forums;`
+
+// New ids keep the relative order of the old ones.
+const remapThreadsCmd = `
+UPDATE import_threads t
+SET new_id = m.new_id
+FROM (SELECT id, nextval(pg_get_serial_sequence('threads', 'id')) AS new_id
+      FROM (SELECT id FROM import_threads ORDER BY id) s) m
+WHERE t.id = m.id;`
+
+const importThreadsCmd = `
+INSERT INTO threads (id, slug, forum, author, title, message, created, slow_mode, locked)
+SELECT new_id, nullif(slug, ''), forum, author, title, message, created, slow_mode, locked
+FROM import_threads;`
+
+const remapPostsCmd = `
+CREATE INDEX ON import_posts (id);
+CREATE INDEX ON import_posts (parent);
+
+UPDATE import_posts p
+SET new_id = m.new_id
+FROM (SELECT id, nextval(pg_get_serial_sequence('posts', 'id')) AS new_id
+      FROM (SELECT id FROM import_posts ORDER BY id) s) m
+WHERE p.id = m.id;
+
+WITH RECURSIVE tree AS (SELECT id, ARRAY [new_id] AS path
+                        FROM import_posts
+                        WHERE parent = 0
+                        UNION ALL
+                        SELECT p.id, tree.path || p.new_id
+                        FROM import_posts p
+                                 JOIN tree ON p.parent = tree.id)
+UPDATE import_posts p
+SET new_path = tree.path
+FROM tree
+WHERE p.id = tree.id;`
+
+// Rows that would be silently dropped by the joins below.
+const checkReferencesCmd = `
+SELECT format('post %s is not connected to a root post', id)
+FROM import_posts
+WHERE new_path IS NULL
+UNION ALL
+SELECT format('post %s references thread %s missing from the dump', id, thread)
+FROM import_posts p
+WHERE NOT EXISTS(SELECT 1 FROM import_threads t WHERE t.id = p.thread)
+UNION ALL
+SELECT format('vote of %s references thread %s missing from the dump', nickname, thread)
+FROM import_votes v
+WHERE NOT EXISTS(SELECT 1 FROM import_threads t WHERE t.id = v.thread)
+LIMIT 1;`
+
+const importPostsCmd = `
+INSERT INTO posts (id, parent, path, thread, forum, author, message, isEdited, created)
+SELECT p.new_id,
+       coalesce(parent.new_id, 0),
+       p.new_path,
+       t.new_id,
+       t.forum,
+       p.author,
+       p.message,
+       p.is_edited,
+       p.created
+FROM import_posts p
+         JOIN import_threads t ON t.id = p.thread
+         LEFT JOIN import_posts parent ON parent.id = p.parent;`
+
+const importVotesCmd = `
+INSERT INTO votes (nickname, thread, voice)
+SELECT v.nickname, t.new_id, v.voice
+FROM import_votes v
+         JOIN import_threads t ON t.id = v.thread;`
+
+const importForumUsersCmd = `
+INSERT INTO forum_users (forum, nickname, fullname, about, email)
+SELECT f.forum, u.nickname, u.fullname, coalesce(u.about, ''), u.email
+FROM (SELECT forum, nickname FROM import_forum_users
+      UNION
+      SELECT forum, author FROM import_threads
+      UNION
+      SELECT t.forum, p.author FROM import_posts p JOIN import_threads t ON t.id = p.thread) f
+         JOIN users u ON u.nickname = f.nickname::citext
+ON CONFLICT DO NOTHING;`
+
+const recountCmd = `
+UPDATE threads t
+SET votes = coalesce((SELECT sum(voice) FROM votes WHERE thread = t.id), 0)
+WHERE t.id IN (SELECT new_id FROM import_threads);
+
+UPDATE forums f
+SET threads = (SELECT count(*) FROM threads WHERE forum = f.slug),
+    posts   = (SELECT count(*) FROM posts WHERE forum = f.slug)
+WHERE f.slug IN (SELECT slug FROM import_forums UNION SELECT forum FROM import_threads);
+
+UPDATE stats_forums s
+SET threads = (SELECT count(*) FROM threads WHERE forum = s.forum),
+    posts   = (SELECT count(*) FROM posts WHERE forum = s.forum),
+    votes   = (SELECT count(*) FROM votes v JOIN threads t ON t.id = v.thread WHERE t.forum = s.forum)
+WHERE s.forum IN (SELECT forum FROM import_threads);
+
+INSERT INTO stats_hourly (forum, bucket, threads, posts)
+SELECT forum, bucket, sum(threads), sum(posts)
+FROM (SELECT forum, date_trunc('hour', created) AS bucket, 1 AS threads, 0 AS posts
+      FROM threads
+      WHERE forum IN (SELECT forum FROM import_threads)
+      UNION ALL
+      SELECT forum, date_trunc('hour', created), 0, 1
+      FROM posts
+      WHERE forum IN (SELECT forum FROM import_threads)) c
+WHERE bucket IS NOT NULL
+GROUP BY 1, 2
+ON CONFLICT (forum, bucket) DO UPDATE SET threads = excluded.threads, posts = excluded.posts;`
+
+var stagingColumns = map[string]struct {
+	table   string
+	columns []string
+}{
+	TypeUser:      {"import_users", []string{"nickname", "fullname", "about", "email"}},
+	TypeForum:     {"import_forums", []string{"slug", "title", "user_nickname"}},
+	TypeThread:    {"import_threads", []string{"id", "slug", "forum", "author", "title", "message", "created", "slow_mode", "locked"}},
+	TypePost:      {"import_posts", []string{"id", "parent", "path", "thread", "author", "message", "is_edited", "created"}},
+	TypeVote:      {"import_votes", []string{"nickname", "thread", "voice"}},
+	TypeForumUser: {"import_forum_users", []string{"forum", "nickname"}},
+}
+
+// Import loads a dump in one transaction. Thread and post ids are reassigned,
+// post paths are rebuilt from parents and counters are recomputed. Forums
+// must not exist yet, users that already exist are kept. Errors caused by the
+// input wrap pkgErrors.ErrInvalidDump.
+func (d *Dumper) Import(ctx context.Context, r io.Reader) (Stats, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "dump.Import")
+	defer span.End()
+
+	var stats Stats
+	tx, err := d.pool.Begin(ctx)
+	if err != nil {
+		tracing.Log(ctx, d.log).Error(constants.DBError, zap.Error(err))
+		return stats, db.Error(ctx, err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err = tx.Exec(ctx, createStagingCmd); err != nil {
+		tracing.Log(ctx, d.log).Error(constants.DBError, zap.Error(err))
+		return stats, db.Error(ctx, err)
+	}
+
+	in := newReader(r)
+	var end *Stats
+	for end == nil {
+		record, err := in.peek()
+		if err != nil {
+			return stats, err
+		}
+		if record == nil {
+			return stats, fmt.Errorf("%w: no end record, the dump is truncated", pkgErrors.ErrInvalidDump)
+		}
+
+		if record.Type == TypeEnd {
+			end = new(Stats)
+			if err = end.UnmarshalJSON(record.Data); err != nil {
+				return stats, fmt.Errorf("%w: line %d: %v", pkgErrors.ErrInvalidDump, in.line, err)
+			}
+			in.next()
+			break
+		}
+
+		staging, ok := stagingColumns[record.Type]
+		if !ok {
+			return stats, fmt.Errorf("%w: line %d: unknown record type %q", pkgErrors.ErrInvalidDump, in.line, record.Type)
+		}
+		src := &section{in: in, typ: record.Type}
+		n, err := tx.CopyFrom(ctx, pgx.Identifier{staging.table}, staging.columns, src)
+		if src.err != nil {
+			return stats, src.err
+		}
+		if err != nil {
+			tracing.Log(ctx, d.log).Error(constants.DBError, zap.String("type", record.Type), zap.Error(err))
+			return stats, db.Error(ctx, err)
+		}
+		stats.add(record.Type, n)
+	}
+	if record, err := in.peek(); err != nil || record != nil {
+		return stats, fmt.Errorf("%w: line %d: data after the end record", pkgErrors.ErrInvalidDump, in.line)
+	}
+	if stats != *end {
+		return stats, fmt.Errorf("%w: read %+v, the end record says %+v", pkgErrors.ErrInvalidDump, stats, *end)
+	}
+
+	if err = d.load(ctx, tx); err != nil {
+		return stats, err
+	}
+	if err = tx.Commit(ctx); err != nil {
+		tracing.Log(ctx, d.log).Error(constants.DBError, zap.Error(err))
+		return stats, db.Error(ctx, err)
+	}
+	return stats, nil
+}
+
+// load moves the staged rows into the forum tables.
+func (d *Dumper) load(ctx context.Context, tx pgx.Tx) error {
+	if _, err := tx.Exec(ctx, disableTriggersCmd); err != nil {
+		tracing.Log(ctx, d.log).Error(constants.DBError, zap.Error(err))
+		return db.Error(ctx, err)
+	}
+
+	if _, err := tx.Exec(ctx, remapPostsCmd); err != nil {
+		tracing.Log(ctx, d.log).Error(constants.DBError, zap.Error(err))
+		return db.Error(ctx, err)
+	}
+	var problem string
+	err := tx.QueryRow(ctx, checkReferencesCmd).Scan(&problem)
+	if err == nil {
+		return fmt.Errorf("%w: %s", pkgErrors.ErrInvalidDump, problem)
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		tracing.Log(ctx, d.log).Error(constants.DBError, zap.Error(err))
+		return db.Error(ctx, err)
+	}
+
+	steps := []string{
+		importUsersCmd,
+		importForumsCmd,
+		remapThreadsCmd,
+		importThreadsCmd,
+		importPostsCmd,
+		importVotesCmd,
+		importForumUsersCmd,
+		recountCmd,
+		enableTriggersCmd,
+	}
+	for _, step := range steps {
+		if _, err = tx.Exec(ctx, step); err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) {
+				switch {
+				case pgErr.ConstraintName == "forums_pkey":
+					return pkgErrors.ErrForumAlreadyExists
+				case pgErr.Code == "23503":
+					return fmt.Errorf("%w: %s", pkgErrors.ErrInvalidDump, pgErr.Detail)
+				}
+			}
+			tracing.Log(ctx, d.log).Error(constants.DBError, zap.Error(err))
+			return db.Error(ctx, err)
+		}
+	}
+	return nil
+}
+
+func (s *Stats) add(typ string, n int64) {
+	switch typ {
+	case TypeUser:
+		s.Users += n
+	case TypeForum:
+		s.Forums += n
+	case TypeThread:
+		s.Threads += n
+	case TypePost:
+		s.Posts += n
+	case TypeVote:
+		s.Votes += n
+	case TypeForumUser:
+		s.ForumUsers += n
+	}
+}
+
+type reader struct {
+	scanner *bufio.Scanner
+	record  *Record
+	line    int
+}
+
+func newReader(r io.Reader) *reader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64<<10), maxRecordSize)
+	return &reader{scanner: scanner}
+}
+
+// peek returns the current record, nil at the end of the input.
+func (r *reader) peek() (*Record, error) {
+	if r.record != nil {
+		return r.record, nil
+	}
+	for r.scanner.Scan() {
+		r.line++
+		line := r.scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		record := new(Record)
+		if err := record.UnmarshalJSON(line); err != nil {
+			return nil, fmt.Errorf("%w: line %d: %v", pkgErrors.ErrInvalidDump, r.line, err)
+		}
+		r.record = record
+		return record, nil
+	}
+	if err := r.scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%w: line %d: %v", pkgErrors.ErrInvalidDump, r.line+1, err)
+	}
+	return nil, nil
+}
+
+func (r *reader) next() {
+	r.record = nil
+}
+
+// section feeds COPY with consecutive records of one type.
+type section struct {
+	in     *reader
+	typ    string
+	values []interface{}
+	err    error
+}
+
+func (s *section) Next() bool {
+	record, err := s.in.peek()
+	if err != nil {
+		s.err = err
+		return false
+	}
+	if record == nil || record.Type != s.typ {
+		return false
+	}
+	s.in.next()
+
+	if s.values, err = decode(record); err != nil {
+		s.err = fmt.Errorf("%w: line %d: %v", pkgErrors.ErrInvalidDump, s.in.line, err)
+		return false
+	}
+	return true
+}
+
+func (s *section) Values() ([]interface{}, error) {
+	return s.values, nil
+}
+
+func (s *section) Err() error {
+	return s.err
+}
+
+func decode(record *Record) ([]interface{}, error) {
+	switch record.Type {
+	case TypeUser:
+		var u User
+		if err := u.UnmarshalJSON(record.Data); err != nil {
+			return nil, err
+		}
+		return []interface{}{u.Nickname, u.Fullname, u.About, u.Email}, nil
+	case TypeForum:
+		var f Forum
+		if err := f.UnmarshalJSON(record.Data); err != nil {
+			return nil, err
+		}
+		return []interface{}{f.Slug, f.Title, f.User}, nil
+	case TypeThread:
+		var t Thread
+		if err := t.UnmarshalJSON(record.Data); err != nil {
+			return nil, err
+		}
+		return []interface{}{t.ID, t.Slug, t.Forum, t.Author, t.Title, t.Message, t.Created, t.SlowMode, t.Locked}, nil
+	case TypePost:
+		var p Post
+		if err := p.UnmarshalJSON(record.Data); err != nil {
+			return nil, err
+		}
+		return []interface{}{p.ID, p.Parent, p.Path, p.Thread, p.Author, p.Message, p.IsEdited, p.Created}, nil
+	case TypeVote:
+		var v Vote
+		if err := v.UnmarshalJSON(record.Data); err != nil {
+			return nil, err
+		}
+		return []interface{}{v.Nickname, v.Thread, v.Voice}, nil
+	case TypeForumUser:
+		var fu ForumUser
+		if err := fu.UnmarshalJSON(record.Data); err != nil {
+			return nil, err
+		}
+		return []interface{}{fu.Forum, fu.Nickname}, nil
+	}
+	return nil, fmt.Errorf("unknown record type %q", record.Type)
+}
@@ -0,0 +1,84 @@
+package dump
+
+import (
+	"time"
+
+	"github.com/mailru/easyjson"
+)
+
+//go:generate easyjson -all -snake_case records.go
+
+// Record types in the order they are written by Export.
+const (
+	TypeUser      = "user"
+	TypeForum     = "forum"
+	TypeThread    = "thread"
+	TypePost      = "post"
+	TypeVote      = "vote"
+	TypeForumUser = "forum_user"
+	TypeEnd       = "end"
+)
+
+// Record is one line of a dump.
+type Record struct {
+	Type string
+	Data easyjson.RawMessage
+}
+
+type User struct {
+	Nickname string
+	Fullname string
+	About    string
+	Email    string
+}
+
+type Forum struct {
+	Slug  string
+	Title string
+	User  string
+}
+
+type Thread struct {
+	ID       int64
+	Slug     *string `json:"slug,omitempty"`
+	Forum    string
+	Author   string
+	Title    string
+	Message  string
+	Created  time.Time
+	SlowMode int
+	Locked   bool
+}
+
+type Post struct {
+	ID       int64
+	Parent   int64
+	Path     []int64
+	Thread   int64
+	Author   string
+	Message  string
+	IsEdited bool
+	Created  time.Time
+}
+
+type Vote struct {
+	Nickname string
+	Thread   int64
+	Voice    int
+}
+
+type ForumUser struct {
+	Forum    string
+	Nickname string
+}
+
+// Stats counts the records of a dump. It is also the payload of the end
+// record, so a truncated dump is detected on import.
+type Stats struct {
+	Users      int64
+	Forums     int64
+	Threads    int64
+	Posts      int64
+	Votes      int64
+	ForumUsers int64
+}
@@ -0,0 +1,794 @@
+// Code generated by easyjson for marshaling/unmarshaling. DO NOT EDIT.
+
+package dump
+
+import (
+	json "encoding/json"
+	easyjson "github.com/mailru/easyjson"
+	jlexer "github.com/mailru/easyjson/jlexer"
+	jwriter "github.com/mailru/easyjson/jwriter"
+)
+
+// suppress unused package warning
+var (
+	_ *json.RawMessage
+	_ *jlexer.Lexer
+	_ *jwriter.Writer
+	_ easyjson.Marshaler
+)
+
+func easyjsonAf922c28DecodeGithubComSlavaShagalovVkDbmsProjectInternalPkgDump(in *jlexer.Lexer, out *Vote) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "nickname":
+			out.Nickname = string(in.String())
+		case "thread":
+			out.Thread = int64(in.Int64())
+		case "voice":
+			out.Voice = int(in.Int())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonAf922c28EncodeGithubComSlavaShagalovVkDbmsProjectInternalPkgDump(out *jwriter.Writer, in Vote) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"nickname\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Nickname))
+	}
+	{
+		const prefix string = ",\"thread\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.Thread))
+	}
+	{
+		const prefix string = ",\"voice\":"
+		out.RawString(prefix)
+		out.Int(int(in.Voice))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v Vote) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonAf922c28EncodeGithubComSlavaShagalovVkDbmsProjectInternalPkgDump(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v Vote) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonAf922c28EncodeGithubComSlavaShagalovVkDbmsProjectInternalPkgDump(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *Vote) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonAf922c28DecodeGithubComSlavaShagalovVkDbmsProjectInternalPkgDump(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *Vote) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonAf922c28DecodeGithubComSlavaShagalovVkDbmsProjectInternalPkgDump(l, v)
+}
+func easyjsonAf922c28DecodeGithubComSlavaShagalovVkDbmsProjectInternalPkgDump1(in *jlexer.Lexer, out *User) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "nickname":
+			out.Nickname = string(in.String())
+		case "fullname":
+			out.Fullname = string(in.String())
+		case "about":
+			out.About = string(in.String())
+		case "email":
+			out.Email = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonAf922c28EncodeGithubComSlavaShagalovVkDbmsProjectInternalPkgDump1(out *jwriter.Writer, in User) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"nickname\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Nickname))
+	}
+	{
+		const prefix string = ",\"fullname\":"
+		out.RawString(prefix)
+		out.String(string(in.Fullname))
+	}
+	{
+		const prefix string = ",\"about\":"
+		out.RawString(prefix)
+		out.String(string(in.About))
+	}
+	{
+		const prefix string = ",\"email\":"
+		out.RawString(prefix)
+		out.String(string(in.Email))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v User) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonAf922c28EncodeGithubComSlavaShagalovVkDbmsProjectInternalPkgDump1(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v User) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonAf922c28EncodeGithubComSlavaShagalovVkDbmsProjectInternalPkgDump1(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *User) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonAf922c28DecodeGithubComSlavaShagalovVkDbmsProjectInternalPkgDump1(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *User) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonAf922c28DecodeGithubComSlavaShagalovVkDbmsProjectInternalPkgDump1(l, v)
+}
+func easyjsonAf922c28DecodeGithubComSlavaShagalovVkDbmsProjectInternalPkgDump2(in *jlexer.Lexer, out *Thread) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "id":
+			out.ID = int64(in.Int64())
+		case "slug":
+			if in.IsNull() {
+				in.Skip()
+				out.Slug = nil
+			} else {
+				if out.Slug == nil {
+					out.Slug = new(string)
+				}
+				*out.Slug = string(in.String())
+			}
+		case "forum":
+			out.Forum = string(in.String())
+		case "author":
+			out.Author = string(in.String())
+		case "title":
+			out.Title = string(in.String())
+		case "message":
+			out.Message = string(in.String())
+		case "created":
+			if data := in.Raw(); in.Ok() {
+				in.AddError((out.Created).UnmarshalJSON(data))
+			}
+		case "slow_mode":
+			out.SlowMode = int(in.Int())
+		case "locked":
+			out.Locked = bool(in.Bool())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonAf922c28EncodeGithubComSlavaShagalovVkDbmsProjectInternalPkgDump2(out *jwriter.Writer, in Thread) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"id\":"
+		out.RawString(prefix[1:])
+		out.Int64(int64(in.ID))
+	}
+	if in.Slug != nil {
+		const prefix string = ",\"slug\":"
+		out.RawString(prefix)
+		out.String(string(*in.Slug))
+	}
+	{
+		const prefix string = ",\"forum\":"
+		out.RawString(prefix)
+		out.String(string(in.Forum))
+	}
+	{
+		const prefix string = ",\"author\":"
+		out.RawString(prefix)
+		out.String(string(in.Author))
+	}
+	{
+		const prefix string = ",\"title\":"
+		out.RawString(prefix)
+		out.String(string(in.Title))
+	}
+	{
+		const prefix string = ",\"message\":"
+		out.RawString(prefix)
+		out.String(string(in.Message))
+	}
+	{
+		const prefix string = ",\"created\":"
+		out.RawString(prefix)
+		out.Raw((in.Created).MarshalJSON())
+	}
+	{
+		const prefix string = ",\"slow_mode\":"
+		out.RawString(prefix)
+		out.Int(int(in.SlowMode))
+	}
+	{
+		const prefix string = ",\"locked\":"
+		out.RawString(prefix)
+		out.Bool(bool(in.Locked))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v Thread) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonAf922c28EncodeGithubComSlavaShagalovVkDbmsProjectInternalPkgDump2(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v Thread) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonAf922c28EncodeGithubComSlavaShagalovVkDbmsProjectInternalPkgDump2(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *Thread) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonAf922c28DecodeGithubComSlavaShagalovVkDbmsProjectInternalPkgDump2(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *Thread) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonAf922c28DecodeGithubComSlavaShagalovVkDbmsProjectInternalPkgDump2(l, v)
+}
+func easyjsonAf922c28DecodeGithubComSlavaShagalovVkDbmsProjectInternalPkgDump3(in *jlexer.Lexer, out *Stats) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "users":
+			out.Users = int64(in.Int64())
+		case "forums":
+			out.Forums = int64(in.Int64())
+		case "threads":
+			out.Threads = int64(in.Int64())
+		case "posts":
+			out.Posts = int64(in.Int64())
+		case "votes":
+			out.Votes = int64(in.Int64())
+		case "forum_users":
+			out.ForumUsers = int64(in.Int64())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonAf922c28EncodeGithubComSlavaShagalovVkDbmsProjectInternalPkgDump3(out *jwriter.Writer, in Stats) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"users\":"
+		out.RawString(prefix[1:])
+		out.Int64(int64(in.Users))
+	}
+	{
+		const prefix string = ",\"forums\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.Forums))
+	}
+	{
+		const prefix string = ",\"threads\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.Threads))
+	}
+	{
+		const prefix string = ",\"posts\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.Posts))
+	}
+	{
+		const prefix string = ",\"votes\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.Votes))
+	}
+	{
+		const prefix string = ",\"forum_users\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.ForumUsers))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v Stats) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonAf922c28EncodeGithubComSlavaShagalovVkDbmsProjectInternalPkgDump3(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v Stats) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonAf922c28EncodeGithubComSlavaShagalovVkDbmsProjectInternalPkgDump3(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *Stats) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonAf922c28DecodeGithubComSlavaShagalovVkDbmsProjectInternalPkgDump3(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *Stats) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonAf922c28DecodeGithubComSlavaShagalovVkDbmsProjectInternalPkgDump3(l, v)
+}
+func easyjsonAf922c28DecodeGithubComSlavaShagalovVkDbmsProjectInternalPkgDump4(in *jlexer.Lexer, out *Record) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "type":
+			out.Type = string(in.String())
+		case "data":
+			(out.Data).UnmarshalEasyJSON(in)
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonAf922c28EncodeGithubComSlavaShagalovVkDbmsProjectInternalPkgDump4(out *jwriter.Writer, in Record) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"type\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Type))
+	}
+	{
+		const prefix string = ",\"data\":"
+		out.RawString(prefix)
+		(in.Data).MarshalEasyJSON(out)
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v Record) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonAf922c28EncodeGithubComSlavaShagalovVkDbmsProjectInternalPkgDump4(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v Record) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonAf922c28EncodeGithubComSlavaShagalovVkDbmsProjectInternalPkgDump4(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *Record) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonAf922c28DecodeGithubComSlavaShagalovVkDbmsProjectInternalPkgDump4(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *Record) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonAf922c28DecodeGithubComSlavaShagalovVkDbmsProjectInternalPkgDump4(l, v)
+}
+func easyjsonAf922c28DecodeGithubComSlavaShagalovVkDbmsProjectInternalPkgDump5(in *jlexer.Lexer, out *Post) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "id":
+			out.ID = int64(in.Int64())
+		case "parent":
+			out.Parent = int64(in.Int64())
+		case "path":
+			if in.IsNull() {
+				in.Skip()
+				out.Path = nil
+			} else {
+				in.Delim('[')
+				if out.Path == nil {
+					if !in.IsDelim(']') {
+						out.Path = make([]int64, 0, 8)
+					} else {
+						out.Path = []int64{}
+					}
+				} else {
+					out.Path = (out.Path)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v1 int64
+					v1 = int64(in.Int64())
+					out.Path = append(out.Path, v1)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		case "thread":
+			out.Thread = int64(in.Int64())
+		case "author":
+			out.Author = string(in.String())
+		case "message":
+			out.Message = string(in.String())
+		case "is_edited":
+			out.IsEdited = bool(in.Bool())
+		case "created":
+			if data := in.Raw(); in.Ok() {
+				in.AddError((out.Created).UnmarshalJSON(data))
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonAf922c28EncodeGithubComSlavaShagalovVkDbmsProjectInternalPkgDump5(out *jwriter.Writer, in Post) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"id\":"
+		out.RawString(prefix[1:])
+		out.Int64(int64(in.ID))
+	}
+	{
+		const prefix string = ",\"parent\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.Parent))
+	}
+	{
+		const prefix string = ",\"path\":"
+		out.RawString(prefix)
+		if in.Path == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v2, v3 := range in.Path {
+				if v2 > 0 {
+					out.RawByte(',')
+				}
+				out.Int64(int64(v3))
+			}
+			out.RawByte(']')
+		}
+	}
+	{
+		const prefix string = ",\"thread\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.Thread))
+	}
+	{
+		const prefix string = ",\"author\":"
+		out.RawString(prefix)
+		out.String(string(in.Author))
+	}
+	{
+		const prefix string = ",\"message\":"
+		out.RawString(prefix)
+		out.String(string(in.Message))
+	}
+	{
+		const prefix string = ",\"is_edited\":"
+		out.RawString(prefix)
+		out.Bool(bool(in.IsEdited))
+	}
+	{
+		const prefix string = ",\"created\":"
+		out.RawString(prefix)
+		out.Raw((in.Created).MarshalJSON())
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v Post) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonAf922c28EncodeGithubComSlavaShagalovVkDbmsProjectInternalPkgDump5(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v Post) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonAf922c28EncodeGithubComSlavaShagalovVkDbmsProjectInternalPkgDump5(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *Post) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonAf922c28DecodeGithubComSlavaShagalovVkDbmsProjectInternalPkgDump5(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *Post) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonAf922c28DecodeGithubComSlavaShagalovVkDbmsProjectInternalPkgDump5(l, v)
+}
+func easyjsonAf922c28DecodeGithubComSlavaShagalovVkDbmsProjectInternalPkgDump6(in *jlexer.Lexer, out *ForumUser) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "forum":
+			out.Forum = string(in.String())
+		case "nickname":
+			out.Nickname = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonAf922c28EncodeGithubComSlavaShagalovVkDbmsProjectInternalPkgDump6(out *jwriter.Writer, in ForumUser) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"forum\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Forum))
+	}
+	{
+		const prefix string = ",\"nickname\":"
+		out.RawString(prefix)
+		out.String(string(in.Nickname))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v ForumUser) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonAf922c28EncodeGithubComSlavaShagalovVkDbmsProjectInternalPkgDump6(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v ForumUser) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonAf922c28EncodeGithubComSlavaShagalovVkDbmsProjectInternalPkgDump6(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *ForumUser) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonAf922c28DecodeGithubComSlavaShagalovVkDbmsProjectInternalPkgDump6(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *ForumUser) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonAf922c28DecodeGithubComSlavaShagalovVkDbmsProjectInternalPkgDump6(l, v)
+}
+func easyjsonAf922c28DecodeGithubComSlavaShagalovVkDbmsProjectInternalPkgDump7(in *jlexer.Lexer, out *Forum) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "slug":
+			out.Slug = string(in.String())
+		case "title":
+			out.Title = string(in.String())
+		case "user":
+			out.User = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonAf922c28EncodeGithubComSlavaShagalovVkDbmsProjectInternalPkgDump7(out *jwriter.Writer, in Forum) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"slug\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Slug))
+	}
+	{
+		const prefix string = ",\"title\":"
+		out.RawString(prefix)
+		out.String(string(in.Title))
+	}
+	{
+		const prefix string = ",\"user\":"
+		out.RawString(prefix)
+		out.String(string(in.User))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v Forum) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonAf922c28EncodeGithubComSlavaShagalovVkDbmsProjectInternalPkgDump7(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v Forum) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonAf922c28EncodeGithubComSlavaShagalovVkDbmsProjectInternalPkgDump7(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *Forum) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonAf922c28DecodeGithubComSlavaShagalovVkDbmsProjectInternalPkgDump7(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *Forum) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonAf922c28DecodeGithubComSlavaShagalovVkDbmsProjectInternalPkgDump7(l, v)
+}
@@ -6,7 +6,9 @@ import (
 
 var (
 	// Common
-	ErrInternal = errors.New("internal error")
+	ErrInternal        = errors.New("internal error")
+	ErrTimeout         = errors.New("request timeout")
+	ErrRequestCanceled = errors.New("request canceled")
 
 	// User
 	ErrUserNotFound      = errors.New("user not found")
@@ -19,6 +21,7 @@ var (
 	// Thread
 	ErrThreadNotFound      = errors.New("thread not found")
 	ErrThreadAlreadyExists = errors.New("thread already exists")
+	ErrThreadLocked        = errors.New("thread is locked")
 
 	// Voice
 	ErrVoiceNotFound      = errors.New("voice not found")
@@ -28,14 +31,40 @@ var (
 	ErrPostNotFound       = errors.New("post not found")
 	ErrParentPostNotFound = errors.New("parent post not found")
 
+	// Moderation
+	ErrContentRejected    = errors.New("content rejected")
+	ErrContentOnReview    = errors.New("content queued for review")
+	ErrReviewItemNotFound = errors.New("review item not found")
+
+	// Rate limits
+	ErrTooManyRequests = errors.New("too many requests")
+	ErrSlowMode        = errors.New("thread is in slow mode")
+	ErrNotForumOwner   = errors.New("user is not the forum owner")
+
 	// Params
 	ErrInvalidIDParam    = errors.New("invalid id param")
 	ErrInvalidLimitParam = errors.New("invalid limit param")
 	ErrInvalidSinceParam = errors.New("invalid since param")
 	ErrInvalidDescParam  = errors.New("invalid desc param")
 
+	// Stats
+	ErrInvalidFreshParam    = errors.New("invalid fresh param")
+	ErrInvalidIntervalParam = errors.New("invalid interval param")
+	ErrInvalidRangeParam    = errors.New("invalid from/to range")
+
+	// Dump
+	ErrInvalidDump = errors.New("invalid dump")
+
+	// Health
+	ErrShuttingDown  = errors.New("service is shutting down")
+	ErrDBUnavailable = errors.New("database unavailable")
+	ErrSchemaVersion = errors.New("unexpected schema version")
+
 	// HTTP
-	ErrReadBody = errors.New("read request body error")
+	ErrReadBody             = errors.New("read request body error")
+	ErrBodyTooLarge         = errors.New("request body too large")
+	ErrUnsupportedMediaType = errors.New("unsupported media type")
+	ErrParseBody            = errors.New("parse request body error")
 
 	// JSON
 	ErrParseJSON = errors.New("parse json error")
@@ -0,0 +1,41 @@
+package errors
+
+import (
+	"net/http"
+
+	"google.golang.org/grpc/codes"
+)
+
+// grpcCodes are the errors whose gRPC code is not the one of their HTTP status.
+var grpcCodes = map[error]codes.Code{
+	ErrRequestCanceled:    codes.Canceled,
+	ErrThreadLocked:       codes.FailedPrecondition,
+	ErrParentPostNotFound: codes.FailedPrecondition,
+	// The content is not created until a moderator approves it, retrying does not help.
+	ErrContentOnReview: codes.FailedPrecondition,
+}
+
+var httpToGRPC = map[int]codes.Code{
+	http.StatusBadRequest:            codes.InvalidArgument,
+	http.StatusForbidden:             codes.PermissionDenied,
+	http.StatusNotFound:              codes.NotFound,
+	http.StatusConflict:              codes.AlreadyExists,
+	http.StatusRequestEntityTooLarge: codes.ResourceExhausted,
+	http.StatusUnsupportedMediaType:  codes.InvalidArgument,
+	http.StatusTooManyRequests:       codes.ResourceExhausted,
+	http.StatusInternalServerError:   codes.Internal,
+	http.StatusServiceUnavailable:    codes.Unavailable,
+	http.StatusGatewayTimeout:        codes.DeadlineExceeded,
+}
+
+func GetGRPCCodeByError(err error) (codes.Code, bool) {
+	if code, exist := grpcCodes[err]; exist {
+		return code, true
+	}
+	httpCode, exist := GetHTTPCodeByError(err)
+	code, ok := httpToGRPC[httpCode]
+	if !ok {
+		code = codes.Internal
+	}
+	return code, exist
+}
@@ -4,7 +4,9 @@ import "net/http"
 
 var httpCodes = map[error]int{
 	// Common
-	ErrInternal: http.StatusInternalServerError,
+	ErrInternal:        http.StatusInternalServerError,
+	ErrTimeout:         http.StatusGatewayTimeout,
+	ErrRequestCanceled: http.StatusServiceUnavailable,
 
 	// User
 	ErrUserNotFound:      http.StatusNotFound,
@@ -17,6 +19,7 @@ var httpCodes = map[error]int{
 	// Thread
 	ErrThreadNotFound:      http.StatusNotFound,
 	ErrThreadAlreadyExists: http.StatusConflict,
+	ErrThreadLocked:        http.StatusForbidden,
 
 	// Voice
 	ErrVoiceNotFound:      http.StatusNotFound,
@@ -26,12 +29,40 @@ var httpCodes = map[error]int{
 	ErrPostNotFound:       http.StatusNotFound,
 	ErrParentPostNotFound: http.StatusConflict,
 
+	// Moderation
+	ErrContentRejected:    http.StatusForbidden,
+	ErrContentOnReview:    http.StatusAccepted,
+	ErrReviewItemNotFound: http.StatusNotFound,
+
+	// Rate limits
+	ErrTooManyRequests: http.StatusTooManyRequests,
+	ErrSlowMode:        http.StatusTooManyRequests,
+	ErrNotForumOwner:   http.StatusForbidden,
+
 	// Params
 	ErrInvalidIDParam:    http.StatusBadRequest,
 	ErrInvalidLimitParam: http.StatusBadRequest,
+	ErrInvalidSinceParam: http.StatusBadRequest,
+	ErrInvalidDescParam:  http.StatusBadRequest,
+
+	// Stats
+	ErrInvalidFreshParam:    http.StatusBadRequest,
+	ErrInvalidIntervalParam: http.StatusBadRequest,
+	ErrInvalidRangeParam:    http.StatusBadRequest,
+
+	// Dump
+	ErrInvalidDump: http.StatusBadRequest,
+
+	// Health
+	ErrShuttingDown:  http.StatusServiceUnavailable,
+	ErrDBUnavailable: http.StatusServiceUnavailable,
+	ErrSchemaVersion: http.StatusServiceUnavailable,
 
 	// HTTP
-	ErrReadBody: http.StatusBadRequest,
+	ErrReadBody:             http.StatusBadRequest,
+	ErrBodyTooLarge:         http.StatusRequestEntityTooLarge,
+	ErrUnsupportedMediaType: http.StatusUnsupportedMediaType,
+	ErrParseBody:            http.StatusBadRequest,
 
 	// JSON
 	ErrParseJSON: http.StatusBadRequest,
@@ -0,0 +1,71 @@
+package filter
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// AllForums is the key of the banned word list applied to every forum.
+const AllForums = "*"
+
+type bannedWords struct {
+	mu     sync.RWMutex
+	words  map[string]map[string]struct{}
+	action Action
+}
+
+type BannedWords interface {
+	Filter
+	Set(forum string, words []string)
+}
+
+func NewBannedWords(lists map[string][]string, action Action) BannedWords {
+	f := &bannedWords{words: make(map[string]map[string]struct{}), action: action}
+	for forum, words := range lists {
+		f.Set(forum, words)
+	}
+	return f
+}
+
+func (f *bannedWords) Name() string {
+	return "banned_words"
+}
+
+func (f *bannedWords) Set(forum string, words []string) {
+	set := make(map[string]struct{}, len(words))
+	for _, word := range words {
+		set[strings.ToLower(word)] = struct{}{}
+	}
+
+	f.mu.Lock()
+	f.words[strings.ToLower(forum)] = set
+	f.mu.Unlock()
+}
+
+func (f *bannedWords) Check(_ context.Context, content *Content) (Verdict, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	global := f.words[AllForums]
+	local := f.words[strings.ToLower(content.Forum)]
+	if len(global) == 0 && len(local) == 0 {
+		return Verdict{Action: Accept}, nil
+	}
+
+	for _, word := range tokenize(content.Title + " " + content.Message) {
+		_, inGlobal := global[word]
+		_, inLocal := local[word]
+		if inGlobal || inLocal {
+			return Verdict{Action: f.action, Reason: "banned word: " + word}, nil
+		}
+	}
+	return Verdict{Action: Accept}, nil
+}
+
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
@@ -0,0 +1,91 @@
+package filter
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+)
+
+// Bayes is a naive Bayes spam classifier trained from moderator decisions.
+type Bayes struct {
+	mu         sync.RWMutex
+	spamTokens map[string]int
+	hamTokens  map[string]int
+	spamTotal  int
+	hamTotal   int
+	spamDocs   int
+	hamDocs    int
+
+	minDocs         int
+	reviewThreshold float64
+	rejectThreshold float64
+}
+
+func NewBayes(minDocs int, reviewThreshold, rejectThreshold float64) *Bayes {
+	return &Bayes{
+		spamTokens:      make(map[string]int),
+		hamTokens:       make(map[string]int),
+		minDocs:         minDocs,
+		reviewThreshold: reviewThreshold,
+		rejectThreshold: rejectThreshold,
+	}
+}
+
+func (f *Bayes) Name() string {
+	return "bayes"
+}
+
+func (f *Bayes) Train(text string, spam bool) {
+	tokens := tokenize(text)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	counts, total := f.hamTokens, &f.hamTotal
+	if spam {
+		counts, total = f.spamTokens, &f.spamTotal
+		f.spamDocs++
+	} else {
+		f.hamDocs++
+	}
+
+	for _, token := range tokens {
+		counts[token]++
+		*total++
+	}
+}
+
+// Score returns the probability that text is spam. Until the classifier has
+// seen minDocs documents of each class it returns 0.
+func (f *Bayes) Score(text string) float64 {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	if f.spamDocs < f.minDocs || f.hamDocs < f.minDocs {
+		return 0
+	}
+
+	vocabulary := float64(len(f.spamTokens) + len(f.hamTokens))
+	logOdds := math.Log(float64(f.spamDocs)) - math.Log(float64(f.hamDocs))
+	for _, token := range tokenize(text) {
+		pSpam := (float64(f.spamTokens[token]) + 1) / (float64(f.spamTotal) + vocabulary)
+		pHam := (float64(f.hamTokens[token]) + 1) / (float64(f.hamTotal) + vocabulary)
+		logOdds += math.Log(pSpam) - math.Log(pHam)
+	}
+	return 1 / (1 + math.Exp(-logOdds))
+}
+
+func (f *Bayes) Check(_ context.Context, content *Content) (Verdict, error) {
+	score := f.Score(content.Title + " " + content.Message)
+	reason := fmt.Sprintf("spam score %.3f", score)
+
+	switch {
+	case score >= f.rejectThreshold:
+		return Verdict{Action: Reject, Reason: reason}, nil
+	case score >= f.reviewThreshold:
+		return Verdict{Action: Review, Reason: reason}, nil
+	default:
+		return Verdict{Action: Accept}, nil
+	}
+}
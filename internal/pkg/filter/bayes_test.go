@@ -0,0 +1,53 @@
+package filter
+
+import (
+	"context"
+	"testing"
+)
+
+func trainedBayes() *Bayes {
+	f := NewBayes(2, 0.5, 0.9)
+	for _, text := range []string{"buy cheap pills now", "cheap pills discount buy", "free money buy now"} {
+		f.Train(text, true)
+	}
+	for _, text := range []string{"the kraken sank the ship", "storm over the harbor", "captain lost the map"} {
+		f.Train(text, false)
+	}
+	return f
+}
+
+func TestBayesNeedsMinDocs(t *testing.T) {
+	f := NewBayes(2, 0.5, 0.9)
+	f.Train("buy cheap pills", true)
+	f.Train("the kraken sank the ship", false)
+	if score := f.Score("buy cheap pills"); score != 0 {
+		t.Fatalf("score %v before min docs, want 0", score)
+	}
+}
+
+func TestBayesScore(t *testing.T) {
+	f := trainedBayes()
+	spam, ham := f.Score("buy cheap pills"), f.Score("the kraken and the storm")
+	if spam <= 0.5 || ham >= 0.5 {
+		t.Fatalf("spam score %v, ham score %v", spam, ham)
+	}
+}
+
+func TestBayesCheck(t *testing.T) {
+	f := trainedBayes()
+	for _, tc := range []struct {
+		message string
+		action  Action
+	}{
+		{"buy cheap pills now buy cheap pills", Reject},
+		{"the captain saw the kraken in the harbor", Accept},
+	} {
+		verdict, err := f.Check(context.Background(), &Content{Message: tc.message})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if verdict.Action != tc.action {
+			t.Errorf("%q: %s (%s), want %s", tc.message, verdict.Action, verdict.Reason, tc.action)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package filter
+
+import (
+	"context"
+	"crypto/sha1"
+	"strings"
+	"sync"
+	"time"
+)
+
+type duplicateKey struct {
+	author string
+	hash   [sha1.Size]byte
+}
+
+// duplicate rejects a message that the same author already sent within the window.
+type duplicate struct {
+	mu       sync.Mutex
+	window   time.Duration
+	maxItems int
+	seen     map[duplicateKey]time.Time
+	action   Action
+}
+
+func NewDuplicate(window time.Duration, maxItems int, action Action) Filter {
+	return &duplicate{
+		window:   window,
+		maxItems: maxItems,
+		seen:     make(map[duplicateKey]time.Time),
+		action:   action,
+	}
+}
+
+func (f *duplicate) Name() string {
+	return "duplicate"
+}
+
+func (f *duplicate) Check(_ context.Context, content *Content) (Verdict, error) {
+	key, ok := duplicateKeyOf(content)
+	if !ok {
+		return Verdict{Action: Accept}, nil
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if last, ok := f.seen[key]; ok && time.Since(last) < f.window {
+		return Verdict{Action: f.action, Reason: "duplicate message"}, nil
+	}
+	return Verdict{Action: Accept}, nil
+}
+
+// CheckBatch finds a message the same author repeats within one write and
+// returns the index of the repeat.
+func (f *duplicate) CheckBatch(_ context.Context, contents []Content) (Verdict, int, error) {
+	seen := make(map[duplicateKey]struct{}, len(contents))
+	for i := range contents {
+		key, ok := duplicateKeyOf(&contents[i])
+		if !ok {
+			continue
+		}
+		if _, ok := seen[key]; ok {
+			return Verdict{Action: f.action, Reason: "duplicate message in batch"}, i, nil
+		}
+		seen[key] = struct{}{}
+	}
+	return Verdict{Action: Accept}, 0, nil
+}
+
+// Record remembers published content only, so a retry of a failed write is
+// not taken for a duplicate.
+func (f *duplicate) Record(content *Content) {
+	key, ok := duplicateKeyOf(content)
+	if !ok {
+		return
+	}
+	now := time.Now()
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if len(f.seen) >= f.maxItems {
+		f.evict(now)
+	}
+	f.seen[key] = now
+}
+
+func duplicateKeyOf(content *Content) (duplicateKey, bool) {
+	normalized := strings.Join(tokenize(content.Title+" "+content.Message), " ")
+	if normalized == "" {
+		return duplicateKey{}, false
+	}
+	return duplicateKey{author: strings.ToLower(content.Author), hash: sha1.Sum([]byte(normalized))}, true
+}
+
+func (f *duplicate) evict(now time.Time) {
+	for key, last := range f.seen {
+		if now.Sub(last) >= f.window {
+			delete(f.seen, key)
+		}
+	}
+
+	// Window is still full of fresh entries: drop arbitrary ones to bound memory.
+	for key := range f.seen {
+		if len(f.seen) < f.maxItems {
+			break
+		}
+		delete(f.seen, key)
+	}
+}
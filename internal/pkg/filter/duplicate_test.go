@@ -0,0 +1,98 @@
+package filter
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	pkgErrors "github.com/SlavaShagalov/vk-dbms-project/internal/pkg/errors"
+)
+
+func check(t *testing.T, f Filter, content *Content) Action {
+	t.Helper()
+
+	verdict, err := f.Check(context.Background(), content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return verdict.Action
+}
+
+func TestDuplicateChecksRecordedContent(t *testing.T) {
+	f := NewDuplicate(time.Minute, 10, Reject)
+	content := &Content{Author: "Alice", Message: "Hello, sea!"}
+
+	if action := check(t, f, content); action != Accept {
+		t.Fatalf("first message: %s", action)
+	}
+	// Checking alone records nothing, the write may still fail.
+	if action := check(t, f, content); action != Accept {
+		t.Fatalf("unpublished message: %s", action)
+	}
+
+	f.(Recorder).Record(content)
+	// Case, punctuation and the author case are normalized.
+	if action := check(t, f, &Content{Author: "alice", Message: "hello sea"}); action != Reject {
+		t.Fatalf("duplicate: %s", action)
+	}
+	if action := check(t, f, &Content{Author: "bob", Message: "hello sea"}); action != Accept {
+		t.Fatalf("another author: %s", action)
+	}
+}
+
+func TestDuplicateWindow(t *testing.T) {
+	f := NewDuplicate(time.Millisecond, 10, Reject)
+	content := &Content{Author: "alice", Message: "hello"}
+	f.(Recorder).Record(content)
+	time.Sleep(2 * time.Millisecond)
+	if action := check(t, f, content); action != Accept {
+		t.Fatalf("message after the window: %s", action)
+	}
+}
+
+func TestDuplicateEvictsWhenFull(t *testing.T) {
+	f := NewDuplicate(time.Minute, 2, Reject).(*duplicate)
+	for _, message := range []string{"one", "two", "three"} {
+		f.Record(&Content{Author: "alice", Message: message})
+	}
+	if len(f.seen) > 2 {
+		t.Fatalf("%d entries kept, max 2", len(f.seen))
+	}
+}
+
+func TestChainRecordsAfterPublish(t *testing.T) {
+	chain := NewChain(nil, zap.NewNop(), NewDuplicate(time.Minute, 10, Reject))
+	contents := []Content{{Author: "alice", Message: "hello"}}
+	publish := func(context.Context) error { return nil }
+
+	if err := chain.Apply(context.Background(), contents, publish); err != nil {
+		t.Fatal(err)
+	}
+	// The caller failed to write, nothing was published.
+	if err := chain.Apply(context.Background(), contents, publish); err != nil {
+		t.Fatalf("retry: %v", err)
+	}
+
+	chain.Published(contents)
+	if err := chain.Apply(context.Background(), contents, publish); !errors.Is(err, pkgErrors.ErrContentRejected) {
+		t.Fatalf("duplicate: %v, want %v", err, pkgErrors.ErrContentRejected)
+	}
+}
+
+func TestChainRejectsDuplicatesInBatch(t *testing.T) {
+	chain := NewChain(nil, zap.NewNop(), NewDuplicate(time.Minute, 10, Reject))
+	publish := func(context.Context) error { return nil }
+
+	contents := []Content{{Author: "alice", Message: "hello"}, {Author: "bob", Message: "hello"}}
+	if err := chain.Apply(context.Background(), contents, publish); err != nil {
+		t.Fatalf("different authors: %v", err)
+	}
+
+	contents = append(contents, Content{Author: "Alice", Message: "Hello!"})
+	if err := chain.Apply(context.Background(), contents, publish); !errors.Is(err, pkgErrors.ErrContentRejected) {
+		t.Fatalf("repeat in batch: %v, want %v", err, pkgErrors.ErrContentRejected)
+	}
+}
@@ -0,0 +1,194 @@
+package filter
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+
+	pkgErrors "github.com/SlavaShagalov/vk-dbms-project/internal/pkg/errors"
+)
+
+type Action int
+
+const (
+	Accept Action = iota
+	Review
+	Reject
+)
+
+func (a Action) String() string {
+	switch a {
+	case Review:
+		return "review"
+	case Reject:
+		return "reject"
+	default:
+		return "accept"
+	}
+}
+
+const (
+	KindPost   = "post"
+	KindThread = "thread"
+)
+
+type Content struct {
+	Kind    string
+	Forum   string
+	Thread  int
+	Author  string
+	Title   string
+	Message string
+}
+
+type Verdict struct {
+	Action Action
+	Filter string
+	Reason string
+}
+
+type Filter interface {
+	Name() string
+	Check(ctx context.Context, content *Content) (Verdict, error)
+}
+
+// BatchFilter is a filter that also checks the items of one write against each other.
+type BatchFilter interface {
+	CheckBatch(ctx context.Context, contents []Content) (Verdict, int, error)
+}
+
+// Recorder is a filter that learns from published content.
+type Recorder interface {
+	Record(content *Content)
+}
+
+// Chain runs filters in order. The first Reject stops the chain, a Review is
+// remembered and returned unless a later filter rejects the content.
+type Chain struct {
+	filters []Filter
+	queue   *Queue
+	log     *zap.Logger
+}
+
+func NewChain(queue *Queue, log *zap.Logger, filters ...Filter) *Chain {
+	return &Chain{filters: filters, queue: queue, log: log}
+}
+
+func (c *Chain) Empty() bool {
+	return c == nil || len(c.filters) == 0
+}
+
+func (c *Chain) Check(ctx context.Context, content *Content) (Verdict, error) {
+	result := Verdict{Action: Accept}
+	if c.Empty() {
+		return result, nil
+	}
+
+	for _, f := range c.filters {
+		verdict, err := f.Check(ctx, content)
+		if err != nil {
+			c.log.Error("Content filter failed", zap.String("filter", f.Name()), zap.Error(err))
+			return result, pkgErrors.ErrInternal
+		}
+		if verdict.Action > result.Action {
+			result = verdict
+			result.Filter = f.Name()
+		}
+		if result.Action == Reject {
+			break
+		}
+	}
+	return result, nil
+}
+
+// Apply checks every content item, then the items against each other. Rejected content returns ErrContentRejected,
+// content that needs review is put into the queue together with publish and
+// ErrContentOnReview is returned, or ErrContentRejected when the queue is full. Accepted content is left to the caller.
+func (c *Chain) Apply(ctx context.Context, contents []Content, publish PublishFunc) error {
+	if c.Empty() {
+		return nil
+	}
+
+	held := make([]Verdict, 0)
+	for i := range contents {
+		verdict, err := c.Check(ctx, &contents[i])
+		if err != nil {
+			return err
+		}
+
+		switch verdict.Action {
+		case Reject:
+			c.rejected(&contents[i], verdict)
+			return pkgErrors.ErrContentRejected
+		case Review:
+			held = append(held, verdict)
+		}
+	}
+
+	if len(contents) > 1 {
+		for _, f := range c.filters {
+			batch, ok := f.(BatchFilter)
+			if !ok {
+				continue
+			}
+			verdict, i, err := batch.CheckBatch(ctx, contents)
+			if err != nil {
+				c.log.Error("Content filter failed", zap.String("filter", f.Name()), zap.Error(err))
+				return pkgErrors.ErrInternal
+			}
+			verdict.Filter = f.Name()
+
+			switch verdict.Action {
+			case Reject:
+				c.rejected(&contents[i], verdict)
+				return pkgErrors.ErrContentRejected
+			case Review:
+				held = append(held, verdict)
+			}
+		}
+	}
+
+	if len(held) == 0 {
+		return nil
+	}
+	if c.queue == nil {
+		return pkgErrors.ErrContentRejected
+	}
+
+	item, ok := c.queue.Push(contents, held, func(ctx context.Context) error {
+		if err := publish(ctx); err != nil {
+			return err
+		}
+		c.Published(contents)
+		return nil
+	})
+	if !ok {
+		c.log.Warn("Review queue is full, content rejected", zap.Int("count", len(contents)))
+		return pkgErrors.ErrContentRejected
+	}
+	c.log.Info("Content queued for review", zap.Int("item_id", item.ID), zap.Int("count", len(contents)))
+	return pkgErrors.ErrContentOnReview
+}
+
+func (c *Chain) rejected(content *Content, verdict Verdict) {
+	c.log.Info("Content rejected",
+		zap.String("kind", content.Kind),
+		zap.String("author", content.Author),
+		zap.String("filter", verdict.Filter),
+		zap.String("reason", verdict.Reason))
+}
+
+// Published passes content written by the caller to the recording filters.
+func (c *Chain) Published(contents []Content) {
+	if c.Empty() {
+		return
+	}
+
+	for _, f := range c.filters {
+		if recorder, ok := f.(Recorder); ok {
+			for i := range contents {
+				recorder.Record(&contents[i])
+			}
+		}
+	}
+}
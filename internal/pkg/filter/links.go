@@ -0,0 +1,30 @@
+package filter
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+)
+
+var linkRegexp = regexp.MustCompile(`(?i)\b(?:https?://|ftp://|www\.)\S+`)
+
+type linkLimit struct {
+	max    int
+	action Action
+}
+
+func NewLinkLimit(max int, action Action) Filter {
+	return &linkLimit{max: max, action: action}
+}
+
+func (f *linkLimit) Name() string {
+	return "link_limit"
+}
+
+func (f *linkLimit) Check(_ context.Context, content *Content) (Verdict, error) {
+	count := len(linkRegexp.FindAllStringIndex(content.Title+" "+content.Message, -1))
+	if count > f.max {
+		return Verdict{Action: f.action, Reason: fmt.Sprintf("too many links: %d > %d", count, f.max)}, nil
+	}
+	return Verdict{Action: Accept}, nil
+}
@@ -0,0 +1,101 @@
+package filter
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	pkgErrors "github.com/SlavaShagalov/vk-dbms-project/internal/pkg/errors"
+)
+
+type PublishFunc func(ctx context.Context) error
+
+// Trainer learns from moderator decisions on queued content.
+type Trainer interface {
+	Train(text string, spam bool)
+}
+
+type Item struct {
+	ID       int
+	Contents []Content
+	Verdicts []Verdict
+	Created  time.Time
+
+	publish PublishFunc
+}
+
+// Queue keeps content held for review until a moderator approves or rejects it.
+// Items live in process memory only: they are lost on restart, and with
+// several replicas each one has its own queue. At most maxItems are held.
+type Queue struct {
+	mu       sync.Mutex
+	nextID   int
+	maxItems int
+	items    map[int]*Item
+	trainers []Trainer
+}
+
+func NewQueue(maxItems int, trainers ...Trainer) *Queue {
+	return &Queue{nextID: 1, maxItems: maxItems, items: make(map[int]*Item), trainers: trainers}
+}
+
+// Push adds the item, it returns false when the queue is full.
+func (q *Queue) Push(contents []Content, verdicts []Verdict, publish PublishFunc) (Item, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.items) >= q.maxItems {
+		return Item{}, false
+	}
+	item := &Item{
+		ID:       q.nextID,
+		Contents: contents,
+		Verdicts: verdicts,
+		Created:  time.Now(),
+		publish:  publish,
+	}
+	q.items[item.ID] = item
+	q.nextID++
+	return *item, true
+}
+
+func (q *Queue) List() []Item {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	items := make([]Item, 0, len(q.items))
+	for _, item := range q.items {
+		items = append(items, *item)
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].ID < items[j].ID })
+	return items
+}
+
+// Resolve removes the item from the queue, publishes it when approved and
+// feeds the decision to the trainers.
+func (q *Queue) Resolve(ctx context.Context, id int, approve bool) error {
+	q.mu.Lock()
+	item, ok := q.items[id]
+	if ok {
+		delete(q.items, id)
+	}
+	q.mu.Unlock()
+
+	if !ok {
+		return pkgErrors.ErrReviewItemNotFound
+	}
+
+	if approve && item.publish != nil {
+		if err := item.publish(ctx); err != nil {
+			return err
+		}
+	}
+
+	for _, content := range item.Contents {
+		for _, trainer := range q.trainers {
+			trainer.Train(content.Title+" "+content.Message, !approve)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package filter
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.uber.org/zap"
+
+	pkgErrors "github.com/SlavaShagalov/vk-dbms-project/internal/pkg/errors"
+)
+
+func TestQueueRejectsWhenFull(t *testing.T) {
+	queue := NewQueue(1)
+	chain := NewChain(queue, zap.NewNop(), NewLinkLimit(0, Review))
+	publish := func(context.Context) error { return nil }
+
+	contents := []Content{{Author: "alice", Message: "see https://example.com"}}
+	if err := chain.Apply(context.Background(), contents, publish); !errors.Is(err, pkgErrors.ErrContentOnReview) {
+		t.Fatalf("first item: %v, want %v", err, pkgErrors.ErrContentOnReview)
+	}
+	if err := chain.Apply(context.Background(), contents, publish); !errors.Is(err, pkgErrors.ErrContentRejected) {
+		t.Fatalf("full queue: %v, want %v", err, pkgErrors.ErrContentRejected)
+	}
+
+	items := queue.List()
+	if len(items) != 1 {
+		t.Fatalf("%d items queued, want 1", len(items))
+	}
+	if err := queue.Resolve(context.Background(), items[0].ID, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := chain.Apply(context.Background(), contents, publish); !errors.Is(err, pkgErrors.ErrContentOnReview) {
+		t.Fatalf("after resolve: %v, want %v", err, pkgErrors.ErrContentOnReview)
+	}
+}
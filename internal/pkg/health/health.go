@@ -0,0 +1,63 @@
+package health
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+
+	pkgErrors "github.com/SlavaShagalov/vk-dbms-project/internal/pkg/errors"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/tracing"
+)
+
+const getSchemaVersionCmd = `SELECT coalesce(max(version), 0) FROM schema_migrations;`
+
+type Checker struct {
+	pool          *pgxpool.Pool
+	schemaVersion int
+	shuttingDown  atomic.Bool
+	log           *zap.Logger
+}
+
+func NewChecker(pool *pgxpool.Pool, schemaVersion int, log *zap.Logger) *Checker {
+	return &Checker{pool: pool, schemaVersion: schemaVersion, log: log}
+}
+
+// Ready reports whether the instance should receive traffic. Without a pool
+// (memory storage) only the shutdown state is checked.
+func (c *Checker) Ready(ctx context.Context) error {
+	if c.shuttingDown.Load() {
+		return pkgErrors.ErrShuttingDown
+	}
+	if c.pool == nil {
+		return nil
+	}
+
+	version := 0
+	if err := c.pool.QueryRow(ctx, getSchemaVersionCmd).Scan(&version); err != nil {
+		tracing.Log(ctx, c.log).Warn("Readiness check failed", zap.Error(err))
+		return pkgErrors.ErrDBUnavailable
+	}
+	if version != c.schemaVersion {
+		tracing.Log(ctx, c.log).Warn("Unexpected schema version",
+			zap.Int("expected", c.schemaVersion), zap.Int("actual", version))
+		return pkgErrors.ErrSchemaVersion
+	}
+	return nil
+}
+
+// Drain returns a context that is done delay after ctx. In between the checker
+// reports shutdown, so load balancers stop routing before the listeners close.
+func (c *Checker) Drain(ctx context.Context, delay time.Duration) context.Context {
+	drainCtx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-ctx.Done()
+		c.shuttingDown.Store(true)
+		c.log.Info("Not ready, waiting before shutdown", zap.Duration("delay", delay))
+		time.Sleep(delay)
+		cancel()
+	}()
+	return drainCtx
+}
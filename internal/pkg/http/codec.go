@@ -0,0 +1,81 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/codec"
+	pkgErrors "github.com/SlavaShagalov/vk-dbms-project/internal/pkg/errors"
+)
+
+// RequestCodec is the format of the request body. Unknown media types are read
+// as JSON like before the other formats existed, so clients that send no or a
+// wrong Content-Type keep working.
+func RequestCodec(r *http.Request) codec.Codec {
+	if c := codec.ForContentType(r.Header.Get("Content-Type")); c != nil {
+		return c
+	}
+	return codec.JSON
+}
+
+// ResponseCodec is the format negotiated from Accept, without it the response
+// is in the format of the request.
+func ResponseCodec(r *http.Request) codec.Codec {
+	return codec.Negotiate(r.Header.Get("Accept"), RequestCodec(r))
+}
+
+// ReadRequest reads the body and decodes it into v in the format of its
+// Content-Type.
+func ReadRequest(r *http.Request, log *zap.Logger, v interface{}) error {
+	body, err := ReadBody(r, log)
+	if err != nil {
+		return err
+	}
+
+	c := RequestCodec(r)
+	if err = c.Unmarshal(body, v); err != nil {
+		switch {
+		case errors.Is(err, codec.ErrUnsupported):
+			return pkgErrors.ErrUnsupportedMediaType
+		case c == codec.JSON:
+			return pkgErrors.ErrParseJSON
+		default:
+			return pkgErrors.ErrParseBody
+		}
+	}
+	return nil
+}
+
+// WriteResponse encodes v in the negotiated format and writes it with the
+// status. Types the format can not carry are sent as JSON.
+func WriteResponse(w http.ResponseWriter, r *http.Request, status int, v interface{}) error {
+	c := ResponseCodec(r)
+	data, err := c.Marshal(v)
+	if errors.Is(err, codec.ErrUnsupported) {
+		c = codec.JSON
+		data, err = c.Marshal(v)
+	}
+	if err != nil {
+		return pkgErrors.ErrInternal
+	}
+
+	h := w.Header()
+	h.Set("Content-Type", c.ContentType())
+	varyAccept(h)
+	w.WriteHeader(status)
+	if _, err = w.Write(data); err != nil {
+		return pkgErrors.ErrInternal
+	}
+	return nil
+}
+
+func varyAccept(h http.Header) {
+	for _, value := range h.Values("Vary") {
+		if value == "Accept" {
+			return
+		}
+	}
+	h.Add("Vary", "Accept")
+}
@@ -0,0 +1,113 @@
+package http
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/codec"
+)
+
+// ETag builds a strong entity tag from the versions of the rows a response is
+// made of. It is empty when a version is unknown, the middleware hashes the
+// body then.
+func ETag(versions ...time.Time) string {
+	h := fnv.New64a()
+	var buf [8]byte
+	for _, version := range versions {
+		if version.IsZero() {
+			return ""
+		}
+		binary.BigEndian.PutUint64(buf[:], uint64(version.UnixNano()))
+		_, _ = h.Write(buf[:])
+	}
+	return `"` + strconv.FormatUint(h.Sum64(), 16) + `"`
+}
+
+// BodyETag builds a strong entity tag from the response body.
+func BodyETag(body []byte) string {
+	h := fnv.New64a()
+	_, _ = h.Write(body)
+	return `"` + strconv.FormatUint(h.Sum64(), 16) + `"`
+}
+
+// LastModified returns the latest of the versions.
+func LastModified(versions ...time.Time) time.Time {
+	var last time.Time
+	for _, version := range versions {
+		if version.After(last) {
+			last = version
+		}
+	}
+	return last
+}
+
+// NotModified sets ETag and Last-Modified from the row versions and answers
+// 304 when the client already has them. Handlers call it before encoding the
+// body and return on true. Formats other than JSON get their name as an ETag
+// suffix, the bytes differ.
+func NotModified(w http.ResponseWriter, r *http.Request, versions ...time.Time) bool {
+	etag := ETag(versions...)
+	if etag == "" {
+		return false
+	}
+	if c := ResponseCodec(r); c != codec.JSON {
+		etag = etag[:len(etag)-1] + "-" + c.Name() + `"`
+	}
+	modified := LastModified(versions...)
+
+	varyAccept(w.Header())
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Last-Modified", modified.UTC().Format(http.TimeFormat))
+	if !Fresh(r, etag, modified) {
+		return false
+	}
+	WriteNotModified(w)
+	return true
+}
+
+// Fresh evaluates If-None-Match and, without it, If-Modified-Since like
+// RFC 9110 does for GET.
+func Fresh(r *http.Request, etag string, modified time.Time) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+	if match := r.Header.Get("If-None-Match"); match != "" {
+		return etagMatch(match, etag)
+	}
+	since := r.Header.Get("If-Modified-Since")
+	if since == "" || modified.IsZero() {
+		return false
+	}
+	t, err := http.ParseTime(since)
+	if err != nil {
+		return false
+	}
+	return !modified.Truncate(time.Second).After(t)
+}
+
+// WriteNotModified sends 304 without the headers that describe a body.
+func WriteNotModified(w http.ResponseWriter) {
+	h := w.Header()
+	h.Del("Content-Type")
+	h.Del("Content-Length")
+	w.WriteHeader(http.StatusNotModified)
+}
+
+// etagMatch uses the weak comparison If-None-Match requires.
+func etagMatch(header, etag string) bool {
+	if etag == "" {
+		return false
+	}
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -6,10 +6,28 @@ import (
 	"go.uber.org/zap"
 	"io"
 	"net/http"
+	"sync/atomic"
 )
 
+var maxBodySize atomic.Int64
+
+// SetMaxBodySize limits the request body accepted by ReadBody, 0 disables the limit.
+func SetMaxBodySize(size int64) {
+	maxBodySize.Store(size)
+}
+
 func ReadBody(r *http.Request, log *zap.Logger) ([]byte, error) {
-	body, err := io.ReadAll(r.Body)
+	limit := maxBodySize.Load()
+	if limit > 0 && r.ContentLength > limit {
+		return nil, pkgErrors.ErrBodyTooLarge
+	}
+
+	reader := io.Reader(r.Body)
+	if limit > 0 {
+		reader = io.LimitReader(r.Body, limit+1)
+	}
+
+	body, err := io.ReadAll(reader)
 	if err != nil {
 		log.Error(constants.FailedReadRequestBody, zap.Error(err))
 		return nil, pkgErrors.ErrReadBody
@@ -20,5 +38,9 @@ func ReadBody(r *http.Request, log *zap.Logger) ([]byte, error) {
 		log.Error(constants.FailedCloseRequestBody, zap.Error(err))
 	}
 
+	if limit > 0 && int64(len(body)) > limit {
+		return nil, pkgErrors.ErrBodyTooLarge
+	}
+
 	return body, nil
 }
@@ -0,0 +1,74 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type ServerConfig struct {
+	Addr              string
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	MaxHeaderBytes    int
+}
+
+func NewServer(cfg *ServerConfig, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              cfg.Addr,
+		Handler:           handler,
+		ReadTimeout:       cfg.ReadTimeout,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+		MaxHeaderBytes:    cfg.MaxHeaderBytes,
+	}
+}
+
+// Serve runs the servers until ctx is done or one of them fails, then drains
+// in-flight requests for at most drainTimeout. It returns after every handler
+// has finished or the drain timeout has expired.
+func Serve(ctx context.Context, drainTimeout time.Duration, log *zap.Logger, servers ...*http.Server) error {
+	errCh := make(chan error, len(servers))
+	for _, server := range servers {
+		go func(server *http.Server) {
+			log.Info("Server started", zap.String("addr", server.Addr))
+			errCh <- server.ListenAndServe()
+		}(server)
+	}
+
+	var serveErr error
+	select {
+	case err := <-errCh:
+		if !errors.Is(err, http.ErrServerClosed) {
+			serveErr = err
+		}
+	case <-ctx.Done():
+	}
+
+	log.Info("Shutting down servers", zap.Duration("drain_timeout", drainTimeout))
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), drainTimeout)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for _, server := range servers {
+		wg.Add(1)
+		go func(server *http.Server) {
+			defer wg.Done()
+			if err := server.Shutdown(shutdownCtx); err != nil {
+				log.Error("Server drain interrupted", zap.String("addr", server.Addr), zap.Error(err))
+				_ = server.Close()
+			}
+		}(server)
+	}
+	wg.Wait()
+
+	log.Info("Servers stopped")
+	return serveErr
+}
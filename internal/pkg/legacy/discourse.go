@@ -0,0 +1,148 @@
+package legacy
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// DiscourseSource reads a Discourse JSON export, an object with the users,
+// categories, topics and posts arrays. The arrays are streamed, so the file
+// is reopened for every stage instead of being loaded into memory.
+type DiscourseSource struct {
+	path string
+}
+
+func NewDiscourseSource(path string) (*DiscourseSource, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	return &DiscourseSource{path: path}, f.Close()
+}
+
+func (s *DiscourseSource) Users(fn func(*User) error) error {
+	return s.each("users", func(raw []byte) error {
+		var u discourseUser
+		if err := u.UnmarshalJSON(raw); err != nil {
+			return err
+		}
+		return fn(&User{ID: u.ID, Username: u.Username, Name: u.Name, Email: u.Email})
+	})
+}
+
+func (s *DiscourseSource) Categories(fn func(*Category) error) error {
+	return s.each("categories", func(raw []byte) error {
+		var c discourseCategory
+		if err := c.UnmarshalJSON(raw); err != nil {
+			return err
+		}
+		return fn(&Category{ID: c.ID, Name: c.Name, Slug: c.Slug})
+	})
+}
+
+func (s *DiscourseSource) Topics(fn func(*Topic) error) error {
+	return s.each("topics", func(raw []byte) error {
+		var t discourseTopic
+		if err := t.UnmarshalJSON(raw); err != nil {
+			return err
+		}
+		return fn(&Topic{ID: t.ID, CategoryID: t.CategoryID, UserID: t.UserID, Title: t.Title, Created: t.CreatedAt})
+	})
+}
+
+func (s *DiscourseSource) Posts(fn func(*Post) error) error {
+	return s.each("posts", func(raw []byte) error {
+		var p discoursePost
+		if err := p.UnmarshalJSON(raw); err != nil {
+			return err
+		}
+		return fn(&Post{
+			ID:      p.ID,
+			TopicID: p.TopicID,
+			UserID:  p.UserID,
+			Number:  p.PostNumber,
+			ReplyTo: p.ReplyToPostNumber,
+			Text:    p.Raw,
+			Created: p.CreatedAt,
+		})
+	})
+}
+
+// each calls fn for every element of the top-level array key. A missing key
+// is an empty array.
+func (s *DiscourseSource) each(key string, fn func(raw []byte) error) error {
+	f, err := os.Open(s.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	dec := json.NewDecoder(f)
+	if err = expectDelim(dec, '{'); err != nil {
+		return err
+	}
+	for dec.More() {
+		token, err := dec.Token()
+		if err != nil {
+			return fmt.Errorf("discourse: %w", err)
+		}
+		if token != key {
+			if err = skipValue(dec); err != nil {
+				return fmt.Errorf("discourse: %s: %w", token, err)
+			}
+			continue
+		}
+
+		if err = expectDelim(dec, '['); err != nil {
+			return fmt.Errorf("discourse: %s: %w", key, err)
+		}
+		for n := 0; dec.More(); n++ {
+			var raw json.RawMessage
+			if err = dec.Decode(&raw); err != nil {
+				return fmt.Errorf("discourse: %s[%d]: %w", key, n, err)
+			}
+			if err = fn(raw); err != nil {
+				return fmt.Errorf("discourse: %s[%d]: %w", key, n, err)
+			}
+		}
+		return nil
+	}
+	return nil
+}
+
+// skipValue consumes the next value token by token, so skipping the posts
+// array does not buffer it.
+func skipValue(dec *json.Decoder) error {
+	depth := 0
+	for {
+		token, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		switch token {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+		if depth == 0 {
+			return nil
+		}
+	}
+}
+
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	token, err := dec.Token()
+	if err == io.EOF {
+		return fmt.Errorf("expected %v, got end of input", delim)
+	}
+	if err != nil {
+		return err
+	}
+	if token != delim {
+		return fmt.Errorf("expected %v, got %v", delim, token)
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package legacy
+
+import "time"
+
+//go:generate easyjson -all -snake_case discourse_models.go
+
+type discourseUser struct {
+	ID       int64
+	Username string
+	Name     string
+	Email    string
+}
+
+type discourseCategory struct {
+	ID   int64
+	Name string
+	Slug string
+}
+
+type discourseTopic struct {
+	ID         int64
+	CategoryID int64
+	UserID     int64
+	Title      string
+	CreatedAt  time.Time
+}
+
+type discoursePost struct {
+	ID                int64
+	TopicID           int64
+	UserID            int64
+	PostNumber        int
+	ReplyToPostNumber int
+	Raw               string
+	CreatedAt         time.Time
+}
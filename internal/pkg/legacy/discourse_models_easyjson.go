@@ -0,0 +1,392 @@
+// Code generated by easyjson for marshaling/unmarshaling. DO NOT EDIT.
+
+package legacy
+
+import (
+	json "encoding/json"
+	easyjson "github.com/mailru/easyjson"
+	jlexer "github.com/mailru/easyjson/jlexer"
+	jwriter "github.com/mailru/easyjson/jwriter"
+)
+
+// suppress unused package warning
+var (
+	_ *json.RawMessage
+	_ *jlexer.Lexer
+	_ *jwriter.Writer
+	_ easyjson.Marshaler
+)
+
+func easyjson39c980e0DecodeGithubComSlavaShagalovVkDbmsProjectInternalPkgLegacy(in *jlexer.Lexer, out *discourseUser) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "id":
+			out.ID = int64(in.Int64())
+		case "username":
+			out.Username = string(in.String())
+		case "name":
+			out.Name = string(in.String())
+		case "email":
+			out.Email = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson39c980e0EncodeGithubComSlavaShagalovVkDbmsProjectInternalPkgLegacy(out *jwriter.Writer, in discourseUser) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"id\":"
+		out.RawString(prefix[1:])
+		out.Int64(int64(in.ID))
+	}
+	{
+		const prefix string = ",\"username\":"
+		out.RawString(prefix)
+		out.String(string(in.Username))
+	}
+	{
+		const prefix string = ",\"name\":"
+		out.RawString(prefix)
+		out.String(string(in.Name))
+	}
+	{
+		const prefix string = ",\"email\":"
+		out.RawString(prefix)
+		out.String(string(in.Email))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v discourseUser) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson39c980e0EncodeGithubComSlavaShagalovVkDbmsProjectInternalPkgLegacy(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v discourseUser) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson39c980e0EncodeGithubComSlavaShagalovVkDbmsProjectInternalPkgLegacy(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *discourseUser) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson39c980e0DecodeGithubComSlavaShagalovVkDbmsProjectInternalPkgLegacy(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *discourseUser) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson39c980e0DecodeGithubComSlavaShagalovVkDbmsProjectInternalPkgLegacy(l, v)
+}
+func easyjson39c980e0DecodeGithubComSlavaShagalovVkDbmsProjectInternalPkgLegacy1(in *jlexer.Lexer, out *discourseTopic) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "id":
+			out.ID = int64(in.Int64())
+		case "category_id":
+			out.CategoryID = int64(in.Int64())
+		case "user_id":
+			out.UserID = int64(in.Int64())
+		case "title":
+			out.Title = string(in.String())
+		case "created_at":
+			if data := in.Raw(); in.Ok() {
+				in.AddError((out.CreatedAt).UnmarshalJSON(data))
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson39c980e0EncodeGithubComSlavaShagalovVkDbmsProjectInternalPkgLegacy1(out *jwriter.Writer, in discourseTopic) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"id\":"
+		out.RawString(prefix[1:])
+		out.Int64(int64(in.ID))
+	}
+	{
+		const prefix string = ",\"category_id\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.CategoryID))
+	}
+	{
+		const prefix string = ",\"user_id\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.UserID))
+	}
+	{
+		const prefix string = ",\"title\":"
+		out.RawString(prefix)
+		out.String(string(in.Title))
+	}
+	{
+		const prefix string = ",\"created_at\":"
+		out.RawString(prefix)
+		out.Raw((in.CreatedAt).MarshalJSON())
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v discourseTopic) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson39c980e0EncodeGithubComSlavaShagalovVkDbmsProjectInternalPkgLegacy1(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v discourseTopic) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson39c980e0EncodeGithubComSlavaShagalovVkDbmsProjectInternalPkgLegacy1(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *discourseTopic) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson39c980e0DecodeGithubComSlavaShagalovVkDbmsProjectInternalPkgLegacy1(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *discourseTopic) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson39c980e0DecodeGithubComSlavaShagalovVkDbmsProjectInternalPkgLegacy1(l, v)
+}
+func easyjson39c980e0DecodeGithubComSlavaShagalovVkDbmsProjectInternalPkgLegacy2(in *jlexer.Lexer, out *discoursePost) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "id":
+			out.ID = int64(in.Int64())
+		case "topic_id":
+			out.TopicID = int64(in.Int64())
+		case "user_id":
+			out.UserID = int64(in.Int64())
+		case "post_number":
+			out.PostNumber = int(in.Int())
+		case "reply_to_post_number":
+			out.ReplyToPostNumber = int(in.Int())
+		case "raw":
+			out.Raw = string(in.String())
+		case "created_at":
+			if data := in.Raw(); in.Ok() {
+				in.AddError((out.CreatedAt).UnmarshalJSON(data))
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson39c980e0EncodeGithubComSlavaShagalovVkDbmsProjectInternalPkgLegacy2(out *jwriter.Writer, in discoursePost) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"id\":"
+		out.RawString(prefix[1:])
+		out.Int64(int64(in.ID))
+	}
+	{
+		const prefix string = ",\"topic_id\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.TopicID))
+	}
+	{
+		const prefix string = ",\"user_id\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.UserID))
+	}
+	{
+		const prefix string = ",\"post_number\":"
+		out.RawString(prefix)
+		out.Int(int(in.PostNumber))
+	}
+	{
+		const prefix string = ",\"reply_to_post_number\":"
+		out.RawString(prefix)
+		out.Int(int(in.ReplyToPostNumber))
+	}
+	{
+		const prefix string = ",\"raw\":"
+		out.RawString(prefix)
+		out.String(string(in.Raw))
+	}
+	{
+		const prefix string = ",\"created_at\":"
+		out.RawString(prefix)
+		out.Raw((in.CreatedAt).MarshalJSON())
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v discoursePost) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson39c980e0EncodeGithubComSlavaShagalovVkDbmsProjectInternalPkgLegacy2(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v discoursePost) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson39c980e0EncodeGithubComSlavaShagalovVkDbmsProjectInternalPkgLegacy2(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *discoursePost) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson39c980e0DecodeGithubComSlavaShagalovVkDbmsProjectInternalPkgLegacy2(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *discoursePost) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson39c980e0DecodeGithubComSlavaShagalovVkDbmsProjectInternalPkgLegacy2(l, v)
+}
+func easyjson39c980e0DecodeGithubComSlavaShagalovVkDbmsProjectInternalPkgLegacy3(in *jlexer.Lexer, out *discourseCategory) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "id":
+			out.ID = int64(in.Int64())
+		case "name":
+			out.Name = string(in.String())
+		case "slug":
+			out.Slug = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson39c980e0EncodeGithubComSlavaShagalovVkDbmsProjectInternalPkgLegacy3(out *jwriter.Writer, in discourseCategory) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"id\":"
+		out.RawString(prefix[1:])
+		out.Int64(int64(in.ID))
+	}
+	{
+		const prefix string = ",\"name\":"
+		out.RawString(prefix)
+		out.String(string(in.Name))
+	}
+	{
+		const prefix string = ",\"slug\":"
+		out.RawString(prefix)
+		out.String(string(in.Slug))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v discourseCategory) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson39c980e0EncodeGithubComSlavaShagalovVkDbmsProjectInternalPkgLegacy3(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v discourseCategory) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson39c980e0EncodeGithubComSlavaShagalovVkDbmsProjectInternalPkgLegacy3(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *discourseCategory) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson39c980e0DecodeGithubComSlavaShagalovVkDbmsProjectInternalPkgLegacy3(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *discourseCategory) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson39c980e0DecodeGithubComSlavaShagalovVkDbmsProjectInternalPkgLegacy3(l, v)
+}
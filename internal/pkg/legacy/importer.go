@@ -0,0 +1,288 @@
+package legacy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/constants"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/db"
+	pkgErrors "github.com/SlavaShagalov/vk-dbms-project/internal/pkg/errors"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/tracing"
+)
+
+const defaultBatchSize = 5000
+
+// Options of an import run.
+type Options struct {
+	// Name identifies the import: id mappings and checkpoints are kept
+	// under it, so a rerun with the same name resumes.
+	Name string
+	// Owner owns the created forums and gets the content of unknown users.
+	Owner     string
+	BatchSize int
+	// DryRun runs the import in a transaction that is rolled back.
+	DryRun bool
+}
+
+type StageReport struct {
+	// Resumed records were imported by an earlier run and skipped.
+	Resumed  int64
+	Read     int64
+	Imported int64
+	// Matched records were mapped to existing users or forums.
+	Matched  int64
+	Rejected int64
+}
+
+type Report struct {
+	DryRun     bool
+	Users      StageReport
+	Categories StageReport
+	Topics     StageReport
+	Posts      StageReport
+}
+
+type Importer struct {
+	pool *pgxpool.Pool
+	log  *zap.Logger
+}
+
+func NewImporter(pool *pgxpool.Pool, log *zap.Logger) *Importer {
+	return &Importer{pool: pool, log: log}
+}
+
+// querier is the pool in a real run and the rolled back transaction in a dry run.
+type querier interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+	QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row
+}
+
+const getOwnerCmd = `SELECT nickname FROM users WHERE nickname = $1;`
+
+const getCheckpointCmd = `
+SELECT position
+FROM legacy_checkpoints
+WHERE source = $1
+  AND stage = $2;`
+
+const saveCheckpointCmd = `
+INSERT INTO legacy_checkpoints (source, stage, position)
+VALUES ($1, $2, $3)
+ON CONFLICT (source, stage) DO UPDATE SET position   = excluded.position,
+                                          updated_at = now();`
+
+// Run imports users, categories, topics and posts in that order. Every batch
+// is committed together with its checkpoint, so an interrupted run continues
+// where it stopped.
+func (imp *Importer) Run(ctx context.Context, src Source, opts *Options) (*Report, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "legacy.Import")
+	defer span.End()
+
+	if opts.Name == "" {
+		return nil, errors.New("import name is empty")
+	}
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+
+	var q querier = imp.pool
+	if opts.DryRun {
+		tx, err := imp.pool.Begin(ctx)
+		if err != nil {
+			tracing.Log(ctx, imp.log).Error(constants.DBError, zap.Error(err))
+			return nil, db.Error(ctx, err)
+		}
+		defer tx.Rollback(ctx)
+		q = tx
+	}
+
+	owner := ""
+	if err := q.QueryRow(ctx, getOwnerCmd, opts.Owner).Scan(&owner); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, pkgErrors.ErrUserNotFound
+		}
+		tracing.Log(ctx, imp.log).Error(constants.DBError, zap.Error(err))
+		return nil, db.Error(ctx, err)
+	}
+
+	report := &Report{DryRun: opts.DryRun}
+	run := func(st *stage, each func(add func([]interface{}) error) error, stageReport *StageReport) error {
+		r := &runner{imp: imp, q: q, st: st, name: opts.Name, owner: owner, size: batchSize, report: stageReport}
+		return r.run(ctx, each)
+	}
+
+	err := run(usersStage, func(add func([]interface{}) error) error {
+		return src.Users(func(u *User) error {
+			fullname := u.Name
+			if fullname == "" {
+				fullname = u.Username
+			}
+			return add([]interface{}{u.ID, nickname(u), fullname, email(opts.Name, u)})
+		})
+	}, &report.Users)
+	if err == nil {
+		err = run(categoriesStage, func(add func([]interface{}) error) error {
+			return src.Categories(func(c *Category) error {
+				slug := c.Slug
+				if slug == "" {
+					slug = slugify(c.Name, c.ID)
+				}
+				return add([]interface{}{c.ID, slug, c.Name})
+			})
+		}, &report.Categories)
+	}
+	if err == nil {
+		err = run(topicsStage, func(add func([]interface{}) error) error {
+			return src.Topics(func(t *Topic) error {
+				return add([]interface{}{t.ID, t.CategoryID, t.UserID, t.Title, t.Created, t.FirstPostID})
+			})
+		}, &report.Topics)
+	}
+	if err == nil {
+		err = run(postsStage, func(add func([]interface{}) error) error {
+			return src.Posts(func(p *Post) error {
+				return add([]interface{}{p.ID, p.TopicID, p.UserID, p.Number, p.ReplyTo, p.Text, p.Created})
+			})
+		}, &report.Posts)
+	}
+	return report, err
+}
+
+// runner batches the records of one stage.
+type runner struct {
+	imp    *Importer
+	q      querier
+	st     *stage
+	name   string
+	owner  string
+	size   int
+	report *StageReport
+	rows   [][]interface{}
+}
+
+func (r *runner) run(ctx context.Context, each func(add func([]interface{}) error) error) error {
+	var position int64
+	err := r.q.QueryRow(ctx, getCheckpointCmd, r.name, r.st.name).Scan(&position)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		tracing.Log(ctx, r.imp.log).Error(constants.DBError, zap.Error(err))
+		return db.Error(ctx, err)
+	}
+	r.report.Resumed = position
+
+	var seen int64
+	err = each(func(row []interface{}) error {
+		seen++
+		if seen <= position {
+			return nil
+		}
+		r.rows = append(r.rows, row)
+		if len(r.rows) < r.size {
+			return nil
+		}
+		return r.flush(ctx, seen)
+	})
+	if err == nil && len(r.rows) > 0 {
+		err = r.flush(ctx, seen)
+	}
+	if err == nil {
+		tracing.Log(ctx, r.imp.log).Info("Legacy import stage done", zap.String("stage", r.st.name),
+			zap.Int64("imported", r.report.Imported), zap.Int64("matched", r.report.Matched),
+			zap.Int64("rejected", r.report.Rejected))
+	}
+	return err
+}
+
+// flush loads the batch and moves the checkpoint to position in one transaction.
+func (r *runner) flush(ctx context.Context, position int64) error {
+	tx, err := r.q.Begin(ctx)
+	if err != nil {
+		tracing.Log(ctx, r.imp.log).Error(constants.DBError, zap.Error(err))
+		return db.Error(ctx, err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err = tx.Exec(ctx, r.st.create); err != nil {
+		tracing.Log(ctx, r.imp.log).Error(constants.DBError, zap.String("stage", r.st.name), zap.Error(err))
+		return db.Error(ctx, err)
+	}
+	n, err := tx.CopyFrom(ctx, pgx.Identifier{r.st.table}, r.st.columns, pgx.CopyFromRows(r.rows))
+	if err != nil {
+		tracing.Log(ctx, r.imp.log).Error(constants.DBError, zap.String("stage", r.st.name), zap.Error(err))
+		return db.Error(ctx, err)
+	}
+
+	var imported, mapped int64
+	for _, step := range r.st.steps {
+		args := []interface{}{r.name}
+		if step.withOwner {
+			args = append(args, r.owner)
+		}
+		tag, err := tx.Exec(ctx, step.cmd, args...)
+		if err != nil {
+			tracing.Log(ctx, r.imp.log).Error(constants.DBError, zap.String("stage", r.st.name), zap.Error(err))
+			return db.Error(ctx, err)
+		}
+		if step.imported {
+			imported += tag.RowsAffected()
+		}
+		if step.mapped {
+			mapped += tag.RowsAffected()
+		}
+	}
+
+	if _, err = tx.Exec(ctx, saveCheckpointCmd, r.name, r.st.name, position); err != nil {
+		tracing.Log(ctx, r.imp.log).Error(constants.DBError, zap.Error(err))
+		return db.Error(ctx, err)
+	}
+	if err = tx.Commit(ctx); err != nil {
+		tracing.Log(ctx, r.imp.log).Error(constants.DBError, zap.Error(err))
+		return db.Error(ctx, err)
+	}
+
+	r.report.Read += n
+	r.report.Imported += imported
+	r.report.Matched += mapped - imported
+	r.report.Rejected += n - mapped
+	r.rows = r.rows[:0]
+	return nil
+}
+
+var nicknameChars = regexp.MustCompile(`[^A-Za-z0-9_.]+`)
+
+// nickname keeps the characters allowed in nicknames. Collisions with
+// existing users get the legacy id as a suffix on insert.
+func nickname(u *User) string {
+	name := nicknameChars.ReplaceAllString(u.Username, "_")
+	if strings.Trim(name, "_.") == "" {
+		return "user_" + strconv.FormatInt(u.ID, 10)
+	}
+	return name
+}
+
+// email invents a unique address for accounts without one, users.email is
+// required.
+func email(source string, u *User) string {
+	if u.Email != "" {
+		return u.Email
+	}
+	return fmt.Sprintf("%s+%d@%s.invalid", nickname(u), u.ID, slugify(source, 0))
+}
+
+var slugChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+func slugify(name string, id int64) string {
+	slug := strings.Trim(slugChars.ReplaceAllString(strings.ToLower(name), "-"), "-")
+	if slug == "" {
+		return "forum-" + strconv.FormatInt(id, 10)
+	}
+	return slug
+}
@@ -0,0 +1,162 @@
+package legacy
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// PhpBBSource reads CSV files exported from the phpBB tables, each with a
+// header row: users.csv, forums.csv, topics.csv and posts.csv. MySQL NULLs
+// (\N) read as empty values. phpBB posts are flat, so ReplyTo is always 0.
+type PhpBBSource struct {
+	dir string
+}
+
+var phpBBFiles = map[string][]string{
+	"users.csv":  {"user_id", "username", "user_email"},
+	"forums.csv": {"forum_id", "forum_name"},
+	"topics.csv": {"topic_id", "forum_id", "topic_title", "topic_poster", "topic_time", "topic_first_post_id"},
+	"posts.csv":  {"post_id", "topic_id", "poster_id", "post_time", "post_text"},
+}
+
+// anonymousUserID is the phpBB guest account, its posts go to the owner.
+const anonymousUserID = 1
+
+func NewPhpBBSource(dir string) (*PhpBBSource, error) {
+	for name := range phpBBFiles {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			return nil, err
+		}
+	}
+	return &PhpBBSource{dir: dir}, nil
+}
+
+func (s *PhpBBSource) Users(fn func(*User) error) error {
+	return s.each("users.csv", func(row *csvRow) error {
+		u := &User{ID: row.int("user_id"), Username: row.str("username"), Email: row.str("user_email")}
+		if row.err != nil || u.ID == anonymousUserID {
+			return row.err
+		}
+		return fn(u)
+	})
+}
+
+func (s *PhpBBSource) Categories(fn func(*Category) error) error {
+	return s.each("forums.csv", func(row *csvRow) error {
+		c := &Category{ID: row.int("forum_id"), Name: row.str("forum_name")}
+		if row.err != nil {
+			return row.err
+		}
+		return fn(c)
+	})
+}
+
+func (s *PhpBBSource) Topics(fn func(*Topic) error) error {
+	return s.each("topics.csv", func(row *csvRow) error {
+		t := &Topic{
+			ID:          row.int("topic_id"),
+			CategoryID:  row.int("forum_id"),
+			UserID:      row.int("topic_poster"),
+			Title:       row.str("topic_title"),
+			Created:     row.time("topic_time"),
+			FirstPostID: row.int("topic_first_post_id"),
+		}
+		if row.err != nil {
+			return row.err
+		}
+		return fn(t)
+	})
+}
+
+func (s *PhpBBSource) Posts(fn func(*Post) error) error {
+	return s.each("posts.csv", func(row *csvRow) error {
+		p := &Post{
+			ID:      row.int("post_id"),
+			TopicID: row.int("topic_id"),
+			UserID:  row.int("poster_id"),
+			Text:    row.str("post_text"),
+			Created: row.time("post_time"),
+		}
+		if row.err != nil {
+			return row.err
+		}
+		return fn(p)
+	})
+}
+
+func (s *PhpBBSource) each(name string, fn func(row *csvRow) error) error {
+	f, err := os.Open(filepath.Join(s.dir, name))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	r.ReuseRecord = true
+	header, err := r.Read()
+	if err != nil {
+		return fmt.Errorf("phpbb: %s: header: %w", name, err)
+	}
+	row := &csvRow{columns: make(map[string]int, len(header))}
+	for i, column := range header {
+		row.columns[column] = i
+	}
+	for _, column := range phpBBFiles[name] {
+		if _, ok := row.columns[column]; !ok {
+			return fmt.Errorf("phpbb: %s: missing column %s", name, column)
+		}
+	}
+
+	for {
+		row.values, err = r.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("phpbb: %s: %w", name, err)
+		}
+		row.err = nil
+		if err = fn(row); err != nil {
+			line, _ := r.FieldPos(0)
+			return fmt.Errorf("phpbb: %s: line %d: %w", name, line, err)
+		}
+	}
+}
+
+// csvRow reads columns by name and keeps the first conversion error.
+type csvRow struct {
+	columns map[string]int
+	values  []string
+	err     error
+}
+
+func (r *csvRow) str(column string) string {
+	i, ok := r.columns[column]
+	if !ok || i >= len(r.values) || r.values[i] == `\N` {
+		return ""
+	}
+	return r.values[i]
+}
+
+func (r *csvRow) int(column string) int64 {
+	value := r.str(column)
+	if value == "" {
+		return 0
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil && r.err == nil {
+		r.err = fmt.Errorf("%s: %w", column, err)
+	}
+	return n
+}
+
+// time reads a unix timestamp, the way phpBB stores dates.
+func (r *csvRow) time(column string) time.Time {
+	return time.Unix(r.int(column), 0).UTC()
+}
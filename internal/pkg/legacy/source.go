@@ -0,0 +1,48 @@
+// Package legacy imports communities exported from phpBB and Discourse.
+package legacy
+
+import "time"
+
+// Source reads an export. Every call reads its records from the start, in the
+// same order, so a checkpoint can be resumed by skipping records.
+type Source interface {
+	Users(fn func(*User) error) error
+	Categories(fn func(*Category) error) error
+	Topics(fn func(*Topic) error) error
+	Posts(fn func(*Post) error) error
+}
+
+type User struct {
+	ID       int64
+	Username string
+	Name     string
+	Email    string
+}
+
+type Category struct {
+	ID   int64
+	Name string
+	Slug string
+}
+
+type Topic struct {
+	ID         int64
+	CategoryID int64
+	UserID     int64
+	Title      string
+	Created    time.Time
+	// FirstPostID is set by sources without post numbers.
+	FirstPostID int64
+}
+
+type Post struct {
+	ID      int64
+	TopicID int64
+	UserID  int64
+	// Number is the position in the topic, the first post becomes the thread
+	// message. ReplyTo is the number of the parent post, 0 for none.
+	Number  int
+	ReplyTo int
+	Text    string
+	Created time.Time
+}
@@ -0,0 +1,225 @@
+package legacy
+
+// stage loads one record type. Records are copied into a session staging
+// table and moved into the forum tables by steps, which get the import name
+// as $1 and, when withOwner is set, the owner nickname as $2.
+type stage struct {
+	name    string
+	table   string
+	columns []string
+	create  string
+	steps   []step
+}
+
+type step struct {
+	cmd       string
+	withOwner bool
+	// imported and mapped select what the affected rows count as.
+	imported bool
+	mapped   bool
+}
+
+const matchUsersCmd = `
+INSERT INTO legacy_users (source, legacy_id, nickname)
+SELECT $1, s.legacy_id, u.nickname
+FROM legacy_stage_users s
+         JOIN users u ON u.email = s.email::citext
+ON CONFLICT DO NOTHING;`
+
+var usersStage = &stage{
+	name:    "users",
+	table:   "legacy_stage_users",
+	columns: []string{"legacy_id", "nickname", "fullname", "email"},
+	create: `
+CREATE TEMP TABLE IF NOT EXISTS legacy_stage_users
+(
+    legacy_id bigint,
+    nickname  text,
+    fullname  text,
+    email     text
+) ON COMMIT DELETE ROWS;
+TRUNCATE legacy_stage_users;`,
+	steps: []step{
+		// Users are matched on email first.
+		{cmd: matchUsersCmd, mapped: true},
+		{cmd: `
+WITH candidates AS (SELECT s.legacy_id,
+                           CASE
+                               WHEN EXISTS(SELECT 1 FROM users WHERE nickname = s.nickname::citext)
+                                   OR count(*) OVER (PARTITION BY lower(s.nickname)) > 1
+                                   THEN s.nickname || '_' || s.legacy_id
+                               ELSE s.nickname END AS nickname,
+                           s.fullname,
+                           s.email
+                    FROM legacy_stage_users s
+                    WHERE NOT EXISTS(SELECT 1
+                                     FROM legacy_users l
+                                     WHERE l.source = $1
+                                       AND l.legacy_id = s.legacy_id)),
+     created AS (
+         INSERT INTO users (nickname, fullname, about, email)
+             SELECT nickname, fullname, '', email
+             FROM candidates
+             ON CONFLICT DO NOTHING
+             RETURNING nickname)
+INSERT
+INTO legacy_users (source, legacy_id, nickname)
+SELECT $1, c.legacy_id, created.nickname
+FROM candidates c
+         JOIN created ON created.nickname = c.nickname::citext;`, imported: true, mapped: true},
+		// Accounts sharing an email within the batch end up as one user.
+		{cmd: matchUsersCmd, mapped: true},
+	},
+}
+
+// Categories with the slug of an existing forum are merged into it.
+var categoriesStage = &stage{
+	name:    "categories",
+	table:   "legacy_stage_categories",
+	columns: []string{"legacy_id", "slug", "title"},
+	create: `
+CREATE TEMP TABLE IF NOT EXISTS legacy_stage_categories
+(
+    legacy_id bigint,
+    slug      text,
+    title     text
+) ON COMMIT DELETE ROWS;
+TRUNCATE legacy_stage_categories;`,
+	steps: []step{
+		{cmd: `
+INSERT INTO forums (slug, title, user_nickname)
+SELECT DISTINCT ON (lower(s.slug)) s.slug, s.title, $2
+FROM legacy_stage_categories s
+WHERE NOT EXISTS(SELECT 1 FROM legacy_forums l WHERE l.source = $1 AND l.legacy_id = s.legacy_id)
+ON CONFLICT DO NOTHING;`, withOwner: true, imported: true},
+		{cmd: `
+INSERT INTO legacy_forums (source, legacy_id, slug)
+SELECT $1, s.legacy_id, f.slug
+FROM legacy_stage_categories s
+         JOIN forums f ON f.slug = s.slug::citext
+ON CONFLICT DO NOTHING;`, mapped: true},
+	},
+}
+
+// New ids follow the legacy order, so listings sorted by id keep it.
+var topicsStage = &stage{
+	name:    "topics",
+	table:   "legacy_stage_topics",
+	columns: []string{"legacy_id", "category_id", "user_id", "title", "created", "first_post"},
+	create: `
+CREATE TEMP TABLE IF NOT EXISTS legacy_stage_topics
+(
+    legacy_id   bigint,
+    category_id bigint,
+    user_id     bigint,
+    title       text,
+    created     timestamp with time zone,
+    first_post  bigint,
+    id          bigint
+) ON COMMIT DELETE ROWS;
+TRUNCATE legacy_stage_topics;`,
+	steps: []step{
+		{cmd: `
+UPDATE legacy_stage_topics s
+SET id = m.id
+FROM (SELECT legacy_id, nextval(pg_get_serial_sequence('threads', 'id')) AS id
+      FROM (SELECT o.legacy_id
+            FROM legacy_stage_topics o
+            WHERE NOT EXISTS(SELECT 1 FROM legacy_threads l WHERE l.source = $1 AND l.legacy_id = o.legacy_id)
+            ORDER BY o.legacy_id) ordered) m
+WHERE s.legacy_id = m.legacy_id;`},
+		// The message is filled in from the first post.
+		{cmd: `
+WITH created AS (
+    INSERT INTO threads (id, title, author, forum, message, created)
+        SELECT s.id, s.title, coalesce(u.nickname, $2), f.slug, '', s.created
+        FROM legacy_stage_topics s
+                 JOIN legacy_forums f ON f.source = $1 AND f.legacy_id = s.category_id
+                 LEFT JOIN legacy_users u ON u.source = $1 AND u.legacy_id = s.user_id
+        WHERE s.id IS NOT NULL
+        ORDER BY s.id
+        RETURNING id)
+INSERT
+INTO legacy_threads (source, legacy_id, id, first_post)
+SELECT $1, s.legacy_id, s.id, nullif(s.first_post, 0)
+FROM legacy_stage_topics s
+         JOIN created ON created.id = s.id;`, withOwner: true, imported: true, mapped: true},
+	},
+}
+
+// Parents are looked up by post number among earlier batches and the current
+// one. Replies to the first post or to missing posts become top-level posts.
+var postsStage = &stage{
+	name:    "posts",
+	table:   "legacy_stage_posts",
+	columns: []string{"legacy_id", "topic_id", "user_id", "number", "reply_to", "message", "created"},
+	create: `
+CREATE TEMP TABLE IF NOT EXISTS legacy_stage_posts
+(
+    legacy_id bigint,
+    topic_id  bigint,
+    user_id   bigint,
+    number    int,
+    reply_to  int,
+    message   text,
+    created   timestamp with time zone,
+    id        bigint,
+    first     boolean
+) ON COMMIT DELETE ROWS;
+TRUNCATE legacy_stage_posts;`,
+	steps: []step{
+		{cmd: `
+UPDATE legacy_stage_posts s
+SET first = (s.number = 1 OR coalesce(s.legacy_id = l.first_post, false))
+FROM legacy_threads l
+WHERE l.source = $1
+  AND l.legacy_id = s.topic_id;`},
+		{cmd: `
+UPDATE threads t
+SET message = s.message
+FROM legacy_stage_posts s
+         JOIN legacy_threads l ON l.source = $1 AND l.legacy_id = s.topic_id
+WHERE t.id = l.id
+  AND s.first;`, imported: true, mapped: true},
+		{cmd: `
+UPDATE legacy_stage_posts s
+SET id = m.id
+FROM (SELECT legacy_id, nextval(pg_get_serial_sequence('posts', 'id')) AS id
+      FROM (SELECT o.legacy_id
+            FROM legacy_stage_posts o
+            WHERE NOT o.first
+              AND NOT EXISTS(SELECT 1 FROM legacy_posts l WHERE l.source = $1 AND l.legacy_id = o.legacy_id)
+            ORDER BY o.legacy_id) ordered) m
+WHERE s.legacy_id = m.legacy_id;`},
+		{cmd: `
+WITH created AS (
+    INSERT INTO posts (id, parent, author, message, thread, forum, created)
+        SELECT s.id,
+               coalesce(parent.id, batch_parent.id, 0),
+               coalesce(u.nickname, $2),
+               s.message,
+               l.id,
+               t.forum,
+               s.created
+        FROM legacy_stage_posts s
+                 JOIN legacy_threads l ON l.source = $1 AND l.legacy_id = s.topic_id
+                 JOIN threads t ON t.id = l.id
+                 LEFT JOIN legacy_users u ON u.source = $1 AND u.legacy_id = s.user_id
+                 LEFT JOIN legacy_posts parent ON s.reply_to > 0
+            AND parent.source = $1
+            AND parent.topic_id = s.topic_id
+            AND parent.number = s.reply_to
+                 LEFT JOIN legacy_stage_posts batch_parent ON s.reply_to > 0
+            AND batch_parent.topic_id = s.topic_id
+            AND batch_parent.number = s.reply_to
+            AND batch_parent.id < s.id
+        WHERE s.id IS NOT NULL
+        ORDER BY s.id
+        RETURNING id)
+INSERT
+INTO legacy_posts (source, legacy_id, id, topic_id, number)
+SELECT $1, s.legacy_id, s.id, s.topic_id, nullif(s.number, 0)
+FROM legacy_stage_posts s
+         JOIN created ON created.id = s.id;`, withOwner: true, imported: true, mapped: true},
+	},
+}
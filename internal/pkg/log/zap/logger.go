@@ -0,0 +1,29 @@
+package zap
+
+import (
+	"os"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// NewLogger creates a stdout logger with the given level (debug, info, warn,
+// error) and format (console or json).
+func NewLogger(level, format string) (*zap.Logger, error) {
+	lvl, err := zapcore.ParseLevel(level)
+	if err != nil {
+		return nil, err
+	}
+
+	var encoder zapcore.Encoder
+	if format == "json" {
+		cfg := ProdConfig()
+		cfg.EncodeLevel = zapcore.LowercaseLevelEncoder
+		encoder = zapcore.NewJSONEncoder(cfg)
+	} else {
+		encoder = zapcore.NewConsoleEncoder(ProdConfig())
+	}
+
+	core := zapcore.NewCore(encoder, zapcore.Lock(os.Stdout), lvl)
+	return zap.New(core, zap.AddCaller(), zap.AddStacktrace(zapcore.ErrorLevel)), nil
+}
@@ -0,0 +1,157 @@
+package memory
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SlavaShagalov/vk-dbms-project/internal/models"
+)
+
+// Store holds the forum data for the memory repositories. Every repository
+// takes the lock for the whole call, so a call is atomic like a single
+// PostgreSQL statement. Map keys are lowercased to mirror the citext columns.
+type Store struct {
+	sync.RWMutex
+
+	userSeq   int
+	forumSeq  int64
+	threadSeq int
+	postSeq   int
+	version   time.Time
+
+	Users       map[string]*models.User
+	Emails      map[string]*models.User
+	Forums      map[string]*models.Forum
+	Threads     map[int]*Thread
+	ThreadSlugs map[string]*Thread
+	Posts       map[int]*Post
+	Votes       map[VoteKey]int
+	// ForumUsers keeps a copy of the user taken when they first wrote in the
+	// forum, like the forum_users table.
+	ForumUsers map[string]map[string]models.User
+}
+
+type Thread struct {
+	models.Thread
+	SlowMode int
+	Locked   bool
+	// Posts are ordered by id.
+	Posts []*Post
+}
+
+type Post struct {
+	models.Post
+	Path []int
+}
+
+type VoteKey struct {
+	Thread   int
+	Nickname string
+}
+
+func NewStore() *Store {
+	s := &Store{}
+	s.reset()
+	return s
+}
+
+// Clear removes all data, the sequences keep counting like after TRUNCATE.
+func (s *Store) Clear() {
+	s.Lock()
+	defer s.Unlock()
+	s.reset()
+}
+
+func (s *Store) reset() {
+	s.Users = make(map[string]*models.User)
+	s.Emails = make(map[string]*models.User)
+	s.Forums = make(map[string]*models.Forum)
+	s.Threads = make(map[int]*Thread)
+	s.ThreadSlugs = make(map[string]*Thread)
+	s.Posts = make(map[int]*Post)
+	s.Votes = make(map[VoteKey]int)
+	s.ForumUsers = make(map[string]map[string]models.User)
+}
+
+func Key(s string) string {
+	return strings.ToLower(s)
+}
+
+func (s *Store) NextUserID() int {
+	s.userSeq++
+	return s.userSeq
+}
+
+func (s *Store) NextForumID() int64 {
+	s.forumSeq++
+	return s.forumSeq
+}
+
+func (s *Store) NextThreadID() int {
+	s.threadSeq++
+	return s.threadSeq
+}
+
+func (s *Store) NextPostID() int {
+	s.postSeq++
+	return s.postSeq
+}
+
+// Version stamps a written row like the updated_at trigger. Versions have
+// microsecond precision like timestamptz and always grow, so two writes in a
+// row never share an ETag.
+func (s *Store) Version() time.Time {
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	if !now.After(s.version) {
+		now = s.version.Add(time.Microsecond)
+	}
+	s.version = now
+	return now
+}
+
+// Thread finds a thread by id or slug like the pgx repositories do.
+func (s *Store) Thread(slugOrId string) (*Thread, bool) {
+	if id, err := strconv.Atoi(slugOrId); err == nil {
+		thread, ok := s.Threads[id]
+		return thread, ok
+	}
+	thread, ok := s.ThreadSlugs[Key(slugOrId)]
+	return thread, ok
+}
+
+// AddForumUser mirrors the update_forum_users trigger.
+func (s *Store) AddForumUser(forum, nickname string) {
+	user, ok := s.Users[Key(nickname)]
+	if !ok {
+		return
+	}
+
+	users, ok := s.ForumUsers[Key(forum)]
+	if !ok {
+		users = make(map[string]models.User)
+		s.ForumUsers[Key(forum)] = users
+	}
+	if _, ok = users[Key(nickname)]; !ok {
+		users[Key(nickname)] = models.User{
+			Nickname: user.Nickname,
+			Fullname: user.Fullname,
+			About:    user.About,
+			Email:    user.Email,
+		}
+	}
+}
+
+// ComparePaths orders materialized paths like PostgreSQL compares bigint arrays.
+func ComparePaths(a, b []int) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			if a[i] < b[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return len(a) - len(b)
+}
@@ -0,0 +1,201 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "forum"
+
+// Entities counted by AddCreated
+const (
+	EntityPost   = "post"
+	EntityThread = "thread"
+	EntityVote   = "vote"
+)
+
+// Metrics holds the backend collectors. A nil *Metrics is valid and records nothing.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	requests *prometheus.CounterVec
+	latency  *prometheus.HistogramVec
+	inFlight prometheus.Gauge
+	queries  *prometheus.HistogramVec
+	created  *prometheus.CounterVec
+
+	grpcRequests *prometheus.CounterVec
+	grpcLatency  *prometheus.HistogramVec
+	grpcInFlight prometheus.Gauge
+
+	cacheLookups       *prometheus.CounterVec
+	cacheInvalidations *prometheus.CounterVec
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "requests_total",
+			Help:      "HTTP requests by route, method and status code.",
+		}, []string{"route", "method", "code"}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "HTTP request latency by route and method.",
+			Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5},
+		}, []string{"route", "method"}),
+		inFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "requests_in_flight",
+			Help:      "HTTP requests being served.",
+		}),
+		grpcRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "grpc",
+			Name:      "requests_total",
+			Help:      "gRPC calls by method and status code.",
+		}, []string{"method", "code"}),
+		grpcLatency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "grpc",
+			Name:      "request_duration_seconds",
+			Help:      "gRPC call latency by method.",
+			Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5},
+		}, []string{"method"}),
+		grpcInFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "grpc",
+			Name:      "requests_in_flight",
+			Help:      "gRPC calls being served.",
+		}),
+		queries: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "repository",
+			Name:      "query_duration_seconds",
+			Help:      "Repository method duration by repository, method and result.",
+			Buckets:   []float64{.0001, .0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 5},
+		}, []string{"repository", "method", "result"}),
+		created: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "created_total",
+			Help:      "Posts, threads and votes created.",
+		}, []string{"entity"}),
+		cacheLookups: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "cache",
+			Name:      "lookups_total",
+			Help:      "Cache lookups by cache and result, hit or miss.",
+		}, []string{"cache", "result"}),
+		cacheInvalidations: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "cache",
+			Name:      "invalidations_total",
+			Help:      "Dropped cache keys by source, write or notify.",
+		}, []string{"source"}),
+	}
+
+	m.registry.MustRegister(
+		m.requests,
+		m.latency,
+		m.inFlight,
+		m.grpcRequests,
+		m.grpcLatency,
+		m.grpcInFlight,
+		m.queries,
+		m.created,
+		m.cacheLookups,
+		m.cacheInvalidations,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return m
+}
+
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+func (m *Metrics) Register(collector prometheus.Collector) {
+	if m == nil {
+		return
+	}
+	m.registry.MustRegister(collector)
+}
+
+func (m *Metrics) RequestStarted() {
+	if m == nil {
+		return
+	}
+	m.inFlight.Inc()
+}
+
+func (m *Metrics) RequestFinished(route, method string, code int, duration time.Duration) {
+	if m == nil {
+		return
+	}
+	m.inFlight.Dec()
+	m.requests.WithLabelValues(route, method, strconv.Itoa(code)).Inc()
+	m.latency.WithLabelValues(route, method).Observe(duration.Seconds())
+}
+
+func (m *Metrics) GRPCRequestStarted() {
+	if m == nil {
+		return
+	}
+	m.grpcInFlight.Inc()
+}
+
+func (m *Metrics) GRPCRequestFinished(method, code string, duration time.Duration) {
+	if m == nil {
+		return
+	}
+	m.grpcInFlight.Dec()
+	m.grpcRequests.WithLabelValues(method, code).Inc()
+	m.grpcLatency.WithLabelValues(method).Observe(duration.Seconds())
+}
+
+func (m *Metrics) ObserveQuery(repository, method string, start time.Time, err error) {
+	if m == nil {
+		return
+	}
+	result := "ok"
+	if err != nil {
+		result = "error"
+	}
+	m.queries.WithLabelValues(repository, method, result).Observe(time.Since(start).Seconds())
+}
+
+func (m *Metrics) AddCreated(entity string, count int) {
+	if m == nil || count <= 0 {
+		return
+	}
+	m.created.WithLabelValues(entity).Add(float64(count))
+}
+
+func (m *Metrics) ObserveCache(cache string, hit bool) {
+	if m == nil {
+		return
+	}
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	m.cacheLookups.WithLabelValues(cache, result).Inc()
+}
+
+func (m *Metrics) AddCacheInvalidations(source string, count int) {
+	if m == nil || count <= 0 {
+		return
+	}
+	m.cacheInvalidations.WithLabelValues(source).Add(float64(count))
+}
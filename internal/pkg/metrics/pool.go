@@ -0,0 +1,65 @@
+package metrics
+
+import (
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type poolCollector struct {
+	pool *pgxpool.Pool
+
+	acquired        *prometheus.Desc
+	idle            *prometheus.Desc
+	constructing    *prometheus.Desc
+	total           *prometheus.Desc
+	max             *prometheus.Desc
+	acquireCount    *prometheus.Desc
+	acquireDuration *prometheus.Desc
+	emptyAcquire    *prometheus.Desc
+	canceledAcquire *prometheus.Desc
+}
+
+// NewPoolCollector exports pgxpool statistics.
+func NewPoolCollector(pool *pgxpool.Pool) prometheus.Collector {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "db_pool", name), help, nil, nil)
+	}
+
+	return &poolCollector{
+		pool:            pool,
+		acquired:        desc("acquired_conns", "Connections currently acquired."),
+		idle:            desc("idle_conns", "Idle connections."),
+		constructing:    desc("constructing_conns", "Connections being established."),
+		total:           desc("total_conns", "Total connections."),
+		max:             desc("max_conns", "Maximum pool size."),
+		acquireCount:    desc("acquires_total", "Successful acquires."),
+		acquireDuration: desc("acquire_wait_seconds_total", "Total time spent waiting for a connection."),
+		emptyAcquire:    desc("empty_acquires_total", "Acquires that had to wait for a connection."),
+		canceledAcquire: desc("canceled_acquires_total", "Acquires canceled by their context."),
+	}
+}
+
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.acquired
+	ch <- c.idle
+	ch <- c.constructing
+	ch <- c.total
+	ch <- c.max
+	ch <- c.acquireCount
+	ch <- c.acquireDuration
+	ch <- c.emptyAcquire
+	ch <- c.canceledAcquire
+}
+
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	stat := c.pool.Stat()
+	ch <- prometheus.MustNewConstMetric(c.acquired, prometheus.GaugeValue, float64(stat.AcquiredConns()))
+	ch <- prometheus.MustNewConstMetric(c.idle, prometheus.GaugeValue, float64(stat.IdleConns()))
+	ch <- prometheus.MustNewConstMetric(c.constructing, prometheus.GaugeValue, float64(stat.ConstructingConns()))
+	ch <- prometheus.MustNewConstMetric(c.total, prometheus.GaugeValue, float64(stat.TotalConns()))
+	ch <- prometheus.MustNewConstMetric(c.max, prometheus.GaugeValue, float64(stat.MaxConns()))
+	ch <- prometheus.MustNewConstMetric(c.acquireCount, prometheus.CounterValue, float64(stat.AcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.acquireDuration, prometheus.CounterValue, stat.AcquireDuration().Seconds())
+	ch <- prometheus.MustNewConstMetric(c.emptyAcquire, prometheus.CounterValue, float64(stat.EmptyAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.canceledAcquire, prometheus.CounterValue, float64(stat.CanceledAcquireCount()))
+}
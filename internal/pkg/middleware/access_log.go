@@ -1,19 +1,65 @@
 package middleware
 
 import (
+	"math"
+	"math/rand"
 	"net/http"
+	"sync/atomic"
+	"time"
 
 	"github.com/julienschmidt/httprouter"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
+
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/tracing"
 )
 
+// accessSampleRate is the share of successful GET requests that are logged, stored as float64 bits.
+var accessSampleRate atomic.Uint64
+
+func init() {
+	SetAccessLogSampling(1)
+}
+
+// SetAccessLogSampling logs only the given fraction of successful GET requests.
+// Other methods and failed requests are always logged.
+func SetAccessLogSampling(rate float64) {
+	accessSampleRate.Store(math.Float64bits(rate))
+}
+
+// AccessLog assigns the request id and logs the request once the handler has finished.
 func AccessLog(handler httprouter.Handle, log *zap.Logger) httprouter.Handle {
 	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
-		log.Info("New request",
+		start := time.Now()
+
+		id := tracing.NewRequestID(r.Header.Get(tracing.RequestIDHeader))
+		w.Header().Set(tracing.RequestIDHeader, id)
+		ctx := tracing.WithRequestID(r.Context(), id)
+		trace.SpanFromContext(ctx).SetAttributes(attribute.String("http.request_id", id))
+
+		rw := wrapResponseWriter(w)
+		handler(rw, r.WithContext(ctx), p)
+
+		status := rw.Status()
+		if r.Method == http.MethodGet && status < http.StatusBadRequest && !sampled() {
+			return
+		}
+
+		tracing.Log(ctx, log).Info("Request",
 			zap.String("method", r.Method),
 			zap.String("url", r.URL.String()),
 			zap.String("protocol", r.Proto),
+			zap.Int("status", status),
+			zap.Int("size", rw.size),
+			zap.Duration("latency", time.Since(start)),
+			zap.String("remote_addr", remoteHost(r)),
+			zap.String("user", rw.user),
 			zap.String("origin", r.Header.Get("Origin")))
-		handler(w, r, p)
 	}
 }
+
+func sampled() bool {
+	rate := math.Float64frombits(accessSampleRate.Load())
+	return rate >= 1 || rand.Float64() < rate
+}
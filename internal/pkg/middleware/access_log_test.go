@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// wrapper stands for a writer that hides the access log one, like compressWriter.
+type wrapper struct {
+	http.ResponseWriter
+}
+
+func (w wrapper) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func TestAccessLogUser(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	handler := AccessLog(func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		SetUser(wrapper{w}, "alice", "bob", "", "Alice")
+		w.WriteHeader(http.StatusCreated)
+	}, zap.New(core))
+
+	handler(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/api/thread/1/create", nil), nil)
+
+	entries := logs.FilterMessage("Request").All()
+	if len(entries) != 1 {
+		t.Fatalf("%d request entries, want 1", len(entries))
+	}
+	if user := entries[0].ContextMap()["user"]; user != "alice,bob" {
+		t.Fatalf("user %q, want %q", user, "alice,bob")
+	}
+}
@@ -0,0 +1,299 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	EncodingBrotli = "br"
+	EncodingZstd   = "zstd"
+	EncodingGzip   = "gzip"
+)
+
+type CompressOptions struct {
+	// MinSize is the smallest body that is compressed, smaller ones are not
+	// worth the CPU and the header overhead.
+	MinSize int
+	// Encodings in the order of preference when the client accepts several
+	// with the same quality.
+	Encodings   []string
+	GzipLevel   int
+	BrotliLevel int
+	// ZstdLevel is a zstd.EncoderLevel, 1 is the fastest and 4 the best.
+	ZstdLevel int
+}
+
+// compressibleTypes are the media types worth compressing, images and other
+// compressed formats are sent as is.
+var compressibleTypes = []string{
+	"application/json",
+	"application/x-ndjson",
+	"application/problem+json",
+	"application/javascript",
+	"application/xml",
+	"application/msgpack",
+	"application/x-protobuf",
+	"image/svg+xml",
+	"text/",
+}
+
+// encoder is a pooled compressor reset onto each response.
+type encoder interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+// Compress encodes responses with the best encoding the client accepts. Bodies
+// below MinSize, responses already encoded by the handler and media types
+// that do not compress are passed through. Strong ETags get the encoding as a
+// suffix, since the compressed bytes differ, and the suffix is stripped from
+// If-None-Match before the handler compares it.
+func Compress(handler http.Handler, opts CompressOptions) http.Handler {
+	pools := make(map[string]*sync.Pool, len(opts.Encodings))
+	for _, encoding := range opts.Encodings {
+		var newEncoder func() encoder
+		switch encoding {
+		case EncodingGzip:
+			level := opts.GzipLevel
+			newEncoder = func() encoder {
+				w, _ := gzip.NewWriterLevel(io.Discard, level)
+				return w
+			}
+		case EncodingBrotli:
+			level := opts.BrotliLevel
+			newEncoder = func() encoder { return brotli.NewWriterLevel(io.Discard, level) }
+		case EncodingZstd:
+			level := zstd.EncoderLevel(opts.ZstdLevel)
+			newEncoder = func() encoder {
+				w, _ := zstd.NewWriter(io.Discard, zstd.WithEncoderLevel(level),
+					zstd.WithEncoderConcurrency(1), zstd.WithLowerEncoderMem(true))
+				return w
+			}
+		default:
+			continue
+		}
+		pools[encoding] = &sync.Pool{New: func() interface{} { return newEncoder() }}
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		encoding := negotiateEncoding(r.Header.Get("Accept-Encoding"), opts.Encodings, pools)
+		if encoding == "" || r.Method == http.MethodHead {
+			w.Header().Add("Vary", "Accept-Encoding")
+			handler.ServeHTTP(w, r)
+			return
+		}
+
+		cw := &compressWriter{
+			ResponseWriter: w,
+			encoding:       encoding,
+			pool:           pools[encoding],
+			minSize:        opts.MinSize,
+		}
+		if match := r.Header.Get("If-None-Match"); match != "" {
+			if stripped := stripETagSuffix(match, encoding); stripped != match {
+				r.Header.Set("If-None-Match", stripped)
+				cw.suffixed = true
+			}
+		}
+		defer cw.close()
+		handler.ServeHTTP(cw, r)
+	})
+}
+
+// negotiateEncoding picks the supported encoding with the highest quality in
+// Accept-Encoding, ties go to the first one in the preference list.
+func negotiateEncoding(header string, preference []string, pools map[string]*sync.Pool) string {
+	if header == "" {
+		return ""
+	}
+	qualities := make(map[string]float64)
+	wildcard := -1.0
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if name == "*" {
+			wildcard = q
+		} else {
+			qualities[name] = q
+		}
+	}
+
+	best, bestQ := "", 0.0
+	for _, encoding := range preference {
+		if pools[encoding] == nil {
+			continue
+		}
+		q, ok := qualities[encoding]
+		if !ok {
+			q = wildcard
+		}
+		if q > bestQ {
+			best, bestQ = encoding, q
+		}
+	}
+	return best
+}
+
+// compressWriter holds the body until MinSize bytes decide whether it is
+// compressed, then streams it through a pooled encoder.
+type compressWriter struct {
+	http.ResponseWriter
+	encoding string
+	pool     *sync.Pool
+	minSize  int
+	suffixed bool
+
+	status  int
+	buf     bytes.Buffer
+	decided bool
+	enc     encoder
+}
+
+func (w *compressWriter) WriteHeader(status int) {
+	if w.status != 0 {
+		return
+	}
+	w.status = status
+	// Responses without a body are never compressed.
+	if status < http.StatusOK || status == http.StatusNoContent || status == http.StatusNotModified {
+		_ = w.start(false)
+	}
+}
+
+func (w *compressWriter) Write(data []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	if w.decided {
+		if w.enc != nil {
+			return w.enc.Write(data)
+		}
+		return w.ResponseWriter.Write(data)
+	}
+
+	w.buf.Write(data)
+	if w.buf.Len() >= w.minSize {
+		if err := w.start(w.compressible()); err != nil {
+			return 0, err
+		}
+	}
+	return len(data), nil
+}
+
+// Flush sends what is buffered, a streamed response is compressed whatever its size.
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		if w.status == 0 {
+			w.status = http.StatusOK
+		}
+		if w.start(w.compressible()) != nil {
+			return
+		}
+	}
+	if w.enc != nil && w.enc.Flush() != nil {
+		return
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *compressWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *compressWriter) compressible() bool {
+	h := w.Header()
+	if h.Get("Content-Encoding") != "" {
+		return false
+	}
+	contentType := h.Get("Content-Type")
+	for _, prefix := range compressibleTypes {
+		if strings.HasPrefix(contentType, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// start writes the header and what is buffered, through an encoder when compress is set.
+func (w *compressWriter) start(compress bool) error {
+	w.decided = true
+	h := w.Header()
+	if compress || w.compressible() {
+		h.Add("Vary", "Accept-Encoding")
+	}
+	if compress {
+		h.Del("Content-Length")
+		h.Set("Content-Encoding", w.encoding)
+		if etag := h.Get("ETag"); etag != "" {
+			h.Set("ETag", addETagSuffix(etag, w.encoding))
+		}
+		w.enc = w.pool.Get().(encoder)
+		w.enc.Reset(w.ResponseWriter)
+	} else if w.status == http.StatusNotModified && w.suffixed {
+		// The client revalidates the compressed representation it holds.
+		if etag := h.Get("ETag"); etag != "" {
+			h.Set("ETag", addETagSuffix(etag, w.encoding))
+		}
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+
+	if w.buf.Len() == 0 {
+		return nil
+	}
+	var err error
+	if w.enc != nil {
+		_, err = w.enc.Write(w.buf.Bytes())
+	} else {
+		_, err = w.ResponseWriter.Write(w.buf.Bytes())
+	}
+	w.buf.Reset()
+	return err
+}
+
+func (w *compressWriter) close() {
+	if !w.decided {
+		if w.status == 0 {
+			// The handler wrote nothing, net/http sends 200 with an empty body.
+			return
+		}
+		_ = w.start(false)
+	}
+	if w.enc != nil {
+		_ = w.enc.Close()
+		w.enc.Reset(io.Discard)
+		w.pool.Put(w.enc)
+		w.enc = nil
+	}
+}
+
+// addETagSuffix turns "abc" into "abc-gzip", weak tags keep their prefix.
+func addETagSuffix(etag, encoding string) string {
+	if !strings.HasSuffix(etag, `"`) {
+		return etag
+	}
+	return etag[:len(etag)-1] + "-" + encoding + `"`
+}
+
+func stripETagSuffix(header, encoding string) string {
+	return strings.ReplaceAll(header, "-"+encoding+`"`, `"`)
+}
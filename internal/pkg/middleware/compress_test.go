@@ -0,0 +1,117 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/klauspost/compress/gzip"
+)
+
+func TestNegotiateEncoding(t *testing.T) {
+	preference := []string{EncodingBrotli, EncodingZstd, EncodingGzip}
+	pools := map[string]*sync.Pool{EncodingBrotli: {}, EncodingZstd: {}, EncodingGzip: {}}
+
+	for _, tc := range []struct {
+		header string
+		want   string
+	}{
+		{"", ""},
+		{"identity", ""},
+		{"gzip", EncodingGzip},
+		{"gzip, br", EncodingBrotli},
+		{"GZIP;q=0.9, zstd;q=0.5", EncodingGzip},
+		{"br;q=0, gzip;q=0.1", EncodingGzip},
+		{"*", EncodingBrotli},
+		{"*;q=0.5, br;q=0", EncodingZstd},
+		{"gzip;q=bad, zstd", EncodingZstd},
+		{"deflate", ""},
+	} {
+		if got := negotiateEncoding(tc.header, preference, pools); got != tc.want {
+			t.Errorf("%q: %q, want %q", tc.header, got, tc.want)
+		}
+	}
+
+	if got := negotiateEncoding("br, gzip", preference, map[string]*sync.Pool{EncodingGzip: {}}); got != EncodingGzip {
+		t.Errorf("unconfigured encoding: %q, want gzip", got)
+	}
+}
+
+func TestETagSuffix(t *testing.T) {
+	for _, tc := range []struct {
+		etag string
+		want string
+	}{
+		{`"abc"`, `"abc-gzip"`},
+		{`W/"abc"`, `W/"abc-gzip"`},
+		{`abc`, `abc`},
+	} {
+		if got := addETagSuffix(tc.etag, EncodingGzip); got != tc.want {
+			t.Errorf("add %s: %s, want %s", tc.etag, got, tc.want)
+		}
+	}
+
+	if got := stripETagSuffix(`"abc-gzip", "def-gzip"`, EncodingGzip); got != `"abc", "def"` {
+		t.Errorf("strip: %s", got)
+	}
+	if got := stripETagSuffix(`"abc-br"`, EncodingGzip); got != `"abc-br"` {
+		t.Errorf("strip another encoding: %s", got)
+	}
+}
+
+func TestCompress(t *testing.T) {
+	body := strings.Repeat(`{"message":"hello"}`, 100)
+	handler := Compress(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.Header().Set("ETag", `"v1"`)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Content-Type", r.URL.Query().Get("type"))
+		w.Header().Set("ETag", `"v1"`)
+		if r.URL.Query().Get("empty") == "" {
+			_, _ = io.WriteString(w, body)
+		}
+	}), CompressOptions{MinSize: 100, Encodings: []string{EncodingGzip}, GzipLevel: gzip.BestSpeed})
+
+	serve := func(target, match string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, target, nil)
+		r.Header.Set("Accept-Encoding", "gzip")
+		if match != "" {
+			r.Header.Set("If-None-Match", match)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	w := serve("/?type=application/json", "")
+	if w.Header().Get("Content-Encoding") != EncodingGzip || w.Header().Get("ETag") != `"v1-gzip"` {
+		t.Fatalf("large body: headers %v", w.Header())
+	}
+	reader, err := gzip.NewReader(w.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded, _ := io.ReadAll(reader); string(decoded) != body {
+		t.Fatal("decoded body differs")
+	}
+
+	w = serve("/?type=application/json&empty=1", "")
+	if w.Header().Get("Content-Encoding") != "" || w.Header().Get("ETag") != `"v1"` {
+		t.Fatalf("empty body: headers %v", w.Header())
+	}
+
+	w = serve("/?type=image/png", "")
+	if w.Header().Get("Content-Encoding") != "" || w.Body.String() != body {
+		t.Fatalf("incompressible type: headers %v", w.Header())
+	}
+
+	w = serve("/?type=application/json", `"v1-gzip"`)
+	if w.Code != http.StatusNotModified || w.Header().Get("ETag") != `"v1-gzip"` {
+		t.Fatalf("revalidation: %d, headers %v", w.Code, w.Header())
+	}
+}
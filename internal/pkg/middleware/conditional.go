@@ -0,0 +1,101 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/julienschmidt/httprouter"
+
+	pkgHTTP "github.com/SlavaShagalov/vk-dbms-project/internal/pkg/http"
+)
+
+type cachePolicies struct {
+	fallback string
+	routes   map[string]string
+}
+
+var cacheControl atomic.Pointer[cachePolicies]
+
+// SetCacheControl configures the Cache-Control of successful GET responses.
+// Routes are keyed like in SetTimeouts; an empty policy leaves the header out.
+func SetCacheControl(fallback string, routes map[string]string) {
+	cacheControl.Store(&cachePolicies{fallback: fallback, routes: routes})
+}
+
+// Conditional answers GET requests with 304 when If-None-Match or
+// If-Modified-Since match. Handlers that know the row versions set ETag and
+// Last-Modified themselves, other responses get a strong ETag from the body
+// hash, which still saves the client the transfer.
+func Conditional(handler httprouter.Handle) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			handler(w, r, p)
+			return
+		}
+
+		bw := &bufferedWriter{ResponseWriter: w}
+		handler(bw, r, p)
+
+		if bw.status == http.StatusOK || bw.status == http.StatusNotModified {
+			if policy := cacheControlFor(r); policy != "" {
+				w.Header().Set("Cache-Control", policy)
+			}
+		}
+		if bw.status != http.StatusOK {
+			bw.flush()
+			return
+		}
+
+		etag := w.Header().Get("ETag")
+		if etag == "" {
+			etag = pkgHTTP.BodyETag(bw.body.Bytes())
+			w.Header().Set("ETag", etag)
+		}
+		modified, _ := http.ParseTime(w.Header().Get("Last-Modified"))
+		if pkgHTTP.Fresh(r, etag, modified) {
+			pkgHTTP.WriteNotModified(w)
+			return
+		}
+		bw.flush()
+	}
+}
+
+func cacheControlFor(r *http.Request) string {
+	cfg := cacheControl.Load()
+	if cfg == nil {
+		return ""
+	}
+	if policy, ok := cfg.routes[r.Method+" "+route(r)]; ok {
+		return policy
+	}
+	return cfg.fallback
+}
+
+// bufferedWriter holds the response until the preconditions are evaluated.
+type bufferedWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (w *bufferedWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+func (w *bufferedWriter) Write(data []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.body.Write(data)
+}
+
+func (w *bufferedWriter) flush() {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+	_, _ = w.ResponseWriter.Write(w.body.Bytes())
+}
@@ -0,0 +1,140 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/codec"
+	pkgHTTP "github.com/SlavaShagalov/vk-dbms-project/internal/pkg/http"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/openapi"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/tracing"
+)
+
+// contractMaxBody is the largest request or response body that is validated,
+// bigger ones are passed through unchecked.
+const contractMaxBody = 1 << 20
+
+// ContractReporter receives the violations found for one request.
+type ContractReporter func(r *http.Request, status int, violations []openapi.Violation)
+
+// LogContractViolations logs every violation as a warning.
+func LogContractViolations(log *zap.Logger) ContractReporter {
+	return func(r *http.Request, status int, violations []openapi.Violation) {
+		for _, violation := range violations {
+			tracing.Log(r.Context(), log).Warn("Contract violation",
+				zap.String("method", r.Method),
+				zap.String("url", r.URL.String()),
+				zap.Int("status", status),
+				zap.String("kind", violation.Kind),
+				zap.String("location", violation.Location),
+				zap.String("message", violation.Message))
+		}
+	}
+}
+
+// Contract validates requests and responses of the operations described in
+// the spec. Other routes are served as is.
+func Contract(handler http.Handler, spec *openapi.Spec, report ContractReporter) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, _, ok := spec.Find(r.Method, r.URL.Path); !ok {
+			handler.ServeHTTP(w, r)
+			return
+		}
+
+		var body []byte
+		checkRequest := true
+		if r.Body != nil {
+			var err error
+			body, err = io.ReadAll(io.LimitReader(r.Body, contractMaxBody+1))
+			if err != nil || len(body) > contractMaxBody {
+				checkRequest = false
+			}
+			r.Body = readCloser{io.MultiReader(bytes.NewReader(body), r.Body), r.Body}
+		}
+
+		var violations []openapi.Violation
+		if checkRequest {
+			violations = spec.ValidateRequest(r, body)
+			if pkgHTTP.RequestCodec(r) != codec.JSON {
+				violations = withoutBody(violations)
+			}
+		}
+
+		cw := &contractWriter{ResponseWriter: w}
+		handler.ServeHTTP(cw, r)
+
+		status := cw.Status()
+		if !cw.truncated {
+			responseViolations := spec.ValidateResponse(r.Method, r.URL.Path, status, cw.body.Bytes())
+			if codec.ForContentType(w.Header().Get("Content-Type")) != codec.JSON {
+				responseViolations = withoutBody(responseViolations)
+			}
+			violations = append(violations, responseViolations...)
+		}
+		if len(violations) > 0 {
+			report(r, status, violations)
+		}
+	})
+}
+
+// withoutBody drops the body violations, the schemas describe the JSON form
+// and the other formats are checked by their decoders.
+func withoutBody(violations []openapi.Violation) []openapi.Violation {
+	kept := violations[:0]
+	for _, violation := range violations {
+		if !strings.HasPrefix(violation.Location, "body") {
+			kept = append(kept, violation)
+		}
+	}
+	return kept
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// contractWriter keeps a copy of the response body for validation.
+type contractWriter struct {
+	http.ResponseWriter
+	status    int
+	body      bytes.Buffer
+	truncated bool
+}
+
+func (w *contractWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *contractWriter) Write(data []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	if !w.truncated {
+		if w.body.Len()+len(data) > contractMaxBody {
+			w.truncated = true
+			w.body.Reset()
+		} else {
+			w.body.Write(data)
+		}
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *contractWriter) Status() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
+
+func (w *contractWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
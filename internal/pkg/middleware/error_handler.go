@@ -2,6 +2,8 @@ package middleware
 
 import (
 	pkgErrors "github.com/SlavaShagalov/vk-dbms-project/internal/pkg/errors"
+	pkgHTTP "github.com/SlavaShagalov/vk-dbms-project/internal/pkg/http"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/tracing"
 	"net/http"
 
 	"github.com/julienschmidt/httprouter"
@@ -11,6 +13,7 @@ import (
 
 func HandleError(handler func(w http.ResponseWriter, r *http.Request, p httprouter.Params) error, log *zap.Logger) httprouter.Handle {
 	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		log := tracing.Log(r.Context(), log)
 		defer func() {
 			if err := recover(); err != nil {
 				log.Error("after recover: ", zap.Any("error", err))
@@ -31,10 +34,7 @@ func HandleError(handler func(w http.ResponseWriter, r *http.Request, p httprout
 				log.Info("Response", zap.Int("http_code", httpCode), zap.String("message", err.Error()))
 			}
 
-			response := ErrorResponse{Message: err.Error()}
-			body, _ := response.MarshalJSON()
-			w.WriteHeader(httpCode)
-			_, err = w.Write(body)
+			err = pkgHTTP.WriteResponse(w, r, httpCode, &ErrorResponse{Message: err.Error()})
 			if err != nil {
 				log.Error(err.Error())
 			}
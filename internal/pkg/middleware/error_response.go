@@ -1,7 +1,5 @@
 package middleware
 
-//go:generate easyjson -all -snake_case error_response.go
+import "github.com/SlavaShagalov/vk-dbms-project/internal/models"
 
-type ErrorResponse struct {
-	Message string `json:"message"`
-}
+type ErrorResponse = models.Error
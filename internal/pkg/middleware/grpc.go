@@ -0,0 +1,123 @@
+package middleware
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/runtime/protoiface"
+
+	pkgErrors "github.com/SlavaShagalov/vk-dbms-project/internal/pkg/errors"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/tracing"
+)
+
+// UnaryHandleError is AccessLog and HandleError for the gRPC API: it assigns
+// the request id, recovers panics, turns the pkg/errors into status codes and
+// logs the call.
+func UnaryHandleError(log *zap.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		start := time.Now()
+		ctx = withGRPCRequestID(ctx)
+		defer func() {
+			if p := recover(); p != nil {
+				tracing.Log(ctx, log).Error("after recover: ", zap.Any("error", p))
+				err = pkgErrors.ErrInternal
+			}
+			err = grpcError(ctx, log, info.FullMethod, start, err)
+		}()
+		return handler(ctx, req)
+	}
+}
+
+// StreamHandleError is UnaryHandleError for streaming calls.
+func StreamHandleError(log *zap.Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		start := time.Now()
+		ctx := withGRPCRequestID(ss.Context())
+		defer func() {
+			if p := recover(); p != nil {
+				tracing.Log(ctx, log).Error("after recover: ", zap.Any("error", p))
+				err = pkgErrors.ErrInternal
+			}
+			err = grpcError(ctx, log, info.FullMethod, start, err)
+		}()
+		return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+func withGRPCRequestID(ctx context.Context) context.Context {
+	var header string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(tracing.RequestIDHeader); len(values) > 0 {
+			header = values[0]
+		}
+	}
+	id := tracing.NewRequestID(header)
+	_ = grpc.SetHeader(ctx, metadata.Pairs(strings.ToLower(tracing.RequestIDHeader), id))
+	return tracing.WithRequestID(ctx, id)
+}
+
+// grpcError converts err to a status and logs the call. Statuses set by the
+// handler, like conflicts with details, are kept.
+func grpcError(ctx context.Context, log *zap.Logger, method string, start time.Time, err error) error {
+	log = tracing.Log(ctx, log)
+
+	code := codes.OK
+	if err != nil {
+		if st, ok := status.FromError(err); ok {
+			code = st.Code()
+		} else {
+			var exist bool
+			code, exist = pkgErrors.GetGRPCCodeByError(err)
+			if !exist {
+				err = errors.Wrap(err, "undefined error")
+			}
+			err = status.Error(code, err.Error())
+		}
+	}
+
+	fields := []zap.Field{
+		zap.String("method", method),
+		zap.String("code", code.String()),
+		zap.Duration("latency", time.Since(start)),
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		fields = append(fields, zap.String("remote_addr", p.Addr.String()))
+	}
+	switch code {
+	case codes.OK:
+		log.Info("gRPC request", fields...)
+	case codes.Internal, codes.Unknown, codes.Unavailable, codes.DeadlineExceeded:
+		log.Error("gRPC request", append(fields, zap.Error(err))...)
+	default:
+		log.Info("gRPC request", append(fields, zap.String("message", status.Convert(err).Message()))...)
+	}
+	return err
+}
+
+// GRPCConflict is the AlreadyExists status with the existing objects in the
+// details, as the body of a 409 response carries them.
+func GRPCConflict(err error, existing protoiface.MessageV1) error {
+	st := status.New(codes.AlreadyExists, err.Error())
+	if detailed, detailsErr := st.WithDetails(existing); detailsErr == nil {
+		st = detailed
+	}
+	return st.Err()
+}
+
+// serverStream passes the context with the request id to the handler.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
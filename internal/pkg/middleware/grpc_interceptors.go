@@ -0,0 +1,233 @@
+package middleware
+
+import (
+	"context"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	otelCodes "go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	pkgErrors "github.com/SlavaShagalov/vk-dbms-project/internal/pkg/errors"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/metrics"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/ratelimit"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/tracing"
+)
+
+// GRPCRoute ties a gRPC method to the HTTP route it mirrors, so that both
+// share the route timeout and rate limit group.
+type GRPCRoute struct {
+	// Route is the method and pattern, e.g. "GET /api/thread/:slug_or_id/details".
+	Route string
+	// Group is the rate limit group, empty for no limit.
+	Group string
+}
+
+// GRPCRoutes is keyed by the full method name.
+type GRPCRoutes map[string]GRPCRoute
+
+// UnaryTrace is Trace for the gRPC API.
+func UnaryTrace() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, span := startGRPCSpan(ctx, info.FullMethod)
+		defer span.End()
+
+		resp, err := handler(ctx, req)
+		endGRPCSpan(span, err)
+		return resp, err
+	}
+}
+
+// StreamTrace is UnaryTrace for streaming calls.
+func StreamTrace() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, span := startGRPCSpan(ss.Context(), info.FullMethod)
+		defer span.End()
+
+		err := handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+		endGRPCSpan(span, err)
+		return err
+	}
+}
+
+func startGRPCSpan(ctx context.Context, method string) (context.Context, trace.Span) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx = otel.GetTextMapPropagator().Extract(ctx, metadataCarrier(md))
+
+	service, name, _ := strings.Cut(strings.TrimPrefix(method, "/"), "/")
+	return tracing.Tracer().Start(ctx, strings.TrimPrefix(method, "/"),
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			semconv.RPCSystemGRPC,
+			semconv.RPCService(service),
+			semconv.RPCMethod(name),
+		),
+	)
+}
+
+func endGRPCSpan(span trace.Span, err error) {
+	code := status.Code(err)
+	span.SetAttributes(semconv.RPCGRPCStatusCodeKey.Int(int(code)))
+	switch code {
+	case codes.Internal, codes.Unknown, codes.Unavailable, codes.DeadlineExceeded, codes.DataLoss:
+		span.SetStatus(otelCodes.Error, code.String())
+	}
+}
+
+// metadataCarrier lets the propagator read the trace context from metadata.
+type metadataCarrier metadata.MD
+
+func (c metadataCarrier) Get(key string) string {
+	values := metadata.MD(c).Get(key)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+func (c metadataCarrier) Set(key, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// UnaryMetrics is Metrics for the gRPC API. It goes before UnaryHandleError to
+// see the status codes.
+func UnaryMetrics(m *metrics.Metrics) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		m.GRPCRequestStarted()
+		resp, err := handler(ctx, req)
+		m.GRPCRequestFinished(info.FullMethod, status.Code(err).String(), time.Since(start))
+		return resp, err
+	}
+}
+
+// StreamMetrics is UnaryMetrics for streaming calls.
+func StreamMetrics(m *metrics.Metrics) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		m.GRPCRequestStarted()
+		err := handler(srv, ss)
+		m.GRPCRequestFinished(info.FullMethod, status.Code(err).String(), time.Since(start))
+		return err
+	}
+}
+
+// UnaryTimeout is Timeout for the gRPC API: the call gets the deadline of its
+// route, unless the client asked for an earlier one.
+func UnaryTimeout(routes GRPCRoutes) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, cancel := withRouteTimeout(ctx, routes, info.FullMethod)
+		defer cancel()
+		return handler(ctx, req)
+	}
+}
+
+// StreamTimeout is UnaryTimeout for streaming calls.
+func StreamTimeout(routes GRPCRoutes) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, cancel := withRouteTimeout(ss.Context(), routes, info.FullMethod)
+		defer cancel()
+		return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+func withRouteTimeout(ctx context.Context, routes GRPCRoutes, method string) (context.Context, context.CancelFunc) {
+	route, ok := routes[method]
+	if !ok {
+		return ctx, func() {}
+	}
+	timeout := routeTimeout(route.Route)
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// UnaryRateLimit is RateLimit for the gRPC API. The delay is sent in the
+// retry-after header.
+func UnaryRateLimit(limiter *ratelimit.Limiter, routes GRPCRoutes) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := allowGRPC(ctx, limiter, routes, info.FullMethod); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamRateLimit is UnaryRateLimit for streaming calls.
+func StreamRateLimit(limiter *ratelimit.Limiter, routes GRPCRoutes) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := allowGRPC(ss.Context(), limiter, routes, info.FullMethod); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
+
+func allowGRPC(ctx context.Context, limiter *ratelimit.Limiter, routes GRPCRoutes, method string) error {
+	route, ok := routes[method]
+	if !ok || route.Group == "" {
+		return nil
+	}
+
+	ok, retry := limiter.Allow(ctx, route.Group, grpcClientIP(ctx, limiter.TrustProxy()))
+	if !ok {
+		return grpcTooManyRequests(ctx, retry)
+	}
+	return nil
+}
+
+// AllowGRPCUsers is AllowUsers for the gRPC API.
+func AllowGRPCUsers(ctx context.Context, limiter *ratelimit.Limiter, group string, nicknames ...string) error {
+	if ok, retry := limiter.AllowUsers(ctx, group, nicknames...); !ok {
+		return grpcTooManyRequests(ctx, retry)
+	}
+	return nil
+}
+
+func grpcTooManyRequests(ctx context.Context, retry time.Duration) error {
+	_ = grpc.SetHeader(ctx, metadata.Pairs("retry-after", strconv.Itoa(int(math.Ceil(retry.Seconds())))))
+	return pkgErrors.ErrTooManyRequests
+}
+
+func grpcClientIP(ctx context.Context, trustProxy bool) string {
+	if trustProxy {
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if values := md.Get("x-forwarded-for"); len(values) > 0 {
+				ip, _, _ := strings.Cut(values[0], ",")
+				return strings.TrimSpace(ip)
+			}
+			if values := md.Get("x-real-ip"); len(values) > 0 {
+				return values[0]
+			}
+		}
+	}
+
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/metrics"
+)
+
+func Metrics(handler httprouter.Handle, m *metrics.Metrics) httprouter.Handle {
+	if m == nil {
+		return handler
+	}
+
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		start := time.Now()
+		m.RequestStarted()
+
+		rw := wrapResponseWriter(w)
+		defer func() {
+			m.RequestFinished(route(r), r.Method, rw.Status(), time.Since(start))
+		}()
+		handler(rw, r, p)
+	}
+}
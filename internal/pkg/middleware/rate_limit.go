@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+
+	pkgErrors "github.com/SlavaShagalov/vk-dbms-project/internal/pkg/errors"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/ratelimit"
+)
+
+func RateLimit(handler func(w http.ResponseWriter, r *http.Request, p httprouter.Params) error,
+	limiter *ratelimit.Limiter, group string) func(w http.ResponseWriter, r *http.Request, p httprouter.Params) error {
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) error {
+		ok, retry := limiter.Allow(r.Context(), group, clientIP(r, limiter.TrustProxy()))
+		if !ok {
+			return tooManyRequests(w, retry)
+		}
+		return handler(w, r, p)
+	}
+}
+
+// AllowUsers applies the per-user limits of the group to the acting nicknames.
+// Handlers call it after decoding the body, RateLimit has already charged the ip.
+func AllowUsers(w http.ResponseWriter, r *http.Request, limiter *ratelimit.Limiter, group string, nicknames ...string) error {
+	if ok, retry := limiter.AllowUsers(r.Context(), group, nicknames...); !ok {
+		return tooManyRequests(w, retry)
+	}
+	return nil
+}
+
+func tooManyRequests(w http.ResponseWriter, retry time.Duration) error {
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retry.Seconds()))))
+	return pkgErrors.ErrTooManyRequests
+}
+
+func clientIP(r *http.Request, trustProxy bool) string {
+	if !trustProxy {
+		return remoteHost(r)
+	}
+
+	if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+		ip, _, _ := strings.Cut(forwarded, ",")
+		return strings.TrimSpace(ip)
+	}
+	if ip := r.Header.Get("X-Real-IP"); ip != "" {
+		return ip
+	}
+	return remoteHost(r)
+}
+
+func remoteHost(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+)
+
+// responseWriter remembers the status code and body size written by a handler,
+// and the users the handler acted for.
+type responseWriter struct {
+	http.ResponseWriter
+	status int
+	size   int
+	user   string
+}
+
+func wrapResponseWriter(w http.ResponseWriter) *responseWriter {
+	if rw, ok := w.(*responseWriter); ok {
+		return rw
+	}
+	return &responseWriter{ResponseWriter: w}
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *responseWriter) Write(data []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(data)
+	w.size += n
+	return n, err
+}
+
+func (w *responseWriter) Status() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
+
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// SetUser records the nicknames the request acts for in the access log.
+// Repeated nicknames are logged once.
+func SetUser(w http.ResponseWriter, nicknames ...string) {
+	for {
+		if rw, ok := w.(*responseWriter); ok {
+			rw.user = joinNicknames(nicknames)
+			return
+		}
+		unwrapper, ok := w.(interface{ Unwrap() http.ResponseWriter })
+		if !ok {
+			return
+		}
+		w = unwrapper.Unwrap()
+	}
+}
+
+func joinNicknames(nicknames []string) string {
+	unique := make([]string, 0, len(nicknames))
+	seen := make(map[string]struct{}, len(nicknames))
+	for _, nickname := range nicknames {
+		key := strings.ToLower(nickname)
+		if _, ok := seen[key]; ok || nickname == "" {
+			continue
+		}
+		seen[key] = struct{}{}
+		unique = append(unique, nickname)
+	}
+	return strings.Join(unique, ",")
+}
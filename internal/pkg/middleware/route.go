@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+type routeKey struct{}
+
+// Router registers handlers with their route pattern in the request context,
+// where Trace, Timeout, Metrics and Conditional look it up.
+type Router struct {
+	*httprouter.Router
+}
+
+func NewRouter() *Router {
+	return &Router{Router: httprouter.New()}
+}
+
+func (r *Router) GET(path string, handle httprouter.Handle) {
+	r.Handle(http.MethodGet, path, handle)
+}
+
+func (r *Router) POST(path string, handle httprouter.Handle) {
+	r.Handle(http.MethodPost, path, handle)
+}
+
+func (r *Router) PUT(path string, handle httprouter.Handle) {
+	r.Handle(http.MethodPut, path, handle)
+}
+
+func (r *Router) DELETE(path string, handle httprouter.Handle) {
+	r.Handle(http.MethodDelete, path, handle)
+}
+
+func (r *Router) Handle(method, path string, handle httprouter.Handle) {
+	r.Router.Handle(method, path, func(w http.ResponseWriter, req *http.Request, p httprouter.Params) {
+		handle(w, req.WithContext(context.WithValue(req.Context(), routeKey{}, path)), p)
+	})
+}
+
+// route returns the registered pattern of the request, e.g.
+// "/api/thread/:slug_or_id/details", or the path for handlers registered
+// without Router.
+func route(r *http.Request) string {
+	if pattern, ok := r.Context().Value(routeKey{}).(string); ok {
+		return pattern
+	}
+	return r.URL.Path
+}
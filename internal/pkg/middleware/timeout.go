@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+type timeouts struct {
+	fallback time.Duration
+	routes   map[string]time.Duration
+}
+
+var routeTimeouts atomic.Pointer[timeouts]
+
+// SetTimeouts configures request deadlines. Routes are keyed by method and
+// pattern, e.g. "GET /api/thread/:slug_or_id/posts"; 0 disables the deadline.
+func SetTimeouts(fallback time.Duration, routes map[string]time.Duration) {
+	routeTimeouts.Store(&timeouts{fallback: fallback, routes: routes})
+}
+
+// routeTimeout returns the deadline of the route, 0 when there is none.
+func routeTimeout(route string) time.Duration {
+	cfg := routeTimeouts.Load()
+	if cfg == nil {
+		return 0
+	}
+	if timeout, ok := cfg.routes[route]; ok {
+		return timeout
+	}
+	return cfg.fallback
+}
+
+func Timeout(handler httprouter.Handle) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		timeout := routeTimeout(r.Method + " " + route(r))
+		if timeout <= 0 {
+			handler(w, r, p)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+		handler(w, r.WithContext(ctx), p)
+	}
+}
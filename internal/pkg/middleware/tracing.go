@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/tracing"
+)
+
+func Trace(handler httprouter.Handle) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		route := route(r)
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		ctx, span := tracing.Tracer().Start(ctx, r.Method+" "+route,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPMethod(r.Method),
+				semconv.HTTPRoute(route),
+				semconv.HTTPTarget(r.URL.RequestURI()),
+			),
+		)
+		defer span.End()
+
+		rw := wrapResponseWriter(w)
+		handler(rw, r.WithContext(ctx), p)
+
+		span.SetAttributes(semconv.HTTPStatusCode(rw.Status()))
+		if rw.Status() >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(rw.Status()))
+		}
+	}
+}
@@ -0,0 +1,283 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+// lockKey serializes migrations of concurrently started instances.
+const lockKey = 7_305_298_120_941_221_633
+
+const (
+	createTableCmd = `
+CREATE TABLE IF NOT EXISTS schema_migrations
+(
+    version    bigint PRIMARY KEY,
+    name       text                     NOT NULL,
+    applied_at timestamp with time zone NOT NULL DEFAULT now()
+);`
+	lockCmd          = `SELECT pg_advisory_lock($1);`
+	unlockCmd        = `SELECT pg_advisory_unlock($1);`
+	getVersionCmd    = `SELECT coalesce(max(version), 0) FROM schema_migrations;`
+	getAppliedCmd    = `SELECT version, applied_at FROM schema_migrations;`
+	insertVersionCmd = `INSERT INTO schema_migrations (version, name) VALUES ($1, $2);`
+	deleteVersionCmd = `DELETE FROM schema_migrations WHERE version = $1;`
+)
+
+// undefinedTable is raised when schema_migrations was never created.
+const undefinedTable = "42P01"
+
+var fileRegexp = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+var ErrVersionMismatch = errors.New("schema version mismatch")
+
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+type Status struct {
+	Migration
+	Applied   bool
+	AppliedAt time.Time
+}
+
+type Migrator struct {
+	pool       *pgxpool.Pool
+	migrations []Migration
+	log        *zap.Logger
+}
+
+// New loads the migrations from the root of fsys.
+func New(pool *pgxpool.Pool, fsys fs.FS, log *zap.Logger) (*Migrator, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		match := fileRegexp.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+
+		version, _ := strconv.Atoi(match[1])
+		data, err := fs.ReadFile(fsys, path.Join(".", entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		} else if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has two names: %s and %s", version, migration.Name, match[2])
+		}
+		if match[3] == "up" {
+			migration.Up = string(data)
+		} else {
+			migration.Down = string(data)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up script", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	return &Migrator{pool: pool, migrations: migrations, log: log}, nil
+}
+
+// Latest is the version the binary expects.
+func (m *Migrator) Latest() int {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// Version returns the applied schema version, 0 for an empty database.
+func (m *Migrator) Version(ctx context.Context) (int, error) {
+	version := 0
+	err := m.pool.QueryRow(ctx, getVersionCmd).Scan(&version)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == undefinedTable {
+		return 0, nil
+	}
+	return version, err
+}
+
+// Check fails unless the database is exactly at Latest.
+func (m *Migrator) Check(ctx context.Context) error {
+	version, err := m.Version(ctx)
+	if err != nil {
+		return err
+	}
+	if version != m.Latest() {
+		return fmt.Errorf("%w: database is at %d, binary expects %d", ErrVersionMismatch, version, m.Latest())
+	}
+	return nil
+}
+
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	applied, err := m.applied(ctx, m.pool)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		appliedAt, ok := applied[migration.Version]
+		statuses = append(statuses, Status{Migration: migration, Applied: ok, AppliedAt: appliedAt})
+	}
+	return statuses, nil
+}
+
+// Up applies at most steps pending migrations, all of them if steps is 0.
+func (m *Migrator) Up(ctx context.Context, steps int) ([]Migration, error) {
+	var done []Migration
+	err := m.withLock(ctx, func(conn *pgxpool.Conn) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range m.migrations {
+			if steps > 0 && len(done) == steps {
+				break
+			}
+			if _, ok := applied[migration.Version]; ok {
+				continue
+			}
+
+			m.log.Info("Applying migration", zap.Int("version", migration.Version), zap.String("name", migration.Name))
+			if err = m.apply(ctx, conn, migration.Up, insertVersionCmd, migration.Version, migration.Name); err != nil {
+				return fmt.Errorf("migration %d_%s up: %w", migration.Version, migration.Name, err)
+			}
+			done = append(done, migration)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// Down reverts the last steps applied migrations.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	var done []Migration
+	err := m.withLock(ctx, func(conn *pgxpool.Conn) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(m.migrations) - 1; i >= 0 && len(done) < steps; i-- {
+			migration := m.migrations[i]
+			if _, ok := applied[migration.Version]; !ok {
+				continue
+			}
+			if migration.Down == "" {
+				return fmt.Errorf("migration %d_%s is irreversible", migration.Version, migration.Name)
+			}
+
+			m.log.Info("Reverting migration", zap.Int("version", migration.Version), zap.String("name", migration.Name))
+			if err = m.apply(ctx, conn, migration.Down, deleteVersionCmd, migration.Version); err != nil {
+				return fmt.Errorf("migration %d_%s down: %w", migration.Version, migration.Name, err)
+			}
+			done = append(done, migration)
+		}
+		return nil
+	})
+	return done, err
+}
+
+type querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+func (m *Migrator) applied(ctx context.Context, q querier) (map[int]time.Time, error) {
+	rows, err := q.Query(ctx, getAppliedCmd)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == undefinedTable {
+		return map[int]time.Time{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[int]time.Time)
+	for rows.Next() {
+		var version int
+		var appliedAt time.Time
+		if err = rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		applied[version] = appliedAt
+	}
+	if err = rows.Err(); err != nil {
+		if errors.As(err, &pgErr) && pgErr.Code == undefinedTable {
+			return map[int]time.Time{}, nil
+		}
+		return nil, err
+	}
+	return applied, nil
+}
+
+// apply runs the script and records it in one transaction.
+func (m *Migrator) apply(ctx context.Context, conn *pgxpool.Conn, script, recordCmd string, args ...any) error {
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if _, err = tx.Exec(ctx, script); err != nil {
+		return err
+	}
+	if _, err = tx.Exec(ctx, recordCmd, args...); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *pgxpool.Conn) error) error {
+	conn, err := m.pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	if _, err = conn.Exec(ctx, lockCmd, int64(lockKey)); err != nil {
+		return err
+	}
+	defer func() {
+		if _, err := conn.Exec(context.Background(), unlockCmd, int64(lockKey)); err != nil {
+			m.log.Error("Failed to release migration lock", zap.Error(err))
+		}
+	}()
+
+	if _, err = conn.Exec(ctx, createTableCmd); err != nil {
+		return err
+	}
+	return fn(conn)
+}
@@ -0,0 +1,74 @@
+package migrate
+
+import (
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"go.uber.org/zap"
+
+	"github.com/SlavaShagalov/vk-dbms-project/db"
+)
+
+func TestNew(t *testing.T) {
+	fsys := fstest.MapFS{
+		"0002_second.up.sql":   {Data: []byte("SELECT 2;")},
+		"0001_first.up.sql":    {Data: []byte("SELECT 1;")},
+		"0001_first.down.sql":  {Data: []byte("SELECT -1;")},
+		"README.md":            {Data: []byte("not a migration")},
+		"0003_bad-name.up.sql": {Data: []byte("SELECT 3;")},
+	}
+
+	m, err := New(nil, fsys, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.migrations) != 2 || m.migrations[0].Version != 1 || m.migrations[1].Version != 2 {
+		t.Fatalf("migrations %+v, want 1 and 2 in order", m.migrations)
+	}
+	if first := m.migrations[0]; first.Name != "first" || first.Up != "SELECT 1;" || first.Down != "SELECT -1;" {
+		t.Fatalf("first migration %+v", first)
+	}
+	if m.migrations[1].Down != "" {
+		t.Fatal("second migration got a down script")
+	}
+	if latest := m.Latest(); latest != 2 {
+		t.Fatalf("latest %d, want 2", latest)
+	}
+}
+
+func TestNewErrors(t *testing.T) {
+	for name, fsys := range map[string]fstest.MapFS{
+		"no up script": {
+			"0001_first.down.sql": {Data: []byte("SELECT -1;")},
+		},
+		"two names": {
+			"0001_first.up.sql":   {Data: []byte("SELECT 1;")},
+			"0001_other.down.sql": {Data: []byte("SELECT -1;")},
+		},
+	} {
+		if _, err := New(nil, fsys, zap.NewNop()); err == nil {
+			t.Errorf("%s: no error", name)
+		}
+	}
+}
+
+func TestEmbeddedMigrations(t *testing.T) {
+	fsys, err := fs.Sub(db.Migrations, "migrations")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := New(nil, fsys, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, migration := range m.migrations {
+		if migration.Version != i+1 {
+			t.Fatalf("migration %d_%s, want version %d", migration.Version, migration.Name, i+1)
+		}
+		if migration.Down == "" {
+			t.Errorf("migration %d_%s has no down script", migration.Version, migration.Name)
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package openapi
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Spec is the part of a Swagger 2.0 document needed to validate the API
+// traffic: paths, parameters and the JSON schemas of bodies.
+type Spec struct {
+	BasePath    string                           `yaml:"basePath"`
+	Paths       map[string]map[string]*Operation `yaml:"paths"`
+	Parameters  map[string]*Parameter            `yaml:"parameters"`
+	Definitions map[string]*Schema               `yaml:"definitions"`
+
+	routes []*route
+}
+
+type Operation struct {
+	ID         string               `yaml:"operationId"`
+	Parameters []*Parameter         `yaml:"parameters"`
+	Responses  map[string]*Response `yaml:"responses"`
+}
+
+type Parameter struct {
+	Ref      string        `yaml:"$ref"`
+	Name     string        `yaml:"name"`
+	In       string        `yaml:"in"`
+	Required bool          `yaml:"required"`
+	Type     string        `yaml:"type"`
+	Format   string        `yaml:"format"`
+	Enum     []interface{} `yaml:"enum"`
+	Minimum  *float64      `yaml:"minimum"`
+	Maximum  *float64      `yaml:"maximum"`
+	Items    *Schema       `yaml:"items"`
+	Schema   *Schema       `yaml:"schema"`
+}
+
+type Response struct {
+	Schema *Schema `yaml:"schema"`
+}
+
+type Schema struct {
+	Ref         string             `yaml:"$ref"`
+	Type        string             `yaml:"type"`
+	Format      string             `yaml:"format"`
+	Enum        []interface{}      `yaml:"enum"`
+	Pattern     string             `yaml:"pattern"`
+	Minimum     *float64           `yaml:"minimum"`
+	Maximum     *float64           `yaml:"maximum"`
+	Required    []string           `yaml:"required"`
+	Properties  map[string]*Schema `yaml:"properties"`
+	Items       *Schema            `yaml:"items"`
+	ReadOnly    bool               `yaml:"readOnly"`
+	XIsNullable bool               `yaml:"x-isnullable"`
+
+	pattern *regexp.Regexp
+}
+
+// route is a path template split into segments, "{name}" segments match any value.
+type route struct {
+	path      string
+	segments  []string
+	literals  int
+	operation map[string]*Operation
+}
+
+// Load parses the document and resolves parameter and schema references.
+func Load(data []byte) (*Spec, error) {
+	spec := &Spec{}
+	if err := yaml.Unmarshal(data, spec); err != nil {
+		return nil, fmt.Errorf("parse spec: %w", err)
+	}
+
+	for name, schema := range spec.Definitions {
+		if err := spec.compile(schema); err != nil {
+			return nil, fmt.Errorf("definition %s: %w", name, err)
+		}
+	}
+
+	for path, operations := range spec.Paths {
+		for method, op := range operations {
+			for i, param := range op.Parameters {
+				if param.Ref != "" {
+					resolved, ok := spec.Parameters[strings.TrimPrefix(param.Ref, "#/parameters/")]
+					if !ok {
+						return nil, fmt.Errorf("%s %s: unknown parameter %s", method, path, param.Ref)
+					}
+					op.Parameters[i] = resolved
+					param = resolved
+				}
+				if err := spec.compile(param.Schema); err != nil {
+					return nil, fmt.Errorf("%s %s: %w", method, path, err)
+				}
+				if err := spec.compile(param.Items); err != nil {
+					return nil, fmt.Errorf("%s %s: %w", method, path, err)
+				}
+			}
+			for status, response := range op.Responses {
+				if err := spec.compile(response.Schema); err != nil {
+					return nil, fmt.Errorf("%s %s %s: %w", method, path, status, err)
+				}
+			}
+		}
+
+		r := &route{path: path, segments: strings.Split(strings.Trim(path, "/"), "/"), operation: operations}
+		for _, segment := range r.segments {
+			if !isParam(segment) {
+				r.literals++
+			}
+		}
+		spec.routes = append(spec.routes, r)
+	}
+
+	// Literal segments win over parameters, so /forum/create is not taken for
+	// /forum/{slug}.
+	sort.Slice(spec.routes, func(i, j int) bool {
+		if spec.routes[i].literals != spec.routes[j].literals {
+			return spec.routes[i].literals > spec.routes[j].literals
+		}
+		return spec.routes[i].path < spec.routes[j].path
+	})
+	return spec, nil
+}
+
+func (spec *Spec) compile(schema *Schema) error {
+	if schema == nil {
+		return nil
+	}
+	if schema.Ref != "" {
+		if _, ok := spec.Definitions[strings.TrimPrefix(schema.Ref, "#/definitions/")]; !ok {
+			return fmt.Errorf("unknown schema %s", schema.Ref)
+		}
+		return nil
+	}
+	if schema.Pattern != "" && schema.pattern == nil {
+		pattern, err := regexp.Compile(schema.Pattern)
+		if err != nil {
+			return fmt.Errorf("pattern %q: %w", schema.Pattern, err)
+		}
+		schema.pattern = pattern
+	}
+	for _, property := range schema.Properties {
+		if err := spec.compile(property); err != nil {
+			return err
+		}
+	}
+	return spec.compile(schema.Items)
+}
+
+func (spec *Spec) resolve(schema *Schema) *Schema {
+	for schema != nil && schema.Ref != "" {
+		schema = spec.Definitions[strings.TrimPrefix(schema.Ref, "#/definitions/")]
+	}
+	return schema
+}
+
+// Find returns the operation serving the request path and its path parameters.
+// Paths outside the base path or absent from the spec are not found.
+func (spec *Spec) Find(method, path string) (*Operation, map[string]string, bool) {
+	if !strings.HasPrefix(path, spec.BasePath+"/") {
+		return nil, nil, false
+	}
+	segments := strings.Split(strings.Trim(strings.TrimPrefix(path, spec.BasePath), "/"), "/")
+
+	for _, r := range spec.routes {
+		if len(r.segments) != len(segments) {
+			continue
+		}
+		params := make(map[string]string)
+		matched := true
+		for i, segment := range r.segments {
+			if isParam(segment) {
+				params[strings.Trim(segment, "{}")] = segments[i]
+			} else if segment != segments[i] {
+				matched = false
+				break
+			}
+		}
+		if !matched {
+			continue
+		}
+		op, ok := r.operation[strings.ToLower(method)]
+		return op, params, ok
+	}
+	return nil, nil, false
+}
+
+// Response returns the documented response for the status code.
+func (op *Operation) Response(status int) (*Response, bool) {
+	if response, ok := op.Responses[strconv.Itoa(status)]; ok {
+		return response, true
+	}
+	response, ok := op.Responses["default"]
+	return response, ok
+}
+
+func isParam(segment string) bool {
+	return strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}")
+}
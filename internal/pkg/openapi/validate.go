@@ -0,0 +1,317 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	KindRequest  = "request"
+	KindResponse = "response"
+)
+
+// Violation is a mismatch between the traffic and the spec. Location points at
+// the offending value, e.g. "query.limit" or "body.author.nickname".
+type Violation struct {
+	Kind     string
+	Location string
+	Message  string
+}
+
+func (v Violation) Error() string {
+	if v.Location == "" {
+		return v.Kind + ": " + v.Message
+	}
+	return v.Kind + " " + v.Location + ": " + v.Message
+}
+
+// ValidateRequest checks the path, query and body parameters of the request.
+// The body is passed separately since the handler still has to read it.
+func (spec *Spec) ValidateRequest(r *http.Request, body []byte) []Violation {
+	op, pathParams, ok := spec.Find(r.Method, r.URL.Path)
+	if !ok {
+		return nil
+	}
+
+	v := &validator{spec: spec, kind: KindRequest}
+	query := r.URL.Query()
+	for _, param := range op.Parameters {
+		switch param.In {
+		case "path":
+			v.param(param, "path."+param.Name, []string{pathParams[param.Name]})
+		case "query":
+			values, ok := query[param.Name]
+			if !ok {
+				if param.Required {
+					v.add("query."+param.Name, "required parameter is missing")
+				}
+				continue
+			}
+			v.param(param, "query."+param.Name, values)
+		case "body":
+			if len(bytes.TrimSpace(body)) == 0 {
+				if param.Required {
+					v.add("body", "required body is missing")
+				}
+				continue
+			}
+			v.json(param.Schema, "body", body)
+		}
+	}
+	return v.violations
+}
+
+// ValidateResponse checks that the status code is documented for the
+// operation and the body matches its schema.
+func (spec *Spec) ValidateResponse(method, path string, status int, body []byte) []Violation {
+	op, _, ok := spec.Find(method, path)
+	if !ok {
+		return nil
+	}
+
+	v := &validator{spec: spec, kind: KindResponse}
+	response, ok := op.Response(status)
+	if !ok {
+		v.add("status", "status %d is not documented", status)
+		return v.violations
+	}
+	if response.Schema != nil {
+		v.json(response.Schema, "body", body)
+	}
+	return v.violations
+}
+
+type validator struct {
+	spec       *Spec
+	kind       string
+	violations []Violation
+}
+
+func (v *validator) add(location, format string, args ...interface{}) {
+	v.violations = append(v.violations, Violation{
+		Kind:     v.kind,
+		Location: location,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+func (v *validator) param(param *Parameter, location string, values []string) {
+	if param.Type == "array" {
+		if param.Items == nil {
+			return
+		}
+		// collectionFormat defaults to csv.
+		for _, value := range values {
+			for i, item := range strings.Split(value, ",") {
+				v.scalar(param.Items, location+"["+strconv.Itoa(i)+"]", item)
+			}
+		}
+		return
+	}
+
+	schema := &Schema{
+		Type:    param.Type,
+		Format:  param.Format,
+		Enum:    param.Enum,
+		Minimum: param.Minimum,
+		Maximum: param.Maximum,
+	}
+	for _, value := range values {
+		if param.In == "path" && value == "" {
+			v.add(location, "required parameter is missing")
+			continue
+		}
+		v.scalar(schema, location, value)
+	}
+}
+
+// scalar validates a query or path value, converting it to the schema type first.
+func (v *validator) scalar(schema *Schema, location, value string) {
+	switch schema.Type {
+	case "number", "integer":
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			v.add(location, "%q is not a number", value)
+			return
+		}
+		v.value(schema, location, json.Number(value))
+	case "boolean":
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			v.add(location, "%q is not a boolean", value)
+			return
+		}
+		v.value(schema, location, b)
+	default:
+		v.value(schema, location, value)
+	}
+}
+
+func (v *validator) json(schema *Schema, location string, data []byte) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		v.add(location, "invalid JSON: %v", err)
+		return
+	}
+	v.value(schema, location, value)
+}
+
+func (v *validator) value(schema *Schema, location string, value interface{}) {
+	schema = v.spec.resolve(schema)
+	if schema == nil {
+		return
+	}
+
+	if value == nil {
+		if !schema.XIsNullable {
+			v.add(location, "null is not allowed")
+		}
+		return
+	}
+
+	switch schema.Type {
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			v.add(location, "expected object, got %s", typeName(value))
+			return
+		}
+		v.object(schema, location, object)
+	case "array":
+		array, ok := value.([]interface{})
+		if !ok {
+			v.add(location, "expected array, got %s", typeName(value))
+			return
+		}
+		for i, item := range array {
+			v.value(schema.Items, location+"["+strconv.Itoa(i)+"]", item)
+		}
+	case "string":
+		s, ok := value.(string)
+		if !ok {
+			v.add(location, "expected string, got %s", typeName(value))
+			return
+		}
+		v.string(schema, location, s)
+	case "number", "integer":
+		number, ok := value.(json.Number)
+		if !ok {
+			v.add(location, "expected number, got %s", typeName(value))
+			return
+		}
+		v.number(schema, location, number)
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			v.add(location, "expected boolean, got %s", typeName(value))
+			return
+		}
+	}
+
+	if len(schema.Enum) > 0 && !inEnum(schema.Enum, value) {
+		v.add(location, "%v is not one of %v", value, schema.Enum)
+	}
+}
+
+// object checks required and undeclared properties. Undeclared ones are
+// reported too, that is how renamed or mistyped fields show up.
+func (v *validator) object(schema *Schema, location string, object map[string]interface{}) {
+	for _, name := range schema.Required {
+		property := v.spec.resolve(schema.Properties[name])
+		if v.kind == KindRequest && property != nil && property.ReadOnly {
+			continue
+		}
+		if _, ok := object[name]; !ok {
+			v.add(location+"."+name, "required property is missing")
+		}
+	}
+
+	names := make([]string, 0, len(object))
+	for name := range object {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		property, ok := schema.Properties[name]
+		if !ok {
+			v.add(location+"."+name, "property is not declared")
+			continue
+		}
+		v.value(property, location+"."+name, object[name])
+	}
+}
+
+func (v *validator) string(schema *Schema, location, s string) {
+	switch schema.Format {
+	case "date-time":
+		if _, err := time.Parse(time.RFC3339Nano, s); err != nil {
+			v.add(location, "%q is not an RFC 3339 date-time", s)
+		}
+	case "email":
+		if !strings.Contains(s, "@") {
+			v.add(location, "%q is not an email", s)
+		}
+	}
+	if schema.pattern != nil && !schema.pattern.MatchString(s) {
+		v.add(location, "%q does not match %s", s, schema.Pattern)
+	}
+}
+
+func (v *validator) number(schema *Schema, location string, number json.Number) {
+	f, err := number.Float64()
+	if err != nil {
+		v.add(location, "%s is not a number", number)
+		return
+	}
+	switch schema.Format {
+	case "int32", "int64":
+		bits := 64
+		if schema.Format == "int32" {
+			bits = 32
+		}
+		if _, err = strconv.ParseInt(number.String(), 10, bits); err != nil {
+			v.add(location, "%s is not an %s", number, schema.Format)
+		}
+	}
+	if schema.Minimum != nil && f < *schema.Minimum {
+		v.add(location, "%s is less than %v", number, *schema.Minimum)
+	}
+	if schema.Maximum != nil && f > *schema.Maximum {
+		v.add(location, "%s is greater than %v", number, *schema.Maximum)
+	}
+}
+
+func inEnum(enum []interface{}, value interface{}) bool {
+	if number, ok := value.(json.Number); ok {
+		value = number.String()
+	}
+	for _, allowed := range enum {
+		if fmt.Sprint(allowed) == fmt.Sprint(value) {
+			return true
+		}
+	}
+	return false
+}
+
+func typeName(value interface{}) string {
+	switch value.(type) {
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case json.Number:
+		return "number"
+	case bool:
+		return "boolean"
+	}
+	return fmt.Sprintf("%T", value)
+}
@@ -0,0 +1,171 @@
+package openapi
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/SlavaShagalov/vk-dbms-project/docs"
+)
+
+const testSpec = `
+basePath: /api
+paths:
+  /forum/create:
+    post:
+      operationId: forumCreate
+      parameters:
+        - name: forum
+          in: body
+          required: true
+          schema:
+            $ref: '#/definitions/Forum'
+      responses:
+        201:
+          schema:
+            $ref: '#/definitions/Forum'
+  /forum/{slug}/threads:
+    get:
+      operationId: forumGetThreads
+      parameters:
+        - $ref: '#/parameters/slug'
+        - name: limit
+          in: query
+          type: number
+          format: int32
+          minimum: 1
+          maximum: 10000
+        - name: desc
+          in: query
+          type: boolean
+      responses:
+        200:
+          schema:
+            type: array
+            items:
+              $ref: '#/definitions/Forum'
+        404:
+          schema:
+            type: object
+parameters:
+  slug:
+    name: slug
+    in: path
+    required: true
+    type: string
+definitions:
+  Forum:
+    type: object
+    required: [title, user, slug, posts]
+    properties:
+      title:
+        type: string
+      user:
+        type: string
+        pattern: '^[a-zA-Z0-9_.]+$'
+      slug:
+        type: string
+      posts:
+        type: number
+        format: int64
+        readOnly: true
+      created:
+        type: string
+        format: date-time
+        x-isnullable: true
+`
+
+func loadTestSpec(t *testing.T) *Spec {
+	t.Helper()
+
+	spec, err := Load([]byte(testSpec))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return spec
+}
+
+func locations(violations []Violation) string {
+	var locations []string
+	for _, violation := range violations {
+		locations = append(locations, violation.Location)
+	}
+	return strings.Join(locations, ",")
+}
+
+func TestLoadSwagger(t *testing.T) {
+	if _, err := Load(docs.Swagger); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFind(t *testing.T) {
+	spec := loadTestSpec(t)
+
+	if op, _, ok := spec.Find("POST", "/api/forum/create"); !ok || op.ID != "forumCreate" {
+		t.Fatalf("/forum/create: %+v, %v", op, ok)
+	}
+	op, params, ok := spec.Find("GET", "/api/forum/pirates/threads")
+	if !ok || op.ID != "forumGetThreads" || params["slug"] != "pirates" {
+		t.Fatalf("/forum/{slug}/threads: %+v, %v, %v", op, params, ok)
+	}
+	if _, _, ok = spec.Find("POST", "/api/forum/pirates/threads"); ok {
+		t.Fatal("undocumented method found")
+	}
+	if _, _, ok = spec.Find("GET", "/forum/pirates/threads"); ok {
+		t.Fatal("path outside the base path found")
+	}
+}
+
+func TestValidateRequest(t *testing.T) {
+	spec := loadTestSpec(t)
+
+	for _, tc := range []struct {
+		name   string
+		method string
+		target string
+		body   string
+		want   string
+	}{
+		{"valid query", "GET", "/api/forum/pirates/threads?limit=10&desc=true", "", ""},
+		{"query types", "GET", "/api/forum/pirates/threads?limit=ten&desc=yes", "", "query.limit,query.desc"},
+		{"query range", "GET", "/api/forum/pirates/threads?limit=0", "", "query.limit"},
+		{"valid body", "POST", "/api/forum/create", `{"title":"Pirates","user":"j.sparrow","slug":"pirates"}`, ""},
+		{"missing body", "POST", "/api/forum/create", "", "body"},
+		{"invalid JSON", "POST", "/api/forum/create", `{"title":`, "body"},
+		{"missing property", "POST", "/api/forum/create", `{"title":"Pirates","user":"j.sparrow"}`, "body.slug"},
+		{"undeclared property", "POST", "/api/forum/create", `{"title":"Pirates","user":"j.sparrow","slug":"pirates","owner":"x"}`, "body.owner"},
+		{"pattern", "POST", "/api/forum/create", `{"title":"Pirates","user":"j sparrow","slug":"pirates"}`, "body.user"},
+		{"nullable", "POST", "/api/forum/create", `{"title":"Pirates","user":"j.sparrow","slug":"pirates","created":null}`, ""},
+		{"not nullable", "POST", "/api/forum/create", `{"title":null,"user":"j.sparrow","slug":"pirates"}`, "body.title"},
+		{"date-time", "POST", "/api/forum/create", `{"title":"Pirates","user":"j.sparrow","slug":"pirates","created":"yesterday"}`, "body.created"},
+		{"unknown path", "GET", "/api/unknown", "", ""},
+	} {
+		r := httptest.NewRequest(tc.method, tc.target, nil)
+		if got := locations(spec.ValidateRequest(r, []byte(tc.body))); got != tc.want {
+			t.Errorf("%s: violations at %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestValidateResponse(t *testing.T) {
+	spec := loadTestSpec(t)
+
+	for _, tc := range []struct {
+		name   string
+		status int
+		body   string
+		want   string
+	}{
+		{"valid", 200, `[{"title":"Pirates","user":"j.sparrow","slug":"pirates","posts":1}]`, ""},
+		{"read-only required", 200, `[{"title":"Pirates","user":"j.sparrow","slug":"pirates"}]`, "body[0].posts"},
+		{"int64", 200, `[{"title":"Pirates","user":"j.sparrow","slug":"pirates","posts":1.5}]`, "body[0].posts"},
+		{"wrong type", 200, `{"message":"ok"}`, "body"},
+		{"undocumented status", 500, `{}`, "status"},
+	} {
+		violations := spec.ValidateResponse("GET", "/api/forum/pirates/threads", tc.status, []byte(tc.body))
+		if got := locations(violations); got != tc.want {
+			t.Errorf("%s: violations at %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package pb
+
+import (
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/SlavaShagalov/vk-dbms-project/internal/models"
+	pkgErrors "github.com/SlavaShagalov/vk-dbms-project/internal/pkg/errors"
+)
+
+// Conversions between the models and their messages. Zero times are sent as
+// an unset timestamp, like the JSON API leaves them out of requests.
+
+func timestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+func fromTimestamp(ts *timestamppb.Timestamp) time.Time {
+	if ts == nil {
+		return time.Time{}
+	}
+	return ts.AsTime()
+}
+
+func NewUser(user *models.User) *User {
+	return &User{
+		Id:       int64(user.ID),
+		Nickname: user.Nickname,
+		Fullname: user.Fullname,
+		About:    user.About,
+		Email:    user.Email,
+	}
+}
+
+func (x *User) Model() models.User {
+	return models.User{
+		ID:       int(x.GetId()),
+		Nickname: x.GetNickname(),
+		Fullname: x.GetFullname(),
+		About:    x.GetAbout(),
+		Email:    x.GetEmail(),
+	}
+}
+
+func NewUsers(users []models.User) *Users {
+	msg := &Users{Users: make([]*User, 0, len(users))}
+	for i := range users {
+		msg.Users = append(msg.Users, NewUser(&users[i]))
+	}
+	return msg
+}
+
+func NewForum(forum *models.Forum) *Forum {
+	return &Forum{
+		Id:      forum.ID,
+		Title:   forum.Title,
+		User:    forum.User,
+		Slug:    forum.Slug,
+		Posts:   forum.Posts,
+		Threads: forum.Threads,
+	}
+}
+
+func (x *Forum) Model() models.Forum {
+	return models.Forum{
+		ID:      x.GetId(),
+		Title:   x.GetTitle(),
+		User:    x.GetUser(),
+		Slug:    x.GetSlug(),
+		Posts:   x.GetPosts(),
+		Threads: x.GetThreads(),
+	}
+}
+
+func NewThread(thread *models.Thread) *Thread {
+	return &Thread{
+		Id:      int64(thread.Id),
+		Title:   thread.Title,
+		Author:  thread.Author,
+		Forum:   thread.Forum,
+		Message: thread.Message,
+		Votes:   int32(thread.Votes),
+		Slug:    thread.Slug,
+		Created: timestamp(thread.Created),
+	}
+}
+
+func (x *Thread) Model() models.Thread {
+	return models.Thread{
+		Id:      int(x.GetId()),
+		Title:   x.GetTitle(),
+		Author:  x.GetAuthor(),
+		Forum:   x.GetForum(),
+		Message: x.GetMessage(),
+		Votes:   int(x.GetVotes()),
+		Slug:    x.GetSlug(),
+		Created: fromTimestamp(x.GetCreated()),
+	}
+}
+
+func NewThreads(threads []models.Thread) *Threads {
+	msg := &Threads{Threads: make([]*Thread, 0, len(threads))}
+	for i := range threads {
+		msg.Threads = append(msg.Threads, NewThread(&threads[i]))
+	}
+	return msg
+}
+
+func NewPost(post *models.Post) *Post {
+	return &Post{
+		Id:       int64(post.Id),
+		Parent:   int64(post.Parent),
+		Author:   post.Author,
+		Message:  post.Message,
+		IsEdited: post.IsEdited,
+		Forum:    post.Forum,
+		Thread:   int64(post.Thread),
+		Created:  timestamp(post.Created),
+	}
+}
+
+func (x *Post) Model() models.Post {
+	return models.Post{
+		Id:       int(x.GetId()),
+		Parent:   int(x.GetParent()),
+		Author:   x.GetAuthor(),
+		Message:  x.GetMessage(),
+		IsEdited: x.GetIsEdited(),
+		Forum:    x.GetForum(),
+		Thread:   int(x.GetThread()),
+		Created:  fromTimestamp(x.GetCreated()),
+	}
+}
+
+func NewPosts(posts []models.Post) *Posts {
+	msg := &Posts{Posts: make([]*Post, 0, len(posts))}
+	for i := range posts {
+		msg.Posts = append(msg.Posts, NewPost(&posts[i]))
+	}
+	return msg
+}
+
+func (x *Posts) Model() models.PostList {
+	posts := make(models.PostList, 0, len(x.GetPosts()))
+	for _, post := range x.GetPosts() {
+		posts = append(posts, post.Model())
+	}
+	return posts
+}
+
+func NewPostFull(full *models.FullPost) *PostFull {
+	msg := &PostFull{}
+	if full.Post != nil {
+		msg.Post = NewPost(full.Post)
+	}
+	if full.Author != nil {
+		msg.Author = NewUser(full.Author)
+	}
+	if full.Forum != nil {
+		msg.Forum = NewForum(full.Forum)
+	}
+	if full.Thread != nil {
+		msg.Thread = NewThread(full.Thread)
+	}
+	return msg
+}
+
+func NewVote(vote *models.Vote) *Vote {
+	return &Vote{Nickname: vote.Nickname, Voice: int32(vote.Voice)}
+}
+
+func (x *Vote) Model() models.Vote {
+	return models.Vote{Nickname: x.GetNickname(), Voice: int(x.GetVoice())}
+}
+
+func NewStatus(status *models.Status) *Status {
+	return &Status{
+		User:   int64(status.User),
+		Forum:  int64(status.Forum),
+		Thread: int64(status.Thread),
+		Post:   int64(status.Post),
+	}
+}
+
+// Limit is the page size of the list requests, 100 when unset like in the HTTP API.
+func Limit(limit *int32) (int, error) {
+	if limit == nil {
+		return 100, nil
+	}
+	if *limit < 0 {
+		return 0, pkgErrors.ErrInvalidLimitParam
+	}
+	return int(*limit), nil
+}
@@ -0,0 +1,960 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: forum_service.proto
+
+// gRPC API форумов. Методы повторяют HTTP API из docs/swagger.yml и работают
+// поверх тех же сервисов. Ошибки возвращаются кодами gRPC, при конфликте
+// в деталях статуса передаётся уже существующий объект, как в ответе 409.
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	UserService_CreateUser_FullMethodName = "/forum.UserService/CreateUser"
+	UserService_GetUser_FullMethodName    = "/forum.UserService/GetUser"
+	UserService_UpdateUser_FullMethodName = "/forum.UserService/UpdateUser"
+)
+
+// UserServiceClient is the client API for UserService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type UserServiceClient interface {
+	// Создание пользователя, при конфликте в деталях статуса Users.
+	CreateUser(ctx context.Context, in *CreateUserRequest, opts ...grpc.CallOption) (*User, error)
+	GetUser(ctx context.Context, in *GetUserRequest, opts ...grpc.CallOption) (*User, error)
+	// Пустые поля не изменяются.
+	UpdateUser(ctx context.Context, in *UpdateUserRequest, opts ...grpc.CallOption) (*User, error)
+}
+
+type userServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewUserServiceClient(cc grpc.ClientConnInterface) UserServiceClient {
+	return &userServiceClient{cc}
+}
+
+func (c *userServiceClient) CreateUser(ctx context.Context, in *CreateUserRequest, opts ...grpc.CallOption) (*User, error) {
+	out := new(User)
+	err := c.cc.Invoke(ctx, UserService_CreateUser_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userServiceClient) GetUser(ctx context.Context, in *GetUserRequest, opts ...grpc.CallOption) (*User, error) {
+	out := new(User)
+	err := c.cc.Invoke(ctx, UserService_GetUser_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userServiceClient) UpdateUser(ctx context.Context, in *UpdateUserRequest, opts ...grpc.CallOption) (*User, error) {
+	out := new(User)
+	err := c.cc.Invoke(ctx, UserService_UpdateUser_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// UserServiceServer is the server API for UserService service.
+// All implementations must embed UnimplementedUserServiceServer
+// for forward compatibility
+type UserServiceServer interface {
+	// Создание пользователя, при конфликте в деталях статуса Users.
+	CreateUser(context.Context, *CreateUserRequest) (*User, error)
+	GetUser(context.Context, *GetUserRequest) (*User, error)
+	// Пустые поля не изменяются.
+	UpdateUser(context.Context, *UpdateUserRequest) (*User, error)
+	mustEmbedUnimplementedUserServiceServer()
+}
+
+// UnimplementedUserServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedUserServiceServer struct {
+}
+
+func (UnimplementedUserServiceServer) CreateUser(context.Context, *CreateUserRequest) (*User, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateUser not implemented")
+}
+func (UnimplementedUserServiceServer) GetUser(context.Context, *GetUserRequest) (*User, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUser not implemented")
+}
+func (UnimplementedUserServiceServer) UpdateUser(context.Context, *UpdateUserRequest) (*User, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateUser not implemented")
+}
+func (UnimplementedUserServiceServer) mustEmbedUnimplementedUserServiceServer() {}
+
+// UnsafeUserServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to UserServiceServer will
+// result in compilation errors.
+type UnsafeUserServiceServer interface {
+	mustEmbedUnimplementedUserServiceServer()
+}
+
+func RegisterUserServiceServer(s grpc.ServiceRegistrar, srv UserServiceServer) {
+	s.RegisterService(&UserService_ServiceDesc, srv)
+}
+
+func _UserService_CreateUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateUserRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServiceServer).CreateUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UserService_CreateUser_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServiceServer).CreateUser(ctx, req.(*CreateUserRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserService_GetUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetUserRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServiceServer).GetUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UserService_GetUser_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServiceServer).GetUser(ctx, req.(*GetUserRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserService_UpdateUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateUserRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServiceServer).UpdateUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UserService_UpdateUser_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServiceServer).UpdateUser(ctx, req.(*UpdateUserRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// UserService_ServiceDesc is the grpc.ServiceDesc for UserService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var UserService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "forum.UserService",
+	HandlerType: (*UserServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreateUser",
+			Handler:    _UserService_CreateUser_Handler,
+		},
+		{
+			MethodName: "GetUser",
+			Handler:    _UserService_GetUser_Handler,
+		},
+		{
+			MethodName: "UpdateUser",
+			Handler:    _UserService_UpdateUser_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "forum_service.proto",
+}
+
+const (
+	ForumService_CreateForum_FullMethodName      = "/forum.ForumService/CreateForum"
+	ForumService_GetForum_FullMethodName         = "/forum.ForumService/GetForum"
+	ForumService_CreateThread_FullMethodName     = "/forum.ForumService/CreateThread"
+	ForumService_ListForumUsers_FullMethodName   = "/forum.ForumService/ListForumUsers"
+	ForumService_ListForumThreads_FullMethodName = "/forum.ForumService/ListForumThreads"
+)
+
+// ForumServiceClient is the client API for ForumService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type ForumServiceClient interface {
+	// Создание форума, при конфликте в деталях статуса Forum.
+	CreateForum(ctx context.Context, in *Forum, opts ...grpc.CallOption) (*Forum, error)
+	GetForum(ctx context.Context, in *GetForumRequest, opts ...grpc.CallOption) (*Forum, error)
+	// Создание ветки в форуме thread.forum, при конфликте в деталях статуса Thread.
+	CreateThread(ctx context.Context, in *Thread, opts ...grpc.CallOption) (*Thread, error)
+	ListForumUsers(ctx context.Context, in *ListForumUsersRequest, opts ...grpc.CallOption) (*Users, error)
+	ListForumThreads(ctx context.Context, in *ListForumThreadsRequest, opts ...grpc.CallOption) (*Threads, error)
+}
+
+type forumServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewForumServiceClient(cc grpc.ClientConnInterface) ForumServiceClient {
+	return &forumServiceClient{cc}
+}
+
+func (c *forumServiceClient) CreateForum(ctx context.Context, in *Forum, opts ...grpc.CallOption) (*Forum, error) {
+	out := new(Forum)
+	err := c.cc.Invoke(ctx, ForumService_CreateForum_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *forumServiceClient) GetForum(ctx context.Context, in *GetForumRequest, opts ...grpc.CallOption) (*Forum, error) {
+	out := new(Forum)
+	err := c.cc.Invoke(ctx, ForumService_GetForum_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *forumServiceClient) CreateThread(ctx context.Context, in *Thread, opts ...grpc.CallOption) (*Thread, error) {
+	out := new(Thread)
+	err := c.cc.Invoke(ctx, ForumService_CreateThread_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *forumServiceClient) ListForumUsers(ctx context.Context, in *ListForumUsersRequest, opts ...grpc.CallOption) (*Users, error) {
+	out := new(Users)
+	err := c.cc.Invoke(ctx, ForumService_ListForumUsers_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *forumServiceClient) ListForumThreads(ctx context.Context, in *ListForumThreadsRequest, opts ...grpc.CallOption) (*Threads, error) {
+	out := new(Threads)
+	err := c.cc.Invoke(ctx, ForumService_ListForumThreads_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ForumServiceServer is the server API for ForumService service.
+// All implementations must embed UnimplementedForumServiceServer
+// for forward compatibility
+type ForumServiceServer interface {
+	// Создание форума, при конфликте в деталях статуса Forum.
+	CreateForum(context.Context, *Forum) (*Forum, error)
+	GetForum(context.Context, *GetForumRequest) (*Forum, error)
+	// Создание ветки в форуме thread.forum, при конфликте в деталях статуса Thread.
+	CreateThread(context.Context, *Thread) (*Thread, error)
+	ListForumUsers(context.Context, *ListForumUsersRequest) (*Users, error)
+	ListForumThreads(context.Context, *ListForumThreadsRequest) (*Threads, error)
+	mustEmbedUnimplementedForumServiceServer()
+}
+
+// UnimplementedForumServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedForumServiceServer struct {
+}
+
+func (UnimplementedForumServiceServer) CreateForum(context.Context, *Forum) (*Forum, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateForum not implemented")
+}
+func (UnimplementedForumServiceServer) GetForum(context.Context, *GetForumRequest) (*Forum, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetForum not implemented")
+}
+func (UnimplementedForumServiceServer) CreateThread(context.Context, *Thread) (*Thread, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateThread not implemented")
+}
+func (UnimplementedForumServiceServer) ListForumUsers(context.Context, *ListForumUsersRequest) (*Users, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListForumUsers not implemented")
+}
+func (UnimplementedForumServiceServer) ListForumThreads(context.Context, *ListForumThreadsRequest) (*Threads, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListForumThreads not implemented")
+}
+func (UnimplementedForumServiceServer) mustEmbedUnimplementedForumServiceServer() {}
+
+// UnsafeForumServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ForumServiceServer will
+// result in compilation errors.
+type UnsafeForumServiceServer interface {
+	mustEmbedUnimplementedForumServiceServer()
+}
+
+func RegisterForumServiceServer(s grpc.ServiceRegistrar, srv ForumServiceServer) {
+	s.RegisterService(&ForumService_ServiceDesc, srv)
+}
+
+func _ForumService_CreateForum_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Forum)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ForumServiceServer).CreateForum(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ForumService_CreateForum_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ForumServiceServer).CreateForum(ctx, req.(*Forum))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ForumService_GetForum_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetForumRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ForumServiceServer).GetForum(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ForumService_GetForum_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ForumServiceServer).GetForum(ctx, req.(*GetForumRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ForumService_CreateThread_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Thread)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ForumServiceServer).CreateThread(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ForumService_CreateThread_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ForumServiceServer).CreateThread(ctx, req.(*Thread))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ForumService_ListForumUsers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListForumUsersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ForumServiceServer).ListForumUsers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ForumService_ListForumUsers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ForumServiceServer).ListForumUsers(ctx, req.(*ListForumUsersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ForumService_ListForumThreads_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListForumThreadsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ForumServiceServer).ListForumThreads(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ForumService_ListForumThreads_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ForumServiceServer).ListForumThreads(ctx, req.(*ListForumThreadsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// ForumService_ServiceDesc is the grpc.ServiceDesc for ForumService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ForumService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "forum.ForumService",
+	HandlerType: (*ForumServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreateForum",
+			Handler:    _ForumService_CreateForum_Handler,
+		},
+		{
+			MethodName: "GetForum",
+			Handler:    _ForumService_GetForum_Handler,
+		},
+		{
+			MethodName: "CreateThread",
+			Handler:    _ForumService_CreateThread_Handler,
+		},
+		{
+			MethodName: "ListForumUsers",
+			Handler:    _ForumService_ListForumUsers_Handler,
+		},
+		{
+			MethodName: "ListForumThreads",
+			Handler:    _ForumService_ListForumThreads_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "forum_service.proto",
+}
+
+const (
+	ThreadService_GetThread_FullMethodName       = "/forum.ThreadService/GetThread"
+	ThreadService_UpdateThread_FullMethodName    = "/forum.ThreadService/UpdateThread"
+	ThreadService_CreatePosts_FullMethodName     = "/forum.ThreadService/CreatePosts"
+	ThreadService_ListThreadPosts_FullMethodName = "/forum.ThreadService/ListThreadPosts"
+	ThreadService_Vote_FullMethodName            = "/forum.ThreadService/Vote"
+)
+
+// ThreadServiceClient is the client API for ThreadService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type ThreadServiceClient interface {
+	GetThread(ctx context.Context, in *GetThreadRequest, opts ...grpc.CallOption) (*Thread, error)
+	// Пустые поля не изменяются.
+	UpdateThread(ctx context.Context, in *UpdateThreadRequest, opts ...grpc.CallOption) (*Thread, error)
+	CreatePosts(ctx context.Context, in *CreatePostsRequest, opts ...grpc.CallOption) (*Posts, error)
+	// Сообщения ветки по одному, в порядке сортировки.
+	ListThreadPosts(ctx context.Context, in *ListThreadPostsRequest, opts ...grpc.CallOption) (ThreadService_ListThreadPostsClient, error)
+	Vote(ctx context.Context, in *VoteRequest, opts ...grpc.CallOption) (*Thread, error)
+}
+
+type threadServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewThreadServiceClient(cc grpc.ClientConnInterface) ThreadServiceClient {
+	return &threadServiceClient{cc}
+}
+
+func (c *threadServiceClient) GetThread(ctx context.Context, in *GetThreadRequest, opts ...grpc.CallOption) (*Thread, error) {
+	out := new(Thread)
+	err := c.cc.Invoke(ctx, ThreadService_GetThread_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *threadServiceClient) UpdateThread(ctx context.Context, in *UpdateThreadRequest, opts ...grpc.CallOption) (*Thread, error) {
+	out := new(Thread)
+	err := c.cc.Invoke(ctx, ThreadService_UpdateThread_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *threadServiceClient) CreatePosts(ctx context.Context, in *CreatePostsRequest, opts ...grpc.CallOption) (*Posts, error) {
+	out := new(Posts)
+	err := c.cc.Invoke(ctx, ThreadService_CreatePosts_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *threadServiceClient) ListThreadPosts(ctx context.Context, in *ListThreadPostsRequest, opts ...grpc.CallOption) (ThreadService_ListThreadPostsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &ThreadService_ServiceDesc.Streams[0], ThreadService_ListThreadPosts_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &threadServiceListThreadPostsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type ThreadService_ListThreadPostsClient interface {
+	Recv() (*Post, error)
+	grpc.ClientStream
+}
+
+type threadServiceListThreadPostsClient struct {
+	grpc.ClientStream
+}
+
+func (x *threadServiceListThreadPostsClient) Recv() (*Post, error) {
+	m := new(Post)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *threadServiceClient) Vote(ctx context.Context, in *VoteRequest, opts ...grpc.CallOption) (*Thread, error) {
+	out := new(Thread)
+	err := c.cc.Invoke(ctx, ThreadService_Vote_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ThreadServiceServer is the server API for ThreadService service.
+// All implementations must embed UnimplementedThreadServiceServer
+// for forward compatibility
+type ThreadServiceServer interface {
+	GetThread(context.Context, *GetThreadRequest) (*Thread, error)
+	// Пустые поля не изменяются.
+	UpdateThread(context.Context, *UpdateThreadRequest) (*Thread, error)
+	CreatePosts(context.Context, *CreatePostsRequest) (*Posts, error)
+	// Сообщения ветки по одному, в порядке сортировки.
+	ListThreadPosts(*ListThreadPostsRequest, ThreadService_ListThreadPostsServer) error
+	Vote(context.Context, *VoteRequest) (*Thread, error)
+	mustEmbedUnimplementedThreadServiceServer()
+}
+
+// UnimplementedThreadServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedThreadServiceServer struct {
+}
+
+func (UnimplementedThreadServiceServer) GetThread(context.Context, *GetThreadRequest) (*Thread, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetThread not implemented")
+}
+func (UnimplementedThreadServiceServer) UpdateThread(context.Context, *UpdateThreadRequest) (*Thread, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateThread not implemented")
+}
+func (UnimplementedThreadServiceServer) CreatePosts(context.Context, *CreatePostsRequest) (*Posts, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreatePosts not implemented")
+}
+func (UnimplementedThreadServiceServer) ListThreadPosts(*ListThreadPostsRequest, ThreadService_ListThreadPostsServer) error {
+	return status.Errorf(codes.Unimplemented, "method ListThreadPosts not implemented")
+}
+func (UnimplementedThreadServiceServer) Vote(context.Context, *VoteRequest) (*Thread, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Vote not implemented")
+}
+func (UnimplementedThreadServiceServer) mustEmbedUnimplementedThreadServiceServer() {}
+
+// UnsafeThreadServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ThreadServiceServer will
+// result in compilation errors.
+type UnsafeThreadServiceServer interface {
+	mustEmbedUnimplementedThreadServiceServer()
+}
+
+func RegisterThreadServiceServer(s grpc.ServiceRegistrar, srv ThreadServiceServer) {
+	s.RegisterService(&ThreadService_ServiceDesc, srv)
+}
+
+func _ThreadService_GetThread_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetThreadRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ThreadServiceServer).GetThread(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ThreadService_GetThread_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ThreadServiceServer).GetThread(ctx, req.(*GetThreadRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ThreadService_UpdateThread_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateThreadRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ThreadServiceServer).UpdateThread(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ThreadService_UpdateThread_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ThreadServiceServer).UpdateThread(ctx, req.(*UpdateThreadRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ThreadService_CreatePosts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreatePostsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ThreadServiceServer).CreatePosts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ThreadService_CreatePosts_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ThreadServiceServer).CreatePosts(ctx, req.(*CreatePostsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ThreadService_ListThreadPosts_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ListThreadPostsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ThreadServiceServer).ListThreadPosts(m, &threadServiceListThreadPostsServer{stream})
+}
+
+type ThreadService_ListThreadPostsServer interface {
+	Send(*Post) error
+	grpc.ServerStream
+}
+
+type threadServiceListThreadPostsServer struct {
+	grpc.ServerStream
+}
+
+func (x *threadServiceListThreadPostsServer) Send(m *Post) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _ThreadService_Vote_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VoteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ThreadServiceServer).Vote(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ThreadService_Vote_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ThreadServiceServer).Vote(ctx, req.(*VoteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// ThreadService_ServiceDesc is the grpc.ServiceDesc for ThreadService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ThreadService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "forum.ThreadService",
+	HandlerType: (*ThreadServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetThread",
+			Handler:    _ThreadService_GetThread_Handler,
+		},
+		{
+			MethodName: "UpdateThread",
+			Handler:    _ThreadService_UpdateThread_Handler,
+		},
+		{
+			MethodName: "CreatePosts",
+			Handler:    _ThreadService_CreatePosts_Handler,
+		},
+		{
+			MethodName: "Vote",
+			Handler:    _ThreadService_Vote_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ListThreadPosts",
+			Handler:       _ThreadService_ListThreadPosts_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "forum_service.proto",
+}
+
+const (
+	PostService_GetPost_FullMethodName    = "/forum.PostService/GetPost"
+	PostService_UpdatePost_FullMethodName = "/forum.PostService/UpdatePost"
+)
+
+// PostServiceClient is the client API for PostService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type PostServiceClient interface {
+	GetPost(ctx context.Context, in *GetPostRequest, opts ...grpc.CallOption) (*PostFull, error)
+	UpdatePost(ctx context.Context, in *UpdatePostRequest, opts ...grpc.CallOption) (*Post, error)
+}
+
+type postServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewPostServiceClient(cc grpc.ClientConnInterface) PostServiceClient {
+	return &postServiceClient{cc}
+}
+
+func (c *postServiceClient) GetPost(ctx context.Context, in *GetPostRequest, opts ...grpc.CallOption) (*PostFull, error) {
+	out := new(PostFull)
+	err := c.cc.Invoke(ctx, PostService_GetPost_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *postServiceClient) UpdatePost(ctx context.Context, in *UpdatePostRequest, opts ...grpc.CallOption) (*Post, error) {
+	out := new(Post)
+	err := c.cc.Invoke(ctx, PostService_UpdatePost_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// PostServiceServer is the server API for PostService service.
+// All implementations must embed UnimplementedPostServiceServer
+// for forward compatibility
+type PostServiceServer interface {
+	GetPost(context.Context, *GetPostRequest) (*PostFull, error)
+	UpdatePost(context.Context, *UpdatePostRequest) (*Post, error)
+	mustEmbedUnimplementedPostServiceServer()
+}
+
+// UnimplementedPostServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedPostServiceServer struct {
+}
+
+func (UnimplementedPostServiceServer) GetPost(context.Context, *GetPostRequest) (*PostFull, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPost not implemented")
+}
+func (UnimplementedPostServiceServer) UpdatePost(context.Context, *UpdatePostRequest) (*Post, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdatePost not implemented")
+}
+func (UnimplementedPostServiceServer) mustEmbedUnimplementedPostServiceServer() {}
+
+// UnsafePostServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to PostServiceServer will
+// result in compilation errors.
+type UnsafePostServiceServer interface {
+	mustEmbedUnimplementedPostServiceServer()
+}
+
+func RegisterPostServiceServer(s grpc.ServiceRegistrar, srv PostServiceServer) {
+	s.RegisterService(&PostService_ServiceDesc, srv)
+}
+
+func _PostService_GetPost_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPostRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PostServiceServer).GetPost(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PostService_GetPost_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PostServiceServer).GetPost(ctx, req.(*GetPostRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PostService_UpdatePost_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdatePostRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PostServiceServer).UpdatePost(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PostService_UpdatePost_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PostServiceServer).UpdatePost(ctx, req.(*UpdatePostRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// PostService_ServiceDesc is the grpc.ServiceDesc for PostService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var PostService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "forum.PostService",
+	HandlerType: (*PostServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetPost",
+			Handler:    _PostService_GetPost_Handler,
+		},
+		{
+			MethodName: "UpdatePost",
+			Handler:    _PostService_UpdatePost_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "forum_service.proto",
+}
+
+const (
+	DatabaseService_GetStatus_FullMethodName = "/forum.DatabaseService/GetStatus"
+	DatabaseService_Clear_FullMethodName     = "/forum.DatabaseService/Clear"
+)
+
+// DatabaseServiceClient is the client API for DatabaseService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type DatabaseServiceClient interface {
+	GetStatus(ctx context.Context, in *GetStatusRequest, opts ...grpc.CallOption) (*Status, error)
+	// Безвозвратное удаление всех данных.
+	Clear(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*emptypb.Empty, error)
+}
+
+type databaseServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewDatabaseServiceClient(cc grpc.ClientConnInterface) DatabaseServiceClient {
+	return &databaseServiceClient{cc}
+}
+
+func (c *databaseServiceClient) GetStatus(ctx context.Context, in *GetStatusRequest, opts ...grpc.CallOption) (*Status, error) {
+	out := new(Status)
+	err := c.cc.Invoke(ctx, DatabaseService_GetStatus_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *databaseServiceClient) Clear(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, DatabaseService_Clear_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DatabaseServiceServer is the server API for DatabaseService service.
+// All implementations must embed UnimplementedDatabaseServiceServer
+// for forward compatibility
+type DatabaseServiceServer interface {
+	GetStatus(context.Context, *GetStatusRequest) (*Status, error)
+	// Безвозвратное удаление всех данных.
+	Clear(context.Context, *emptypb.Empty) (*emptypb.Empty, error)
+	mustEmbedUnimplementedDatabaseServiceServer()
+}
+
+// UnimplementedDatabaseServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedDatabaseServiceServer struct {
+}
+
+func (UnimplementedDatabaseServiceServer) GetStatus(context.Context, *GetStatusRequest) (*Status, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStatus not implemented")
+}
+func (UnimplementedDatabaseServiceServer) Clear(context.Context, *emptypb.Empty) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Clear not implemented")
+}
+func (UnimplementedDatabaseServiceServer) mustEmbedUnimplementedDatabaseServiceServer() {}
+
+// UnsafeDatabaseServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to DatabaseServiceServer will
+// result in compilation errors.
+type UnsafeDatabaseServiceServer interface {
+	mustEmbedUnimplementedDatabaseServiceServer()
+}
+
+func RegisterDatabaseServiceServer(s grpc.ServiceRegistrar, srv DatabaseServiceServer) {
+	s.RegisterService(&DatabaseService_ServiceDesc, srv)
+}
+
+func _DatabaseService_GetStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseServiceServer).GetStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DatabaseService_GetStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseServiceServer).GetStatus(ctx, req.(*GetStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatabaseService_Clear_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseServiceServer).Clear(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DatabaseService_Clear_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseServiceServer).Clear(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// DatabaseService_ServiceDesc is the grpc.ServiceDesc for DatabaseService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var DatabaseService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "forum.DatabaseService",
+	HandlerType: (*DatabaseServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetStatus",
+			Handler:    _DatabaseService_GetStatus_Handler,
+		},
+		{
+			MethodName: "Clear",
+			Handler:    _DatabaseService_Clear_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "forum_service.proto",
+}
@@ -0,0 +1,57 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+type memoryStore struct {
+	mu         sync.Mutex
+	buckets    map[string]*bucket
+	maxBuckets int
+}
+
+func NewMemoryStore(maxBuckets int) Store {
+	return &memoryStore{buckets: make(map[string]*bucket), maxBuckets: maxBuckets}
+}
+
+func (s *memoryStore) Take(_ context.Context, key string, limit Limit) (bool, time.Duration, error) {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b, ok := s.buckets[key]
+	if !ok {
+		if len(s.buckets) >= s.maxBuckets {
+			s.sweep(now, limit)
+		}
+		b = &bucket{tokens: float64(limit.Burst), updated: now}
+		s.buckets[key] = b
+	}
+
+	b.tokens = math.Min(float64(limit.Burst), b.tokens+now.Sub(b.updated).Seconds()*limit.Rate)
+	b.updated = now
+	if b.tokens < 1 {
+		return false, limit.retryAfter(b.tokens), nil
+	}
+
+	b.tokens--
+	return true, 0, nil
+}
+
+// sweep drops buckets that are full again, they behave like missing ones.
+func (s *memoryStore) sweep(now time.Time, limit Limit) {
+	for key, b := range s.buckets {
+		if b.tokens+now.Sub(b.updated).Seconds()*limit.Rate >= float64(limit.Burst) {
+			delete(s.buckets, key)
+		}
+	}
+}
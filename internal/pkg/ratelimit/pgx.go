@@ -0,0 +1,56 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type pgxStore struct {
+	pool *pgxpool.Pool
+}
+
+// NewPgxStore keeps buckets in the rate_limits table so that every replica
+// shares the same limits.
+func NewPgxStore(pool *pgxpool.Pool) Store {
+	return &pgxStore{pool: pool}
+}
+
+const takeCmd = `
+INSERT INTO rate_limits AS r (key, tokens, allowed, updated)
+VALUES ($1, $3::float8 - 1, true, now())
+ON CONFLICT (key) DO UPDATE
+SET tokens = case
+                 when LEAST($3::float8, r.tokens + EXTRACT(EPOCH FROM now() - r.updated) * $2::float8) >= 1
+                     then LEAST($3::float8, r.tokens + EXTRACT(EPOCH FROM now() - r.updated) * $2::float8) - 1
+                 else LEAST($3::float8, r.tokens + EXTRACT(EPOCH FROM now() - r.updated) * $2::float8) end,
+    allowed = LEAST($3::float8, r.tokens + EXTRACT(EPOCH FROM now() - r.updated) * $2::float8) >= 1,
+    updated = now()
+RETURNING tokens, allowed;`
+
+const cleanupCmd = `
+DELETE FROM rate_limits
+WHERE updated < now() - make_interval(secs => $1);`
+
+func (s *pgxStore) Cleanup(ctx context.Context, idle time.Duration) (int64, error) {
+	tag, err := s.pool.Exec(ctx, cleanupCmd, idle.Seconds())
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+func (s *pgxStore) Take(ctx context.Context, key string, limit Limit) (bool, time.Duration, error) {
+	var tokens float64
+	var allowed bool
+	row := s.pool.QueryRow(ctx, takeCmd, key, limit.Rate, limit.Burst)
+	if err := row.Scan(&tokens, &allowed); err != nil {
+		return true, 0, err
+	}
+
+	if !allowed {
+		return false, limit.retryAfter(tokens), nil
+	}
+	return true, 0, nil
+}
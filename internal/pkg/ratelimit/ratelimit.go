@@ -0,0 +1,168 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Limit describes a token bucket: Rate tokens are added per second up to Burst.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+func (l Limit) Enabled() bool {
+	return l.Rate > 0 && l.Burst > 0
+}
+
+// retryAfter returns the time needed to refill one token when tokens are left.
+func (l Limit) retryAfter(tokens float64) time.Duration {
+	if tokens >= 1 {
+		return 0
+	}
+	return time.Duration(math.Ceil((1 - tokens) / l.Rate * float64(time.Second)))
+}
+
+// refill returns the time an empty bucket needs to become full.
+func (l Limit) refill() time.Duration {
+	return time.Duration(math.Ceil(float64(l.Burst) / l.Rate * float64(time.Second)))
+}
+
+type Store interface {
+	// Take removes one token from the bucket stored under key. When the bucket
+	// is empty it reports false and the time after which a token is available.
+	Take(ctx context.Context, key string, limit Limit) (bool, time.Duration, error)
+}
+
+// Cleaner is implemented by stores that keep buckets outside the process and
+// need idle ones to be deleted.
+type Cleaner interface {
+	// Cleanup deletes buckets not touched for idle and reports how many.
+	Cleanup(ctx context.Context, idle time.Duration) (int64, error)
+}
+
+// Route groups
+const (
+	GroupUsers   = "users"
+	GroupForums  = "forums"
+	GroupThreads = "threads"
+	GroupPosts   = "posts"
+	GroupVotes   = "votes"
+)
+
+type Group struct {
+	PerIP   Limit
+	PerUser Limit
+}
+
+type Limiter struct {
+	store      Store
+	groups     map[string]Group
+	trustProxy bool
+	log        *zap.Logger
+}
+
+// NewLimiter creates a limiter for the given route groups. With trustProxy the
+// client address is taken from X-Forwarded-For / X-Real-IP headers.
+func NewLimiter(store Store, groups map[string]Group, trustProxy bool, log *zap.Logger) *Limiter {
+	return &Limiter{store: store, groups: groups, trustProxy: trustProxy, log: log}
+}
+
+func (l *Limiter) TrustProxy() bool {
+	return l != nil && l.trustProxy
+}
+
+// Allow takes a token from the ip bucket of the group. Store errors are logged
+// and the request is let through.
+func (l *Limiter) Allow(ctx context.Context, group, ip string) (bool, time.Duration) {
+	if l == nil {
+		return true, 0
+	}
+
+	limits, ok := l.groups[group]
+	if !ok {
+		return true, 0
+	}
+
+	if limits.PerIP.Enabled() && ip != "" {
+		if ok, retry := l.take(ctx, "ip:"+group+":"+ip, limits.PerIP); !ok {
+			return false, retry
+		}
+	}
+	return true, 0
+}
+
+// AllowUsers takes a token from the user bucket of the group for every acting
+// nickname, which handlers know only once the body is decoded. Nicknames are
+// case-insensitive, each one is charged once per request.
+func (l *Limiter) AllowUsers(ctx context.Context, group string, nicknames ...string) (bool, time.Duration) {
+	if l == nil {
+		return true, 0
+	}
+
+	limits, ok := l.groups[group]
+	if !ok || !limits.PerUser.Enabled() {
+		return true, 0
+	}
+
+	seen := make(map[string]struct{}, len(nicknames))
+	for _, nickname := range nicknames {
+		key := strings.ToLower(nickname)
+		if _, ok := seen[key]; ok || key == "" {
+			continue
+		}
+		seen[key] = struct{}{}
+		if ok, retry := l.take(ctx, "user:"+group+":"+key, limits.PerUser); !ok {
+			return false, retry
+		}
+	}
+	return true, 0
+}
+
+// Cleanup deletes idle buckets every interval until ctx is done. Buckets idle
+// for the longest refill time of the groups are full and behave like missing
+// ones. It returns at once when the store needs no cleanup.
+func (l *Limiter) Cleanup(ctx context.Context, interval time.Duration) {
+	cleaner, ok := l.store.(Cleaner)
+	if !ok {
+		return
+	}
+
+	var idle time.Duration
+	for _, group := range l.groups {
+		for _, limit := range []Limit{group.PerIP, group.PerUser} {
+			if limit.Enabled() && limit.refill() > idle {
+				idle = limit.refill()
+			}
+		}
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			deleted, err := cleaner.Cleanup(ctx, idle)
+			if err != nil {
+				l.log.Error("Failed to clean up rate limits", zap.Error(err))
+				continue
+			}
+			l.log.Debug("Rate limits cleaned up", zap.Int64("deleted", deleted))
+		}
+	}
+}
+
+func (l *Limiter) take(ctx context.Context, key string, limit Limit) (bool, time.Duration) {
+	ok, retry, err := l.store.Take(ctx, key, limit)
+	if err != nil {
+		l.log.Error("Rate limit store error", zap.String("key", key), zap.Error(err))
+		return true, 0
+	}
+	return ok, retry
+}
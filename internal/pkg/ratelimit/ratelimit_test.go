@@ -0,0 +1,129 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestMemoryStoreBucket(t *testing.T) {
+	store := NewMemoryStore(10)
+	limit := Limit{Rate: 1, Burst: 2}
+
+	for i := 0; i < limit.Burst; i++ {
+		if ok, _, _ := store.Take(context.Background(), "key", limit); !ok {
+			t.Fatalf("request %d of the burst was limited", i+1)
+		}
+	}
+	ok, retry, err := store.Take(context.Background(), "key", limit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok {
+		t.Fatal("request over the burst was allowed")
+	}
+	if retry <= 0 || retry > time.Second {
+		t.Fatalf("retry after %s, want (0, 1s]", retry)
+	}
+
+	if ok, _, _ := store.Take(context.Background(), "other", limit); !ok {
+		t.Fatal("another key shares the bucket")
+	}
+}
+
+func TestMemoryStoreRefill(t *testing.T) {
+	store := NewMemoryStore(10)
+	limit := Limit{Rate: 100, Burst: 1}
+
+	store.Take(context.Background(), "key", limit)
+	if ok, _, _ := store.Take(context.Background(), "key", limit); ok {
+		t.Fatal("empty bucket allowed a request")
+	}
+	time.Sleep(20 * time.Millisecond)
+	if ok, _, _ := store.Take(context.Background(), "key", limit); !ok {
+		t.Fatal("bucket was not refilled")
+	}
+}
+
+func TestMemoryStoreSweep(t *testing.T) {
+	store := NewMemoryStore(2).(*memoryStore)
+	limit := Limit{Rate: 1000, Burst: 1}
+
+	store.Take(context.Background(), "a", limit)
+	store.Take(context.Background(), "b", limit)
+	time.Sleep(5 * time.Millisecond)
+	store.Take(context.Background(), "c", limit)
+	if len(store.buckets) != 1 {
+		t.Fatalf("%d buckets kept, want only the new one", len(store.buckets))
+	}
+}
+
+func TestLimitRetryAfter(t *testing.T) {
+	limit := Limit{Rate: 2, Burst: 4}
+	if retry := limit.retryAfter(1); retry != 0 {
+		t.Fatalf("retry after %s with a token left", retry)
+	}
+	if retry := limit.retryAfter(0.5); retry != 250*time.Millisecond {
+		t.Fatalf("retry after %s, want 250ms", retry)
+	}
+	if refill := limit.refill(); refill != 2*time.Second {
+		t.Fatalf("refill %s, want 2s", refill)
+	}
+}
+
+type failingStore struct{}
+
+func (failingStore) Take(context.Context, string, Limit) (bool, time.Duration, error) {
+	return false, 0, errors.New("store is down")
+}
+
+func TestLimiterAllow(t *testing.T) {
+	groups := map[string]Group{GroupPosts: {PerIP: Limit{Rate: 1, Burst: 1}}}
+	limiter := NewLimiter(NewMemoryStore(10), groups, false, zap.NewNop())
+
+	if ok, _ := limiter.Allow(context.Background(), GroupPosts, "10.0.0.1"); !ok {
+		t.Fatal("first request was limited")
+	}
+	if ok, retry := limiter.Allow(context.Background(), GroupPosts, "10.0.0.1"); ok || retry <= 0 {
+		t.Fatalf("second request: allowed %v, retry after %s", ok, retry)
+	}
+	if ok, _ := limiter.Allow(context.Background(), GroupPosts, "10.0.0.2"); !ok {
+		t.Fatal("another ip was limited")
+	}
+	if ok, _ := limiter.Allow(context.Background(), GroupUsers, "10.0.0.1"); !ok {
+		t.Fatal("group without limits was limited")
+	}
+
+	var nilLimiter *Limiter
+	if ok, _ := nilLimiter.Allow(context.Background(), GroupPosts, "10.0.0.1"); !ok {
+		t.Fatal("nil limiter limited a request")
+	}
+
+	failing := NewLimiter(failingStore{}, groups, false, zap.NewNop())
+	if ok, _ := failing.Allow(context.Background(), GroupPosts, "10.0.0.1"); !ok {
+		t.Fatal("store error limited a request")
+	}
+}
+
+func TestLimiterAllowUsers(t *testing.T) {
+	groups := map[string]Group{GroupPosts: {PerUser: Limit{Rate: 1, Burst: 1}}}
+	limiter := NewLimiter(NewMemoryStore(10), groups, false, zap.NewNop())
+
+	// A batch by one author takes one token.
+	if ok, _ := limiter.AllowUsers(context.Background(), GroupPosts, "alice", "Alice", "bob"); !ok {
+		t.Fatal("first batch was limited")
+	}
+	if ok, retry := limiter.AllowUsers(context.Background(), GroupPosts, "ALICE"); ok || retry <= 0 {
+		t.Fatalf("second request of alice: allowed %v, retry after %s", ok, retry)
+	}
+	if ok, _ := limiter.AllowUsers(context.Background(), GroupPosts, "carol", ""); !ok {
+		t.Fatal("another user was limited")
+	}
+	// The ip bucket of the group is not touched.
+	if ok, _ := limiter.Allow(context.Background(), GroupPosts, "10.0.0.1"); !ok {
+		t.Fatal("ip was limited by user buckets")
+	}
+}
@@ -0,0 +1,342 @@
+package reconcile
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/constants"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/db"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/tracing"
+)
+
+// The counter triggers update forums, threads and stats_forums rows, so
+// locking the rows to fix makes concurrent inserts wait and apply their
+// increments on top of the recomputed values. Only the rows found wrong are
+// locked, after the check, so inserts into the other forums do not wait.
+const lockForumsCmd = `
+SELECT f.slug
+FROM forums f
+WHERE f.slug = ANY ($1::text[]::citext[])
+ORDER BY f.id
+FOR UPDATE;`
+
+const lockThreadsCmd = `
+SELECT t.id
+FROM threads t
+WHERE t.id = ANY ($1::bigint[])
+ORDER BY t.id
+FOR UPDATE;`
+
+const lockStatsForumsCmd = `
+SELECT s.forum
+FROM stats_forums s
+WHERE s.forum = ANY ($1::text[]::citext[])
+ORDER BY s.forum
+FOR UPDATE;`
+
+const forumCountersCmd = `
+SELECT f.slug, coalesce(f.threads, 0), coalesce(t.n, 0), coalesce(f.posts, 0), coalesce(p.n, 0)
+FROM forums f
+         LEFT JOIN (SELECT forum, count(*) AS n
+                    FROM threads
+                    WHERE forum = ANY ($1::text[]::citext[])
+                    GROUP BY forum) t ON t.forum = f.slug
+         LEFT JOIN (SELECT forum, count(*) AS n
+                    FROM posts
+                    WHERE forum = ANY ($1::text[]::citext[])
+                    GROUP BY forum) p ON p.forum = f.slug
+WHERE f.slug = ANY ($1::text[]::citext[])
+  AND (f.threads IS DISTINCT FROM coalesce(t.n, 0) OR f.posts IS DISTINCT FROM coalesce(p.n, 0));`
+
+const threadVotesCmd = `
+SELECT t.forum, t.id, coalesce(t.votes, 0), coalesce(v.n, 0)
+FROM threads t
+         LEFT JOIN (SELECT v.thread, sum(v.voice) AS n
+                    FROM votes v
+                             JOIN threads t ON t.id = v.thread
+                    WHERE t.forum = ANY ($1::text[]::citext[])
+                    GROUP BY v.thread) v ON v.thread = t.id
+WHERE t.forum = ANY ($1::text[]::citext[])
+  AND t.votes IS DISTINCT FROM coalesce(v.n, 0);`
+
+// expectedForumUsersCmd is the forum_users content derived from threads,
+// posts and users.
+const expectedForumUsersCmd = `
+WITH expected AS (SELECT a.forum,
+                         u.nickname COLLATE "ucs_basic" AS nickname,
+                         u.fullname,
+                         coalesce(u.about, '')         AS about,
+                         u.email
+                  FROM (SELECT forum, author
+                        FROM threads
+                        WHERE forum = ANY ($1::text[]::citext[])
+                        UNION
+                        SELECT forum, author
+                        FROM posts
+                        WHERE forum = ANY ($1::text[]::citext[])) a
+                           JOIN users u ON u.nickname = a.author)`
+
+const forumUsersCmd = expectedForumUsersCmd + `
+SELECT f.slug,
+       (SELECT count(*) FROM forum_users fu WHERE fu.forum = f.slug),
+       (SELECT count(*) FROM expected e WHERE e.forum = f.slug),
+       (SELECT count(*)
+        FROM expected e
+        WHERE e.forum = f.slug
+          AND NOT EXISTS(SELECT 1 FROM forum_users fu WHERE fu.forum = e.forum AND fu.nickname = e.nickname)),
+       (SELECT count(*)
+        FROM forum_users fu
+        WHERE fu.forum = f.slug
+          AND NOT EXISTS(SELECT 1 FROM expected e WHERE e.forum = fu.forum AND e.nickname = fu.nickname)),
+       (SELECT count(*)
+        FROM forum_users fu
+                 JOIN expected e ON e.forum = fu.forum AND e.nickname = fu.nickname
+        WHERE fu.forum = f.slug
+          AND (fu.fullname, fu.about, fu.email) IS DISTINCT FROM (e.fullname, e.about, e.email))
+FROM forums f
+WHERE f.slug = ANY ($1::text[]::citext[]);`
+
+// statsForumsCmd compares the stats_forums rows with the source tables. Users
+// are compared with the expected forum_users, which are fixed before them.
+const statsForumsCmd = expectedForumUsersCmd + `
+SELECT f.slug,
+       coalesce(s.threads, 0),
+       (SELECT count(*) FROM threads WHERE forum = f.slug),
+       coalesce(s.posts, 0),
+       (SELECT count(*) FROM posts WHERE forum = f.slug),
+       coalesce(s.votes, 0),
+       (SELECT count(*) FROM votes v JOIN threads t ON t.id = v.thread WHERE t.forum = f.slug),
+       coalesce(s.users, 0),
+       (SELECT count(*) FROM expected e WHERE e.forum = f.slug)
+FROM forums f
+         LEFT JOIN stats_forums s ON s.forum = f.slug
+WHERE f.slug = ANY ($1::text[]::citext[]);`
+
+const fixForumCountersCmd = `
+UPDATE forums f
+SET threads = (SELECT count(*) FROM threads WHERE forum = f.slug),
+    posts   = (SELECT count(*) FROM posts WHERE forum = f.slug)
+WHERE f.slug = ANY ($1::text[]::citext[]);`
+
+const fixThreadVotesCmd = `
+UPDATE threads t
+SET votes = coalesce((SELECT sum(voice) FROM votes WHERE thread = t.id), 0)
+WHERE t.id = ANY ($1::bigint[]);`
+
+const deleteExtraForumUsersCmd = `
+DELETE
+FROM forum_users fu
+WHERE fu.forum = ANY ($1::text[]::citext[])
+  AND NOT EXISTS(SELECT 1 FROM threads WHERE forum = fu.forum AND author = fu.nickname)
+  AND NOT EXISTS(SELECT 1 FROM posts WHERE forum = fu.forum AND author = fu.nickname);`
+
+const fixStatsForumsCmd = `
+INSERT
+INTO stats_forums (forum, threads, posts, votes, users)
+SELECT f.slug,
+       (SELECT count(*) FROM threads WHERE forum = f.slug),
+       (SELECT count(*) FROM posts WHERE forum = f.slug),
+       (SELECT count(*) FROM votes v JOIN threads t ON t.id = v.thread WHERE t.forum = f.slug),
+       (SELECT count(*) FROM forum_users WHERE forum = f.slug)
+FROM forums f
+WHERE f.slug = ANY ($1::text[]::citext[])
+ORDER BY f.slug
+ON CONFLICT (forum) DO UPDATE SET threads = excluded.threads,
+                                  posts   = excluded.posts,
+                                  votes   = excluded.votes,
+                                  users   = excluded.users;`
+
+const upsertForumUsersCmd = expectedForumUsersCmd + `
+INSERT
+INTO forum_users (forum, nickname, fullname, about, email)
+SELECT forum, nickname, fullname, about, email
+FROM expected
+ON CONFLICT (nickname, forum) DO UPDATE SET fullname = excluded.fullname,
+                                            about    = excluded.about,
+                                            email    = excluded.email
+WHERE (forum_users.fullname, forum_users.about, forum_users.email)
+          IS DISTINCT FROM (excluded.fullname, excluded.about, excluded.email);`
+
+// reconcileBatch checks slugs in one transaction and, unless dryRun, locks
+// and fixes the rows found wrong before committing. The fixes recompute the
+// values rather than apply the checked ones, so changes made between the
+// check and the locks are kept.
+func (r *Reconciler) reconcileBatch(ctx context.Context, slugs []string, dryRun bool, report *Report) error {
+	options := pgx.TxOptions{}
+	if dryRun {
+		options.AccessMode = pgx.ReadOnly
+	}
+	tx, err := r.pool.BeginTx(ctx, options)
+	if err != nil {
+		tracing.Log(ctx, r.log).Error(constants.DBError, zap.Error(err))
+		return db.Error(ctx, err)
+	}
+	defer tx.Rollback(ctx)
+
+	forums, threads, forumUsers, stats, err := r.check(ctx, tx, slugs, report)
+	if err != nil {
+		tracing.Log(ctx, r.log).Error(constants.DBError, zap.Error(err))
+		return db.Error(ctx, err)
+	}
+	if dryRun || len(forums)+len(threads)+len(forumUsers)+len(stats) == 0 {
+		return nil
+	}
+
+	// forum_users are written by the same inserts that update the forum
+	// counters, so their forums are locked too.
+	locks := &pgx.Batch{}
+	if locked := union(forums, forumUsers); len(locked) > 0 {
+		locks.Queue(lockForumsCmd, locked)
+	}
+	if len(threads) > 0 {
+		locks.Queue(lockThreadsCmd, threads)
+	}
+	if len(stats) > 0 {
+		locks.Queue(lockStatsForumsCmd, stats)
+	}
+	if err = tx.SendBatch(ctx, locks).Close(); err != nil {
+		tracing.Log(ctx, r.log).Error(constants.DBError, zap.Error(err))
+		return db.Error(ctx, err)
+	}
+
+	batch := &pgx.Batch{}
+	if len(forums) > 0 {
+		batch.Queue(fixForumCountersCmd, forums)
+	}
+	if len(threads) > 0 {
+		batch.Queue(fixThreadVotesCmd, threads)
+	}
+	if len(forumUsers) > 0 {
+		batch.Queue(deleteExtraForumUsersCmd, forumUsers)
+		batch.Queue(upsertForumUsersCmd, forumUsers)
+	}
+	// After forum_users, whose triggers change the users counts.
+	if len(stats) > 0 {
+		batch.Queue(fixStatsForumsCmd, stats)
+	}
+	if batch.Len() > 0 {
+		if err = tx.SendBatch(ctx, batch).Close(); err != nil {
+			tracing.Log(ctx, r.log).Error(constants.DBError, zap.Error(err))
+			return db.Error(ctx, err)
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		tracing.Log(ctx, r.log).Error(constants.DBError, zap.Error(err))
+		return db.Error(ctx, err)
+	}
+	return nil
+}
+
+func union(a, b []string) []string {
+	seen := make(map[string]struct{}, len(a)+len(b))
+	result := make([]string, 0, len(a)+len(b))
+	for _, s := range append(a[:len(a):len(a)], b...) {
+		if _, ok := seen[s]; !ok {
+			seen[s] = struct{}{}
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+// check adds the discrepancies of slugs to report and returns what to fix:
+// forums with wrong counters, threads with wrong votes, forums with wrong
+// forum_users and forums with wrong stats_forums rows.
+func (r *Reconciler) check(ctx context.Context, tx pgx.Tx, slugs []string, report *Report) (forums []string, threads []int64, forumUsers []string, stats []string, err error) {
+	rows, err := tx.Query(ctx, forumCountersCmd, slugs)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	for rows.Next() {
+		var slug string
+		var storedThreads, actualThreads, storedPosts, actualPosts int64
+		if err = rows.Scan(&slug, &storedThreads, &actualThreads, &storedPosts, &actualPosts); err != nil {
+			rows.Close()
+			return nil, nil, nil, nil, err
+		}
+		forums = append(forums, slug)
+		report.ForumCounters++
+		if storedThreads != actualThreads {
+			report.add(Discrepancy{Forum: slug, Field: "threads", Stored: storedThreads, Actual: actualThreads})
+		}
+		if storedPosts != actualPosts {
+			report.add(Discrepancy{Forum: slug, Field: "posts", Stored: storedPosts, Actual: actualPosts})
+		}
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return nil, nil, nil, nil, err
+	}
+
+	rows, err = tx.Query(ctx, threadVotesCmd, slugs)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	for rows.Next() {
+		d := Discrepancy{Field: "votes"}
+		if err = rows.Scan(&d.Forum, &d.Thread, &d.Stored, &d.Actual); err != nil {
+			rows.Close()
+			return nil, nil, nil, nil, err
+		}
+		threads = append(threads, d.Thread)
+		report.ThreadVotes++
+		report.add(d)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return nil, nil, nil, nil, err
+	}
+
+	rows, err = tx.Query(ctx, forumUsersCmd, slugs)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	for rows.Next() {
+		d := Discrepancy{Field: "forum_users"}
+		var missing, extra, stale int64
+		if err = rows.Scan(&d.Forum, &d.Stored, &d.Actual, &missing, &extra, &stale); err != nil {
+			rows.Close()
+			return nil, nil, nil, nil, err
+		}
+		if missing+extra+stale == 0 {
+			continue
+		}
+		forumUsers = append(forumUsers, d.Forum)
+		report.ForumUsersMissing += missing
+		report.ForumUsersExtra += extra
+		report.ForumUsersStale += stale
+		report.add(d)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return nil, nil, nil, nil, err
+	}
+
+	rows, err = tx.Query(ctx, statsForumsCmd, slugs)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var slug string
+		var values [8]int64
+		if err = rows.Scan(&slug, &values[0], &values[1], &values[2], &values[3], &values[4], &values[5], &values[6], &values[7]); err != nil {
+			return nil, nil, nil, nil, err
+		}
+		found := false
+		for i, field := range []string{"stats.threads", "stats.posts", "stats.votes", "stats.users"} {
+			if stored, actual := values[2*i], values[2*i+1]; stored != actual {
+				report.add(Discrepancy{Forum: slug, Field: field, Stored: stored, Actual: actual})
+				found = true
+			}
+		}
+		if found {
+			stats = append(stats, slug)
+			report.StatsForums++
+		}
+	}
+	return forums, threads, forumUsers, stats, rows.Err()
+}
@@ -0,0 +1,212 @@
+// Package reconcile recomputes the counters, forum_users rows and stats
+// aggregates maintained by triggers, which drift after manual fixes, failed
+// imports and deletes the triggers do not follow.
+package reconcile
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/constants"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/db"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/tracing"
+)
+
+const (
+	defaultBatchSize = 20
+	// maxReported caps Report.Discrepancies, the counts stay exact.
+	maxReported = 1000
+	// lockKey keeps scheduled runs of several instances from overlapping.
+	lockKey = 0x7265636f6e63696c
+)
+
+type Options struct {
+	// Forum limits the run to one forum.
+	Forum string
+	// BatchSize is the number of forums checked and fixed per transaction.
+	BatchSize int
+	// DryRun only reports discrepancies.
+	DryRun bool
+}
+
+// Discrepancy is a stored value that differs from the one computed from the
+// source tables. Thread is 0 for forum-level values.
+type Discrepancy struct {
+	Forum  string
+	Thread int64
+	Field  string
+	Stored int64
+	Actual int64
+}
+
+type Report struct {
+	DryRun        bool
+	Forums        int64
+	ForumCounters int64
+	ThreadVotes   int64
+	// forum_users rows that are missing, have no threads or posts behind
+	// them, or hold an outdated copy of the profile.
+	ForumUsersMissing int64
+	ForumUsersExtra   int64
+	ForumUsersStale   int64
+	// Forums with a wrong stats_forums row and wrong stats_totals values.
+	StatsForums   int64
+	StatsTotals   int64
+	Discrepancies []Discrepancy
+}
+
+// Clean reports whether no discrepancies were found.
+func (r *Report) Clean() bool {
+	return r.ForumCounters == 0 && r.ThreadVotes == 0 &&
+		r.ForumUsersMissing == 0 && r.ForumUsersExtra == 0 && r.ForumUsersStale == 0 &&
+		r.StatsForums == 0 && r.StatsTotals == 0
+}
+
+func (r *Report) add(d Discrepancy) {
+	if len(r.Discrepancies) < maxReported {
+		r.Discrepancies = append(r.Discrepancies, d)
+	}
+}
+
+type Reconciler struct {
+	pool *pgxpool.Pool
+	log  *zap.Logger
+}
+
+func New(pool *pgxpool.Pool, log *zap.Logger) *Reconciler {
+	return &Reconciler{pool: pool, log: log}
+}
+
+const getForumBatchCmd = `
+SELECT id, slug
+FROM forums
+WHERE id > $1
+  AND ($2 = '' OR slug = $2)
+ORDER BY id
+LIMIT $3;`
+
+// Run checks all forums, or opts.Forum, batch by batch and fixes what it
+// finds unless opts.DryRun is set. stats_totals are checked only when all
+// forums are.
+func (r *Reconciler) Run(ctx context.Context, opts *Options) (*Report, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "reconcile.Run")
+	defer span.End()
+
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+
+	report := &Report{DryRun: opts.DryRun}
+	var lastID int64
+	for {
+		slugs, err := r.nextBatch(ctx, &lastID, opts.Forum, batchSize)
+		if err != nil {
+			return report, err
+		}
+		if len(slugs) == 0 {
+			break
+		}
+		if err = r.reconcileBatch(ctx, slugs, opts.DryRun, report); err != nil {
+			return report, err
+		}
+		report.Forums += int64(len(slugs))
+	}
+
+	if opts.Forum == "" {
+		if err := r.reconcileTotals(ctx, opts.DryRun, report); err != nil {
+			return report, err
+		}
+	}
+	return report, nil
+}
+
+func (r *Reconciler) nextBatch(ctx context.Context, lastID *int64, forum string, size int) ([]string, error) {
+	rows, err := r.pool.Query(ctx, getForumBatchCmd, *lastID, forum, size)
+	if err != nil {
+		tracing.Log(ctx, r.log).Error(constants.DBError, zap.Error(err))
+		return nil, db.Error(ctx, err)
+	}
+	defer rows.Close()
+
+	slugs := make([]string, 0, size)
+	for rows.Next() {
+		var slug string
+		if err = rows.Scan(lastID, &slug); err != nil {
+			tracing.Log(ctx, r.log).Error(constants.DBError, zap.Error(err))
+			return nil, db.Error(ctx, err)
+		}
+		slugs = append(slugs, slug)
+	}
+	if err = rows.Err(); err != nil {
+		tracing.Log(ctx, r.log).Error(constants.DBError, zap.Error(err))
+		return nil, db.Error(ctx, err)
+	}
+	return slugs, nil
+}
+
+// Schedule runs the reconciliation every interval until ctx is done. A run is
+// skipped while another instance holds the lock.
+func (r *Reconciler) Schedule(ctx context.Context, interval time.Duration, opts *Options) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		started := time.Now()
+		report, locked, err := r.runLocked(ctx, opts)
+		switch {
+		case err != nil:
+			r.log.Error("Reconciliation failed", zap.Error(err))
+		case !locked:
+			r.log.Debug("Reconciliation is running elsewhere, skipped")
+		case report.Clean():
+			r.log.Info("Reconciliation found no discrepancies",
+				zap.Int64("forums", report.Forums), zap.Duration("took", time.Since(started)))
+		default:
+			r.log.Warn("Reconciliation found discrepancies",
+				zap.Bool("dry_run", report.DryRun),
+				zap.Int64("forums", report.Forums),
+				zap.Int64("forum_counters", report.ForumCounters),
+				zap.Int64("thread_votes", report.ThreadVotes),
+				zap.Int64("forum_users_missing", report.ForumUsersMissing),
+				zap.Int64("forum_users_extra", report.ForumUsersExtra),
+				zap.Int64("forum_users_stale", report.ForumUsersStale),
+				zap.Int64("stats_forums", report.StatsForums),
+				zap.Int64("stats_totals", report.StatsTotals),
+				zap.Duration("took", time.Since(started)))
+		}
+	}
+}
+
+func (r *Reconciler) runLocked(ctx context.Context, opts *Options) (*Report, bool, error) {
+	conn, err := r.pool.Acquire(ctx)
+	if err != nil {
+		return nil, false, db.Error(ctx, err)
+	}
+	defer conn.Release()
+
+	locked := false
+	if err = conn.QueryRow(ctx, `SELECT pg_try_advisory_lock($1);`, int64(lockKey)).Scan(&locked); err != nil {
+		return nil, false, db.Error(ctx, err)
+	}
+	if !locked {
+		return nil, false, nil
+	}
+	defer func() {
+		if _, err := conn.Exec(context.Background(), `SELECT pg_advisory_unlock($1);`, int64(lockKey)); err != nil {
+			r.log.Error(constants.DBError, zap.Error(err))
+		}
+	}()
+
+	report, err := r.Run(ctx, opts)
+	return report, true, err
+}
@@ -0,0 +1,59 @@
+package reconcile
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestUnion(t *testing.T) {
+	a := make([]string, 2, 4)
+	copy(a, []string{"pirates", "sea"})
+
+	got := union(a, []string{"sea", "kraken", "pirates"})
+	if want := []string{"pirates", "sea", "kraken"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("union %v, want %v", got, want)
+	}
+	// a has spare capacity, appending must not write into it.
+	if extra := a[:4]; extra[2] != "" || extra[3] != "" {
+		t.Fatalf("union wrote into its argument: %v", extra)
+	}
+
+	if got = union(nil, nil); len(got) != 0 {
+		t.Fatalf("union of nothing %v", got)
+	}
+}
+
+func TestReportClean(t *testing.T) {
+	report := &Report{DryRun: true, Forums: 10}
+	if !report.Clean() {
+		t.Fatal("checked forums made the report dirty")
+	}
+
+	for name, dirty := range map[string]*Report{
+		"forum counters":      {ForumCounters: 1},
+		"thread votes":        {ThreadVotes: 1},
+		"forum users missing": {ForumUsersMissing: 1},
+		"forum users extra":   {ForumUsersExtra: 1},
+		"forum users stale":   {ForumUsersStale: 1},
+		"stats forums":        {StatsForums: 1},
+		"stats totals":        {StatsTotals: 1},
+	} {
+		if dirty.Clean() {
+			t.Errorf("%s: report is clean", name)
+		}
+	}
+}
+
+func TestReportAddCapsDiscrepancies(t *testing.T) {
+	report := &Report{}
+	for i := 0; i < maxReported+10; i++ {
+		report.ThreadVotes++
+		report.add(Discrepancy{Thread: int64(i), Field: "votes"})
+	}
+	if len(report.Discrepancies) != maxReported {
+		t.Fatalf("%d discrepancies kept, max %d", len(report.Discrepancies), maxReported)
+	}
+	if report.ThreadVotes != maxReported+10 {
+		t.Fatalf("count %d, want the exact %d", report.ThreadVotes, maxReported+10)
+	}
+}
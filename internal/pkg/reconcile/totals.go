@@ -0,0 +1,99 @@
+package reconcile
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/constants"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/db"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/tracing"
+)
+
+const lockStatsTotalsCmd = `
+SELECT name
+FROM stats_totals
+WHERE name IN ('active_users', 'forums', 'users')
+ORDER BY name
+FOR UPDATE;`
+
+const actualTotalsCmd = `
+WITH actual (name, value) AS (VALUES ('users', (SELECT count(*) FROM users)),
+                                     ('forums', (SELECT count(*) FROM forums)),
+                                     ('active_users', (SELECT count(DISTINCT nickname) FROM forum_users)))`
+
+const statsTotalsCmd = actualTotalsCmd + `
+SELECT a.name, coalesce(s.value, 0), a.value
+FROM actual a
+         LEFT JOIN stats_totals s ON s.name = a.name
+WHERE coalesce(s.value, 0) <> a.value;`
+
+const fixStatsTotalsCmd = actualTotalsCmd + `
+INSERT
+INTO stats_totals (name, value)
+SELECT name, value
+FROM actual
+WHERE name = ANY ($1::text[])
+ORDER BY name
+ON CONFLICT (name) DO UPDATE SET value = excluded.value;`
+
+// reconcileTotals checks stats_totals after the forums are fixed, so that
+// active_users is counted from the fixed forum_users.
+func (r *Reconciler) reconcileTotals(ctx context.Context, dryRun bool, report *Report) error {
+	options := pgx.TxOptions{}
+	if dryRun {
+		options.AccessMode = pgx.ReadOnly
+	}
+	tx, err := r.pool.BeginTx(ctx, options)
+	if err != nil {
+		tracing.Log(ctx, r.log).Error(constants.DBError, zap.Error(err))
+		return db.Error(ctx, err)
+	}
+	defer tx.Rollback(ctx)
+
+	if !dryRun {
+		if _, err = tx.Exec(ctx, lockStatsTotalsCmd); err != nil {
+			tracing.Log(ctx, r.log).Error(constants.DBError, zap.Error(err))
+			return db.Error(ctx, err)
+		}
+	}
+
+	rows, err := tx.Query(ctx, statsTotalsCmd)
+	if err != nil {
+		tracing.Log(ctx, r.log).Error(constants.DBError, zap.Error(err))
+		return db.Error(ctx, err)
+	}
+	var names []string
+	for rows.Next() {
+		var name string
+		d := Discrepancy{}
+		if err = rows.Scan(&name, &d.Stored, &d.Actual); err != nil {
+			rows.Close()
+			tracing.Log(ctx, r.log).Error(constants.DBError, zap.Error(err))
+			return db.Error(ctx, err)
+		}
+		d.Field = "stats." + name
+		names = append(names, name)
+		report.StatsTotals++
+		report.add(d)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		tracing.Log(ctx, r.log).Error(constants.DBError, zap.Error(err))
+		return db.Error(ctx, err)
+	}
+	if dryRun || len(names) == 0 {
+		return nil
+	}
+
+	if _, err = tx.Exec(ctx, fixStatsTotalsCmd, names); err != nil {
+		tracing.Log(ctx, r.log).Error(constants.DBError, zap.Error(err))
+		return db.Error(ctx, err)
+	}
+	if err = tx.Commit(ctx); err != nil {
+		tracing.Log(ctx, r.log).Error(constants.DBError, zap.Error(err))
+		return db.Error(ctx, err)
+	}
+	return nil
+}
@@ -0,0 +1,324 @@
+// Package synth generates reproducible forum datasets in the dump format.
+//
+// Users, forums and threads get Zipf distributed popularity, so a few forums
+// hold most of the threads and a few threads most of the posts and votes.
+// Reply trees mix chains of answers to recent posts with replies to older
+// ones, which makes them both deep and wide. The same options always produce
+// the same records.
+package synth
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"time"
+
+	"github.com/mailru/easyjson"
+
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/dump"
+)
+
+const (
+	defaultMaxDepth = 64
+	// recentWindow posts at the end of a thread are answered to grow chains.
+	recentWindow = 8
+)
+
+// defaultStart keeps the creation dates of a seed stable between runs.
+var defaultStart = time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+type Options struct {
+	Seed int64
+	// Prefix of nicknames and slugs, "syn<seed>" by default. Forums must not
+	// exist on import, so datasets loaded into one database need different
+	// prefixes or seeds.
+	Prefix  string
+	Users   int
+	Forums  int
+	Threads int
+	Posts   int
+	Votes   int
+	// MaxDepth limits the length of posts.path, 64 by default.
+	MaxDepth int
+	// Threads and posts are created within a year after Start, 2023-01-01 by default.
+	Start time.Time
+}
+
+func (o *Options) validate() error {
+	switch {
+	case o.Users < 1 || o.Forums < 1 || o.Threads < 0:
+		return errors.New("users and forums must be positive, threads must not be negative")
+	case o.Posts < 0 || o.Votes < 0:
+		return errors.New("posts and votes must not be negative")
+	case o.Posts > 0 && o.Threads == 0:
+		return errors.New("posts need threads")
+	case o.Votes > o.Threads*o.Users:
+		return fmt.Errorf("%d votes do not fit %d threads with %d users", o.Votes, o.Threads, o.Users)
+	case o.MaxDepth < 0:
+		return errors.New("max depth must not be negative")
+	}
+	return nil
+}
+
+// Walk generates the dataset and passes its records to fn in dump order:
+// users, forums, threads, posts and votes. Thread and post ids start at 1.
+func Walk(opts Options, fn func(typ string, data easyjson.Marshaler) error) (dump.Stats, error) {
+	var stats dump.Stats
+	if err := opts.validate(); err != nil {
+		return stats, err
+	}
+	g := newGenerator(opts)
+
+	for i := 0; i < opts.Users; i++ {
+		if err := fn(dump.TypeUser, g.user(i)); err != nil {
+			return stats, err
+		}
+		stats.Users++
+	}
+	for i := 0; i < opts.Forums; i++ {
+		if err := fn(dump.TypeForum, g.forum(i)); err != nil {
+			return stats, err
+		}
+		stats.Forums++
+	}
+
+	threads := g.threads()
+	for i := range threads {
+		if err := fn(dump.TypeThread, &threads[i]); err != nil {
+			return stats, err
+		}
+		stats.Threads++
+	}
+
+	rng := g.stream(4)
+	postCounts := g.spread(opts.Posts, opts.Threads, 1.1, opts.Posts)
+	var id int64
+	for i := range threads {
+		tree := newTree(rng, g.maxDepth)
+		created := threads[i].Created
+		for n := 0; n < postCounts[i]; n++ {
+			id++
+			parent, path := tree.add(id)
+			created = created.Add(time.Duration(1+rng.Int63n(int64(6*time.Hour))) / time.Second * time.Second)
+			post := &dump.Post{
+				ID:       id,
+				Parent:   parent,
+				Path:     path,
+				Thread:   threads[i].ID,
+				Author:   g.author(),
+				Message:  Text(rng, 3, 60),
+				IsEdited: rng.Intn(20) == 0,
+				Created:  created,
+			}
+			if err := fn(dump.TypePost, post); err != nil {
+				return stats, err
+			}
+			stats.Posts++
+		}
+	}
+
+	rng = g.stream(5)
+	voteCounts := g.spread(opts.Votes, opts.Threads, 1.05, opts.Users)
+	for i := range threads {
+		for _, voter := range distinct(rng, voteCounts[i], opts.Users) {
+			vote := &dump.Vote{Nickname: g.nickname(voter), Thread: threads[i].ID, Voice: 1}
+			if rng.Intn(4) == 0 {
+				vote.Voice = -1
+			}
+			if err := fn(dump.TypeVote, vote); err != nil {
+				return stats, err
+			}
+			stats.Votes++
+		}
+	}
+	return stats, nil
+}
+
+// Generate writes the dataset as a dump that Dumper.Import or forumctl import loads.
+func Generate(opts Options, w io.Writer) (dump.Stats, error) {
+	out := bufio.NewWriterSize(w, 64<<10)
+	write := func(typ string, data easyjson.Marshaler) error {
+		raw, err := easyjson.Marshal(data)
+		if err != nil {
+			return err
+		}
+		if _, err = easyjson.MarshalToWriter(&dump.Record{Type: typ, Data: raw}, out); err != nil {
+			return err
+		}
+		return out.WriteByte('\n')
+	}
+
+	stats, err := Walk(opts, write)
+	if err == nil {
+		err = write(dump.TypeEnd, stats)
+	}
+	if err == nil {
+		err = out.Flush()
+	}
+	return stats, err
+}
+
+// Load streams the generated dump into Import, which writes it with COPY in
+// one transaction.
+func Load(ctx context.Context, d *dump.Dumper, opts Options) (dump.Stats, error) {
+	return LoadWith(ctx, d.Import, opts)
+}
+
+// LoadWith streams the generated dump into any importer, e.g. the admin API.
+func LoadWith(ctx context.Context, load func(ctx context.Context, r io.Reader) (dump.Stats, error), opts Options) (dump.Stats, error) {
+	if err := opts.validate(); err != nil {
+		return dump.Stats{}, err
+	}
+	pr, pw := io.Pipe()
+	go func() {
+		_, err := Generate(opts, pw)
+		pw.CloseWithError(err)
+	}()
+	stats, err := load(ctx, pr)
+	// Unblocks the generator when the import stops early.
+	pr.CloseWithError(io.ErrClosedPipe)
+	return stats, err
+}
+
+type generator struct {
+	opts     Options
+	prefix   string
+	start    time.Time
+	maxDepth int
+	// authors maps Zipf ranks to users, so the most active users are spread
+	// over the whole range.
+	authors []int
+	zipf    *rand.Zipf
+}
+
+func newGenerator(opts Options) *generator {
+	g := &generator{opts: opts, prefix: opts.Prefix, start: opts.Start, maxDepth: opts.MaxDepth}
+	if g.prefix == "" {
+		g.prefix = fmt.Sprintf("syn%d", opts.Seed)
+	}
+	if g.start.IsZero() {
+		g.start = defaultStart
+	}
+	if g.maxDepth == 0 {
+		g.maxDepth = defaultMaxDepth
+	}
+	rng := g.stream(0)
+	g.authors = rng.Perm(opts.Users)
+	g.zipf = newZipf(rng, 1.1, opts.Users)
+	return g
+}
+
+// stream returns an independent random source per part of the dataset, so
+// changing the number of posts does not change the users or threads.
+func (g *generator) stream(part int64) *rand.Rand {
+	return rand.New(rand.NewSource(g.opts.Seed*1_000_003 + part))
+}
+
+func (g *generator) nickname(i int) string { return fmt.Sprintf("%s.u%d", g.prefix, i) }
+func (g *generator) forumSlug(i int) string {
+	return fmt.Sprintf("%s-f%d", g.prefix, i)
+}
+
+// author picks a user, a few of them write most of the content. The Zipf
+// source is shared, so users are drawn in a fixed order.
+func (g *generator) author() string {
+	return g.nickname(g.authors[pick(g.zipf)])
+}
+
+func (g *generator) user(i int) *dump.User {
+	rng := rand.New(rand.NewSource(g.opts.Seed*2_000_003 + int64(i)))
+	nickname := g.nickname(i)
+	return &dump.User{
+		Nickname: nickname,
+		Fullname: firstNames[rng.Intn(len(firstNames))] + " " + lastNames[rng.Intn(len(lastNames))],
+		About:    Text(rng, 0, 30),
+		Email:    nickname + "@synth.test",
+	}
+}
+
+func (g *generator) forum(i int) *dump.Forum {
+	rng := rand.New(rand.NewSource(g.opts.Seed*3_000_003 + int64(i)))
+	return &dump.Forum{
+		Slug:  g.forumSlug(i),
+		Title: title(Text(rng, 1, 4)),
+		User:  g.nickname(rng.Intn(g.opts.Users)),
+	}
+}
+
+// threads are spread over forums by a Zipf distribution and over a year.
+func (g *generator) threads() []dump.Thread {
+	rng := g.stream(3)
+	forums := newZipf(rng, 1.2, g.opts.Forums)
+	threads := make([]dump.Thread, g.opts.Threads)
+	for i := range threads {
+		t := &threads[i]
+		t.ID = int64(i + 1)
+		t.Forum = g.forumSlug(pick(forums))
+		t.Author = g.author()
+		t.Title = title(Text(rng, 2, 8))
+		t.Message = Text(rng, 10, 120)
+		t.Created = g.start.Add(time.Duration(rng.Int63n(int64(365*24*time.Hour))) / time.Second * time.Second)
+		if rng.Intn(5) != 0 {
+			slug := fmt.Sprintf("%s-t%d", g.prefix, i)
+			t.Slug = &slug
+		}
+	}
+	return threads
+}
+
+// spread splits total between n buckets of at most limit with Zipf
+// distributed sizes. Bucket ranks are shuffled, the hottest thread is not
+// always the first one.
+func (g *generator) spread(total, n int, s float64, limit int) []int {
+	counts := make([]int, n)
+	if n == 0 {
+		return counts
+	}
+	rng := rand.New(rand.NewSource(g.opts.Seed*4_000_003 + int64(total)))
+	perm := rng.Perm(n)
+	zipf := newZipf(rng, s, n)
+	for i := 0; i < total; i++ {
+		bucket := perm[pick(zipf)]
+		for counts[bucket] >= limit {
+			bucket = rng.Intn(n)
+		}
+		counts[bucket]++
+	}
+	return counts
+}
+
+// newZipf returns nil for a single value, rand.NewZipf needs at least two.
+func newZipf(rng *rand.Rand, s float64, n int) *rand.Zipf {
+	if n < 2 {
+		return nil
+	}
+	return rand.NewZipf(rng, s, 1, uint64(n-1))
+}
+
+// pick draws a value of z, a nil z always gives 0.
+func pick(z *rand.Zipf) int {
+	if z == nil {
+		return 0
+	}
+	return int(z.Uint64())
+}
+
+// distinct picks n different values below max.
+func distinct(rng *rand.Rand, n, max int) []int {
+	if n*2 > max {
+		return rng.Perm(max)[:n]
+	}
+	seen := make(map[int]bool, n)
+	values := make([]int, 0, n)
+	for len(values) < n {
+		v := rng.Intn(max)
+		if !seen[v] {
+			seen[v] = true
+			values = append(values, v)
+		}
+	}
+	return values
+}
@@ -0,0 +1,50 @@
+package synth
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/mailru/easyjson"
+
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/dump"
+)
+
+func generate(t *testing.T, opts Options) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if _, err := Generate(opts, &buf); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestGenerateIsReproducible(t *testing.T) {
+	opts := Options{Seed: 7, Users: 30, Forums: 4, Threads: 25, Posts: 400, Votes: 100, MaxDepth: 8}
+
+	first := generate(t, opts)
+	if second := generate(t, opts); !bytes.Equal(first, second) {
+		t.Fatal("the same options produced different dumps")
+	}
+
+	opts.Seed++
+	if other := generate(t, opts); bytes.Equal(first, other) {
+		t.Fatal("another seed produced the same dump")
+	}
+}
+
+func TestWalk(t *testing.T) {
+	opts := Options{Seed: 1, Users: 10, Forums: 2, Threads: 5, Posts: 50, Votes: 20, MaxDepth: 3}
+	stats, err := Walk(opts, func(_ string, data easyjson.Marshaler) error {
+		if post, ok := data.(*dump.Post); ok && len(post.Path) > opts.MaxDepth {
+			t.Fatalf("post %d is %d deep, max %d", post.ID, len(post.Path), opts.MaxDepth)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Users != 10 || stats.Forums != 2 || stats.Threads != 5 || stats.Posts != 50 || stats.Votes != 20 {
+		t.Fatalf("stats %+v do not match the options", stats)
+	}
+}
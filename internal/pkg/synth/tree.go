@@ -0,0 +1,47 @@
+package synth
+
+import "math/rand"
+
+// tree builds the reply tree of one thread.
+type tree struct {
+	rng      *rand.Rand
+	maxDepth int
+	ids      []int64
+	paths    [][]int64
+}
+
+func newTree(rng *rand.Rand, maxDepth int) *tree {
+	return &tree{rng: rng, maxDepth: maxDepth}
+}
+
+// add places post id in the tree and returns its parent, 0 for a root post,
+// and its path. Half of the replies answer one of the latest posts, which
+// grows long chains, the rest answer any earlier post and widen the tree.
+func (t *tree) add(id int64) (int64, []int64) {
+	var parent []int64
+	if n := len(t.ids); n > 0 && t.rng.Intn(10) != 0 {
+		i := t.rng.Intn(n)
+		if t.rng.Intn(2) == 0 {
+			window := recentWindow
+			if window > n {
+				window = n
+			}
+			i = n - 1 - t.rng.Intn(window)
+		}
+		parent = t.paths[i]
+		if len(parent) >= t.maxDepth {
+			parent = parent[:t.maxDepth-1]
+		}
+	}
+
+	path := make([]int64, len(parent)+1)
+	copy(path, parent)
+	path[len(parent)] = id
+	t.ids = append(t.ids, id)
+	t.paths = append(t.paths, path)
+
+	if len(parent) == 0 {
+		return 0, path
+	}
+	return parent[len(parent)-1], path
+}
@@ -0,0 +1,36 @@
+package synth
+
+import (
+	"math/rand"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var words = strings.Fields(`sea ship sail wind storm kraken anchor harbor island treasure map
+captain crew deck mast rope compass tide wave reef fog lighthouse cannon port starboard
+bottle rum parrot gold silver chest cove shore current voyage north south east west
+night morning cloud rain thunder whale gull net fish salt boat oar keel hull bay cape
+dock lantern signal flag chart star moon sun old young brave lost found deep calm wild`)
+
+var firstNames = strings.Fields(`Anna Boris Clara Denis Elena Fedor Galina Igor Irina Kirill
+Lena Maxim Nina Oleg Olga Pavel Rita Sergey Tanya Victor Yulia Zakhar`)
+
+var lastNames = strings.Fields(`Ivanov Smirnova Kuznetsov Popova Sokolov Lebedeva Kozlov
+Novikova Morozov Petrova Volkov Solovieva Vasiliev Zaitseva Pavlov Semenova`)
+
+// Text returns min to max random words, loadgen uses it for the posts it
+// writes while running.
+func Text(rng *rand.Rand, min, max int) string {
+	n := min + rng.Intn(max-min+1)
+	parts := make([]string, n)
+	for i := range parts {
+		parts[i] = words[rng.Intn(len(words))]
+	}
+	return strings.Join(parts, " ")
+}
+
+func title(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+	return string(unicode.ToUpper(r)) + s[size:]
+}
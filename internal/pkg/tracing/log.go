@@ -0,0 +1,27 @@
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// Log returns log with the request, trace and span ids of ctx attached.
+func Log(ctx context.Context, log *zap.Logger) *zap.Logger {
+	fields := make([]zap.Field, 0, 3)
+	if id := RequestID(ctx); id != "" {
+		fields = append(fields, zap.String("request_id", id))
+	}
+	if spanCtx := trace.SpanContextFromContext(ctx); spanCtx.IsValid() {
+		fields = append(fields,
+			zap.String("trace_id", spanCtx.TraceID().String()),
+			zap.String("span_id", spanCtx.SpanID().String()),
+		)
+	}
+
+	if len(fields) == 0 {
+		return log
+	}
+	return log.With(fields...)
+}
@@ -0,0 +1,55 @@
+package tracing
+
+import (
+	"context"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type queryTracer struct{}
+
+// NewQueryTracer creates a pgx tracer that starts a client span for every query.
+func NewQueryTracer() pgx.QueryTracer {
+	return queryTracer{}
+}
+
+func (queryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	if !trace.SpanFromContext(ctx).IsRecording() {
+		return ctx
+	}
+
+	sql := strings.TrimSpace(data.SQL)
+	ctx, _ = Tracer().Start(ctx, spanName(sql),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemPostgreSQL,
+			semconv.DBStatement(sql),
+		),
+	)
+	return ctx
+}
+
+func (queryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	span := trace.SpanFromContext(ctx)
+	if !span.IsRecording() {
+		return
+	}
+
+	span.SetAttributes(attribute.Int64("db.rows_affected", data.CommandTag.RowsAffected()))
+	if data.Err != nil {
+		span.RecordError(data.Err)
+		span.SetStatus(codes.Error, data.Err.Error())
+	}
+	span.End()
+}
+
+// spanName uses the SQL verb, e.g. "SELECT", to keep span names bounded.
+func spanName(sql string) string {
+	verb, _, _ := strings.Cut(sql, " ")
+	return "pgx " + strings.ToUpper(strings.TrimSpace(verb))
+}
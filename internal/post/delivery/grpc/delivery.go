@@ -0,0 +1,49 @@
+package grpc
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+
+	"github.com/SlavaShagalov/vk-dbms-project/internal/models"
+	mw "github.com/SlavaShagalov/vk-dbms-project/internal/pkg/middleware"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/pb"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/ratelimit"
+	pkgPost "github.com/SlavaShagalov/vk-dbms-project/internal/post"
+)
+
+type delivery struct {
+	pb.UnimplementedPostServiceServer
+	serv pkgPost.Service
+	log  *zap.Logger
+}
+
+func RegisterHandlers(server *grpc.Server, logger *zap.Logger, serv pkgPost.Service) {
+	pb.RegisterPostServiceServer(server, &delivery{serv: serv, log: logger})
+}
+
+// Routes are the HTTP routes the methods mirror.
+var Routes = mw.GRPCRoutes{
+	pb.PostService_GetPost_FullMethodName:    {Route: "GET /api/post/:id/details"},
+	pb.PostService_UpdatePost_FullMethodName: {Route: "POST /api/post/:id/details", Group: ratelimit.GroupPosts},
+}
+
+func (del *delivery) GetPost(ctx context.Context, request *pb.GetPostRequest) (*pb.PostFull, error) {
+	fullPost, err := del.serv.GetPost(ctx, int(request.GetId()), request.GetRelated())
+	if err != nil {
+		return nil, err
+	}
+	return pb.NewPostFull(&fullPost), nil
+}
+
+func (del *delivery) UpdatePost(ctx context.Context, request *pb.UpdatePostRequest) (*pb.Post, error) {
+	post, err := del.serv.UpdatePost(ctx, &models.Post{
+		Id:      int(request.GetId()),
+		Message: request.GetMessage(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pb.NewPost(&post), nil
+}
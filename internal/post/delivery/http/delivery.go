@@ -4,7 +4,9 @@ import (
 	"github.com/SlavaShagalov/vk-dbms-project/internal/models"
 	pkgErrors "github.com/SlavaShagalov/vk-dbms-project/internal/pkg/errors"
 	pkgHTTP "github.com/SlavaShagalov/vk-dbms-project/internal/pkg/http"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/metrics"
 	mw "github.com/SlavaShagalov/vk-dbms-project/internal/pkg/middleware"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/ratelimit"
 	pkgPost "github.com/SlavaShagalov/vk-dbms-project/internal/post"
 	"github.com/julienschmidt/httprouter"
 	"go.uber.org/zap"
@@ -18,11 +20,11 @@ type delivery struct {
 	log  *zap.Logger
 }
 
-func RegisterHandlers(router *httprouter.Router, log *zap.Logger, serv pkgPost.Service) {
+func RegisterHandlers(router *mw.Router, log *zap.Logger, serv pkgPost.Service, limiter *ratelimit.Limiter, m *metrics.Metrics) {
 	del := delivery{serv, log}
 
-	router.GET("/api/post/:id/details", mw.AccessLog(mw.HandleError(del.GetPost, log), log))
-	router.POST("/api/post/:id/details", mw.AccessLog(mw.HandleError(del.UpdatePost, log), log))
+	router.GET("/api/post/:id/details", mw.Trace(mw.Timeout(mw.AccessLog(mw.Metrics(mw.Conditional(mw.HandleError(del.GetPost, log)), m), log))))
+	router.POST("/api/post/:id/details", mw.Trace(mw.Timeout(mw.AccessLog(mw.Metrics(mw.HandleError(mw.RateLimit(del.UpdatePost, limiter, ratelimit.GroupPosts), log), m), log))))
 }
 
 func (del *delivery) GetPost(w http.ResponseWriter, r *http.Request, p httprouter.Params) error {
@@ -35,21 +37,15 @@ func (del *delivery) GetPost(w http.ResponseWriter, r *http.Request, p httproute
 	queryValues := r.URL.Query()
 	related := queryValues.Get("related")
 
-	fullPost, err := del.serv.GetPost(id, strings.Split(related, ","))
+	fullPost, err := del.serv.GetPost(r.Context(), id, strings.Split(related, ","))
 	if err != nil {
 		return err
 	}
-
-	data, err := fullPost.MarshalJSON()
-	if err != nil {
-		return pkgErrors.ErrInternal
+	if pkgHTTP.NotModified(w, r, fullPost.Versions()...) {
+		return nil
 	}
 
-	_, err = w.Write(data)
-	if err != nil {
-		return pkgErrors.ErrInternal
-	}
-	return nil
+	return pkgHTTP.WriteResponse(w, r, http.StatusOK, &fullPost)
 }
 
 func (del *delivery) UpdatePost(w http.ResponseWriter, r *http.Request, p httprouter.Params) error {
@@ -59,30 +55,16 @@ func (del *delivery) UpdatePost(w http.ResponseWriter, r *http.Request, p httpro
 		return pkgErrors.ErrInvalidIDParam
 	}
 
-	body, err := pkgHTTP.ReadBody(r, del.log)
-	if err != nil {
-		return err
-	}
-
 	post := models.Post{}
-	if err := post.UnmarshalJSON(body); err != nil {
-		return pkgErrors.ErrParseJSON
+	if err := pkgHTTP.ReadRequest(r, del.log, &post); err != nil {
+		return err
 	}
 
 	post.Id = id
-	post, err = del.serv.UpdatePost(&post)
+	post, err = del.serv.UpdatePost(r.Context(), &post)
 	if err != nil {
 		return err
 	}
 
-	data, err := post.MarshalJSON()
-	if err != nil {
-		return pkgErrors.ErrInternal
-	}
-
-	_, err = w.Write(data)
-	if err != nil {
-		return pkgErrors.ErrInternal
-	}
-	return nil
+	return pkgHTTP.WriteResponse(w, r, http.StatusOK, &post)
 }
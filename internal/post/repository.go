@@ -1,13 +1,15 @@
 package post
 
 import (
+	"context"
+
 	"github.com/SlavaShagalov/vk-dbms-project/internal/models"
 )
 
 type Repository interface {
-	GetPost(id int) (models.Post, error)
-	GetPostAuthor(post *models.Post) (models.User, error)
-	GetPostThread(post *models.Post) (models.Thread, error)
-	GetPostForum(post *models.Post) (models.Forum, error)
-	UpdatePost(post *models.Post) (models.Post, error)
+	GetPost(ctx context.Context, id int) (models.Post, error)
+	GetPostAuthor(ctx context.Context, post *models.Post) (models.User, error)
+	GetPostThread(ctx context.Context, post *models.Post) (models.Thread, error)
+	GetPostForum(ctx context.Context, post *models.Post) (models.Forum, error)
+	UpdatePost(ctx context.Context, post *models.Post) (models.Post, error)
 }
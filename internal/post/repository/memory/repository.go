@@ -0,0 +1,86 @@
+package memory
+
+import (
+	"context"
+	"strings"
+
+	"github.com/SlavaShagalov/vk-dbms-project/internal/models"
+	pkgErrors "github.com/SlavaShagalov/vk-dbms-project/internal/pkg/errors"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/memory"
+	pkgPost "github.com/SlavaShagalov/vk-dbms-project/internal/post"
+)
+
+type repository struct {
+	store *memory.Store
+}
+
+func NewRepository(store *memory.Store) pkgPost.Repository {
+	return &repository{store: store}
+}
+
+func (rep *repository) GetPost(_ context.Context, id int) (models.Post, error) {
+	rep.store.RLock()
+	defer rep.store.RUnlock()
+
+	post, ok := rep.store.Posts[id]
+	if !ok {
+		return models.Post{}, pkgErrors.ErrPostNotFound
+	}
+	return post.Post, nil
+}
+
+func (rep *repository) GetPostAuthor(_ context.Context, post *models.Post) (models.User, error) {
+	rep.store.RLock()
+	defer rep.store.RUnlock()
+
+	user, ok := rep.store.Users[memory.Key(post.Author)]
+	if !ok {
+		return models.User{}, pkgErrors.ErrUserNotFound
+	}
+	return *user, nil
+}
+
+// GetPostForum and GetPostThread report ErrUserNotFound for a missing row,
+// the same as the pgx repository.
+func (rep *repository) GetPostForum(_ context.Context, post *models.Post) (models.Forum, error) {
+	rep.store.RLock()
+	defer rep.store.RUnlock()
+
+	forum, ok := rep.store.Forums[memory.Key(post.Forum)]
+	if !ok {
+		return models.Forum{}, pkgErrors.ErrUserNotFound
+	}
+	return *forum, nil
+}
+
+func (rep *repository) GetPostThread(_ context.Context, post *models.Post) (models.Thread, error) {
+	rep.store.RLock()
+	defer rep.store.RUnlock()
+
+	thread, ok := rep.store.Threads[post.Thread]
+	if !ok {
+		return models.Thread{}, pkgErrors.ErrUserNotFound
+	}
+	return thread.Thread, nil
+}
+
+func (rep *repository) UpdatePost(_ context.Context, post *models.Post) (models.Post, error) {
+	rep.store.Lock()
+	defer rep.store.Unlock()
+
+	stored, ok := rep.store.Posts[post.Id]
+	if !ok {
+		return models.Post{}, pkgErrors.ErrPostNotFound
+	}
+
+	before := stored.Post
+	message := strings.Trim(post.Message, " ")
+	stored.IsEdited = message != "" && message != strings.Trim(stored.Message, " ")
+	if message != "" {
+		stored.Message = post.Message
+	}
+	if stored.Post != before {
+		stored.UpdatedAt = rep.store.Version()
+	}
+	return stored.Post, nil
+}
@@ -0,0 +1,47 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/SlavaShagalov/vk-dbms-project/internal/models"
+	pkgMetrics "github.com/SlavaShagalov/vk-dbms-project/internal/pkg/metrics"
+	pkgPost "github.com/SlavaShagalov/vk-dbms-project/internal/post"
+)
+
+const name = "post"
+
+type repository struct {
+	rep pkgPost.Repository
+	m   *pkgMetrics.Metrics
+}
+
+// NewRepository wraps rep and records the duration of every call.
+func NewRepository(rep pkgPost.Repository, m *pkgMetrics.Metrics) pkgPost.Repository {
+	return &repository{rep: rep, m: m}
+}
+
+func (rep *repository) GetPost(ctx context.Context, id int) (post models.Post, err error) {
+	defer func(start time.Time) { rep.m.ObserveQuery(name, "GetPost", start, err) }(time.Now())
+	return rep.rep.GetPost(ctx, id)
+}
+
+func (rep *repository) GetPostAuthor(ctx context.Context, post *models.Post) (user models.User, err error) {
+	defer func(start time.Time) { rep.m.ObserveQuery(name, "GetPostAuthor", start, err) }(time.Now())
+	return rep.rep.GetPostAuthor(ctx, post)
+}
+
+func (rep *repository) GetPostThread(ctx context.Context, post *models.Post) (thread models.Thread, err error) {
+	defer func(start time.Time) { rep.m.ObserveQuery(name, "GetPostThread", start, err) }(time.Now())
+	return rep.rep.GetPostThread(ctx, post)
+}
+
+func (rep *repository) GetPostForum(ctx context.Context, post *models.Post) (forum models.Forum, err error) {
+	defer func(start time.Time) { rep.m.ObserveQuery(name, "GetPostForum", start, err) }(time.Now())
+	return rep.rep.GetPostForum(ctx, post)
+}
+
+func (rep *repository) UpdatePost(ctx context.Context, post *models.Post) (updated models.Post, err error) {
+	defer func(start time.Time) { rep.m.ObserveQuery(name, "UpdatePost", start, err) }(time.Now())
+	return rep.rep.UpdatePost(ctx, post)
+}
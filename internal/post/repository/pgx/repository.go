@@ -9,7 +9,9 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/SlavaShagalov/vk-dbms-project/internal/models"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/db"
 	pkgErrors "github.com/SlavaShagalov/vk-dbms-project/internal/pkg/errors"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/tracing"
 	pkgPost "github.com/SlavaShagalov/vk-dbms-project/internal/post"
 )
 
@@ -23,70 +25,70 @@ func NewRepository(pool *pgxpool.Pool, log *zap.Logger) pkgPost.Repository {
 }
 
 const getPostById = `
-SELECT  id, parent, author, message, isEdited, forum, thread, created
+SELECT  id, parent, author, message, isEdited, forum, thread, created, updated_at
 FROM posts
 WHERE id = $1;`
 
-func (rep *repository) GetPost(id int) (models.Post, error) {
+func (rep *repository) GetPost(ctx context.Context, id int) (models.Post, error) {
 	tmp := models.Post{}
-	row := rep.pool.QueryRow(context.Background(), getPostById, id)
-	if err := row.Scan(&tmp.Id, &tmp.Parent, &tmp.Author, &tmp.Message, &tmp.IsEdited, &tmp.Forum, &tmp.Thread, &tmp.Created); err != nil {
+	row := rep.pool.QueryRow(ctx, getPostById, id)
+	if err := row.Scan(&tmp.Id, &tmp.Parent, &tmp.Author, &tmp.Message, &tmp.IsEdited, &tmp.Forum, &tmp.Thread, &tmp.Created, &tmp.UpdatedAt); err != nil {
 		if errors.Is(pgx.ErrNoRows, err) {
 			return tmp, pkgErrors.ErrPostNotFound
 		}
-		return tmp, pkgErrors.ErrInternal
+		return tmp, db.Error(ctx, err)
 	}
 
 	return tmp, nil
 }
 
 const getPostAuthor = `
-SELECT id, nickname, fullname, about, email
+SELECT id, nickname, fullname, about, email, updated_at
 FROM users
 WHERE nickname = $1;`
 
-func (rep *repository) GetPostAuthor(post *models.Post) (models.User, error) {
+func (rep *repository) GetPostAuthor(ctx context.Context, post *models.Post) (models.User, error) {
 	tmp := models.User{}
-	row := rep.pool.QueryRow(context.Background(), getPostAuthor, post.Author)
-	if err := row.Scan(&tmp.ID, &tmp.Nickname, &tmp.Fullname, &tmp.About, &tmp.Email); err != nil {
+	row := rep.pool.QueryRow(ctx, getPostAuthor, post.Author)
+	if err := row.Scan(&tmp.ID, &tmp.Nickname, &tmp.Fullname, &tmp.About, &tmp.Email, &tmp.UpdatedAt); err != nil {
 		if errors.Is(pgx.ErrNoRows, err) {
 			return tmp, pkgErrors.ErrUserNotFound
 		}
-		return tmp, pkgErrors.ErrInternal
+		return tmp, db.Error(ctx, err)
 	}
 	return tmp, nil
 }
 
 const getPostForum = `
-SELECT id, title, user_nickname, slug, posts, threads
+SELECT id, title, user_nickname, slug, posts, threads, updated_at
 FROM forums
 WHERE slug = $1;`
 
-func (rep *repository) GetPostForum(post *models.Post) (models.Forum, error) {
+func (rep *repository) GetPostForum(ctx context.Context, post *models.Post) (models.Forum, error) {
 	tmp := models.Forum{}
-	row := rep.pool.QueryRow(context.Background(), getPostForum, post.Forum)
-	if err := row.Scan(&tmp.ID, &tmp.Title, &tmp.User, &tmp.Slug, &tmp.Posts, &tmp.Threads); err != nil {
+	row := rep.pool.QueryRow(ctx, getPostForum, post.Forum)
+	if err := row.Scan(&tmp.ID, &tmp.Title, &tmp.User, &tmp.Slug, &tmp.Posts, &tmp.Threads, &tmp.UpdatedAt); err != nil {
 		if errors.Is(pgx.ErrNoRows, err) {
 			return tmp, pkgErrors.ErrUserNotFound
 		}
-		return tmp, pkgErrors.ErrInternal
+		return tmp, db.Error(ctx, err)
 	}
 	return tmp, nil
 }
 
 const getPostThread = `
-SELECT  id, title, author, forum, message, slug, votes, created
+SELECT  id, title, author, forum, message, slug, votes, created, updated_at
 FROM threads
 WHERE id = $1;`
 
-func (rep *repository) GetPostThread(post *models.Post) (models.Thread, error) {
+func (rep *repository) GetPostThread(ctx context.Context, post *models.Post) (models.Thread, error) {
 	tmp := models.Thread{}
-	row := rep.pool.QueryRow(context.Background(), getPostThread, post.Thread)
-	if err := row.Scan(&tmp.Id, &tmp.Title, &tmp.Author, &tmp.Forum, &tmp.Message, &tmp.Slug, &tmp.Votes, &tmp.Created); err != nil {
+	row := rep.pool.QueryRow(ctx, getPostThread, post.Thread)
+	if err := row.Scan(&tmp.Id, &tmp.Title, &tmp.Author, &tmp.Forum, &tmp.Message, &tmp.Slug, &tmp.Votes, &tmp.Created, &tmp.UpdatedAt); err != nil {
 		if errors.Is(pgx.ErrNoRows, err) {
 			return tmp, pkgErrors.ErrUserNotFound
 		}
-		return tmp, pkgErrors.ErrInternal
+		return tmp, db.Error(ctx, err)
 	}
 	return tmp, nil
 }
@@ -98,16 +100,16 @@ SET isEdited = case when (trim($2) = '') OR (trim($2) = trim(message)) then fals
 WHERE id = $1
 RETURNING id, parent, author, message, isEdited, forum, thread, created;`
 
-func (rep *repository) UpdatePost(post *models.Post) (models.Post, error) {
+func (rep *repository) UpdatePost(ctx context.Context, post *models.Post) (models.Post, error) {
 	tmp := models.Post{}
 
-	row := rep.pool.QueryRow(context.Background(), updatePost, post.Id, post.Message)
+	row := rep.pool.QueryRow(ctx, updatePost, post.Id, post.Message)
 	if err := row.Scan(&tmp.Id, &tmp.Parent, &tmp.Author, &tmp.Message, &tmp.IsEdited, &tmp.Forum, &tmp.Thread, &tmp.Created); err != nil {
 		if errors.Is(pgx.ErrNoRows, err) {
 			return tmp, pkgErrors.ErrPostNotFound
 		}
-		rep.log.Error("DB error", zap.Error(err))
-		return tmp, pkgErrors.ErrInternal
+		tracing.Log(ctx, rep.log).Error("DB error", zap.Error(err))
+		return tmp, db.Error(ctx, err)
 	}
 
 	return tmp, nil
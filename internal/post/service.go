@@ -1,10 +1,12 @@
 package post
 
 import (
+	"context"
+
 	"github.com/SlavaShagalov/vk-dbms-project/internal/models"
 )
 
 type Service interface {
-	GetPost(id int, related []string) (models.FullPost, error)
-	UpdatePost(post *models.Post) (models.Post, error)
+	GetPost(ctx context.Context, id int, related []string) (models.FullPost, error)
+	UpdatePost(ctx context.Context, post *models.Post) (models.Post, error)
 }
@@ -1,22 +1,26 @@
 package service
 
 import (
+	"context"
+
 	"github.com/SlavaShagalov/vk-dbms-project/internal/models"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/filter"
 	pkgPost "github.com/SlavaShagalov/vk-dbms-project/internal/post"
 	"go.uber.org/zap"
 )
 
 type service struct {
-	rep pkgPost.Repository
-	log *zap.Logger
+	rep    pkgPost.Repository
+	filter *filter.Chain
+	log    *zap.Logger
 }
 
-func NewService(rep pkgPost.Repository, log *zap.Logger) pkgPost.Service {
-	return &service{rep: rep, log: log}
+func NewService(rep pkgPost.Repository, filter *filter.Chain, log *zap.Logger) pkgPost.Service {
+	return &service{rep: rep, filter: filter, log: log}
 }
 
-func (serv *service) GetPost(id int, related []string) (models.FullPost, error) {
-	post, err := serv.rep.GetPost(id)
+func (serv *service) GetPost(ctx context.Context, id int, related []string) (models.FullPost, error) {
+	post, err := serv.rep.GetPost(ctx, id)
 	if err != nil {
 		return models.FullPost{}, err
 	}
@@ -30,21 +34,21 @@ func (serv *service) GetPost(id int, related []string) (models.FullPost, error)
 	for _, tag := range related {
 		switch tag {
 		case "user":
-			user, err := serv.rep.GetPostAuthor(tmp.Post)
+			user, err := serv.rep.GetPostAuthor(ctx, tmp.Post)
 			if err != nil {
 				return tmp, err
 			}
 			tmp.Author = &user
 
 		case "thread":
-			thread, err := serv.rep.GetPostThread(tmp.Post)
+			thread, err := serv.rep.GetPostThread(ctx, tmp.Post)
 			if err != nil {
 				return tmp, err
 			}
 			tmp.Thread = &thread
 
 		case "forum":
-			forum, err := serv.rep.GetPostForum(tmp.Post)
+			forum, err := serv.rep.GetPostForum(ctx, tmp.Post)
 			if err != nil {
 				return tmp, err
 			}
@@ -54,6 +58,40 @@ func (serv *service) GetPost(id int, related []string) (models.FullPost, error)
 	return tmp, nil
 }
 
-func (serv *service) UpdatePost(post *models.Post) (models.Post, error) {
-	return serv.rep.UpdatePost(post)
+func (serv *service) UpdatePost(ctx context.Context, post *models.Post) (models.Post, error) {
+	if serv.filter.Empty() || post.Message == "" {
+		return serv.rep.UpdatePost(ctx, post)
+	}
+
+	current, err := serv.rep.GetPost(ctx, post.Id)
+	if err != nil {
+		return current, err
+	}
+	// The same message is not an edit and is answered with the post as is.
+	if post.Message == current.Message {
+		return serv.rep.UpdatePost(ctx, post)
+	}
+
+	contents := []filter.Content{{
+		Kind:    filter.KindPost,
+		Forum:   current.Forum,
+		Thread:  current.Thread,
+		Author:  current.Author,
+		Message: post.Message,
+	}}
+	update := *post
+	err = serv.filter.Apply(ctx, contents, func(ctx context.Context) error {
+		_, err := serv.rep.UpdatePost(ctx, &update)
+		return err
+	})
+	if err != nil {
+		return current, err
+	}
+
+	updated, err := serv.rep.UpdatePost(ctx, post)
+	if err != nil {
+		return updated, err
+	}
+	serv.filter.Published(contents)
+	return updated, nil
 }
@@ -0,0 +1,44 @@
+package grpc
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	mw "github.com/SlavaShagalov/vk-dbms-project/internal/pkg/middleware"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/pb"
+	pkgService "github.com/SlavaShagalov/vk-dbms-project/internal/service"
+)
+
+type delivery struct {
+	pb.UnimplementedDatabaseServiceServer
+	serv pkgService.Service
+	log  *zap.Logger
+}
+
+func RegisterHandlers(server *grpc.Server, logger *zap.Logger, serv pkgService.Service) {
+	pb.RegisterDatabaseServiceServer(server, &delivery{serv: serv, log: logger})
+}
+
+// Routes are the HTTP routes the methods mirror.
+var Routes = mw.GRPCRoutes{
+	pb.DatabaseService_GetStatus_FullMethodName: {Route: "GET /api/service/status"},
+	pb.DatabaseService_Clear_FullMethodName:     {Route: "POST /api/service/clear"},
+}
+
+func (del *delivery) GetStatus(ctx context.Context, request *pb.GetStatusRequest) (*pb.Status, error) {
+	status, err := del.serv.GetStatus(ctx, request.GetFresh())
+	if err != nil {
+		return nil, err
+	}
+	return pb.NewStatus(&status), nil
+}
+
+func (del *delivery) Clear(ctx context.Context, _ *emptypb.Empty) (*emptypb.Empty, error) {
+	if err := del.serv.Clear(ctx); err != nil {
+		return nil, err
+	}
+	return &emptypb.Empty{}, nil
+}
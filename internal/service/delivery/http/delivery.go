@@ -2,11 +2,16 @@ package http
 
 import (
 	pkgErrors "github.com/SlavaShagalov/vk-dbms-project/internal/pkg/errors"
+	pkgHTTP "github.com/SlavaShagalov/vk-dbms-project/internal/pkg/http"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/metrics"
 	mw "github.com/SlavaShagalov/vk-dbms-project/internal/pkg/middleware"
 	pkgService "github.com/SlavaShagalov/vk-dbms-project/internal/service"
 	"github.com/julienschmidt/httprouter"
 	"go.uber.org/zap"
 	"net/http"
+	"net/url"
+	"strconv"
+	"time"
 )
 
 type delivery struct {
@@ -14,35 +19,118 @@ type delivery struct {
 	log  *zap.Logger
 }
 
-func RegisterHandlers(router *httprouter.Router, log *zap.Logger, serv pkgService.Service) {
+func RegisterHandlers(router *mw.Router, log *zap.Logger, serv pkgService.Service, m *metrics.Metrics) {
 	del := delivery{serv, log}
 
-	router.POST("/api/service/clear", mw.AccessLog(mw.HandleError(del.Clear, log), log))
-	router.GET("/api/service/status", mw.AccessLog(mw.HandleError(del.GetStatus, log), log))
+	router.POST("/api/service/clear", mw.Trace(mw.Timeout(mw.AccessLog(mw.Metrics(mw.HandleError(del.Clear, log), m), log))))
+	router.GET("/api/service/status", mw.Trace(mw.Timeout(mw.AccessLog(mw.Metrics(mw.HandleError(del.GetStatus, log), m), log))))
+	router.GET("/api/service/stats", mw.Trace(mw.Timeout(mw.AccessLog(mw.Metrics(mw.HandleError(del.GetStats, log), m), log))))
+	router.GET("/api/service/stats/forums", mw.Trace(mw.Timeout(mw.AccessLog(mw.Metrics(mw.HandleError(del.GetForumStats, log), m), log))))
+	router.GET("/api/service/stats/series", mw.Trace(mw.Timeout(mw.AccessLog(mw.Metrics(mw.HandleError(del.GetSeries, log), m), log))))
 }
 
-func (del *delivery) Clear(_ http.ResponseWriter, _ *http.Request, _ httprouter.Params) error {
-	if err := del.serv.Clear(); err != nil {
+func (del *delivery) Clear(_ http.ResponseWriter, r *http.Request, _ httprouter.Params) error {
+	if err := del.serv.Clear(r.Context()); err != nil {
 		return err
 	}
 	return nil
 }
 
 func (del *delivery) GetStatus(w http.ResponseWriter, r *http.Request, p httprouter.Params) error {
-	status, err := del.serv.GetStatus()
+	fresh, err := parseFresh(r.URL.Query())
 	if err != nil {
 		return err
 	}
 
-	data, err := status.MarshalJSON()
+	status, err := del.serv.GetStatus(r.Context(), fresh)
 	if err != nil {
-		return pkgErrors.ErrInternal
+		return err
 	}
+	return pkgHTTP.WriteResponse(w, r, http.StatusOK, &status)
+}
 
-	w.Header().Set("Content-Type", "application/json")
-	_, err = w.Write(data)
+func (del *delivery) GetStats(w http.ResponseWriter, r *http.Request, _ httprouter.Params) error {
+	fresh, err := parseFresh(r.URL.Query())
 	if err != nil {
-		return pkgErrors.ErrInternal
+		return err
 	}
-	return nil
+
+	stats, err := del.serv.GetStats(r.Context(), fresh)
+	if err != nil {
+		return err
+	}
+	return pkgHTTP.WriteResponse(w, r, http.StatusOK, &stats)
+}
+
+func (del *delivery) GetForumStats(w http.ResponseWriter, r *http.Request, _ httprouter.Params) error {
+	var err error
+	queryValues := r.URL.Query()
+
+	params := pkgService.ForumStatsParams{
+		Forum: queryValues.Get("forum"),
+		Limit: 100,
+	}
+	strLimit := queryValues.Get("limit")
+	if strLimit != "" {
+		params.Limit, err = strconv.Atoi(strLimit)
+		if err != nil || params.Limit < 0 {
+			return pkgErrors.ErrInvalidLimitParam
+		}
+	}
+	if params.Fresh, err = parseFresh(queryValues); err != nil {
+		return err
+	}
+
+	stats, err := del.serv.GetForumStats(r.Context(), &params)
+	if err != nil {
+		return err
+	}
+	return pkgHTTP.WriteResponse(w, r, http.StatusOK, stats)
+}
+
+func (del *delivery) GetSeries(w http.ResponseWriter, r *http.Request, _ httprouter.Params) error {
+	var err error
+	queryValues := r.URL.Query()
+
+	params := pkgService.SeriesParams{
+		Forum:    queryValues.Get("forum"),
+		Interval: queryValues.Get("interval"),
+	}
+	if params.From, err = parseTime(queryValues.Get("from")); err != nil {
+		return err
+	}
+	if params.To, err = parseTime(queryValues.Get("to")); err != nil {
+		return err
+	}
+	if params.Fresh, err = parseFresh(queryValues); err != nil {
+		return err
+	}
+
+	series, err := del.serv.GetSeries(r.Context(), &params)
+	if err != nil {
+		return err
+	}
+	return pkgHTTP.WriteResponse(w, r, http.StatusOK, &series)
+}
+
+func parseFresh(queryValues url.Values) (bool, error) {
+	switch queryValues.Get("fresh") {
+	case "", "false":
+		return false, nil
+	case "true":
+		return true, nil
+	default:
+		return false, pkgErrors.ErrInvalidFreshParam
+	}
+}
+
+func parseTime(raw string) (time.Time, error) {
+	if raw == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return time.Time{}, pkgErrors.ErrInvalidRangeParam
+	}
+	return t, nil
 }
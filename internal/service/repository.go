@@ -1,10 +1,36 @@
 package post
 
 import (
+	"context"
+	"time"
+
 	"github.com/SlavaShagalov/vk-dbms-project/internal/models"
 )
 
+const (
+	IntervalHour = "hour"
+	IntervalDay  = "day"
+)
+
+type ForumStatsParams struct {
+	Forum string
+	Limit int
+	Fresh bool
+}
+
+// SeriesParams describes the half-open range [From, To) split into Interval buckets.
+type SeriesParams struct {
+	Forum    string
+	From     time.Time
+	To       time.Time
+	Interval string
+	Fresh    bool
+}
+
 type Repository interface {
-	GetStatus() (models.Status, error)
-	Clear() error
+	GetStatus(ctx context.Context, fresh bool) (models.Status, error)
+	GetStats(ctx context.Context, fresh bool) (models.Stats, error)
+	GetForumStats(ctx context.Context, params *ForumStatsParams) (models.ForumStatsList, error)
+	GetSeries(ctx context.Context, params *SeriesParams) ([]models.StatsPoint, error)
+	Clear(ctx context.Context) error
 }
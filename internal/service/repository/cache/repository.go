@@ -0,0 +1,44 @@
+package cache
+
+import (
+	"context"
+
+	"github.com/SlavaShagalov/vk-dbms-project/internal/models"
+	pkgCache "github.com/SlavaShagalov/vk-dbms-project/internal/pkg/cache"
+	pkgService "github.com/SlavaShagalov/vk-dbms-project/internal/service"
+)
+
+type repository struct {
+	rep   pkgService.Repository
+	cache *pkgCache.Cache[models.Forum]
+}
+
+// NewRepository wraps rep and purges the cache after Clear. With PostgreSQL
+// the TRUNCATE notification does the same for the other replicas.
+func NewRepository(rep pkgService.Repository, cache *pkgCache.Cache[models.Forum]) pkgService.Repository {
+	return &repository{rep: rep, cache: cache}
+}
+
+func (rep *repository) GetStatus(ctx context.Context, fresh bool) (models.Status, error) {
+	return rep.rep.GetStatus(ctx, fresh)
+}
+
+func (rep *repository) GetStats(ctx context.Context, fresh bool) (models.Stats, error) {
+	return rep.rep.GetStats(ctx, fresh)
+}
+
+func (rep *repository) GetForumStats(ctx context.Context, params *pkgService.ForumStatsParams) (models.ForumStatsList, error) {
+	return rep.rep.GetForumStats(ctx, params)
+}
+
+func (rep *repository) GetSeries(ctx context.Context, params *pkgService.SeriesParams) ([]models.StatsPoint, error) {
+	return rep.rep.GetSeries(ctx, params)
+}
+
+func (rep *repository) Clear(ctx context.Context) error {
+	err := rep.rep.Clear(ctx)
+	if err == nil {
+		rep.cache.Purge(ctx)
+	}
+	return err
+}
@@ -0,0 +1,138 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/SlavaShagalov/vk-dbms-project/internal/models"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/memory"
+	pkgService "github.com/SlavaShagalov/vk-dbms-project/internal/service"
+)
+
+type repository struct {
+	store *memory.Store
+}
+
+// NewRepository returns a repository that always counts exactly, so the
+// fresh flag makes no difference.
+func NewRepository(store *memory.Store) pkgService.Repository {
+	return &repository{store: store}
+}
+
+func (rep *repository) GetStatus(_ context.Context, _ bool) (models.Status, error) {
+	rep.store.RLock()
+	defer rep.store.RUnlock()
+
+	return models.Status{
+		User:   len(rep.store.Users),
+		Forum:  len(rep.store.Forums),
+		Thread: len(rep.store.Threads),
+		Post:   len(rep.store.Posts),
+	}, nil
+}
+
+func (rep *repository) GetStats(_ context.Context, _ bool) (models.Stats, error) {
+	rep.store.RLock()
+	defer rep.store.RUnlock()
+
+	active := make(map[string]struct{})
+	for _, users := range rep.store.ForumUsers {
+		for nickname := range users {
+			active[nickname] = struct{}{}
+		}
+	}
+
+	return models.Stats{
+		Users:       len(rep.store.Users),
+		Forums:      len(rep.store.Forums),
+		Threads:     len(rep.store.Threads),
+		Posts:       len(rep.store.Posts),
+		Votes:       len(rep.store.Votes),
+		ActiveUsers: len(active),
+	}, nil
+}
+
+func (rep *repository) GetForumStats(_ context.Context, params *pkgService.ForumStatsParams) (models.ForumStatsList, error) {
+	rep.store.RLock()
+	defer rep.store.RUnlock()
+
+	stats := make(map[string]*models.ForumStats, len(rep.store.Forums))
+	for key, forum := range rep.store.Forums {
+		if params.Forum != "" && key != memory.Key(params.Forum) {
+			continue
+		}
+		stats[key] = &models.ForumStats{Forum: forum.Slug, Users: len(rep.store.ForumUsers[key])}
+	}
+	for _, thread := range rep.store.Threads {
+		if forum, ok := stats[memory.Key(thread.Forum)]; ok {
+			forum.Threads++
+		}
+	}
+	for _, post := range rep.store.Posts {
+		if forum, ok := stats[memory.Key(post.Forum)]; ok {
+			forum.Posts++
+		}
+	}
+	for key := range rep.store.Votes {
+		if forum, ok := stats[memory.Key(rep.store.Threads[key.Thread].Forum)]; ok {
+			forum.Votes++
+		}
+	}
+
+	list := make(models.ForumStatsList, 0, len(stats))
+	for _, forum := range stats {
+		list = append(list, *forum)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Posts != list[j].Posts {
+			return list[i].Posts > list[j].Posts
+		}
+		return memory.Key(list[i].Forum) < memory.Key(list[j].Forum)
+	})
+	if len(list) > params.Limit {
+		list = list[:params.Limit]
+	}
+	return list, nil
+}
+
+func (rep *repository) GetSeries(_ context.Context, params *pkgService.SeriesParams) ([]models.StatsPoint, error) {
+	rep.store.RLock()
+	defer rep.store.RUnlock()
+
+	step := time.Hour
+	if params.Interval == pkgService.IntervalDay {
+		step = 24 * time.Hour
+	}
+
+	points := make([]models.StatsPoint, 0, params.To.Sub(params.From)/step)
+	for t := params.From; t.Before(params.To); t = t.Add(step) {
+		points = append(points, models.StatsPoint{Time: t})
+	}
+	bucket := func(forum string, created time.Time) *models.StatsPoint {
+		if params.Forum != "" && memory.Key(forum) != memory.Key(params.Forum) {
+			return nil
+		}
+		if created.Before(params.From) || !created.Before(params.To) {
+			return nil
+		}
+		return &points[created.Sub(params.From)/step]
+	}
+
+	for _, thread := range rep.store.Threads {
+		if point := bucket(thread.Forum, thread.Created); point != nil {
+			point.Threads++
+		}
+	}
+	for _, post := range rep.store.Posts {
+		if point := bucket(post.Forum, post.Created); point != nil {
+			point.Posts++
+		}
+	}
+	return points, nil
+}
+
+func (rep *repository) Clear(_ context.Context) error {
+	rep.store.Clear()
+	return nil
+}
@@ -0,0 +1,47 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/SlavaShagalov/vk-dbms-project/internal/models"
+	pkgMetrics "github.com/SlavaShagalov/vk-dbms-project/internal/pkg/metrics"
+	pkgService "github.com/SlavaShagalov/vk-dbms-project/internal/service"
+)
+
+const name = "service"
+
+type repository struct {
+	rep pkgService.Repository
+	m   *pkgMetrics.Metrics
+}
+
+// NewRepository wraps rep and records the duration of every call.
+func NewRepository(rep pkgService.Repository, m *pkgMetrics.Metrics) pkgService.Repository {
+	return &repository{rep: rep, m: m}
+}
+
+func (rep *repository) GetStatus(ctx context.Context, fresh bool) (status models.Status, err error) {
+	defer func(start time.Time) { rep.m.ObserveQuery(name, "GetStatus", start, err) }(time.Now())
+	return rep.rep.GetStatus(ctx, fresh)
+}
+
+func (rep *repository) GetStats(ctx context.Context, fresh bool) (stats models.Stats, err error) {
+	defer func(start time.Time) { rep.m.ObserveQuery(name, "GetStats", start, err) }(time.Now())
+	return rep.rep.GetStats(ctx, fresh)
+}
+
+func (rep *repository) GetForumStats(ctx context.Context, params *pkgService.ForumStatsParams) (stats models.ForumStatsList, err error) {
+	defer func(start time.Time) { rep.m.ObserveQuery(name, "GetForumStats", start, err) }(time.Now())
+	return rep.rep.GetForumStats(ctx, params)
+}
+
+func (rep *repository) GetSeries(ctx context.Context, params *pkgService.SeriesParams) (points []models.StatsPoint, err error) {
+	defer func(start time.Time) { rep.m.ObserveQuery(name, "GetSeries", start, err) }(time.Now())
+	return rep.rep.GetSeries(ctx, params)
+}
+
+func (rep *repository) Clear(ctx context.Context) (err error) {
+	defer func(start time.Time) { rep.m.ObserveQuery(name, "Clear", start, err) }(time.Now())
+	return rep.rep.Clear(ctx)
+}
@@ -7,7 +7,9 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/SlavaShagalov/vk-dbms-project/internal/models"
-	pkgErrors "github.com/SlavaShagalov/vk-dbms-project/internal/pkg/errors"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/constants"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/db"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/tracing"
 	pkgService "github.com/SlavaShagalov/vk-dbms-project/internal/service"
 )
 
@@ -20,38 +22,200 @@ func NewRepository(pool *pgxpool.Pool, log *zap.Logger) pkgService.Repository {
 	return &repository{pool: pool, log: log}
 }
 
-var getStatusCmd = `
+// Rollups are maintained by the triggers from migration 0005, the fresh
+// variants count the source tables.
+const getStatusCmd = `
+SELECT coalesce((SELECT value FROM stats_totals WHERE name = 'users'), 0)  AS users,
+       coalesce((SELECT value FROM stats_totals WHERE name = 'forums'), 0) AS forums,
+       coalesce(sum(threads), 0)::bigint                                   AS threads,
+       coalesce(sum(posts), 0)::bigint                                     AS posts
+FROM stats_forums;`
+
+const getFreshStatusCmd = `
 SELECT
 (SELECT count(nickname) FROM users)  AS users,
 (SELECT count(slug) FROM forums) AS forums,
 (SELECT count(id) FROM threads) AS threads,
 (SELECT count(id) FROM posts)  AS posts;`
 
-func (rep *repository) GetStatus() (models.Status, error) {
+func (rep *repository) GetStatus(ctx context.Context, fresh bool) (models.Status, error) {
+	cmd := getStatusCmd
+	if fresh {
+		cmd = getFreshStatusCmd
+	}
+
 	tmp := models.Status{}
-	row := rep.pool.QueryRow(context.Background(), getStatusCmd)
+	row := rep.pool.QueryRow(ctx, cmd)
 
 	if err := row.Scan(&tmp.User, &tmp.Forum, &tmp.Thread, &tmp.Post); err != nil {
-		rep.log.Error("DB error", zap.Error(err))
-		return tmp, pkgErrors.ErrInternal
+		tracing.Log(ctx, rep.log).Error(constants.DBError, zap.Error(err))
+		return tmp, db.Error(ctx, err)
 	}
 	return tmp, nil
 }
 
-var clearDbCmd = `
+const getStatsCmd = `
+SELECT coalesce((SELECT value FROM stats_totals WHERE name = 'users'), 0)        AS users,
+       coalesce((SELECT value FROM stats_totals WHERE name = 'forums'), 0)       AS forums,
+       coalesce(sum(threads), 0)::bigint                                         AS threads,
+       coalesce(sum(posts), 0)::bigint                                           AS posts,
+       coalesce(sum(votes), 0)::bigint                                           AS votes,
+       coalesce((SELECT value FROM stats_totals WHERE name = 'active_users'), 0) AS active_users
+FROM stats_forums;`
+
+const getFreshStatsCmd = `
+SELECT (SELECT count(*) FROM users)                        AS users,
+       (SELECT count(*) FROM forums)                       AS forums,
+       (SELECT count(*) FROM threads)                      AS threads,
+       (SELECT count(*) FROM posts)                        AS posts,
+       (SELECT count(*) FROM votes)                        AS votes,
+       (SELECT count(DISTINCT nickname) FROM forum_users) AS active_users;`
+
+func (rep *repository) GetStats(ctx context.Context, fresh bool) (models.Stats, error) {
+	cmd := getStatsCmd
+	if fresh {
+		cmd = getFreshStatsCmd
+	}
+
+	tmp := models.Stats{}
+	row := rep.pool.QueryRow(ctx, cmd)
+
+	if err := row.Scan(&tmp.Users, &tmp.Forums, &tmp.Threads, &tmp.Posts, &tmp.Votes, &tmp.ActiveUsers); err != nil {
+		tracing.Log(ctx, rep.log).Error(constants.DBError, zap.Error(err))
+		return tmp, db.Error(ctx, err)
+	}
+	return tmp, nil
+}
+
+const getForumStatsCmd = `
+SELECT forum, threads, posts, votes, users
+FROM stats_forums
+WHERE $1::text = '' OR forum = $1::text::citext
+ORDER BY posts DESC, forum
+LIMIT $2;`
+
+const getFreshForumStatsCmd = `
+SELECT forum, threads, posts, votes, users
+FROM (SELECT f.slug                                                                                   AS forum,
+             (SELECT count(*) FROM threads WHERE forum = f.slug)                                     AS threads,
+             (SELECT count(*) FROM posts WHERE forum = f.slug)                                       AS posts,
+             (SELECT count(*) FROM votes v JOIN threads t ON t.id = v.thread WHERE t.forum = f.slug) AS votes,
+             (SELECT count(*) FROM forum_users WHERE forum = f.slug)                                 AS users
+      FROM forums f
+      WHERE $1::text = '' OR f.slug = $1::text::citext) s
+ORDER BY posts DESC, forum
+LIMIT $2;`
+
+func (rep *repository) GetForumStats(ctx context.Context, params *pkgService.ForumStatsParams) (models.ForumStatsList, error) {
+	cmd := getForumStatsCmd
+	if params.Fresh {
+		cmd = getFreshForumStatsCmd
+	}
+
+	rows, err := rep.pool.Query(ctx, cmd, params.Forum, params.Limit)
+	if err != nil {
+		tracing.Log(ctx, rep.log).Error(constants.DBError, zap.Error(err))
+		return nil, db.Error(ctx, err)
+	}
+	defer rows.Close()
+
+	stats := make(models.ForumStatsList, 0)
+	tmp := models.ForumStats{}
+	for rows.Next() {
+		if err := rows.Scan(&tmp.Forum, &tmp.Threads, &tmp.Posts, &tmp.Votes, &tmp.Users); err != nil {
+			tracing.Log(ctx, rep.log).Error(constants.DBError, zap.Error(err))
+			return nil, db.Error(ctx, err)
+		}
+		stats = append(stats, tmp)
+	}
+	if err := rows.Err(); err != nil {
+		tracing.Log(ctx, rep.log).Error(constants.DBError, zap.Error(err))
+		return nil, db.Error(ctx, err)
+	}
+	return stats, nil
+}
+
+// Empty buckets are filled with zeros by generate_series. $3 is the
+// date_trunc unit and is also used to build the step interval.
+const getSeriesCmd = `
+WITH buckets AS (SELECT date_trunc($3, bucket, 'UTC') AS time, sum(threads) AS threads, sum(posts) AS posts
+                 FROM stats_hourly
+                 WHERE bucket >= $1
+                   AND bucket < $2
+                   AND ($4::text = '' OR forum = $4::text::citext)
+                 GROUP BY 1)
+SELECT s.time, coalesce(b.threads, 0)::bigint, coalesce(b.posts, 0)::bigint
+FROM generate_series($1::timestamptz, $2::timestamptz - ('1 ' || $3)::interval, ('1 ' || $3)::interval) AS s(time)
+         LEFT JOIN buckets b ON b.time = s.time
+ORDER BY s.time;`
+
+const getFreshSeriesCmd = `
+WITH created AS (SELECT created, 1 AS threads, 0 AS posts
+                 FROM threads
+                 WHERE created >= $1
+                   AND created < $2
+                   AND ($4::text = '' OR forum = $4::text::citext)
+                 UNION ALL
+                 SELECT created, 0, 1
+                 FROM posts
+                 WHERE created >= $1
+                   AND created < $2
+                   AND ($4::text = '' OR forum = $4::text::citext)),
+     buckets AS (SELECT date_trunc($3, created, 'UTC') AS time, sum(threads) AS threads, sum(posts) AS posts
+                 FROM created
+                 GROUP BY 1)
+SELECT s.time, coalesce(b.threads, 0)::bigint, coalesce(b.posts, 0)::bigint
+FROM generate_series($1::timestamptz, $2::timestamptz - ('1 ' || $3)::interval, ('1 ' || $3)::interval) AS s(time)
+         LEFT JOIN buckets b ON b.time = s.time
+ORDER BY s.time;`
+
+func (rep *repository) GetSeries(ctx context.Context, params *pkgService.SeriesParams) ([]models.StatsPoint, error) {
+	cmd := getSeriesCmd
+	if params.Fresh {
+		cmd = getFreshSeriesCmd
+	}
+
+	rows, err := rep.pool.Query(ctx, cmd, params.From, params.To, params.Interval, params.Forum)
+	if err != nil {
+		tracing.Log(ctx, rep.log).Error(constants.DBError, zap.Error(err))
+		return nil, db.Error(ctx, err)
+	}
+	defer rows.Close()
+
+	points := make([]models.StatsPoint, 0)
+	tmp := models.StatsPoint{}
+	for rows.Next() {
+		if err := rows.Scan(&tmp.Time, &tmp.Threads, &tmp.Posts); err != nil {
+			tracing.Log(ctx, rep.log).Error(constants.DBError, zap.Error(err))
+			return nil, db.Error(ctx, err)
+		}
+		tmp.Time = tmp.Time.UTC()
+		points = append(points, tmp)
+	}
+	if err := rows.Err(); err != nil {
+		tracing.Log(ctx, rep.log).Error(constants.DBError, zap.Error(err))
+		return nil, db.Error(ctx, err)
+	}
+	return points, nil
+}
+
+const clearDbCmd = `
 TRUNCATE TABLE
 users,
 forums,
 threads,
 posts,
-votes
+votes,
+stats_totals,
+stats_forums,
+stats_hourly
 CASCADE;`
 
-func (rep *repository) Clear() error {
-	_, err := rep.pool.Exec(context.Background(), clearDbCmd)
+func (rep *repository) Clear(ctx context.Context) error {
+	_, err := rep.pool.Exec(ctx, clearDbCmd)
 	if err != nil {
-		rep.log.Error("DB error", zap.Error(err))
-		return pkgErrors.ErrInternal
+		tracing.Log(ctx, rep.log).Error(constants.DBError, zap.Error(err))
+		return db.Error(ctx, err)
 	}
 	return nil
 }
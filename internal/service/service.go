@@ -1,10 +1,15 @@
 package post
 
 import (
+	"context"
+
 	"github.com/SlavaShagalov/vk-dbms-project/internal/models"
 )
 
 type Service interface {
-	GetStatus() (models.Status, error)
-	Clear() error
+	GetStatus(ctx context.Context, fresh bool) (models.Status, error)
+	GetStats(ctx context.Context, fresh bool) (models.Stats, error)
+	GetForumStats(ctx context.Context, params *ForumStatsParams) (models.ForumStatsList, error)
+	GetSeries(ctx context.Context, params *SeriesParams) (models.StatsSeries, error)
+	Clear(ctx context.Context) error
 }
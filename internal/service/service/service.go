@@ -1,11 +1,28 @@
 package service
 
 import (
+	"context"
+	"time"
+
 	"github.com/SlavaShagalov/vk-dbms-project/internal/models"
+	pkgErrors "github.com/SlavaShagalov/vk-dbms-project/internal/pkg/errors"
 	pkgService "github.com/SlavaShagalov/vk-dbms-project/internal/service"
 	"go.uber.org/zap"
 )
 
+// maxSeriesPoints bounds a single time series response.
+const maxSeriesPoints = 5000
+
+var defaultSeriesRange = map[string]time.Duration{
+	pkgService.IntervalHour: 24 * time.Hour,
+	pkgService.IntervalDay:  30 * 24 * time.Hour,
+}
+
+var seriesSteps = map[string]time.Duration{
+	pkgService.IntervalHour: time.Hour,
+	pkgService.IntervalDay:  24 * time.Hour,
+}
+
 type service struct {
 	rep pkgService.Repository
 	log *zap.Logger
@@ -14,10 +31,65 @@ type service struct {
 func NewService(rep pkgService.Repository, log *zap.Logger) pkgService.Service {
 	return &service{rep: rep, log: log}
 }
-func (serv *service) GetStatus() (models.Status, error) {
-	return serv.rep.GetStatus()
+func (serv *service) GetStatus(ctx context.Context, fresh bool) (models.Status, error) {
+	return serv.rep.GetStatus(ctx, fresh)
+}
+
+func (serv *service) GetStats(ctx context.Context, fresh bool) (models.Stats, error) {
+	return serv.rep.GetStats(ctx, fresh)
+}
+
+func (serv *service) GetForumStats(ctx context.Context, params *pkgService.ForumStatsParams) (models.ForumStatsList, error) {
+	stats, err := serv.rep.GetForumStats(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	if params.Forum != "" && len(stats) == 0 {
+		return nil, pkgErrors.ErrForumNotFound
+	}
+	return stats, nil
+}
+
+// GetSeries aligns the range to whole buckets in UTC: From is rounded down and
+// To is rounded up, so the current bucket is included.
+func (serv *service) GetSeries(ctx context.Context, params *pkgService.SeriesParams) (models.StatsSeries, error) {
+	if params.Interval == "" {
+		params.Interval = pkgService.IntervalHour
+	}
+	step, ok := seriesSteps[params.Interval]
+	if !ok {
+		return models.StatsSeries{}, pkgErrors.ErrInvalidIntervalParam
+	}
+
+	if params.To.IsZero() {
+		params.To = time.Now()
+	}
+	if params.From.IsZero() {
+		params.From = params.To.Add(-defaultSeriesRange[params.Interval])
+	}
+
+	from := params.From.UTC().Truncate(step)
+	to := params.To.UTC().Truncate(step)
+	if to.Before(params.To) {
+		to = to.Add(step)
+	}
+	if !from.Before(to) || to.Sub(from)/step > maxSeriesPoints {
+		return models.StatsSeries{}, pkgErrors.ErrInvalidRangeParam
+	}
+	params.From, params.To = from, to
+
+	points, err := serv.rep.GetSeries(ctx, params)
+	if err != nil {
+		return models.StatsSeries{}, err
+	}
+	return models.StatsSeries{
+		Interval: params.Interval,
+		From:     from,
+		To:       to,
+		Points:   points,
+	}, nil
 }
 
-func (serv *service) Clear() error {
-	return serv.rep.Clear()
+func (serv *service) Clear(ctx context.Context) error {
+	return serv.rep.Clear(ctx)
 }
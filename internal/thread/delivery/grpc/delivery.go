@@ -0,0 +1,107 @@
+package grpc
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+
+	"github.com/SlavaShagalov/vk-dbms-project/internal/models"
+	pkgErrors "github.com/SlavaShagalov/vk-dbms-project/internal/pkg/errors"
+	mw "github.com/SlavaShagalov/vk-dbms-project/internal/pkg/middleware"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/pb"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/ratelimit"
+	pkgThread "github.com/SlavaShagalov/vk-dbms-project/internal/thread"
+)
+
+type delivery struct {
+	pb.UnimplementedThreadServiceServer
+	serv    pkgThread.Service
+	limiter *ratelimit.Limiter
+	log     *zap.Logger
+}
+
+func RegisterHandlers(server *grpc.Server, logger *zap.Logger, serv pkgThread.Service, limiter *ratelimit.Limiter) {
+	pb.RegisterThreadServiceServer(server, &delivery{serv: serv, limiter: limiter, log: logger})
+}
+
+// Routes are the HTTP routes the methods mirror.
+var Routes = mw.GRPCRoutes{
+	pb.ThreadService_GetThread_FullMethodName:       {Route: "GET /api/thread/:slug_or_id/details"},
+	pb.ThreadService_UpdateThread_FullMethodName:    {Route: "POST /api/thread/:slug_or_id/details", Group: ratelimit.GroupThreads},
+	pb.ThreadService_CreatePosts_FullMethodName:     {Route: "POST /api/thread/:slug_or_id/create", Group: ratelimit.GroupPosts},
+	pb.ThreadService_ListThreadPosts_FullMethodName: {Route: "GET /api/thread/:slug_or_id/posts"},
+	pb.ThreadService_Vote_FullMethodName:            {Route: "POST /api/thread/:slug_or_id/vote", Group: ratelimit.GroupVotes},
+}
+
+func (del *delivery) GetThread(ctx context.Context, request *pb.GetThreadRequest) (*pb.Thread, error) {
+	thread, err := del.serv.GetThread(ctx, request.GetSlugOrId())
+	if err != nil {
+		return nil, err
+	}
+	return pb.NewThread(&thread), nil
+}
+
+func (del *delivery) UpdateThread(ctx context.Context, request *pb.UpdateThreadRequest) (*pb.Thread, error) {
+	thread, err := del.serv.UpdateThread(ctx, request.GetSlugOrId(), &models.Thread{
+		Title:   request.GetTitle(),
+		Message: request.GetMessage(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pb.NewThread(&thread), nil
+}
+
+func (del *delivery) CreatePosts(ctx context.Context, request *pb.CreatePostsRequest) (*pb.Posts, error) {
+	posts := (&pb.Posts{Posts: request.GetPosts()}).Model()
+	authors := make([]string, 0, len(posts))
+	for _, post := range posts {
+		authors = append(authors, post.Author)
+	}
+	if err := mw.AllowGRPCUsers(ctx, del.limiter, ratelimit.GroupPosts, authors...); err != nil {
+		return nil, err
+	}
+	posts, err := del.serv.CreatePosts(ctx, request.GetSlugOrId(), posts)
+	if err != nil {
+		return nil, err
+	}
+	return pb.NewPosts(posts), nil
+}
+
+// ListThreadPosts sends the page the HTTP API would return one post at a time.
+func (del *delivery) ListThreadPosts(request *pb.ListThreadPostsRequest, stream pb.ThreadService_ListThreadPostsServer) error {
+	limit, err := pb.Limit(request.Limit)
+	if err != nil {
+		return err
+	}
+	if request.GetSince() < 0 {
+		return pkgErrors.ErrInvalidSinceParam
+	}
+
+	posts, err := del.serv.GetPosts(stream.Context(), request.GetSlugOrId(), limit, int(request.GetSince()),
+		request.GetSort(), request.GetDesc())
+	if err != nil {
+		return err
+	}
+	for i := range posts {
+		if err = stream.Send(pb.NewPost(&posts[i])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (del *delivery) Vote(ctx context.Context, request *pb.VoteRequest) (*pb.Thread, error) {
+	if err := mw.AllowGRPCUsers(ctx, del.limiter, ratelimit.GroupVotes, request.GetNickname()); err != nil {
+		return nil, err
+	}
+	thread, err := del.serv.AddVote(ctx, request.GetSlugOrId(), &models.Vote{
+		Nickname: request.GetNickname(),
+		Voice:    int(request.GetVoice()),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pb.NewThread(&thread), nil
+}
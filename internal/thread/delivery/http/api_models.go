@@ -5,6 +5,19 @@ import "github.com/SlavaShagalov/vk-dbms-project/internal/models"
 //go:generate easyjson -all -snake_case api_models.go
 
 // API requests
+type slowModeRequest struct {
+	Nickname string `json:"nickname"`
+	Seconds  int    `json:"seconds"`
+}
+
+type lockRequest struct {
+	Locked bool `json:"locked"`
+}
+
+type moveRequest struct {
+	Forum string `json:"forum"`
+}
+
 type createRequest struct {
 	Fullname string
 	About    string
@@ -18,6 +31,10 @@ type updateRequest struct {
 }
 
 // API responses
+type slowModeResponse struct {
+	Seconds int `json:"seconds"`
+}
+
 type createResponse struct {
 	ID       int
 	Nickname string
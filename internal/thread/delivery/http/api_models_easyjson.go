@@ -192,7 +192,278 @@ func (v *updateRequest) UnmarshalJSON(data []byte) error {
 func (v *updateRequest) UnmarshalEasyJSON(l *jlexer.Lexer) {
 	easyjsonC0ea9389DecodeGithubComSlavaShagalovVkDbmsProjectInternalThreadDeliveryHttp1(l, v)
 }
-func easyjsonC0ea9389DecodeGithubComSlavaShagalovVkDbmsProjectInternalThreadDeliveryHttp2(in *jlexer.Lexer, out *getResponse) {
+func easyjsonC0ea9389DecodeGithubComSlavaShagalovVkDbmsProjectInternalThreadDeliveryHttp2(in *jlexer.Lexer, out *slowModeResponse) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "seconds":
+			out.Seconds = int(in.Int())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC0ea9389EncodeGithubComSlavaShagalovVkDbmsProjectInternalThreadDeliveryHttp2(out *jwriter.Writer, in slowModeResponse) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"seconds\":"
+		out.RawString(prefix[1:])
+		out.Int(int(in.Seconds))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v slowModeResponse) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC0ea9389EncodeGithubComSlavaShagalovVkDbmsProjectInternalThreadDeliveryHttp2(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v slowModeResponse) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC0ea9389EncodeGithubComSlavaShagalovVkDbmsProjectInternalThreadDeliveryHttp2(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *slowModeResponse) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC0ea9389DecodeGithubComSlavaShagalovVkDbmsProjectInternalThreadDeliveryHttp2(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *slowModeResponse) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC0ea9389DecodeGithubComSlavaShagalovVkDbmsProjectInternalThreadDeliveryHttp2(l, v)
+}
+func easyjsonC0ea9389DecodeGithubComSlavaShagalovVkDbmsProjectInternalThreadDeliveryHttp3(in *jlexer.Lexer, out *slowModeRequest) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "nickname":
+			out.Nickname = string(in.String())
+		case "seconds":
+			out.Seconds = int(in.Int())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC0ea9389EncodeGithubComSlavaShagalovVkDbmsProjectInternalThreadDeliveryHttp3(out *jwriter.Writer, in slowModeRequest) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"nickname\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Nickname))
+	}
+	{
+		const prefix string = ",\"seconds\":"
+		out.RawString(prefix)
+		out.Int(int(in.Seconds))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v slowModeRequest) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC0ea9389EncodeGithubComSlavaShagalovVkDbmsProjectInternalThreadDeliveryHttp3(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v slowModeRequest) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC0ea9389EncodeGithubComSlavaShagalovVkDbmsProjectInternalThreadDeliveryHttp3(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *slowModeRequest) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC0ea9389DecodeGithubComSlavaShagalovVkDbmsProjectInternalThreadDeliveryHttp3(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *slowModeRequest) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC0ea9389DecodeGithubComSlavaShagalovVkDbmsProjectInternalThreadDeliveryHttp3(l, v)
+}
+func easyjsonC0ea9389DecodeGithubComSlavaShagalovVkDbmsProjectInternalThreadDeliveryHttp4(in *jlexer.Lexer, out *moveRequest) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "forum":
+			out.Forum = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC0ea9389EncodeGithubComSlavaShagalovVkDbmsProjectInternalThreadDeliveryHttp4(out *jwriter.Writer, in moveRequest) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"forum\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Forum))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v moveRequest) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC0ea9389EncodeGithubComSlavaShagalovVkDbmsProjectInternalThreadDeliveryHttp4(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v moveRequest) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC0ea9389EncodeGithubComSlavaShagalovVkDbmsProjectInternalThreadDeliveryHttp4(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *moveRequest) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC0ea9389DecodeGithubComSlavaShagalovVkDbmsProjectInternalThreadDeliveryHttp4(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *moveRequest) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC0ea9389DecodeGithubComSlavaShagalovVkDbmsProjectInternalThreadDeliveryHttp4(l, v)
+}
+func easyjsonC0ea9389DecodeGithubComSlavaShagalovVkDbmsProjectInternalThreadDeliveryHttp5(in *jlexer.Lexer, out *lockRequest) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "locked":
+			out.Locked = bool(in.Bool())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonC0ea9389EncodeGithubComSlavaShagalovVkDbmsProjectInternalThreadDeliveryHttp5(out *jwriter.Writer, in lockRequest) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"locked\":"
+		out.RawString(prefix[1:])
+		out.Bool(bool(in.Locked))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v lockRequest) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonC0ea9389EncodeGithubComSlavaShagalovVkDbmsProjectInternalThreadDeliveryHttp5(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v lockRequest) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonC0ea9389EncodeGithubComSlavaShagalovVkDbmsProjectInternalThreadDeliveryHttp5(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *lockRequest) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonC0ea9389DecodeGithubComSlavaShagalovVkDbmsProjectInternalThreadDeliveryHttp5(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *lockRequest) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonC0ea9389DecodeGithubComSlavaShagalovVkDbmsProjectInternalThreadDeliveryHttp5(l, v)
+}
+func easyjsonC0ea9389DecodeGithubComSlavaShagalovVkDbmsProjectInternalThreadDeliveryHttp6(in *jlexer.Lexer, out *getResponse) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -231,7 +502,7 @@ func easyjsonC0ea9389DecodeGithubComSlavaShagalovVkDbmsProjectInternalThreadDeli
 		in.Consumed()
 	}
 }
-func easyjsonC0ea9389EncodeGithubComSlavaShagalovVkDbmsProjectInternalThreadDeliveryHttp2(out *jwriter.Writer, in getResponse) {
+func easyjsonC0ea9389EncodeGithubComSlavaShagalovVkDbmsProjectInternalThreadDeliveryHttp6(out *jwriter.Writer, in getResponse) {
 	out.RawByte('{')
 	first := true
 	_ = first
@@ -266,27 +537,27 @@ func easyjsonC0ea9389EncodeGithubComSlavaShagalovVkDbmsProjectInternalThreadDeli
 // MarshalJSON supports json.Marshaler interface
 func (v getResponse) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
-	easyjsonC0ea9389EncodeGithubComSlavaShagalovVkDbmsProjectInternalThreadDeliveryHttp2(&w, v)
+	easyjsonC0ea9389EncodeGithubComSlavaShagalovVkDbmsProjectInternalThreadDeliveryHttp6(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
 func (v getResponse) MarshalEasyJSON(w *jwriter.Writer) {
-	easyjsonC0ea9389EncodeGithubComSlavaShagalovVkDbmsProjectInternalThreadDeliveryHttp2(w, v)
+	easyjsonC0ea9389EncodeGithubComSlavaShagalovVkDbmsProjectInternalThreadDeliveryHttp6(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
 func (v *getResponse) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
-	easyjsonC0ea9389DecodeGithubComSlavaShagalovVkDbmsProjectInternalThreadDeliveryHttp2(&r, v)
+	easyjsonC0ea9389DecodeGithubComSlavaShagalovVkDbmsProjectInternalThreadDeliveryHttp6(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
 func (v *getResponse) UnmarshalEasyJSON(l *jlexer.Lexer) {
-	easyjsonC0ea9389DecodeGithubComSlavaShagalovVkDbmsProjectInternalThreadDeliveryHttp2(l, v)
+	easyjsonC0ea9389DecodeGithubComSlavaShagalovVkDbmsProjectInternalThreadDeliveryHttp6(l, v)
 }
-func easyjsonC0ea9389DecodeGithubComSlavaShagalovVkDbmsProjectInternalThreadDeliveryHttp3(in *jlexer.Lexer, out *createResponse) {
+func easyjsonC0ea9389DecodeGithubComSlavaShagalovVkDbmsProjectInternalThreadDeliveryHttp7(in *jlexer.Lexer, out *createResponse) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -325,7 +596,7 @@ func easyjsonC0ea9389DecodeGithubComSlavaShagalovVkDbmsProjectInternalThreadDeli
 		in.Consumed()
 	}
 }
-func easyjsonC0ea9389EncodeGithubComSlavaShagalovVkDbmsProjectInternalThreadDeliveryHttp3(out *jwriter.Writer, in createResponse) {
+func easyjsonC0ea9389EncodeGithubComSlavaShagalovVkDbmsProjectInternalThreadDeliveryHttp7(out *jwriter.Writer, in createResponse) {
 	out.RawByte('{')
 	first := true
 	_ = first
@@ -360,27 +631,27 @@ func easyjsonC0ea9389EncodeGithubComSlavaShagalovVkDbmsProjectInternalThreadDeli
 // MarshalJSON supports json.Marshaler interface
 func (v createResponse) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
-	easyjsonC0ea9389EncodeGithubComSlavaShagalovVkDbmsProjectInternalThreadDeliveryHttp3(&w, v)
+	easyjsonC0ea9389EncodeGithubComSlavaShagalovVkDbmsProjectInternalThreadDeliveryHttp7(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
 func (v createResponse) MarshalEasyJSON(w *jwriter.Writer) {
-	easyjsonC0ea9389EncodeGithubComSlavaShagalovVkDbmsProjectInternalThreadDeliveryHttp3(w, v)
+	easyjsonC0ea9389EncodeGithubComSlavaShagalovVkDbmsProjectInternalThreadDeliveryHttp7(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
 func (v *createResponse) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
-	easyjsonC0ea9389DecodeGithubComSlavaShagalovVkDbmsProjectInternalThreadDeliveryHttp3(&r, v)
+	easyjsonC0ea9389DecodeGithubComSlavaShagalovVkDbmsProjectInternalThreadDeliveryHttp7(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
 func (v *createResponse) UnmarshalEasyJSON(l *jlexer.Lexer) {
-	easyjsonC0ea9389DecodeGithubComSlavaShagalovVkDbmsProjectInternalThreadDeliveryHttp3(l, v)
+	easyjsonC0ea9389DecodeGithubComSlavaShagalovVkDbmsProjectInternalThreadDeliveryHttp7(l, v)
 }
-func easyjsonC0ea9389DecodeGithubComSlavaShagalovVkDbmsProjectInternalThreadDeliveryHttp4(in *jlexer.Lexer, out *createRequest) {
+func easyjsonC0ea9389DecodeGithubComSlavaShagalovVkDbmsProjectInternalThreadDeliveryHttp8(in *jlexer.Lexer, out *createRequest) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -415,7 +686,7 @@ func easyjsonC0ea9389DecodeGithubComSlavaShagalovVkDbmsProjectInternalThreadDeli
 		in.Consumed()
 	}
 }
-func easyjsonC0ea9389EncodeGithubComSlavaShagalovVkDbmsProjectInternalThreadDeliveryHttp4(out *jwriter.Writer, in createRequest) {
+func easyjsonC0ea9389EncodeGithubComSlavaShagalovVkDbmsProjectInternalThreadDeliveryHttp8(out *jwriter.Writer, in createRequest) {
 	out.RawByte('{')
 	first := true
 	_ = first
@@ -440,27 +711,27 @@ func easyjsonC0ea9389EncodeGithubComSlavaShagalovVkDbmsProjectInternalThreadDeli
 // MarshalJSON supports json.Marshaler interface
 func (v createRequest) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
-	easyjsonC0ea9389EncodeGithubComSlavaShagalovVkDbmsProjectInternalThreadDeliveryHttp4(&w, v)
+	easyjsonC0ea9389EncodeGithubComSlavaShagalovVkDbmsProjectInternalThreadDeliveryHttp8(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
 func (v createRequest) MarshalEasyJSON(w *jwriter.Writer) {
-	easyjsonC0ea9389EncodeGithubComSlavaShagalovVkDbmsProjectInternalThreadDeliveryHttp4(w, v)
+	easyjsonC0ea9389EncodeGithubComSlavaShagalovVkDbmsProjectInternalThreadDeliveryHttp8(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
 func (v *createRequest) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
-	easyjsonC0ea9389DecodeGithubComSlavaShagalovVkDbmsProjectInternalThreadDeliveryHttp4(&r, v)
+	easyjsonC0ea9389DecodeGithubComSlavaShagalovVkDbmsProjectInternalThreadDeliveryHttp8(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
 func (v *createRequest) UnmarshalEasyJSON(l *jlexer.Lexer) {
-	easyjsonC0ea9389DecodeGithubComSlavaShagalovVkDbmsProjectInternalThreadDeliveryHttp4(l, v)
+	easyjsonC0ea9389DecodeGithubComSlavaShagalovVkDbmsProjectInternalThreadDeliveryHttp8(l, v)
 }
-func easyjsonC0ea9389DecodeGithubComSlavaShagalovVkDbmsProjectInternalThreadDeliveryHttp5(in *jlexer.Lexer, out *createAlreadyExistsResponse) {
+func easyjsonC0ea9389DecodeGithubComSlavaShagalovVkDbmsProjectInternalThreadDeliveryHttp9(in *jlexer.Lexer, out *createAlreadyExistsResponse) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		in.Skip()
@@ -488,7 +759,7 @@ func easyjsonC0ea9389DecodeGithubComSlavaShagalovVkDbmsProjectInternalThreadDeli
 		in.Consumed()
 	}
 }
-func easyjsonC0ea9389EncodeGithubComSlavaShagalovVkDbmsProjectInternalThreadDeliveryHttp5(out *jwriter.Writer, in createAlreadyExistsResponse) {
+func easyjsonC0ea9389EncodeGithubComSlavaShagalovVkDbmsProjectInternalThreadDeliveryHttp9(out *jwriter.Writer, in createAlreadyExistsResponse) {
 	if in == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
 		out.RawString("null")
 	} else {
@@ -506,23 +777,23 @@ func easyjsonC0ea9389EncodeGithubComSlavaShagalovVkDbmsProjectInternalThreadDeli
 // MarshalJSON supports json.Marshaler interface
 func (v createAlreadyExistsResponse) MarshalJSON() ([]byte, error) {
 	w := jwriter.Writer{}
-	easyjsonC0ea9389EncodeGithubComSlavaShagalovVkDbmsProjectInternalThreadDeliveryHttp5(&w, v)
+	easyjsonC0ea9389EncodeGithubComSlavaShagalovVkDbmsProjectInternalThreadDeliveryHttp9(&w, v)
 	return w.Buffer.BuildBytes(), w.Error
 }
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
 func (v createAlreadyExistsResponse) MarshalEasyJSON(w *jwriter.Writer) {
-	easyjsonC0ea9389EncodeGithubComSlavaShagalovVkDbmsProjectInternalThreadDeliveryHttp5(w, v)
+	easyjsonC0ea9389EncodeGithubComSlavaShagalovVkDbmsProjectInternalThreadDeliveryHttp9(w, v)
 }
 
 // UnmarshalJSON supports json.Unmarshaler interface
 func (v *createAlreadyExistsResponse) UnmarshalJSON(data []byte) error {
 	r := jlexer.Lexer{Data: data}
-	easyjsonC0ea9389DecodeGithubComSlavaShagalovVkDbmsProjectInternalThreadDeliveryHttp5(&r, v)
+	easyjsonC0ea9389DecodeGithubComSlavaShagalovVkDbmsProjectInternalThreadDeliveryHttp9(&r, v)
 	return r.Error()
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
 func (v *createAlreadyExistsResponse) UnmarshalEasyJSON(l *jlexer.Lexer) {
-	easyjsonC0ea9389DecodeGithubComSlavaShagalovVkDbmsProjectInternalThreadDeliveryHttp5(l, v)
+	easyjsonC0ea9389DecodeGithubComSlavaShagalovVkDbmsProjectInternalThreadDeliveryHttp9(l, v)
 }
@@ -4,7 +4,9 @@ import (
 	"github.com/SlavaShagalov/vk-dbms-project/internal/models"
 	pkgErrors "github.com/SlavaShagalov/vk-dbms-project/internal/pkg/errors"
 	pkgHTTP "github.com/SlavaShagalov/vk-dbms-project/internal/pkg/http"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/metrics"
 	mw "github.com/SlavaShagalov/vk-dbms-project/internal/pkg/middleware"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/ratelimit"
 	pkgThread "github.com/SlavaShagalov/vk-dbms-project/internal/thread"
 	"github.com/julienschmidt/httprouter"
 	"go.uber.org/zap"
@@ -13,102 +15,85 @@ import (
 )
 
 type delivery struct {
-	serv pkgThread.Service
-	log  *zap.Logger
+	serv    pkgThread.Service
+	limiter *ratelimit.Limiter
+	log     *zap.Logger
 }
 
-func RegisterHandlers(router *httprouter.Router, log *zap.Logger, serv pkgThread.Service) {
-	del := delivery{serv, log}
+func RegisterHandlers(router *mw.Router, log *zap.Logger, serv pkgThread.Service, limiter *ratelimit.Limiter, m *metrics.Metrics) {
+	del := delivery{serv: serv, limiter: limiter, log: log}
 
-	//router.POST("/api/forum/:slug/create", mw.AccessLog(mw.HandleError(del.CreateThread, log), log))
-	router.GET("/api/thread/:slug_or_id/details", mw.AccessLog(mw.HandleError(del.GetThread, log), log))
-	router.POST("/api/thread/:slug_or_id/details", mw.AccessLog(mw.HandleError(del.UpdateThread, log), log))
+	//router.POST("/api/forum/:slug/create", mw.Trace(mw.Timeout(mw.AccessLog(mw.HandleError(del.CreateThread, log), log))))
+	router.GET("/api/thread/:slug_or_id/details", mw.Trace(mw.Timeout(mw.AccessLog(mw.Metrics(mw.Conditional(mw.HandleError(del.GetThread, log)), m), log))))
+	router.POST("/api/thread/:slug_or_id/details", mw.Trace(mw.Timeout(mw.AccessLog(mw.Metrics(mw.HandleError(mw.RateLimit(del.UpdateThread, limiter, ratelimit.GroupThreads), log), m), log))))
 
-	router.POST("/api/thread/:slug_or_id/create", mw.AccessLog(mw.HandleError(del.CreatePost, log), log))
-	router.GET("/api/thread/:slug_or_id/posts", mw.AccessLog(mw.HandleError(del.GetPosts, log), log))
+	router.POST("/api/thread/:slug_or_id/create", mw.Trace(mw.Timeout(mw.AccessLog(mw.Metrics(mw.HandleError(mw.RateLimit(del.CreatePost, limiter, ratelimit.GroupPosts), log), m), log))))
+	router.GET("/api/thread/:slug_or_id/posts", mw.Trace(mw.Timeout(mw.AccessLog(mw.Metrics(mw.Conditional(mw.HandleError(del.GetPosts, log)), m), log))))
 
-	router.POST("/api/thread/:slug_or_id/vote", mw.AccessLog(mw.HandleError(del.AddVote, log), log))
+	router.POST("/api/thread/:slug_or_id/vote", mw.Trace(mw.Timeout(mw.AccessLog(mw.Metrics(mw.HandleError(mw.RateLimit(del.AddVote, limiter, ratelimit.GroupVotes), log), m), log))))
+	router.POST("/api/thread/:slug_or_id/slow_mode", mw.Trace(mw.Timeout(mw.AccessLog(mw.Metrics(mw.HandleError(mw.RateLimit(del.SetSlowMode, limiter, ratelimit.GroupThreads), log), m), log))))
+}
+
+// RegisterAdminHandlers registers thread moderation routes for the admin listener.
+func RegisterAdminHandlers(router *mw.Router, log *zap.Logger, serv pkgThread.Service, m *metrics.Metrics) {
+	del := delivery{serv: serv, log: log}
+
+	router.POST("/api/admin/thread/:slug_or_id/lock", mw.Trace(mw.Timeout(mw.AccessLog(mw.Metrics(mw.HandleError(del.SetLocked, log), m), log))))
+	router.POST("/api/admin/thread/:slug_or_id/move", mw.Trace(mw.Timeout(mw.AccessLog(mw.Metrics(mw.HandleError(del.MoveThread, log), m), log))))
+	router.POST("/api/admin/thread/:slug_or_id/slow_mode", mw.Trace(mw.Timeout(mw.AccessLog(mw.Metrics(mw.HandleError(del.ModerateSlowMode, log), m), log))))
 }
 
 func (del *delivery) CreatePost(w http.ResponseWriter, r *http.Request, p httprouter.Params) error {
 	slugOrId := p.ByName("slug_or_id")
 
-	body, err := pkgHTTP.ReadBody(r, del.log)
-	if err != nil {
+	var posts models.PostList
+	if err := pkgHTTP.ReadRequest(r, del.log, &posts); err != nil {
 		return err
 	}
-
-	var posts models.PostList
-	if err := posts.UnmarshalJSON(body); err != nil {
-		return pkgErrors.ErrParseJSON
+	authors := make([]string, 0, len(posts))
+	for _, post := range posts {
+		authors = append(authors, post.Author)
 	}
-
-	posts, err = del.serv.CreatePosts(slugOrId, posts)
-	if err != nil {
+	mw.SetUser(w, authors...)
+	if err := mw.AllowUsers(w, r, del.limiter, ratelimit.GroupPosts, authors...); err != nil {
 		return err
-	} else {
-		w.WriteHeader(http.StatusCreated)
 	}
 
-	data, err := posts.MarshalJSON()
+	posts, err := del.serv.CreatePosts(r.Context(), slugOrId, posts)
 	if err != nil {
-		return pkgErrors.ErrInternal
+		return err
 	}
 
-	_, err = w.Write(data)
-	if err != nil {
-		return pkgErrors.ErrInternal
-	}
-	return nil
+	return pkgHTTP.WriteResponse(w, r, http.StatusCreated, posts)
 }
 
-func (del *delivery) GetThread(w http.ResponseWriter, _ *http.Request, p httprouter.Params) error {
+func (del *delivery) GetThread(w http.ResponseWriter, r *http.Request, p httprouter.Params) error {
 	slugOrId := p.ByName("slug_or_id")
-	thread, err := del.serv.GetThread(slugOrId)
+	thread, err := del.serv.GetThread(r.Context(), slugOrId)
 	if err != nil {
 		return err
 	}
-
-	data, err := thread.MarshalJSON()
-	if err != nil {
-		return pkgErrors.ErrInternal
+	if pkgHTTP.NotModified(w, r, thread.UpdatedAt) {
+		return nil
 	}
 
-	_, err = w.Write(data)
-	if err != nil {
-		return pkgErrors.ErrInternal
-	}
-	return nil
+	return pkgHTTP.WriteResponse(w, r, http.StatusOK, &thread)
 }
 
 func (del *delivery) UpdateThread(w http.ResponseWriter, r *http.Request, p httprouter.Params) error {
 	slugOrId := p.ByName("slug_or_id")
 
-	body, err := pkgHTTP.ReadBody(r, del.log)
-	if err != nil {
-		return err
-	}
-
 	thread := &models.Thread{}
-	if err := thread.UnmarshalJSON(body); err != nil {
-		return pkgErrors.ErrParseJSON
-	}
-
-	updatedThread, err := del.serv.UpdateThread(slugOrId, thread)
-	if err != nil {
+	if err := pkgHTTP.ReadRequest(r, del.log, thread); err != nil {
 		return err
 	}
 
-	data, err := updatedThread.MarshalJSON()
+	updatedThread, err := del.serv.UpdateThread(r.Context(), slugOrId, thread)
 	if err != nil {
-		return pkgErrors.ErrInternal
+		return err
 	}
 
-	_, err = w.Write(data)
-	if err != nil {
-		return pkgErrors.ErrInternal
-	}
-	return nil
+	return pkgHTTP.WriteResponse(w, r, http.StatusOK, &updatedThread)
 }
 
 func (del *delivery) GetPosts(w http.ResponseWriter, r *http.Request, p httprouter.Params) error {
@@ -146,49 +131,98 @@ func (del *delivery) GetPosts(w http.ResponseWriter, r *http.Request, p httprout
 		}
 	}
 
-	posts, err := del.serv.GetPosts(slugOrId, limit, since, sort, desc)
+	posts, err := del.serv.GetPosts(r.Context(), slugOrId, limit, since, sort, desc)
 	if err != nil {
 		return err
 	}
 
-	data, err := posts.MarshalJSON()
-	if err != nil {
-		return pkgErrors.ErrInternal
+	return pkgHTTP.WriteResponse(w, r, http.StatusOK, posts)
+}
+
+func (del *delivery) AddVote(w http.ResponseWriter, r *http.Request, p httprouter.Params) error {
+	slugOrId := p.ByName("slug_or_id")
+
+	vote := models.Vote{}
+	if err := pkgHTTP.ReadRequest(r, del.log, &vote); err != nil {
+		return err
+	}
+	mw.SetUser(w, vote.Nickname)
+	if err := mw.AllowUsers(w, r, del.limiter, ratelimit.GroupVotes, vote.Nickname); err != nil {
+		return err
 	}
 
-	_, err = w.Write(data)
+	thread, err := del.serv.AddVote(r.Context(), slugOrId, &vote)
 	if err != nil {
-		return pkgErrors.ErrInternal
+		return err
 	}
-	return nil
+
+	return pkgHTTP.WriteResponse(w, r, http.StatusOK, &thread)
 }
 
-func (del *delivery) AddVote(w http.ResponseWriter, r *http.Request, p httprouter.Params) error {
+// SetSlowMode lets the owner of the thread's forum, named in the body, set the slow mode.
+func (del *delivery) SetSlowMode(w http.ResponseWriter, r *http.Request, p httprouter.Params) error {
+	return del.setSlowMode(w, r, p, true)
+}
+
+// ModerateSlowMode sets the slow mode of any thread, the nickname is ignored.
+func (del *delivery) ModerateSlowMode(w http.ResponseWriter, r *http.Request, p httprouter.Params) error {
+	return del.setSlowMode(w, r, p, false)
+}
+
+func (del *delivery) setSlowMode(w http.ResponseWriter, r *http.Request, p httprouter.Params, asOwner bool) error {
 	slugOrId := p.ByName("slug_or_id")
 
-	body, err := pkgHTTP.ReadBody(r, del.log)
-	if err != nil {
+	var request slowModeRequest
+	if err := pkgHTTP.ReadRequest(r, del.log, &request); err != nil {
 		return err
 	}
-
-	vote := models.Vote{}
-	if err := vote.UnmarshalJSON(body); err != nil {
+	if request.Seconds < 0 || asOwner && request.Nickname == "" {
 		return pkgErrors.ErrParseJSON
 	}
 
-	thread, err := del.serv.AddVote(slugOrId, &vote)
-	if err != nil {
+	owner := ""
+	if asOwner {
+		owner = request.Nickname
+		mw.SetUser(w, owner)
+	}
+	if err := del.serv.SetSlowMode(r.Context(), slugOrId, owner, request.Seconds); err != nil {
+		return err
+	}
+
+	return pkgHTTP.WriteResponse(w, r, http.StatusOK, &slowModeResponse{Seconds: request.Seconds})
+}
+
+func (del *delivery) SetLocked(w http.ResponseWriter, r *http.Request, p httprouter.Params) error {
+	slugOrId := p.ByName("slug_or_id")
+
+	var request lockRequest
+	if err := pkgHTTP.ReadRequest(r, del.log, &request); err != nil {
 		return err
 	}
 
-	data, err := thread.MarshalJSON()
+	thread, err := del.serv.SetLocked(r.Context(), slugOrId, request.Locked)
 	if err != nil {
-		return pkgErrors.ErrInternal
+		return err
 	}
 
-	_, err = w.Write(data)
+	return pkgHTTP.WriteResponse(w, r, http.StatusOK, &thread)
+}
+
+func (del *delivery) MoveThread(w http.ResponseWriter, r *http.Request, p httprouter.Params) error {
+	slugOrId := p.ByName("slug_or_id")
+
+	var request moveRequest
+	if err := pkgHTTP.ReadRequest(r, del.log, &request); err != nil {
+		return err
+	}
+	if request.Forum == "" {
+		return pkgErrors.ErrParseJSON
+	}
+
+	thread, err := del.serv.MoveThread(r.Context(), slugOrId, request.Forum)
 	if err != nil {
-		return pkgErrors.ErrInternal
+		return err
 	}
-	return nil
+
+	return pkgHTTP.WriteResponse(w, r, http.StatusOK, &thread)
 }
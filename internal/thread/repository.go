@@ -1,17 +1,24 @@
 package thread
 
 import (
+	"context"
+
 	"github.com/SlavaShagalov/vk-dbms-project/internal/models"
 )
 
 type Repository interface {
-	CreatePosts(slugOrId string, posts []models.Post) ([]models.Post, error)
-	GetThread(slugOrId string) (models.Thread, error)
-	UpdateThread(slugOrId string, thread *models.Thread) (models.Thread, error)
-	GetPostsFlat(slugOrId string, limit, since int, desc bool) (models.PostList, error)
-	GetPostsTree(slugOrId string, limit, since int, desc bool) (models.PostList, error)
-	GetPostsParentTree(slugOrId string, limit, since int, desc bool) (models.PostList, error)
-	AddVote(thread *models.Thread, vote *models.Vote) (models.Thread, error)
-	GetVote(thread *models.Thread, vote *models.Vote) (models.Vote, error)
-	UpdateVote(slugOrId string, thread *models.Thread, vote *models.Vote) (models.Thread, error)
+	CreatePosts(ctx context.Context, slugOrId string, posts []models.Post) ([]models.Post, error)
+	GetThread(ctx context.Context, slugOrId string) (models.Thread, error)
+	UpdateThread(ctx context.Context, slugOrId string, thread *models.Thread) (models.Thread, error)
+	GetPostsFlat(ctx context.Context, slugOrId string, limit, since int, desc bool) (models.PostList, error)
+	GetPostsTree(ctx context.Context, slugOrId string, limit, since int, desc bool) (models.PostList, error)
+	GetPostsParentTree(ctx context.Context, slugOrId string, limit, since int, desc bool) (models.PostList, error)
+	AddVote(ctx context.Context, thread *models.Thread, vote *models.Vote) (models.Thread, error)
+	GetVote(ctx context.Context, thread *models.Thread, vote *models.Vote) (models.Vote, error)
+	UpdateVote(ctx context.Context, slugOrId string, thread *models.Thread, vote *models.Vote) (models.Thread, error)
+	// SetSlowMode fails with ErrNotForumOwner unless owner is the forum user,
+	// moderators pass an empty owner.
+	SetSlowMode(ctx context.Context, thread *models.Thread, owner string, seconds int) error
+	SetLocked(ctx context.Context, thread *models.Thread, locked bool) error
+	MoveThread(ctx context.Context, thread *models.Thread, forum string) (models.Thread, error)
 }
@@ -0,0 +1,31 @@
+package cache
+
+import (
+	"time"
+
+	"github.com/SlavaShagalov/vk-dbms-project/internal/models"
+	pkgCache "github.com/SlavaShagalov/vk-dbms-project/internal/pkg/cache"
+)
+
+//go:generate easyjson -all -snake_case entry.go
+
+// entry is a thread in Redis, with the row version the API form leaves out.
+type entry struct {
+	Thread    models.Thread `json:"thread"`
+	UpdatedAt time.Time     `json:"updated_at"`
+}
+
+var Codec = pkgCache.Codec[models.Thread]{
+	Marshal: func(thread *models.Thread) ([]byte, error) {
+		return entry{Thread: *thread, UpdatedAt: thread.UpdatedAt}.MarshalJSON()
+	},
+	Unmarshal: func(data []byte, thread *models.Thread) error {
+		var e entry
+		if err := e.UnmarshalJSON(data); err != nil {
+			return err
+		}
+		*thread = e.Thread
+		thread.UpdatedAt = e.UpdatedAt
+		return nil
+	},
+}
@@ -0,0 +1,94 @@
+// Code generated by easyjson for marshaling/unmarshaling. DO NOT EDIT.
+
+package cache
+
+import (
+	json "encoding/json"
+	easyjson "github.com/mailru/easyjson"
+	jlexer "github.com/mailru/easyjson/jlexer"
+	jwriter "github.com/mailru/easyjson/jwriter"
+)
+
+// suppress unused package warning
+var (
+	_ *json.RawMessage
+	_ *jlexer.Lexer
+	_ *jwriter.Writer
+	_ easyjson.Marshaler
+)
+
+func easyjson5f4debdaDecodeGithubComSlavaShagalovVkDbmsProjectInternalThreadRepositoryCache(in *jlexer.Lexer, out *entry) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "thread":
+			(out.Thread).UnmarshalEasyJSON(in)
+		case "updated_at":
+			if data := in.Raw(); in.Ok() {
+				in.AddError((out.UpdatedAt).UnmarshalJSON(data))
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjson5f4debdaEncodeGithubComSlavaShagalovVkDbmsProjectInternalThreadRepositoryCache(out *jwriter.Writer, in entry) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"thread\":"
+		out.RawString(prefix[1:])
+		(in.Thread).MarshalEasyJSON(out)
+	}
+	{
+		const prefix string = ",\"updated_at\":"
+		out.RawString(prefix)
+		out.Raw((in.UpdatedAt).MarshalJSON())
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v entry) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjson5f4debdaEncodeGithubComSlavaShagalovVkDbmsProjectInternalThreadRepositoryCache(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v entry) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjson5f4debdaEncodeGithubComSlavaShagalovVkDbmsProjectInternalThreadRepositoryCache(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *entry) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjson5f4debdaDecodeGithubComSlavaShagalovVkDbmsProjectInternalThreadRepositoryCache(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *entry) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjson5f4debdaDecodeGithubComSlavaShagalovVkDbmsProjectInternalThreadRepositoryCache(l, v)
+}
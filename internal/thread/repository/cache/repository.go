@@ -0,0 +1,113 @@
+package cache
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/SlavaShagalov/vk-dbms-project/internal/models"
+	pkgCache "github.com/SlavaShagalov/vk-dbms-project/internal/pkg/cache"
+	pkgThread "github.com/SlavaShagalov/vk-dbms-project/internal/thread"
+)
+
+type repository struct {
+	rep   pkgThread.Repository
+	cache *pkgCache.Cache[models.Thread]
+}
+
+// NewRepository wraps rep and serves GetThread from the cache. Writes drop the
+// cached thread, and the forum entry when they change forum counters.
+func NewRepository(rep pkgThread.Repository, cache *pkgCache.Cache[models.Thread]) pkgThread.Repository {
+	return &repository{rep: rep, cache: cache}
+}
+
+func threadKeys(thread *models.Thread) []string {
+	keys := []string{pkgCache.ThreadKey(thread.Id)}
+	if thread.Slug != "" {
+		keys = append(keys, pkgCache.ThreadSlugKey(thread.Slug))
+	}
+	return keys
+}
+
+func (rep *repository) CreatePosts(ctx context.Context, slugOrId string, posts []models.Post) ([]models.Post, error) {
+	created, err := rep.rep.CreatePosts(ctx, slugOrId, posts)
+	if err == nil && len(created) > 0 {
+		rep.cache.Delete(ctx, pkgCache.ForumKey(created[0].Forum))
+	}
+	return created, err
+}
+
+func (rep *repository) GetThread(ctx context.Context, slugOrId string) (models.Thread, error) {
+	key := pkgCache.ThreadSlugKey(slugOrId)
+	if id, err := strconv.Atoi(slugOrId); err == nil {
+		key = pkgCache.ThreadKey(id)
+	}
+
+	var thread models.Thread
+	version, ok := rep.cache.Get(ctx, key, &thread)
+	if ok {
+		return thread, nil
+	}
+	thread, err := rep.rep.GetThread(ctx, slugOrId)
+	if err == nil {
+		rep.cache.Set(ctx, key, version, &thread)
+	}
+	return thread, err
+}
+
+func (rep *repository) UpdateThread(ctx context.Context, slugOrId string, thread *models.Thread) (models.Thread, error) {
+	updated, err := rep.rep.UpdateThread(ctx, slugOrId, thread)
+	if err == nil {
+		rep.cache.Delete(ctx, threadKeys(&updated)...)
+	}
+	return updated, err
+}
+
+func (rep *repository) GetPostsFlat(ctx context.Context, slugOrId string, limit, since int, desc bool) (models.PostList, error) {
+	return rep.rep.GetPostsFlat(ctx, slugOrId, limit, since, desc)
+}
+
+func (rep *repository) GetPostsTree(ctx context.Context, slugOrId string, limit, since int, desc bool) (models.PostList, error) {
+	return rep.rep.GetPostsTree(ctx, slugOrId, limit, since, desc)
+}
+
+func (rep *repository) GetPostsParentTree(ctx context.Context, slugOrId string, limit, since int, desc bool) (models.PostList, error) {
+	return rep.rep.GetPostsParentTree(ctx, slugOrId, limit, since, desc)
+}
+
+// AddVote returns the votes the database counted, thread may come from the cache.
+func (rep *repository) AddVote(ctx context.Context, thread *models.Thread, vote *models.Vote) (models.Thread, error) {
+	updated, err := rep.rep.AddVote(ctx, thread, vote)
+	if err == nil {
+		rep.cache.Delete(ctx, threadKeys(thread)...)
+	}
+	return updated, err
+}
+
+func (rep *repository) GetVote(ctx context.Context, thread *models.Thread, vote *models.Vote) (models.Vote, error) {
+	return rep.rep.GetVote(ctx, thread, vote)
+}
+
+func (rep *repository) UpdateVote(ctx context.Context, slugOrId string, thread *models.Thread, vote *models.Vote) (models.Thread, error) {
+	updated, err := rep.rep.UpdateVote(ctx, slugOrId, thread, vote)
+	if err == nil {
+		rep.cache.Delete(ctx, threadKeys(thread)...)
+	}
+	return updated, err
+}
+
+// SetSlowMode and SetLocked change columns the cached thread does not hold.
+func (rep *repository) SetSlowMode(ctx context.Context, thread *models.Thread, owner string, seconds int) error {
+	return rep.rep.SetSlowMode(ctx, thread, owner, seconds)
+}
+
+func (rep *repository) SetLocked(ctx context.Context, thread *models.Thread, locked bool) error {
+	return rep.rep.SetLocked(ctx, thread, locked)
+}
+
+func (rep *repository) MoveThread(ctx context.Context, thread *models.Thread, forum string) (models.Thread, error) {
+	moved, err := rep.rep.MoveThread(ctx, thread, forum)
+	if err == nil {
+		rep.cache.Delete(ctx, append(threadKeys(thread), pkgCache.ForumKey(thread.Forum), pkgCache.ForumKey(moved.Forum))...)
+	}
+	return moved, err
+}
@@ -0,0 +1,399 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/SlavaShagalov/vk-dbms-project/internal/models"
+	pkgErrors "github.com/SlavaShagalov/vk-dbms-project/internal/pkg/errors"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/memory"
+	pkgThread "github.com/SlavaShagalov/vk-dbms-project/internal/thread"
+)
+
+type repository struct {
+	store *memory.Store
+}
+
+func NewRepository(store *memory.Store) pkgThread.Repository {
+	return &repository{store: store}
+}
+
+// CreatePosts validates the whole batch before inserting it, so a failed call
+// leaves no posts behind, like the single INSERT of the pgx repository.
+func (rep *repository) CreatePosts(_ context.Context, slugOrId string, posts []models.Post) ([]models.Post, error) {
+	rep.store.Lock()
+	defer rep.store.Unlock()
+
+	result := make([]models.Post, 0)
+
+	thread, ok := rep.store.Thread(slugOrId)
+	if !ok {
+		return result, pkgErrors.ErrThreadNotFound
+	}
+
+	if len(posts) == 0 {
+		return result, nil
+	}
+
+	created := time.Unix(0, time.Now().UnixNano()/1e6*1e6)
+	for _, post := range posts {
+		if _, ok := rep.store.Users[memory.Key(post.Author)]; !ok {
+			return result, pkgErrors.ErrUserNotFound
+		}
+		if post.Parent != 0 {
+			parent, ok := rep.store.Posts[post.Parent]
+			if !ok || parent.Thread != thread.Id {
+				return result, pkgErrors.ErrParentPostNotFound
+			}
+		}
+	}
+
+	if thread.Locked {
+		return []models.Post{}, pkgErrors.ErrThreadLocked
+	}
+	if thread.SlowMode > 0 {
+		// The batch counts too: like the pgx trigger, an author may post once per window.
+		window := created.Add(-time.Duration(thread.SlowMode) * time.Second)
+		authors := make(map[string]struct{}, len(posts))
+		for _, post := range posts {
+			key := memory.Key(post.Author)
+			if _, ok := authors[key]; ok {
+				return []models.Post{}, pkgErrors.ErrSlowMode
+			}
+			authors[key] = struct{}{}
+			for _, existing := range thread.Posts {
+				if memory.Key(existing.Author) == key && existing.Created.After(window) {
+					return []models.Post{}, pkgErrors.ErrSlowMode
+				}
+			}
+		}
+	}
+
+	forum := rep.store.Forums[memory.Key(thread.Forum)]
+	for _, post := range posts {
+		inserted := &memory.Post{Post: models.Post{
+			Id:      rep.store.NextPostID(),
+			Parent:  post.Parent,
+			Author:  post.Author,
+			Message: post.Message,
+			Forum:   thread.Forum,
+			Thread:  thread.Id,
+			Created: created,
+		}}
+		inserted.UpdatedAt = rep.store.Version()
+		if inserted.Parent != 0 {
+			parentPath := rep.store.Posts[inserted.Parent].Path
+			inserted.Path = make([]int, len(parentPath), len(parentPath)+1)
+			copy(inserted.Path, parentPath)
+		}
+		inserted.Path = append(inserted.Path, inserted.Id)
+
+		rep.store.Posts[inserted.Id] = inserted
+		thread.Posts = append(thread.Posts, inserted)
+		if forum != nil {
+			forum.Posts++
+		}
+		rep.store.AddForumUser(thread.Forum, inserted.Author)
+
+		result = append(result, inserted.Post)
+	}
+	if forum != nil && len(posts) > 0 {
+		forum.UpdatedAt = rep.store.Version()
+	}
+	return result, nil
+}
+
+func (rep *repository) GetThread(_ context.Context, slugOrId string) (models.Thread, error) {
+	rep.store.RLock()
+	defer rep.store.RUnlock()
+
+	thread, ok := rep.store.Thread(slugOrId)
+	if !ok {
+		return models.Thread{}, pkgErrors.ErrThreadNotFound
+	}
+	return thread.Thread, nil
+}
+
+func (rep *repository) UpdateThread(_ context.Context, slugOrId string, thread *models.Thread) (models.Thread, error) {
+	rep.store.Lock()
+	defer rep.store.Unlock()
+
+	tmp, ok := rep.store.Thread(slugOrId)
+	if !ok {
+		return models.Thread{}, pkgErrors.ErrThreadNotFound
+	}
+	before := tmp.Thread
+	if strings.Trim(thread.Message, " ") != "" {
+		tmp.Message = thread.Message
+	}
+	if strings.Trim(thread.Title, " ") != "" {
+		tmp.Title = thread.Title
+	}
+	if tmp.Thread != before {
+		tmp.UpdatedAt = rep.store.Version()
+	}
+	return tmp.Thread, nil
+}
+
+func (rep *repository) GetPostsFlat(_ context.Context, slugOrId string, limit, since int, desc bool) (models.PostList, error) {
+	rep.store.RLock()
+	defer rep.store.RUnlock()
+
+	thread, ok := rep.store.Thread(slugOrId)
+	if !ok {
+		return []models.Post{}, pkgErrors.ErrThreadNotFound
+	}
+
+	posts := make([]*memory.Post, 0, len(thread.Posts))
+	for _, post := range thread.Posts {
+		if desc && since != 0 && post.Id >= since || !desc && post.Id <= since {
+			continue
+		}
+		posts = append(posts, post)
+	}
+	sort.SliceStable(posts, func(i, j int) bool {
+		if !posts[i].Created.Equal(posts[j].Created) {
+			return posts[i].Created.Before(posts[j].Created) != desc
+		}
+		return posts[i].Id < posts[j].Id != desc
+	})
+	return page(posts, limit), nil
+}
+
+func (rep *repository) GetPostsTree(_ context.Context, slugOrId string, limit, since int, desc bool) (models.PostList, error) {
+	rep.store.RLock()
+	defer rep.store.RUnlock()
+
+	thread, ok := rep.store.Thread(slugOrId)
+	if !ok {
+		return []models.Post{}, pkgErrors.ErrThreadNotFound
+	}
+
+	var sincePath []int
+	if since != 0 {
+		// A missing since post compares as NULL in SQL and matches nothing.
+		sincePost, ok := rep.store.Posts[since]
+		if !ok {
+			return []models.Post{}, nil
+		}
+		sincePath = sincePost.Path
+	}
+
+	posts := make([]*memory.Post, 0, len(thread.Posts))
+	for _, post := range thread.Posts {
+		if sincePath != nil {
+			cmp := memory.ComparePaths(post.Path, sincePath)
+			if desc && cmp >= 0 || !desc && cmp <= 0 {
+				continue
+			}
+		}
+		posts = append(posts, post)
+	}
+	sort.SliceStable(posts, func(i, j int) bool {
+		return memory.ComparePaths(posts[i].Path, posts[j].Path) < 0 != desc
+	})
+	return page(posts, limit), nil
+}
+
+func (rep *repository) GetPostsParentTree(_ context.Context, slugOrId string, limit, since int, desc bool) (models.PostList, error) {
+	rep.store.RLock()
+	defer rep.store.RUnlock()
+
+	thread, ok := rep.store.Thread(slugOrId)
+	if !ok {
+		return []models.Post{}, pkgErrors.ErrThreadNotFound
+	}
+
+	sinceRoot := 0
+	if since != 0 {
+		sincePost, ok := rep.store.Posts[since]
+		if !ok {
+			return []models.Post{}, nil
+		}
+		sinceRoot = sincePost.Path[0]
+	}
+
+	roots := make([]int, 0)
+	for _, post := range thread.Posts {
+		if post.Parent != 0 {
+			continue
+		}
+		if sinceRoot != 0 && (desc && post.Id >= sinceRoot || !desc && post.Id <= sinceRoot) {
+			continue
+		}
+		roots = append(roots, post.Id)
+	}
+	sort.Slice(roots, func(i, j int) bool { return roots[i] < roots[j] != desc })
+	if len(roots) > limit {
+		roots = roots[:limit]
+	}
+
+	selected := make(map[int]bool, len(roots))
+	for _, root := range roots {
+		selected[root] = true
+	}
+	posts := make([]*memory.Post, 0)
+	for _, post := range thread.Posts {
+		if selected[post.Path[0]] {
+			posts = append(posts, post)
+		}
+	}
+	// Root branches follow the requested order, posts inside a branch are
+	// always in ascending path order.
+	sort.SliceStable(posts, func(i, j int) bool {
+		if posts[i].Path[0] != posts[j].Path[0] {
+			return posts[i].Path[0] < posts[j].Path[0] != desc
+		}
+		return memory.ComparePaths(posts[i].Path, posts[j].Path) < 0
+	})
+	return postModels(posts), nil
+}
+
+func (rep *repository) GetVote(_ context.Context, thread *models.Thread, vote *models.Vote) (models.Vote, error) {
+	rep.store.RLock()
+	defer rep.store.RUnlock()
+
+	tmp := *vote
+	voice, ok := rep.store.Votes[memory.VoteKey{Thread: thread.Id, Nickname: memory.Key(vote.Nickname)}]
+	if !ok {
+		return tmp, pkgErrors.ErrVoiceNotFound
+	}
+	tmp.Voice = voice
+	return tmp, nil
+}
+
+func (rep *repository) AddVote(_ context.Context, thread *models.Thread, vote *models.Vote) (models.Thread, error) {
+	rep.store.Lock()
+	defer rep.store.Unlock()
+
+	key := memory.VoteKey{Thread: thread.Id, Nickname: memory.Key(vote.Nickname)}
+	if _, ok := rep.store.Votes[key]; ok {
+		return models.Thread{}, pkgErrors.ErrVoiceAlreadyExists
+	}
+	if _, ok := rep.store.Users[key.Nickname]; !ok {
+		return models.Thread{}, pkgErrors.ErrUserNotFound
+	}
+	stored, ok := rep.store.Threads[thread.Id]
+	if !ok {
+		return models.Thread{}, pkgErrors.ErrThreadNotFound
+	}
+
+	rep.store.Votes[key] = vote.Voice
+	if vote.Voice != 0 {
+		stored.Votes += vote.Voice
+		stored.UpdatedAt = rep.store.Version()
+	}
+	return stored.Thread, nil
+}
+
+func (rep *repository) UpdateVote(_ context.Context, slugOrId string, thread *models.Thread, vote *models.Vote) (models.Thread, error) {
+	rep.store.Lock()
+	defer rep.store.Unlock()
+
+	key := memory.VoteKey{Thread: thread.Id, Nickname: memory.Key(vote.Nickname)}
+	if voice, ok := rep.store.Votes[key]; ok && voice != vote.Voice {
+		rep.store.Votes[key] = vote.Voice
+		if stored, ok := rep.store.Threads[thread.Id]; ok {
+			stored.Votes += vote.Voice - voice
+			stored.UpdatedAt = rep.store.Version()
+		}
+	}
+
+	stored, ok := rep.store.Thread(slugOrId)
+	if !ok {
+		return models.Thread{}, pkgErrors.ErrThreadNotFound
+	}
+	return stored.Thread, nil
+}
+
+func (rep *repository) SetSlowMode(_ context.Context, thread *models.Thread, owner string, seconds int) error {
+	rep.store.Lock()
+	defer rep.store.Unlock()
+
+	stored, ok := rep.store.Threads[thread.Id]
+	if !ok {
+		return pkgErrors.ErrThreadNotFound
+	}
+	if owner != "" {
+		forum, ok := rep.store.Forums[memory.Key(stored.Forum)]
+		if !ok || memory.Key(forum.User) != memory.Key(owner) {
+			return pkgErrors.ErrNotForumOwner
+		}
+	}
+	if stored.SlowMode != seconds {
+		stored.SlowMode = seconds
+		stored.UpdatedAt = rep.store.Version()
+	}
+	return nil
+}
+
+func (rep *repository) SetLocked(_ context.Context, thread *models.Thread, locked bool) error {
+	rep.store.Lock()
+	defer rep.store.Unlock()
+
+	stored, ok := rep.store.Threads[thread.Id]
+	if !ok {
+		return pkgErrors.ErrThreadNotFound
+	}
+	if stored.Locked != locked {
+		stored.Locked = locked
+		stored.UpdatedAt = rep.store.Version()
+	}
+	return nil
+}
+
+// MoveThread moves the thread with its posts to another forum. Users stay
+// listed in the old forum until it is recounted.
+func (rep *repository) MoveThread(_ context.Context, thread *models.Thread, forum string) (models.Thread, error) {
+	rep.store.Lock()
+	defer rep.store.Unlock()
+
+	target, ok := rep.store.Forums[memory.Key(forum)]
+	if !ok {
+		return models.Thread{}, pkgErrors.ErrForumNotFound
+	}
+	stored, ok := rep.store.Threads[thread.Id]
+	if !ok {
+		return models.Thread{}, pkgErrors.ErrThreadNotFound
+	}
+	if memory.Key(target.Slug) == memory.Key(stored.Forum) {
+		return stored.Thread, nil
+	}
+
+	version := rep.store.Version()
+	if source, ok := rep.store.Forums[memory.Key(stored.Forum)]; ok {
+		source.Threads--
+		source.Posts -= int64(len(stored.Posts))
+		source.UpdatedAt = version
+	}
+	target.Threads++
+	target.Posts += int64(len(stored.Posts))
+	target.UpdatedAt = version
+
+	stored.Forum = target.Slug
+	stored.UpdatedAt = version
+	rep.store.AddForumUser(target.Slug, stored.Author)
+	for _, post := range stored.Posts {
+		post.Forum = target.Slug
+		post.UpdatedAt = version
+		rep.store.AddForumUser(target.Slug, post.Author)
+	}
+	return stored.Thread, nil
+}
+
+func page(posts []*memory.Post, limit int) models.PostList {
+	if len(posts) > limit {
+		posts = posts[:limit]
+	}
+	return postModels(posts)
+}
+
+func postModels(posts []*memory.Post) models.PostList {
+	result := make([]models.Post, 0, len(posts))
+	for _, post := range posts {
+		result = append(result, post.Post)
+	}
+	return result
+}
@@ -0,0 +1,91 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/SlavaShagalov/vk-dbms-project/internal/models"
+	pkgMetrics "github.com/SlavaShagalov/vk-dbms-project/internal/pkg/metrics"
+	pkgThread "github.com/SlavaShagalov/vk-dbms-project/internal/thread"
+)
+
+const name = "thread"
+
+type repository struct {
+	rep pkgThread.Repository
+	m   *pkgMetrics.Metrics
+}
+
+// NewRepository wraps rep and records the duration of every call and the
+// number of created posts and votes.
+func NewRepository(rep pkgThread.Repository, m *pkgMetrics.Metrics) pkgThread.Repository {
+	return &repository{rep: rep, m: m}
+}
+
+func (rep *repository) CreatePosts(ctx context.Context, slugOrId string, posts []models.Post) (created []models.Post, err error) {
+	defer func(start time.Time) { rep.m.ObserveQuery(name, "CreatePosts", start, err) }(time.Now())
+	created, err = rep.rep.CreatePosts(ctx, slugOrId, posts)
+	if err == nil {
+		rep.m.AddCreated(pkgMetrics.EntityPost, len(created))
+	}
+	return created, err
+}
+
+func (rep *repository) GetThread(ctx context.Context, slugOrId string) (thread models.Thread, err error) {
+	defer func(start time.Time) { rep.m.ObserveQuery(name, "GetThread", start, err) }(time.Now())
+	return rep.rep.GetThread(ctx, slugOrId)
+}
+
+func (rep *repository) UpdateThread(ctx context.Context, slugOrId string, thread *models.Thread) (updated models.Thread, err error) {
+	defer func(start time.Time) { rep.m.ObserveQuery(name, "UpdateThread", start, err) }(time.Now())
+	return rep.rep.UpdateThread(ctx, slugOrId, thread)
+}
+
+func (rep *repository) GetPostsFlat(ctx context.Context, slugOrId string, limit, since int, desc bool) (posts models.PostList, err error) {
+	defer func(start time.Time) { rep.m.ObserveQuery(name, "GetPostsFlat", start, err) }(time.Now())
+	return rep.rep.GetPostsFlat(ctx, slugOrId, limit, since, desc)
+}
+
+func (rep *repository) GetPostsTree(ctx context.Context, slugOrId string, limit, since int, desc bool) (posts models.PostList, err error) {
+	defer func(start time.Time) { rep.m.ObserveQuery(name, "GetPostsTree", start, err) }(time.Now())
+	return rep.rep.GetPostsTree(ctx, slugOrId, limit, since, desc)
+}
+
+func (rep *repository) GetPostsParentTree(ctx context.Context, slugOrId string, limit, since int, desc bool) (posts models.PostList, err error) {
+	defer func(start time.Time) { rep.m.ObserveQuery(name, "GetPostsParentTree", start, err) }(time.Now())
+	return rep.rep.GetPostsParentTree(ctx, slugOrId, limit, since, desc)
+}
+
+func (rep *repository) AddVote(ctx context.Context, thread *models.Thread, vote *models.Vote) (updated models.Thread, err error) {
+	defer func(start time.Time) { rep.m.ObserveQuery(name, "AddVote", start, err) }(time.Now())
+	updated, err = rep.rep.AddVote(ctx, thread, vote)
+	if err == nil {
+		rep.m.AddCreated(pkgMetrics.EntityVote, 1)
+	}
+	return updated, err
+}
+
+func (rep *repository) GetVote(ctx context.Context, thread *models.Thread, vote *models.Vote) (found models.Vote, err error) {
+	defer func(start time.Time) { rep.m.ObserveQuery(name, "GetVote", start, err) }(time.Now())
+	return rep.rep.GetVote(ctx, thread, vote)
+}
+
+func (rep *repository) UpdateVote(ctx context.Context, slugOrId string, thread *models.Thread, vote *models.Vote) (updated models.Thread, err error) {
+	defer func(start time.Time) { rep.m.ObserveQuery(name, "UpdateVote", start, err) }(time.Now())
+	return rep.rep.UpdateVote(ctx, slugOrId, thread, vote)
+}
+
+func (rep *repository) SetSlowMode(ctx context.Context, thread *models.Thread, owner string, seconds int) (err error) {
+	defer func(start time.Time) { rep.m.ObserveQuery(name, "SetSlowMode", start, err) }(time.Now())
+	return rep.rep.SetSlowMode(ctx, thread, owner, seconds)
+}
+
+func (rep *repository) SetLocked(ctx context.Context, thread *models.Thread, locked bool) (err error) {
+	defer func(start time.Time) { rep.m.ObserveQuery(name, "SetLocked", start, err) }(time.Now())
+	return rep.rep.SetLocked(ctx, thread, locked)
+}
+
+func (rep *repository) MoveThread(ctx context.Context, thread *models.Thread, forum string) (moved models.Thread, err error) {
+	defer func(start time.Time) { rep.m.ObserveQuery(name, "MoveThread", start, err) }(time.Now())
+	return rep.rep.MoveThread(ctx, thread, forum)
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/constants"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/jackc/pgx/v5"
@@ -15,7 +16,9 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/SlavaShagalov/vk-dbms-project/internal/models"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/db"
 	pkgErrors "github.com/SlavaShagalov/vk-dbms-project/internal/pkg/errors"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/tracing"
 	pkgThread "github.com/SlavaShagalov/vk-dbms-project/internal/thread"
 )
 
@@ -42,10 +45,10 @@ SELECT id
 FROM posts
 WHERE id = $1 AND thread = $2;`
 
-func (rep *repository) CreatePosts(slugOrId string, posts []models.Post) ([]models.Post, error) {
+func (rep *repository) CreatePosts(ctx context.Context, slugOrId string, posts []models.Post) ([]models.Post, error) {
 	result := make([]models.Post, 0)
 
-	thread, err := rep.GetThread(slugOrId)
+	thread, err := rep.GetThread(ctx, slugOrId)
 	if err != nil {
 		return result, err
 	}
@@ -63,13 +66,13 @@ func (rep *repository) CreatePosts(slugOrId string, posts []models.Post) ([]mode
 	for ind, post := range posts {
 		tmpId := 0
 
-		row := rep.pool.QueryRow(context.Background(), checkPostAuthor, post.Author)
+		row := rep.pool.QueryRow(ctx, checkPostAuthor, post.Author)
 		if err := row.Scan(&tmpId); err != nil {
 			return result, pkgErrors.ErrUserNotFound
 		}
 
 		if post.Parent != 0 {
-			row = rep.pool.QueryRow(context.Background(), checkPostParent, post.Parent, thread.Id)
+			row = rep.pool.QueryRow(ctx, checkPostParent, post.Parent, thread.Id)
 			if err := row.Scan(&tmpId); err != nil {
 				return result, pkgErrors.ErrParentPostNotFound
 			}
@@ -83,65 +86,80 @@ func (rep *repository) CreatePosts(slugOrId string, posts []models.Post) ([]mode
 	}
 	cmd += " RETURNING id, parent, author, message, isEdited, forum, thread;"
 
-	rows, err := rep.pool.Query(context.Background(), cmd, args...)
+	rows, err := rep.pool.Query(ctx, cmd, args...)
 	if err != nil {
-		var pgErr *pgconn.PgError
-		if errors.As(err, &pgErr) {
-			if pgErr.Message == "Invalid parent" {
-				return []models.Post{}, pkgErrors.ErrParentPostNotFound
-			}
-			switch pgErr.ConstraintName {
-			case "posts_forum_fkey":
-				return []models.Post{}, pkgErrors.ErrForumNotFound
-			case "posts_thread_fkey":
-				return []models.Post{}, pkgErrors.ErrThreadNotFound
-			case "thread_check":
-				return []models.Post{}, pkgErrors.ErrThreadNotFound
-			case "posts_author_fkey":
-				return []models.Post{}, pkgErrors.ErrUserNotFound
-			}
-		}
-		rep.log.Error(constants.DBError, zap.Error(err))
-		return []models.Post{}, pkgErrors.ErrInternal
+		return []models.Post{}, rep.createPostsError(ctx, err)
 	}
+	defer rows.Close()
 
 	for rows.Next() {
-		if rows.Scan(&postTmp.Id, &postTmp.Parent, &postTmp.Author, &postTmp.Message, &postTmp.IsEdited, &postTmp.Forum, &postTmp.Thread); err != nil {
-			rep.log.Error(constants.DBError, zap.Error(err))
-			return []models.Post{}, pkgErrors.ErrInternal
+		if err := rows.Scan(&postTmp.Id, &postTmp.Parent, &postTmp.Author, &postTmp.Message, &postTmp.IsEdited, &postTmp.Forum, &postTmp.Thread); err != nil {
+			tracing.Log(ctx, rep.log).Error(constants.DBError, zap.Error(err))
+			return []models.Post{}, db.Error(ctx, err)
 		}
 		result = append(result, postTmp)
 	}
+	// Trigger and constraint errors of the insert arrive while reading the rows.
+	if err := rows.Err(); err != nil {
+		return []models.Post{}, rep.createPostsError(ctx, err)
+	}
 
 	return result, nil
 }
 
+func (rep *repository) createPostsError(ctx context.Context, err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		if pgErr.Message == "Invalid parent" {
+			return pkgErrors.ErrParentPostNotFound
+		}
+		if pgErr.Message == "Slow mode" {
+			return pkgErrors.ErrSlowMode
+		}
+		if pgErr.Message == "Thread locked" {
+			return pkgErrors.ErrThreadLocked
+		}
+		switch pgErr.ConstraintName {
+		case "posts_forum_fkey":
+			return pkgErrors.ErrForumNotFound
+		case "posts_thread_fkey":
+			return pkgErrors.ErrThreadNotFound
+		case "thread_check":
+			return pkgErrors.ErrThreadNotFound
+		case "posts_author_fkey":
+			return pkgErrors.ErrUserNotFound
+		}
+	}
+	tracing.Log(ctx, rep.log).Error(constants.DBError, zap.Error(err))
+	return db.Error(ctx, err)
+}
+
 const getThreadBySlugCmd = `
-SELECT  id, title, author, forum, message, slug, votes, created
+SELECT  id, title, author, forum, message, slug, votes, created, updated_at
 FROM threads
 WHERE slug = $1;`
 
 const getThreadByIdCmd = `
-SELECT  id, title, author, forum, message, slug, votes, created
+SELECT  id, title, author, forum, message, slug, votes, created, updated_at
 FROM threads
 WHERE id = $1;`
 
-func (rep *repository) GetThread(slugOrId string) (models.Thread, error) {
+func (rep *repository) GetThread(ctx context.Context, slugOrId string) (models.Thread, error) {
 	tmp := models.Thread{}
 	var row pgx.Row
 
 	if id, err := strconv.Atoi(slugOrId); err == nil {
-		row = rep.pool.QueryRow(context.Background(), getThreadByIdCmd, id)
+		row = rep.pool.QueryRow(ctx, getThreadByIdCmd, id)
 	} else {
-		row = rep.pool.QueryRow(context.Background(), getThreadBySlugCmd, slugOrId)
+		row = rep.pool.QueryRow(ctx, getThreadBySlugCmd, slugOrId)
 	}
 
-	if err := row.Scan(&tmp.Id, &tmp.Title, &tmp.Author, &tmp.Forum, &tmp.Message, &tmp.Slug, &tmp.Votes, &tmp.Created); err != nil {
+	if err := row.Scan(&tmp.Id, &tmp.Title, &tmp.Author, &tmp.Forum, &tmp.Message, &tmp.Slug, &tmp.Votes, &tmp.Created, &tmp.UpdatedAt); err != nil {
 		if errors.Is(pgx.ErrNoRows, err) {
 			return tmp, pkgErrors.ErrThreadNotFound
 		}
-		rep.log.Error(constants.DBError, zap.Error(err))
-		return tmp, pkgErrors.ErrInternal
+		tracing.Log(ctx, rep.log).Error(constants.DBError, zap.Error(err))
+		return tmp, db.Error(ctx, err)
 	}
 
 	return tmp, nil
@@ -161,22 +179,22 @@ SET message = case when trim($2) = '' then message else $2 end,
 WHERE slug = $1
 RETURNING  id, title, author, forum, message, slug, votes, created;`
 
-func (rep *repository) UpdateThread(slugOrId string, thread *models.Thread) (models.Thread, error) {
+func (rep *repository) UpdateThread(ctx context.Context, slugOrId string, thread *models.Thread) (models.Thread, error) {
 	tmp := models.Thread{}
 	var row pgx.Row
 
 	if id, err := strconv.Atoi(slugOrId); err == nil {
-		row = rep.pool.QueryRow(context.Background(), updateThreadByIdCmd, id, thread.Message, thread.Title)
+		row = rep.pool.QueryRow(ctx, updateThreadByIdCmd, id, thread.Message, thread.Title)
 	} else {
-		row = rep.pool.QueryRow(context.Background(), updateThreadBySlugCmd, slugOrId, thread.Message, thread.Title)
+		row = rep.pool.QueryRow(ctx, updateThreadBySlugCmd, slugOrId, thread.Message, thread.Title)
 	}
 
 	if err := row.Scan(&tmp.Id, &tmp.Title, &tmp.Author, &tmp.Forum, &tmp.Message, &tmp.Slug, &tmp.Votes, &tmp.Created); err != nil {
 		if errors.Is(pgx.ErrNoRows, err) {
 			return tmp, pkgErrors.ErrThreadNotFound
 		}
-		rep.log.Error("DB error", zap.Error(err))
-		return tmp, pkgErrors.ErrInternal
+		tracing.Log(ctx, rep.log).Error("DB error", zap.Error(err))
+		return tmp, db.Error(ctx, err)
 	}
 
 	return tmp, nil
@@ -203,43 +221,48 @@ WHERE thread = $1
 ORDER BY created DESC, id DESC 
 LIMIT $2;`
 
-func (rep *repository) GetPostsFlat(slugOrId string, limit, since int, desc bool) (models.PostList, error) {
+func (rep *repository) GetPostsFlat(ctx context.Context, slugOrId string, limit, since int, desc bool) (models.PostList, error) {
 	var rows pgx.Rows
 	var err error
 
 	tmp := make([]models.Post, 0)
 	post := models.Post{}
-	thread, err := rep.GetThread(slugOrId)
+	thread, err := rep.GetThread(ctx, slugOrId)
 	if err != nil {
 		return []models.Post{}, err
 	}
 
 	if desc {
 		if since != 0 {
-			rows, err = rep.pool.Query(context.Background(), getPostsDescWithSinceCmd, thread.Id, since, limit)
+			rows, err = rep.pool.Query(ctx, getPostsDescWithSinceCmd, thread.Id, since, limit)
 			if err != nil {
-				return tmp, pkgErrors.ErrInternal
+				return tmp, db.Error(ctx, err)
 			}
 		} else {
-			rows, err = rep.pool.Query(context.Background(), getPostsDescCmd, thread.Id, limit)
+			rows, err = rep.pool.Query(ctx, getPostsDescCmd, thread.Id, limit)
 			if err != nil {
-				return tmp, pkgErrors.ErrInternal
+				return tmp, db.Error(ctx, err)
 			}
 		}
 	} else {
-		rows, err = rep.pool.Query(context.Background(), getPostsAscCmd, thread.Id, since, limit)
+		rows, err = rep.pool.Query(ctx, getPostsAscCmd, thread.Id, since, limit)
 	}
 
 	if err != nil {
-		return tmp, pkgErrors.ErrInternal
+		return tmp, db.Error(ctx, err)
 	}
+	defer rows.Close()
 
 	for rows.Next() {
 		if err := rows.Scan(&post.Id, &post.Parent, &post.Author, &post.Message, &post.IsEdited, &post.Forum, &post.Thread, &post.Created); err != nil {
-			return tmp, pkgErrors.ErrInternal
+			return tmp, db.Error(ctx, err)
 		}
 		tmp = append(tmp, post)
 	}
+	if err := rows.Err(); err != nil {
+		tracing.Log(ctx, rep.log).Error(constants.DBError, zap.Error(err))
+		return tmp, db.Error(ctx, err)
+	}
 
 	return tmp, nil
 }
@@ -272,13 +295,13 @@ WHERE thread = $1 AND path < (SELECT path FROM posts WHERE id = $2)
 ORDER BY path DESC, id
 LIMIT $3;`
 
-func (rep *repository) GetPostsTree(slugOrId string, limit, since int, desc bool) (models.PostList, error) {
+func (rep *repository) GetPostsTree(ctx context.Context, slugOrId string, limit, since int, desc bool) (models.PostList, error) {
 	var rows pgx.Rows
 	var err error
 
 	tmp := make([]models.Post, 0)
 	post := models.Post{}
-	thread, err := rep.GetThread(slugOrId)
+	thread, err := rep.GetThread(ctx, slugOrId)
 	if err != nil {
 		return []models.Post{}, err
 	}
@@ -301,21 +324,26 @@ func (rep *repository) GetPostsTree(slugOrId string, limit, since int, desc bool
 	}
 
 	if since != 0 {
-		rows, err = rep.pool.Query(context.Background(), cmd, thread.Id, since, limit)
+		rows, err = rep.pool.Query(ctx, cmd, thread.Id, since, limit)
 	} else {
-		rows, err = rep.pool.Query(context.Background(), cmd, thread.Id, limit)
+		rows, err = rep.pool.Query(ctx, cmd, thread.Id, limit)
 	}
 
 	if err != nil {
-		return tmp, pkgErrors.ErrInternal
+		return tmp, db.Error(ctx, err)
 	}
+	defer rows.Close()
 
 	for rows.Next() {
 		if err := rows.Scan(&post.Id, &post.Parent, &post.Author, &post.Message, &post.IsEdited, &post.Forum, &post.Thread, &post.Created); err != nil {
-			return tmp, pkgErrors.ErrInternal
+			return tmp, db.Error(ctx, err)
 		}
 		tmp = append(tmp, post)
 	}
+	if err := rows.Err(); err != nil {
+		tracing.Log(ctx, rep.log).Error(constants.DBError, zap.Error(err))
+		return tmp, db.Error(ctx, err)
+	}
 
 	return tmp, nil
 }
@@ -344,13 +372,13 @@ WHERE path[1] IN (SELECT id FROM posts WHERE thread = $1 AND parent = 0 AND path
 (SELECT path[1] FROM posts WHERE id = $2) ORDER BY id DESC LIMIT $3)
 ORDER BY path[1] DESC, path, id;`
 
-func (rep *repository) GetPostsParentTree(slugOrId string, limit, since int, desc bool) (models.PostList, error) {
+func (rep *repository) GetPostsParentTree(ctx context.Context, slugOrId string, limit, since int, desc bool) (models.PostList, error) {
 	var rows pgx.Rows
 	var err error
 
 	tmp := make([]models.Post, 0)
 	post := models.Post{}
-	thread, err := rep.GetThread(slugOrId)
+	thread, err := rep.GetThread(ctx, slugOrId)
 	if err != nil {
 		return []models.Post{}, err
 	}
@@ -373,22 +401,27 @@ func (rep *repository) GetPostsParentTree(slugOrId string, limit, since int, des
 	}
 
 	if since != 0 {
-		rows, err = rep.pool.Query(context.Background(), cmd, thread.Id, since, limit)
+		rows, err = rep.pool.Query(ctx, cmd, thread.Id, since, limit)
 	} else {
-		rows, err = rep.pool.Query(context.Background(), cmd, thread.Id, limit)
+		rows, err = rep.pool.Query(ctx, cmd, thread.Id, limit)
 	}
 
 	if err != nil {
-		rep.log.Error("DB error", zap.Error(err))
-		return tmp, pkgErrors.ErrInternal
+		tracing.Log(ctx, rep.log).Error("DB error", zap.Error(err))
+		return tmp, db.Error(ctx, err)
 	}
+	defer rows.Close()
 
 	for rows.Next() {
 		if err := rows.Scan(&post.Id, &post.Parent, &post.Author, &post.Message, &post.IsEdited, &post.Forum, &post.Thread, &post.Created); err != nil {
-			return tmp, pkgErrors.ErrInternal
+			return tmp, db.Error(ctx, err)
 		}
 		tmp = append(tmp, post)
 	}
+	if err := rows.Err(); err != nil {
+		tracing.Log(ctx, rep.log).Error(constants.DBError, zap.Error(err))
+		return tmp, db.Error(ctx, err)
+	}
 
 	return tmp, nil
 }
@@ -398,14 +431,14 @@ SELECT voice
 FROM votes
 WHERE nickname = $1 AND thread = $2;`
 
-func (rep *repository) GetVote(thread *models.Thread, vote *models.Vote) (models.Vote, error) {
+func (rep *repository) GetVote(ctx context.Context, thread *models.Thread, vote *models.Vote) (models.Vote, error) {
 	tmp := *vote
-	row := rep.pool.QueryRow(context.Background(), getVoteCmd, vote.Nickname, thread.Id)
+	row := rep.pool.QueryRow(ctx, getVoteCmd, vote.Nickname, thread.Id)
 	if err := row.Scan(&tmp.Voice); err != nil {
 		if errors.Is(pgx.ErrNoRows, err) {
 			return tmp, pkgErrors.ErrVoiceNotFound
 		}
-		return tmp, pkgErrors.ErrInternal
+		return tmp, db.Error(ctx, err)
 	}
 	return tmp, nil
 }
@@ -416,8 +449,8 @@ INSERT INTO votes
 VALUES ($1, $2, $3)
 RETURNING id;`
 
-func (rep *repository) AddVote(thread *models.Thread, vote *models.Vote) (models.Thread, error) {
-	row := rep.pool.QueryRow(context.Background(), addVoteCmd, vote.Nickname, vote.Voice, thread.Id)
+func (rep *repository) AddVote(ctx context.Context, thread *models.Thread, vote *models.Vote) (models.Thread, error) {
+	row := rep.pool.QueryRow(ctx, addVoteCmd, vote.Nickname, vote.Voice, thread.Id)
 	id := 0
 
 	if err := row.Scan(&id); err != nil {
@@ -428,15 +461,14 @@ func (rep *repository) AddVote(thread *models.Thread, vote *models.Vote) (models
 				return models.Thread{}, pkgErrors.ErrVoiceAlreadyExists
 			case "votes_nickname_fkey":
 				return models.Thread{}, pkgErrors.ErrUserNotFound
-			default:
-				rep.log.Error("DB error", zap.Error(err))
-				return models.Thread{}, pkgErrors.ErrInternal
 			}
 		}
+		tracing.Log(ctx, rep.log).Error("DB error", zap.Error(err))
+		return models.Thread{}, db.Error(ctx, err)
 	}
 
-	thread.Votes += vote.Voice
-	return *thread, nil
+	// The trigger has counted the vote, thread may be a stale cached copy.
+	return rep.GetThread(ctx, strconv.Itoa(thread.Id))
 }
 
 const updateVoteCmd = `
@@ -445,15 +477,156 @@ SET voice = $1
 WHERE nickname = $2 AND thread = $3 AND voice != $1
 RETURNING id;`
 
-func (rep *repository) UpdateVote(slugOrId string, thread *models.Thread, vote *models.Vote) (models.Thread, error) {
-	row := rep.pool.QueryRow(context.Background(), updateVoteCmd, vote.Voice, vote.Nickname, thread.Id)
+func (rep *repository) UpdateVote(ctx context.Context, slugOrId string, thread *models.Thread, vote *models.Vote) (models.Thread, error) {
+	row := rep.pool.QueryRow(ctx, updateVoteCmd, vote.Voice, vote.Nickname, thread.Id)
 	id := 0
 	if err := row.Scan(&id); err != nil {
 		if !errors.Is(pgx.ErrNoRows, err) {
-			rep.log.Error("DB error", zap.Error(err))
-			return models.Thread{}, pkgErrors.ErrInternal
+			tracing.Log(ctx, rep.log).Error("DB error", zap.Error(err))
+			return models.Thread{}, db.Error(ctx, err)
+		}
+	}
+
+	return rep.GetThread(ctx, slugOrId)
+}
+
+const setSlowModeCmd = `
+UPDATE threads t
+SET slow_mode = $3
+FROM forums f
+WHERE t.id = $1 AND f.slug = t.forum AND ($2::text = '' OR f.user_nickname = $2::citext)
+RETURNING t.id;`
+
+func (rep *repository) SetSlowMode(ctx context.Context, thread *models.Thread, owner string, seconds int) error {
+	row := rep.pool.QueryRow(ctx, setSlowModeCmd, thread.Id, owner, seconds)
+	id := 0
+	if err := row.Scan(&id); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			if owner != "" {
+				return pkgErrors.ErrNotForumOwner
+			}
+			return pkgErrors.ErrThreadNotFound
+		}
+		tracing.Log(ctx, rep.log).Error(constants.DBError, zap.Error(err))
+		return db.Error(ctx, err)
+	}
+	return nil
+}
+
+const setLockedCmd = `
+UPDATE threads
+SET locked = $2
+WHERE id = $1;`
+
+func (rep *repository) SetLocked(ctx context.Context, thread *models.Thread, locked bool) error {
+	tag, err := rep.pool.Exec(ctx, setLockedCmd, thread.Id, locked)
+	if err != nil {
+		tracing.Log(ctx, rep.log).Error(constants.DBError, zap.Error(err))
+		return db.Error(ctx, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return pkgErrors.ErrThreadNotFound
+	}
+	return nil
+}
+
+const lockThreadCmd = `
+SELECT id, title, author, forum, message, slug, votes, created
+FROM threads
+WHERE id = $1
+FOR UPDATE;`
+
+const getForumSlugCmd = `
+SELECT slug
+FROM forums
+WHERE slug = $1;`
+
+const moveThreadCmd = `
+UPDATE threads
+SET forum = $2
+WHERE id = $1
+RETURNING id, title, author, forum, message, slug, votes, created;`
+
+const movePostsCmd = `
+UPDATE posts
+SET forum = $2
+WHERE thread = $1;`
+
+const moveForumCountersCmd = `
+UPDATE forums
+SET threads = threads + $2,
+	posts = posts + $3
+WHERE slug = $1;`
+
+const moveForumUsersCmd = `
+INSERT INTO forum_users (nickname, fullname, about, email, forum)
+SELECT u.nickname, u.fullname, u.about, u.email, $2
+FROM users u
+WHERE u.nickname IN (SELECT author FROM posts WHERE thread = $1
+                     UNION
+                     SELECT author FROM threads WHERE id = $1)
+ON CONFLICT DO NOTHING;`
+
+// MoveThread moves the thread with its posts to another forum and adjusts the
+// counters of both forums in one transaction. The thread row is locked first,
+// so the source forum comes from the database rather than the caller. Users
+// stay listed in the old forum until it is recounted.
+func (rep *repository) MoveThread(ctx context.Context, thread *models.Thread, forum string) (models.Thread, error) {
+	tmp := models.Thread{}
+
+	tx, err := rep.pool.Begin(ctx)
+	if err != nil {
+		tracing.Log(ctx, rep.log).Error(constants.DBError, zap.Error(err))
+		return tmp, db.Error(ctx, err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	row := tx.QueryRow(ctx, lockThreadCmd, thread.Id)
+	if err = row.Scan(&tmp.Id, &tmp.Title, &tmp.Author, &tmp.Forum, &tmp.Message, &tmp.Slug, &tmp.Votes, &tmp.Created); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return tmp, pkgErrors.ErrThreadNotFound
 		}
+		tracing.Log(ctx, rep.log).Error(constants.DBError, zap.Error(err))
+		return tmp, db.Error(ctx, err)
+	}
+	source := tmp.Forum
+
+	if err = tx.QueryRow(ctx, getForumSlugCmd, forum).Scan(&forum); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return tmp, pkgErrors.ErrForumNotFound
+		}
+		tracing.Log(ctx, rep.log).Error(constants.DBError, zap.Error(err))
+		return tmp, db.Error(ctx, err)
+	}
+	if strings.EqualFold(forum, source) {
+		return tmp, nil
 	}
 
-	return rep.GetThread(slugOrId)
+	row = tx.QueryRow(ctx, moveThreadCmd, thread.Id, forum)
+	if err = row.Scan(&tmp.Id, &tmp.Title, &tmp.Author, &tmp.Forum, &tmp.Message, &tmp.Slug, &tmp.Votes, &tmp.Created); err != nil {
+		tracing.Log(ctx, rep.log).Error(constants.DBError, zap.Error(err))
+		return tmp, db.Error(ctx, err)
+	}
+
+	tag, err := tx.Exec(ctx, movePostsCmd, thread.Id, forum)
+	if err != nil {
+		tracing.Log(ctx, rep.log).Error(constants.DBError, zap.Error(err))
+		return tmp, db.Error(ctx, err)
+	}
+	posts := tag.RowsAffected()
+
+	batch := &pgx.Batch{}
+	batch.Queue(moveForumCountersCmd, source, -1, -posts)
+	batch.Queue(moveForumCountersCmd, forum, 1, posts)
+	batch.Queue(moveForumUsersCmd, thread.Id, forum)
+	if err = tx.SendBatch(ctx, batch).Close(); err != nil {
+		tracing.Log(ctx, rep.log).Error(constants.DBError, zap.Error(err))
+		return tmp, db.Error(ctx, err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		tracing.Log(ctx, rep.log).Error(constants.DBError, zap.Error(err))
+		return tmp, db.Error(ctx, err)
+	}
+	return tmp, nil
 }
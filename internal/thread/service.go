@@ -1,13 +1,18 @@
 package thread
 
 import (
+	"context"
+
 	"github.com/SlavaShagalov/vk-dbms-project/internal/models"
 )
 
 type Service interface {
-	CreatePosts(slugOrId string, posts []models.Post) (models.PostList, error)
-	GetThread(slugOrId string) (models.Thread, error)
-	UpdateThread(slugOrId string, thread *models.Thread) (models.Thread, error)
-	GetPosts(slugOrId string, limit, since int, sort string, desc bool) (models.PostList, error)
-	AddVote(slugOrId string, vote *models.Vote) (models.Thread, error)
+	CreatePosts(ctx context.Context, slugOrId string, posts []models.Post) (models.PostList, error)
+	GetThread(ctx context.Context, slugOrId string) (models.Thread, error)
+	UpdateThread(ctx context.Context, slugOrId string, thread *models.Thread) (models.Thread, error)
+	GetPosts(ctx context.Context, slugOrId string, limit, since int, sort string, desc bool) (models.PostList, error)
+	AddVote(ctx context.Context, slugOrId string, vote *models.Vote) (models.Thread, error)
+	SetSlowMode(ctx context.Context, slugOrId string, owner string, seconds int) error
+	SetLocked(ctx context.Context, slugOrId string, locked bool) (models.Thread, error)
+	MoveThread(ctx context.Context, slugOrId string, forum string) (models.Thread, error)
 }
@@ -1,52 +1,116 @@
 package service
 
 import (
+	"context"
+
 	"github.com/SlavaShagalov/vk-dbms-project/internal/models"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/filter"
 	"github.com/SlavaShagalov/vk-dbms-project/internal/thread"
 	"go.uber.org/zap"
 )
 
 type service struct {
-	rep thread.Repository
-	log *zap.Logger
+	rep    thread.Repository
+	filter *filter.Chain
+	log    *zap.Logger
 }
 
-func NewService(rep thread.Repository, log *zap.Logger) thread.Service {
-	return &service{rep: rep, log: log}
+func NewService(rep thread.Repository, filter *filter.Chain, log *zap.Logger) thread.Service {
+	return &service{rep: rep, filter: filter, log: log}
 }
 
-func (serv *service) CreatePosts(slugOrId string, posts []models.Post) (models.PostList, error) {
-	return serv.rep.CreatePosts(slugOrId, posts)
+func (serv *service) CreatePosts(ctx context.Context, slugOrId string, posts []models.Post) (models.PostList, error) {
+	if serv.filter.Empty() || len(posts) == 0 {
+		return serv.rep.CreatePosts(ctx, slugOrId, posts)
+	}
+
+	thread, err := serv.rep.GetThread(ctx, slugOrId)
+	if err != nil {
+		return nil, err
+	}
+
+	contents := make([]filter.Content, 0, len(posts))
+	for _, post := range posts {
+		contents = append(contents, filter.Content{
+			Kind:    filter.KindPost,
+			Forum:   thread.Forum,
+			Thread:  thread.Id,
+			Author:  post.Author,
+			Message: post.Message,
+		})
+	}
+
+	err = serv.filter.Apply(ctx, contents, func(ctx context.Context) error {
+		_, err := serv.rep.CreatePosts(ctx, slugOrId, posts)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := serv.rep.CreatePosts(ctx, slugOrId, posts)
+	if err != nil {
+		return nil, err
+	}
+	serv.filter.Published(contents)
+	return created, nil
 }
 
-func (serv *service) GetThread(slugOrId string) (models.Thread, error) {
-	return serv.rep.GetThread(slugOrId)
+func (serv *service) GetThread(ctx context.Context, slugOrId string) (models.Thread, error) {
+	return serv.rep.GetThread(ctx, slugOrId)
 }
 
-func (serv *service) UpdateThread(slugOrId string, thread *models.Thread) (models.Thread, error) {
-	return serv.rep.UpdateThread(slugOrId, thread)
+func (serv *service) UpdateThread(ctx context.Context, slugOrId string, thread *models.Thread) (models.Thread, error) {
+	return serv.rep.UpdateThread(ctx, slugOrId, thread)
 }
 
-func (serv *service) GetPosts(slugOrId string, limit, since int, sort string, desc bool) (models.PostList, error) {
+func (serv *service) GetPosts(ctx context.Context, slugOrId string, limit, since int, sort string, desc bool) (models.PostList, error) {
 	switch sort {
 	case "tree":
-		return serv.rep.GetPostsTree(slugOrId, limit, since, desc)
+		return serv.rep.GetPostsTree(ctx, slugOrId, limit, since, desc)
 	case "parent_tree":
-		return serv.rep.GetPostsParentTree(slugOrId, limit, since, desc)
+		return serv.rep.GetPostsParentTree(ctx, slugOrId, limit, since, desc)
 	default:
-		return serv.rep.GetPostsFlat(slugOrId, limit, since, desc)
+		return serv.rep.GetPostsFlat(ctx, slugOrId, limit, since, desc)
 	}
 }
 
-func (serv *service) AddVote(slugOrId string, vote *models.Vote) (models.Thread, error) {
-	thread, err := serv.rep.GetThread(slugOrId)
+func (serv *service) AddVote(ctx context.Context, slugOrId string, vote *models.Vote) (models.Thread, error) {
+	thread, err := serv.rep.GetThread(ctx, slugOrId)
 	if err != nil {
 		return thread, err
 	}
 
-	if _, err := serv.rep.GetVote(&thread, vote); err == nil {
-		return serv.rep.UpdateVote(slugOrId, &thread, vote)
+	if _, err := serv.rep.GetVote(ctx, &thread, vote); err == nil {
+		return serv.rep.UpdateVote(ctx, slugOrId, &thread, vote)
 	} else {
-		return serv.rep.AddVote(&thread, vote)
+		return serv.rep.AddVote(ctx, &thread, vote)
+	}
+}
+
+func (serv *service) SetSlowMode(ctx context.Context, slugOrId string, owner string, seconds int) error {
+	thread, err := serv.rep.GetThread(ctx, slugOrId)
+	if err != nil {
+		return err
+	}
+	return serv.rep.SetSlowMode(ctx, &thread, owner, seconds)
+}
+
+func (serv *service) SetLocked(ctx context.Context, slugOrId string, locked bool) (models.Thread, error) {
+	thread, err := serv.rep.GetThread(ctx, slugOrId)
+	if err != nil {
+		return models.Thread{}, err
+	}
+	if err = serv.rep.SetLocked(ctx, &thread, locked); err != nil {
+		return models.Thread{}, err
+	}
+	return thread, nil
+}
+
+func (serv *service) MoveThread(ctx context.Context, slugOrId string, forum string) (models.Thread, error) {
+	thread, err := serv.rep.GetThread(ctx, slugOrId)
+	if err != nil {
+		return models.Thread{}, err
 	}
+	return serv.rep.MoveThread(ctx, &thread, forum)
 }
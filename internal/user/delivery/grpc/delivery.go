@@ -0,0 +1,71 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+
+	pkgErrors "github.com/SlavaShagalov/vk-dbms-project/internal/pkg/errors"
+	mw "github.com/SlavaShagalov/vk-dbms-project/internal/pkg/middleware"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/pb"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/ratelimit"
+	pkgUser "github.com/SlavaShagalov/vk-dbms-project/internal/user"
+)
+
+type delivery struct {
+	pb.UnimplementedUserServiceServer
+	serv pkgUser.Service
+	log  *zap.Logger
+}
+
+func RegisterHandlers(server *grpc.Server, logger *zap.Logger, serv pkgUser.Service) {
+	pb.RegisterUserServiceServer(server, &delivery{serv: serv, log: logger})
+}
+
+// Routes are the HTTP routes the methods mirror.
+var Routes = mw.GRPCRoutes{
+	pb.UserService_CreateUser_FullMethodName: {Route: "POST /api/user/:nickname/create", Group: ratelimit.GroupUsers},
+	pb.UserService_GetUser_FullMethodName:    {Route: "GET /api/user/:nickname/profile"},
+	pb.UserService_UpdateUser_FullMethodName: {Route: "POST /api/user/:nickname/profile", Group: ratelimit.GroupUsers},
+}
+
+func (del *delivery) CreateUser(ctx context.Context, request *pb.CreateUserRequest) (*pb.User, error) {
+	params := &pkgUser.CreateParams{
+		Nickname: request.GetNickname(),
+		Fullname: request.GetFullname(),
+		About:    request.GetAbout(),
+		Email:    request.GetEmail(),
+	}
+	users, err := del.serv.Create(ctx, params)
+	if err != nil {
+		if errors.Is(err, pkgErrors.ErrUserAlreadyExists) {
+			return nil, mw.GRPCConflict(err, pb.NewUsers(users))
+		}
+		return nil, err
+	}
+	return pb.NewUser(&users[0]), nil
+}
+
+func (del *delivery) GetUser(ctx context.Context, request *pb.GetUserRequest) (*pb.User, error) {
+	user, err := del.serv.GetByNickname(ctx, request.GetNickname())
+	if err != nil {
+		return nil, err
+	}
+	return pb.NewUser(user), nil
+}
+
+func (del *delivery) UpdateUser(ctx context.Context, request *pb.UpdateUserRequest) (*pb.User, error) {
+	params := &pkgUser.UpdateParams{
+		Nickname: request.GetNickname(),
+		Fullname: request.GetFullname(),
+		About:    request.GetAbout(),
+		Email:    request.GetEmail(),
+	}
+	user, err := del.serv.Update(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	return pb.NewUser(user), nil
+}
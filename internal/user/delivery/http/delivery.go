@@ -1,11 +1,13 @@
 package http
 
 import (
-	"context"
 	"errors"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/models"
 	pkgErrors "github.com/SlavaShagalov/vk-dbms-project/internal/pkg/errors"
 	pkgHTTP "github.com/SlavaShagalov/vk-dbms-project/internal/pkg/http"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/metrics"
 	mw "github.com/SlavaShagalov/vk-dbms-project/internal/pkg/middleware"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/ratelimit"
 	pkgUser "github.com/SlavaShagalov/vk-dbms-project/internal/user"
 	"go.uber.org/zap"
 	"net/http"
@@ -18,122 +20,72 @@ type delivery struct {
 	log  *zap.Logger
 }
 
-func RegisterHandlers(router *httprouter.Router, logger *zap.Logger, serv pkgUser.Service) {
+func RegisterHandlers(router *mw.Router, logger *zap.Logger, serv pkgUser.Service, limiter *ratelimit.Limiter, m *metrics.Metrics) {
 	del := delivery{serv, logger}
 
-	router.POST("/api/user/:nickname/create", mw.AccessLog(mw.HandleError(del.Create, logger), logger))
-	router.GET("/api/user/:nickname/profile", mw.AccessLog(mw.HandleError(del.GetByNickname, logger), logger))
-	router.POST("/api/user/:nickname/profile", mw.AccessLog(mw.HandleError(del.Update, logger), logger))
+	router.POST("/api/user/:nickname/create", mw.Trace(mw.Timeout(mw.AccessLog(mw.Metrics(mw.HandleError(mw.RateLimit(del.Create, limiter, ratelimit.GroupUsers), logger), m), logger))))
+	router.GET("/api/user/:nickname/profile", mw.Trace(mw.Timeout(mw.AccessLog(mw.Metrics(mw.Conditional(mw.HandleError(del.GetByNickname, logger)), m), logger))))
+	router.POST("/api/user/:nickname/profile", mw.Trace(mw.Timeout(mw.AccessLog(mw.Metrics(mw.HandleError(mw.RateLimit(del.Update, limiter, ratelimit.GroupUsers), logger), m), logger))))
 }
 
 func (del *delivery) Create(w http.ResponseWriter, r *http.Request, p httprouter.Params) error {
 	nickname := p.ByName("nickname")
+	mw.SetUser(w, nickname)
 
-	body, err := pkgHTTP.ReadBody(r, del.log)
-	if err != nil {
+	var request models.User
+	if err := pkgHTTP.ReadRequest(r, del.log, &request); err != nil {
 		return err
 	}
 
-	var request createRequest
-	err = request.UnmarshalJSON(body)
-	if err != nil {
-		// TODO: log error
-		return pkgErrors.ErrParseJSON
-	}
-
 	params := &pkgUser.CreateParams{
 		Nickname: nickname,
 		Fullname: request.Fullname,
 		About:    request.About,
 		Email:    request.Email,
 	}
-	users, err := del.serv.Create(context.Background(), params)
+	users, err := del.serv.Create(r.Context(), params)
 	if err != nil {
 		if errors.Is(err, pkgErrors.ErrUserAlreadyExists) {
-			response := newCreateAlreadyExistsResponse(users)
-			data, err := response.MarshalJSON()
-			if err != nil {
-				return pkgErrors.ErrInternal
-			}
-
-			w.WriteHeader(http.StatusConflict)
-			_, err = w.Write(data)
-			if err != nil {
-				return pkgErrors.ErrInternal
-			}
-			return nil
+			return pkgHTTP.WriteResponse(w, r, http.StatusConflict, models.UserList(users))
 		}
 		return err
 	}
 
-	response := newCreateResponse(&users[0])
-	data, err := response.MarshalJSON()
-	if err != nil {
-		return pkgErrors.ErrInternal
-	}
-
-	w.WriteHeader(http.StatusCreated)
-	_, err = w.Write(data)
-	if err != nil {
-		return pkgErrors.ErrInternal
-	}
-	return nil
+	return pkgHTTP.WriteResponse(w, r, http.StatusCreated, &users[0])
 }
 
-func (del *delivery) GetByNickname(w http.ResponseWriter, _ *http.Request, p httprouter.Params) error {
+func (del *delivery) GetByNickname(w http.ResponseWriter, r *http.Request, p httprouter.Params) error {
 	nickname := p.ByName("nickname")
-	user, err := del.serv.GetByNickname(context.Background(), nickname)
+	user, err := del.serv.GetByNickname(r.Context(), nickname)
 	if err != nil {
 		return err
 	}
-
-	response := newGetResponse(user)
-	data, err := response.MarshalJSON()
-	if err != nil {
-		return pkgErrors.ErrInternal
+	if pkgHTTP.NotModified(w, r, user.UpdatedAt) {
+		return nil
 	}
 
-	_, err = w.Write(data)
-	if err != nil {
-		return pkgErrors.ErrInternal
-	}
-	return nil
+	return pkgHTTP.WriteResponse(w, r, http.StatusOK, user)
 }
 
 func (del *delivery) Update(w http.ResponseWriter, r *http.Request, p httprouter.Params) error {
 	nickname := p.ByName("nickname")
+	mw.SetUser(w, nickname)
 
-	body, err := pkgHTTP.ReadBody(r, del.log)
-	if err != nil {
+	var request models.User
+	if err := pkgHTTP.ReadRequest(r, del.log, &request); err != nil {
 		return err
 	}
 
-	var request updateRequest
-	err = request.UnmarshalJSON(body)
-	if err != nil {
-		return pkgErrors.ErrParseJSON
-	}
-
 	params := &pkgUser.UpdateParams{
 		Nickname: nickname,
 		Fullname: request.Fullname,
 		About:    request.About,
 		Email:    request.Email,
 	}
-	user, err := del.serv.Update(context.Background(), params)
+	user, err := del.serv.Update(r.Context(), params)
 	if err != nil {
 		return err
 	}
 
-	response := newUpdateResponse(user)
-	data, err := response.MarshalJSON()
-	if err != nil {
-		return pkgErrors.ErrInternal
-	}
-
-	_, err = w.Write(data)
-	if err != nil {
-		return pkgErrors.ErrInternal
-	}
-	return nil
+	return pkgHTTP.WriteResponse(w, r, http.StatusOK, user)
 }
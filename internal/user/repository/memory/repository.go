@@ -0,0 +1,111 @@
+package memory
+
+import (
+	"context"
+	"strings"
+
+	"github.com/SlavaShagalov/vk-dbms-project/internal/models"
+	pkgErrors "github.com/SlavaShagalov/vk-dbms-project/internal/pkg/errors"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/memory"
+	pkgUser "github.com/SlavaShagalov/vk-dbms-project/internal/user"
+)
+
+type repository struct {
+	store *memory.Store
+}
+
+func NewRepository(store *memory.Store) pkgUser.Repository {
+	return &repository{store: store}
+}
+
+func (rep *repository) Create(_ context.Context, params *pkgUser.CreateParams) ([]models.User, error) {
+	rep.store.Lock()
+	defer rep.store.Unlock()
+
+	byNickname, nicknameTaken := rep.store.Users[memory.Key(params.Nickname)]
+	byEmail, emailTaken := rep.store.Emails[memory.Key(params.Email)]
+	if nicknameTaken || emailTaken {
+		users := make([]models.User, 0, 2)
+		if nicknameTaken {
+			users = append(users, *byNickname)
+		}
+		if emailTaken && (!nicknameTaken || byEmail.ID != byNickname.ID) {
+			users = append(users, *byEmail)
+		}
+		return users, pkgErrors.ErrUserAlreadyExists
+	}
+
+	user := &models.User{
+		ID:       rep.store.NextUserID(),
+		Nickname: params.Nickname,
+		Fullname: params.Fullname,
+		About:    params.About,
+		Email:    params.Email,
+	}
+	user.UpdatedAt = rep.store.Version()
+	rep.store.Users[memory.Key(user.Nickname)] = user
+	rep.store.Emails[memory.Key(user.Email)] = user
+	return []models.User{*user}, nil
+}
+
+func (rep *repository) GetByNickname(_ context.Context, nickname string) (*models.User, error) {
+	rep.store.RLock()
+	defer rep.store.RUnlock()
+
+	user, ok := rep.store.Users[memory.Key(nickname)]
+	if !ok {
+		return new(models.User), pkgErrors.ErrUserNotFound
+	}
+	tmp := *user
+	return &tmp, nil
+}
+
+func (rep *repository) GetByEmail(_ context.Context, email string) (*models.User, error) {
+	rep.store.RLock()
+	defer rep.store.RUnlock()
+
+	user, ok := rep.store.Emails[memory.Key(email)]
+	if !ok {
+		return new(models.User), pkgErrors.ErrUserNotFound
+	}
+	tmp := *user
+	return &tmp, nil
+}
+
+func (rep *repository) Update(_ context.Context, params *pkgUser.UpdateParams) (*models.User, error) {
+	rep.store.Lock()
+	defer rep.store.Unlock()
+
+	user, ok := rep.store.Users[memory.Key(params.Nickname)]
+	if !ok {
+		return nil, pkgErrors.ErrUserNotFound
+	}
+	before := *user
+
+	if trim(params.Email) != "" {
+		if other, ok := rep.store.Emails[memory.Key(params.Email)]; ok && other.ID != user.ID {
+			tmp := *user
+			return &tmp, pkgErrors.ErrUserAlreadyExists
+		}
+		delete(rep.store.Emails, memory.Key(user.Email))
+		user.Email = params.Email
+		rep.store.Emails[memory.Key(user.Email)] = user
+	}
+	if trim(params.Fullname) != "" {
+		user.Fullname = params.Fullname
+	}
+	if trim(params.About) != "" {
+		user.About = params.About
+	}
+	if *user != before {
+		user.UpdatedAt = rep.store.Version()
+	}
+
+	tmp := *user
+	return &tmp, nil
+}
+
+// trim matches the SQL trim(), which strips spaces only.
+func trim(s string) string {
+	return strings.Trim(s, " ")
+}
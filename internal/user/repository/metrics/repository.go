@@ -0,0 +1,42 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/SlavaShagalov/vk-dbms-project/internal/models"
+	pkgMetrics "github.com/SlavaShagalov/vk-dbms-project/internal/pkg/metrics"
+	pkgUser "github.com/SlavaShagalov/vk-dbms-project/internal/user"
+)
+
+const name = "user"
+
+type repository struct {
+	rep pkgUser.Repository
+	m   *pkgMetrics.Metrics
+}
+
+// NewRepository wraps rep and records the duration of every call.
+func NewRepository(rep pkgUser.Repository, m *pkgMetrics.Metrics) pkgUser.Repository {
+	return &repository{rep: rep, m: m}
+}
+
+func (rep *repository) Create(ctx context.Context, params *pkgUser.CreateParams) (users []models.User, err error) {
+	defer func(start time.Time) { rep.m.ObserveQuery(name, "Create", start, err) }(time.Now())
+	return rep.rep.Create(ctx, params)
+}
+
+func (rep *repository) GetByNickname(ctx context.Context, nickname string) (user *models.User, err error) {
+	defer func(start time.Time) { rep.m.ObserveQuery(name, "GetByNickname", start, err) }(time.Now())
+	return rep.rep.GetByNickname(ctx, nickname)
+}
+
+func (rep *repository) GetByEmail(ctx context.Context, email string) (user *models.User, err error) {
+	defer func(start time.Time) { rep.m.ObserveQuery(name, "GetByEmail", start, err) }(time.Now())
+	return rep.rep.GetByEmail(ctx, email)
+}
+
+func (rep *repository) Update(ctx context.Context, params *pkgUser.UpdateParams) (user *models.User, err error) {
+	defer func(start time.Time) { rep.m.ObserveQuery(name, "Update", start, err) }(time.Now())
+	return rep.rep.Update(ctx, params)
+}
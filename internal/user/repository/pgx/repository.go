@@ -12,7 +12,9 @@ import (
 
 	"github.com/SlavaShagalov/vk-dbms-project/internal/models"
 	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/constants"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/db"
 	pkgErrors "github.com/SlavaShagalov/vk-dbms-project/internal/pkg/errors"
+	"github.com/SlavaShagalov/vk-dbms-project/internal/pkg/tracing"
 	pkgUser "github.com/SlavaShagalov/vk-dbms-project/internal/user"
 )
 
@@ -55,9 +57,9 @@ func (rep *repository) Create(ctx context.Context, params *pkgUser.CreateParams)
 				}
 				return users, pkgErrors.ErrUserAlreadyExists
 			} else {
-				rep.log.Error(constants.DBError, zap.Error(err), zap.String("cmd", createCmd),
+				tracing.Log(ctx, rep.log).Error(constants.DBError, zap.Error(err), zap.String("cmd", createCmd),
 					zap.Any("params", params))
-				return nil, pkgErrors.ErrInternal
+				return nil, db.Error(ctx, err)
 			}
 		}
 	}
@@ -66,7 +68,7 @@ func (rep *repository) Create(ctx context.Context, params *pkgUser.CreateParams)
 }
 
 const getByNicknameCmd = `
-SELECT id, nickname, fullname, about, email
+SELECT id, nickname, fullname, about, email, updated_at
 FROM users
 WHERE nickname = $1;`
 
@@ -74,13 +76,13 @@ func (rep *repository) GetByNickname(ctx context.Context, nickname string) (*mod
 	row := rep.pool.QueryRow(ctx, getByNicknameCmd, nickname)
 
 	user := new(models.User)
-	if err := row.Scan(&user.ID, &user.Nickname, &user.Fullname, &user.About, &user.Email); err != nil {
+	if err := row.Scan(&user.ID, &user.Nickname, &user.Fullname, &user.About, &user.Email, &user.UpdatedAt); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return user, pkgErrors.ErrUserNotFound
 		} else {
-			rep.log.Error(constants.DBError, zap.Error(err), zap.String("cmd", getByNicknameCmd),
+			tracing.Log(ctx, rep.log).Error(constants.DBError, zap.Error(err), zap.String("cmd", getByNicknameCmd),
 				zap.String("nickname", nickname))
-			return user, pkgErrors.ErrInternal
+			return user, db.Error(ctx, err)
 		}
 	}
 
@@ -100,9 +102,9 @@ func (rep *repository) GetByEmail(ctx context.Context, email string) (*models.Us
 		if errors.Is(err, pgx.ErrNoRows) {
 			return user, pkgErrors.ErrUserNotFound
 		} else {
-			rep.log.Error(constants.DBError, zap.Error(err), zap.String("cmd", getByEmailCmd),
+			tracing.Log(ctx, rep.log).Error(constants.DBError, zap.Error(err), zap.String("cmd", getByEmailCmd),
 				zap.String("email", email))
-			return user, pkgErrors.ErrInternal
+			return user, db.Error(ctx, err)
 		}
 	}
 
@@ -132,9 +134,9 @@ func (rep *repository) Update(ctx context.Context, params *pkgUser.UpdateParams)
 				user, _ = rep.GetByNickname(ctx, params.Nickname)
 				return user, pkgErrors.ErrUserAlreadyExists
 			} else {
-				rep.log.Error(constants.DBError, zap.Error(err), zap.String("cmd", updateCmd),
+				tracing.Log(ctx, rep.log).Error(constants.DBError, zap.Error(err), zap.String("cmd", updateCmd),
 					zap.Any("params", params))
-				return user, pkgErrors.ErrInternal
+				return user, db.Error(ctx, err)
 			}
 		}
 	}